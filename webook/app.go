@@ -3,9 +3,11 @@ package main
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/events"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/job"
 )
 
 type App struct {
 	web       *gin.Engine
 	consumers []events.Consumer
+	jobs      []job.Job
 }
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/search"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/job"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao"
+	"github.com/xiaoshanjiang/my-geektime/webook/ioc"
+)
+
+// 子命令和 Web 服务用同一个配置文件，比如说：
+//
+//	webook replay-dlq --config config/dev.yaml --topic article_published
+//	webook reconcile-interactive --config config/dev.yaml --repair
+//	webook gen-jwt-key --alg EdDSA
+//	webook rebuild-search --config config/dev.yaml
+var commands = map[string]func(ctx context.Context) error{
+	"replay-dlq":            replayDeadLetters,
+	"reconcile-interactive": reconcileInteractive,
+	"gen-jwt-key":           genJWTKey,
+	"rebuild-search":        rebuildSearch,
+}
+
+// 子命令的参数，要在 pflag.Parse 之前定义
+var (
+	replayTopic = pflag.String("topic", "", "replay-dlq：把这个 topic 的死信发回这个 topic")
+	repair      = pflag.Bool("repair", false, "reconcile-interactive：修复不一致的计数，不带的话只报告")
+	jwtKeyAlg   = pflag.String("alg", "HS512", "gen-jwt-key：HS512、RS256 或者 EdDSA")
+)
+
+func runCommand(name string) {
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "未知的命令 %s\n", name)
+		os.Exit(2)
+	}
+	if err := cmd(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "执行 %s 失败：%v\n", name, err)
+		os.Exit(1)
+	}
+}
+
+func replayDeadLetters(ctx context.Context) error {
+	if *replayTopic == "" {
+		return errors.New("缺少 --topic")
+	}
+	replayer := ioc.InitDeadLetterReplayer(ioc.InitLogger())
+	cnt, err := replayer.Replay(ctx, *replayTopic)
+	fmt.Printf("重放了 %d 条死信\n", cnt)
+	return err
+}
+
+func reconcileInteractive(ctx context.Context) error {
+	l := ioc.InitLogger()
+	r := job.NewInteractiveReconciler(
+		dao.NewGORMInteractiveDAO(ioc.InitDB(l)),
+		cache.NewRedisInteractiveCache(ioc.InitRedis()), l)
+	res, err := r.Run(ctx, *repair, func(d job.InteractiveDrift) {
+		fmt.Printf("%s %d：点赞 %d -> %d，收藏 %d -> %d，修复：%t\n",
+			d.Biz, d.BizId, d.LikeCnt, d.RealLikeCnt,
+			d.CollectCnt, d.RealCollectCnt, d.Repaired)
+	})
+	fmt.Printf("扫描了 %d 条，不一致 %d 条，修复了 %d 条\n",
+		res.Scanned, res.Drifted, res.Repaired)
+	return err
+}
+
+// rebuildSearch 搜索索引在每个实例的内存里面，所以这里只是发一条消息，
+// 每个实例收到之后从线上库重建自己的索引，看各个实例的日志确认重建完成
+func rebuildSearch(ctx context.Context) error {
+	if viper.GetString("events.type") == "memory" {
+		return errors.New("memory 模式下别的进程收不到消息，给 Web 服务发送 SIGHUP 来重建")
+	}
+	producer := ioc.NewSyncProducer(ioc.InitBroker())
+	defer producer.Close()
+	err := search.ProduceRebuildEvent(producer)
+	if err == nil {
+		fmt.Println("已经通知所有实例重建搜索索引")
+	}
+	return err
+}
+
+// genJWTKey 生成一把新的 JWT 密钥，把输出加到配置的 jwt.access.keys 或者 jwt.refresh.keys 里面。
+// 等所有实例都加载了之后，再把 signer 改成它
+func genJWTKey(ctx context.Context) error {
+	var secret string
+	switch *jwtKeyAlg {
+	case "HS512":
+		key := make([]byte, 48)
+		_, err := rand.Read(key)
+		if err != nil {
+			return err
+		}
+		secret = fmt.Sprintf("%q", base64.RawURLEncoding.EncodeToString(key))
+	case "RS256", "EdDSA":
+		var key any
+		var err error
+		if *jwtKeyAlg == "RS256" {
+			key, err = rsa.GenerateKey(rand.Reader, 2048)
+		} else {
+			_, key, err = ed25519.GenerateKey(rand.Reader)
+		}
+		if err != nil {
+			return err
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return err
+		}
+		// YAML 的多行字符串，每一行都要缩进
+		block := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		secret = "|\n    " + strings.ReplaceAll(strings.TrimSpace(string(block)), "\n", "\n    ")
+	default:
+		return fmt.Errorf("不支持的算法 %s", *jwtKeyAlg)
+	}
+	fmt.Printf("- kid: \"v%s\"\n  alg: \"%s\"\n  secret: %s\n",
+		time.Now().Format("20060102150405"), *jwtKeyAlg, secret)
+	return nil
+}
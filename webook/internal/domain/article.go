@@ -8,8 +8,14 @@ type Article struct {
 	Content string
 	Author  Author
 	Status  ArticleStatus
-	Ctime   time.Time
-	Utime   time.Time
+	// PublishAt 定时发表的时间，零值代表立刻发表
+	PublishAt time.Time
+	// Category 分类，只能有一个
+	Category string
+	// Tags 标签，可以有多个
+	Tags  []string
+	Ctime time.Time
+	Utime time.Time
 }
 
 func (a Article) Abstract() string {
@@ -32,6 +38,8 @@ const (
 	ArticleStatusUnpublished
 	ArticleStatusPublished
 	ArticleStatusPrivate
+	// ArticleStatusScheduled 定时发表，到时间之后会变成 ArticleStatusPublished
+	ArticleStatusScheduled
 )
 
 func (s ArticleStatus) ToUint8() uint8 {
@@ -50,6 +58,8 @@ func (s ArticleStatus) String() string {
 		return "unpublished"
 	case ArticleStatusPublished:
 		return "published"
+	case ArticleStatusScheduled:
+		return "scheduled"
 	default:
 		return "unknown"
 	}
@@ -68,6 +78,12 @@ var (
 
 type ArticleStatusV2 string
 
+// Tag 标签以及带有这个标签的公开文章数量
+type Tag struct {
+	Name string
+	Cnt  int64
+}
+
 type Author struct {
 	Id   int64
 	Name string
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/textdiff"
+)
+
+// ArticleRevision 文章的历史版本。每一次保存都会追加一个，创建之后就不会再修改
+type ArticleRevision struct {
+	Id        int64
+	ArticleId int64
+	Title     string
+	Content   string
+	Status    ArticleStatus
+	Author    Author
+	Category  string
+	Tags      []string
+	Ctime     time.Time
+}
+
+func (r ArticleRevision) Abstract() string {
+	return Article{Content: r.Content}.Abstract()
+}
+
+type ArticleDiffMode string
+
+const (
+	ArticleDiffModeLine ArticleDiffMode = "line"
+	ArticleDiffModeWord ArticleDiffMode = "word"
+)
+
+// ArticleDiff 两个历史版本之间的差异
+type ArticleDiff struct {
+	From    ArticleRevision
+	To      ArticleRevision
+	Title   []textdiff.Edit
+	Content []textdiff.Edit
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// Comment 评论。评论挂在 biz + bizId 上，所以任何资源都可以评论。
+// 我们只支持两级：顶级评论和它下面的回复，
+// 回复的回复也归到同一个顶级评论下面，通过 ParentId 知道回复的是谁
+type Comment struct {
+	Id    int64
+	Biz   string
+	BizId int64
+	// 评论者
+	Uid int64
+	// RootId 顶级评论的 ID，为 0 说明自己就是顶级评论
+	RootId int64
+	// ParentId 回复的那条评论，顶级评论为 0
+	ParentId int64
+	Content  string
+	// ReplyCnt 回复数量，只有查询顶级评论的时候才有
+	ReplyCnt int64
+	Ctime    time.Time
+	Utime    time.Time
+}
+
+func (c Comment) IsRoot() bool {
+	return c.RootId == 0
+}
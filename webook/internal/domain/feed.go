@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// FeedItem feed 里面的一条，也就是关注的作者发表的一篇文章
+type FeedItem struct {
+	Aid int64
+	// 作者
+	Uid      int64
+	Title    string
+	Abstract string
+	// 发表时间
+	Ctime time.Time
+}
+
+// FeedCursor feed 的分页游标，按照发表时间倒序，同一毫秒的按照文章 ID 倒序。
+// 零值代表第一页
+type FeedCursor struct {
+	Ctime int64
+	Aid   int64
+}
+
+func (c FeedCursor) IsZero() bool {
+	return c.Ctime == 0 && c.Aid == 0
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// FollowRelation 关注关系，Follower 关注了 Followee
+type FollowRelation struct {
+	Follower int64
+	Followee int64
+	Ctime    time.Time
+}
+
+// FollowStatics 一个用户的关注数和粉丝数
+type FollowStatics struct {
+	// 粉丝数
+	Followers int64
+	// 关注数
+	Followees int64
+}
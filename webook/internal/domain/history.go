@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// UserBizRecord 用户和某个资源的一条记录，比如说阅读记录和点赞记录
+type UserBizRecord struct {
+	Uid   int64
+	Biz   string
+	BizId int64
+	// 阅读记录是最近一次阅读的时间，同一篇文章只保留一条；点赞记录是点赞的时间
+	Utime time.Time
+	// 列表页展示用的标题和摘要，文章撤回了的时候是空的
+	Title    string
+	Abstract string
+}
+
+// UserBizCursor 阅读记录和点赞列表的分页游标，按照时间倒序，同一毫秒的按照 BizId 倒序。
+// 零值代表第一页
+type UserBizCursor struct {
+	Utime int64
+	BizId int64
+}
+
+func (c UserBizCursor) IsZero() bool {
+	return c.Utime == 0 && c.BizId == 0
+}
@@ -1,25 +1,53 @@
 package domain
 
+import "time"
+
 // Interactive 这个是总体交互的计数
 type Interactive struct {
 	ReadCnt    int64 `json:"read_cnt"`
 	LikeCnt    int64 `json:"like_cnt"`
 	CollectCnt int64 `json:"collect_cnt"`
+	// 评论数，由评论模块维护
+	CommentCnt int64 `json:"comment_cnt"`
 	// 这个是当下这个资源，你有没有点赞或者收集
 	// 你也可以考虑把这两个字段分离出去，作为一个单独的结构体
 	Liked     bool `json:"liked"`
 	Collected bool `json:"collected"`
 }
 
+// InteractiveDelta 计数的增量，异步计数模式下由消费者合并之后更新
+type InteractiveDelta struct {
+	Biz        string
+	BizId      int64
+	LikeCnt    int64
+	CollectCnt int64
+}
+
 type Self struct {
 	Liked     bool `json:"liked"`
 	Collected bool `json:"collected"`
 }
 
+// Collection 收藏夹，Id 为 0 的是默认收藏夹，不在数据库里面
 type Collection struct {
-	Name  string
-	Uid   int64
-	Items []Resource
+	Id   int64
+	Name string
+	Uid  int64
+	// 收藏夹里面有多少个东西
+	ItemCnt int64
+	Ctime   time.Time
+	Utime   time.Time
+}
+
+// CollectionItem 收藏夹里面的一个东西
+type CollectionItem struct {
+	Cid   int64
+	Biz   string
+	BizId int64
+	// 文章的标题，文章撤回了或者不是文章的时候是空的
+	Title string
+	// 收藏的时间
+	Ctime time.Time
 }
 
 // max(发送者总速率/单一分区写入速率, 发送者总速率/单一消费者速率) + buffer
@@ -0,0 +1,18 @@
+package domain
+
+// ArticleSearchResult 一页搜索结果
+type ArticleSearchResult struct {
+	// Total 命中的文章总数
+	Total int
+	Hits  []ArticleSearchHit
+}
+
+type ArticleSearchHit struct {
+	// Article 只有 Id, Title, Content 和 Utime
+	Article Article
+	Score   float64
+	// Title 高亮之后的标题
+	Title string
+	// Snippet 高亮之后的摘要
+	Snippet string
+}
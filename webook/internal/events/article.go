@@ -0,0 +1,62 @@
+package events
+
+// 这里的事件会在 DAO 的事务里面写进发件箱，
+// 所以不能放在 events/article 里面，那边的消费者依赖了 repository，会循环引用
+
+// ArticleReadEvent 读者阅读文章
+type ArticleReadEvent struct {
+	Version int
+	Uid     int64
+	Aid     int64
+	// 阅读时间，毫秒数。老版本的生产者没有这个字段，消费者要自己兜底
+	Ctime int64
+}
+
+func (e *ArticleReadEvent) Upgrade() error {
+	return UpgradeVersion(&e.Version, ArticleReadVersion)
+}
+
+// ArticlePublishedEvent 文章第一次发表，或者撤回之后重新发表。
+// 和阅读事件不同，这里带上了标题和摘要，
+// 因为 feed 要把它们冗余存储下来，不然每次刷 feed 都要回查文章
+type ArticlePublishedEvent struct {
+	Version  int
+	Aid      int64
+	Uid      int64
+	Title    string
+	Abstract string
+	// 发表时间，毫秒数
+	Ctime int64
+}
+
+func (e *ArticlePublishedEvent) Upgrade() error {
+	return UpgradeVersion(&e.Version, ArticlePublishedVersion)
+}
+
+// ArticleWithdrawnEvent 文章撤回，改成了仅自己可见
+type ArticleWithdrawnEvent struct {
+	Version int
+	Aid     int64
+	Uid     int64
+	// 撤回时间，毫秒数
+	Ctime int64
+}
+
+func (e *ArticleWithdrawnEvent) Upgrade() error {
+	return UpgradeVersion(&e.Version, ArticleWithdrawnVersion)
+}
+
+// ArticleEditedEvent 已经发表的文章修改之后再次发表
+type ArticleEditedEvent struct {
+	Version  int
+	Aid      int64
+	Uid      int64
+	Title    string
+	Abstract string
+	// 修改时间，毫秒数
+	Utime int64
+}
+
+func (e *ArticleEditedEvent) Upgrade() error {
+	return UpgradeVersion(&e.Version, ArticleEditedVersion)
+}
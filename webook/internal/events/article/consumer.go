@@ -5,37 +5,40 @@ import (
 	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
 	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
 	"github.com/xiaoshanjiang/my-geektime/webook/pkg/saramax"
 )
 
 type InteractiveReadEventConsumer struct {
-	client sarama.Client
+	broker saramax.Broker
 	repo   repository.InteractiveRepository
 	l      logger.LoggerV1
 }
 
 func NewInteractiveReadEventConsumer(
-	client sarama.Client,
+	broker saramax.Broker,
 	l logger.LoggerV1,
 	repo repository.InteractiveRepository) *InteractiveReadEventConsumer {
 	return &InteractiveReadEventConsumer{
-		client: client,
+		broker: broker,
 		l:      l,
 		repo:   repo,
 	}
 }
 
 func (r *InteractiveReadEventConsumer) Start() error {
-	cg, err := sarama.NewConsumerGroupFromClient("interactive", r.client)
+	cg, err := r.broker.NewConsumerGroup("interactive")
 	if err != nil {
 		return err
 	}
 	go func() {
+		// 阅读事件有计数和阅读记录两个消费者组，重试 topic 又是共用的，转发过去 read_history 也会再处理一次。
+		// 所以这里不转发，批量处理失败就停止消费，不提交，再均衡或者重启之后从这一批重新消费
 		err := cg.Consume(context.Background(),
-			[]string{"article_read"},
-			saramax.NewHandler[ReadEvent](r.l, r.Consume))
+			[]string{TopicReadEvent},
+			saramax.NewBatchHandler[ReadEvent](r.l, r.BatchConsume))
 		if err != nil {
 			r.l.Error("退出了消费循环异常", logger.Error(err))
 		}
@@ -43,9 +46,20 @@ func (r *InteractiveReadEventConsumer) Start() error {
 	return err
 }
 
-// Consume 这个不是幂等的
-func (r *InteractiveReadEventConsumer) Consume(msg *sarama.ConsumerMessage, t ReadEvent) error {
+// BatchConsume 一批消息的计数合并成一个事务写进去
+// 数据库里面记录了每个 partition 处理到的偏移量，重复投递的消息会被跳过，所以是幂等的
+func (r *InteractiveReadEventConsumer) BatchConsume(msgs []*sarama.ConsumerMessage,
+	ts []ReadEvent) error {
+	offsets := make([]int64, 0, len(msgs))
+	bizs := make([]string, 0, len(ts))
+	bizIds := make([]int64, 0, len(ts))
+	for i, t := range ts {
+		offsets = append(offsets, msgs[i].Offset)
+		bizs = append(bizs, domain.BizArrticle)
+		bizIds = append(bizIds, t.Aid)
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
-	return r.repo.IncrReadCnt(ctx, "article", t.Aid)
+	return r.repo.BatchIncrReadCnt(ctx, msgs[0].Topic, msgs[0].Partition,
+		offsets, bizs, bizIds)
 }
@@ -0,0 +1,82 @@
+package article
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/saramax"
+)
+
+// HistoryReadEventConsumer 把阅读事件记成阅读记录。
+// 和计数用不同的消费者组，两边互不影响
+type HistoryReadEventConsumer struct {
+	broker saramax.Broker
+	repo   repository.ReadHistoryRepository
+	l      logger.LoggerV1
+}
+
+func NewHistoryReadEventConsumer(
+	broker saramax.Broker,
+	l logger.LoggerV1,
+	repo repository.ReadHistoryRepository) *HistoryReadEventConsumer {
+	return &HistoryReadEventConsumer{
+		broker: broker,
+		l:      l,
+		repo:   repo,
+	}
+}
+
+func (r *HistoryReadEventConsumer) Start() error {
+	cg, err := r.broker.NewConsumerGroup("read_history")
+	if err != nil {
+		return err
+	}
+	go func() {
+		// 阅读事件有计数和阅读记录两个消费者组，重试 topic 又是共用的，转发过去 interactive 也会再处理一次。
+		// 所以这里不转发，批量处理失败就停止消费，不提交，再均衡或者重启之后从这一批重新消费
+		err := cg.Consume(context.Background(),
+			[]string{TopicReadEvent},
+			saramax.NewBatchHandler[ReadEvent](r.l, r.BatchConsume))
+		if err != nil {
+			r.l.Error("退出了消费循环异常", logger.Error(err))
+		}
+	}()
+	return err
+}
+
+// BatchConsume 一批写进去。阅读记录只会把时间往后推，所以重复消费是幂等的
+func (r *HistoryReadEventConsumer) BatchConsume(msgs []*sarama.ConsumerMessage,
+	ts []ReadEvent) error {
+	hs := make([]domain.UserBizRecord, 0, len(ts))
+	for i, t := range ts {
+		// 没有登录的读者没有阅读记录
+		if t.Uid <= 0 {
+			continue
+		}
+		hs = append(hs, domain.UserBizRecord{
+			Uid:   t.Uid,
+			Biz:   domain.BizArrticle,
+			BizId: t.Aid,
+			Utime: r.readTime(msgs[i], t),
+		})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return r.repo.BatchAdd(ctx, hs)
+}
+
+// readTime 老版本的事件没有阅读时间，用消息的时间兜底
+func (r *HistoryReadEventConsumer) readTime(msg *sarama.ConsumerMessage, t ReadEvent) time.Time {
+	if t.Ctime > 0 {
+		return time.UnixMilli(t.Ctime)
+	}
+	if !msg.Timestamp.IsZero() {
+		return msg.Timestamp
+	}
+	return time.Now()
+}
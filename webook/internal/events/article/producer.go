@@ -5,8 +5,18 @@ import (
 	"encoding/json"
 
 	"github.com/IBM/sarama"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events"
+)
+
+const (
+	TopicReadEvent      = events.TopicArticleRead
+	TopicPublishedEvent = events.TopicArticlePublished
+	TopicWithdrawnEvent = events.TopicArticleWithdrawn
+	TopicEditedEvent    = events.TopicArticleEdited
 )
 
+//go:generate mockgen -source=./producer.go -package=evtmocks -destination=mocks/producer.mock.go Producer
 type Producer interface {
 	ProduceReadEvent(ctx context.Context, evt ReadEvent) error
 }
@@ -18,12 +28,13 @@ type KafkaProducer struct {
 // ProduceReadEvent 如果你有复杂的重试逻辑，就用装饰器
 // 你认为你的重试逻辑很简单，你就放这里
 func (k *KafkaProducer) ProduceReadEvent(ctx context.Context, evt ReadEvent) error {
+	evt.Version = events.ArticleReadVersion
 	data, err := json.Marshal(evt)
 	if err != nil {
 		return err
 	}
 	_, _, err = k.producer.SendMessage(&sarama.ProducerMessage{
-		Topic: "read_article",
+		Topic: events.TopicArticleRead,
 		Value: sarama.ByteEncoder(data),
 	})
 	return err
@@ -35,7 +46,13 @@ func NewKafkaProducer(pc sarama.SyncProducer) Producer {
 	}
 }
 
-type ReadEvent struct {
-	Uid int64
-	Aid int64
-}
+type ReadEvent = events.ArticleReadEvent
+
+// PublishedEvent 文章发表事件，在线上库的事务里面写进发件箱，具体看 GORMArticleDAO.Sync
+type PublishedEvent = events.ArticlePublishedEvent
+
+// WithdrawnEvent 文章撤回事件，和 PublishedEvent 一样走发件箱
+type WithdrawnEvent = events.ArticleWithdrawnEvent
+
+// EditedEvent 已经发表的文章再次发表，和 PublishedEvent 一样走发件箱
+type EditedEvent = events.ArticleEditedEvent
@@ -0,0 +1,163 @@
+package feed
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/article"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/follow"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/saramax"
+)
+
+// retryPolicy 立刻重试失败之后，经过重试 topic 再重试几轮，间隔越来越长，最后进入死信 topic
+func retryPolicy() saramax.RetryPolicy {
+	return saramax.NewExponentialBackoff(time.Second*10, time.Minute*10, 5)
+}
+
+// ArticlePublishedConsumer 文章发表之后写 feed
+type ArticlePublishedConsumer struct {
+	broker   saramax.Broker
+	producer sarama.SyncProducer
+	svc      service.FeedService
+	l        logger.LoggerV1
+}
+
+func NewArticlePublishedConsumer(broker saramax.Broker,
+	producer sarama.SyncProducer,
+	l logger.LoggerV1,
+	svc service.FeedService) *ArticlePublishedConsumer {
+	return &ArticlePublishedConsumer{
+		broker:   broker,
+		producer: producer,
+		svc:      svc,
+		l:        l,
+	}
+}
+
+func (c *ArticlePublishedConsumer) Start() error {
+	cg, err := c.broker.NewConsumerGroup("feed_article")
+	if err != nil {
+		return err
+	}
+	go func() {
+		er := cg.Consume(context.Background(),
+			[]string{article.TopicPublishedEvent, saramax.RetryTopic(article.TopicPublishedEvent)},
+			saramax.NewHandler[article.PublishedEvent](c.l, c.Consume).
+				WithDeadLetter(c.producer, retryPolicy()))
+		if er != nil {
+			c.l.Error("退出了消费循环异常", logger.Error(er))
+		}
+	}()
+	return nil
+}
+
+// Consume 是幂等的，重复消费会被唯一索引挡住
+func (c *ArticlePublishedConsumer) Consume(msg *sarama.ConsumerMessage, evt article.PublishedEvent) error {
+	// 要给所有粉丝写收件箱，所以超时时间长一点
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	return c.svc.PublishArticle(ctx, domain.FeedItem{
+		Aid:      evt.Aid,
+		Uid:      evt.Uid,
+		Title:    evt.Title,
+		Abstract: evt.Abstract,
+		Ctime:    time.UnixMilli(evt.Ctime),
+	})
+}
+
+// ArticleWithdrawnConsumer 文章撤回之后清理收件箱和发件箱
+type ArticleWithdrawnConsumer struct {
+	broker   saramax.Broker
+	producer sarama.SyncProducer
+	svc      service.FeedService
+	l        logger.LoggerV1
+}
+
+func NewArticleWithdrawnConsumer(broker saramax.Broker,
+	producer sarama.SyncProducer,
+	l logger.LoggerV1,
+	svc service.FeedService) *ArticleWithdrawnConsumer {
+	return &ArticleWithdrawnConsumer{
+		broker:   broker,
+		producer: producer,
+		svc:      svc,
+		l:        l,
+	}
+}
+
+func (c *ArticleWithdrawnConsumer) Start() error {
+	cg, err := c.broker.NewConsumerGroup("feed_article_withdrawn")
+	if err != nil {
+		return err
+	}
+	go func() {
+		er := cg.Consume(context.Background(),
+			[]string{article.TopicWithdrawnEvent, saramax.RetryTopic(article.TopicWithdrawnEvent)},
+			saramax.NewHandler[article.WithdrawnEvent](c.l, c.Consume).
+				WithDeadLetter(c.producer, retryPolicy()))
+		if er != nil {
+			c.l.Error("退出了消费循环异常", logger.Error(er))
+		}
+	}()
+	return nil
+}
+
+// Consume 删除本身就是幂等的。
+// 撤回之后重新发表会再发一个发表事件，那时候再写回 feed
+func (c *ArticleWithdrawnConsumer) Consume(msg *sarama.ConsumerMessage, evt article.WithdrawnEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	return c.svc.WithdrawArticle(ctx, evt.Aid)
+}
+
+// FollowEventConsumer 取消关注之后清理收件箱
+type FollowEventConsumer struct {
+	broker   saramax.Broker
+	producer sarama.SyncProducer
+	svc      service.FeedService
+	l        logger.LoggerV1
+}
+
+func NewFollowEventConsumer(broker saramax.Broker,
+	producer sarama.SyncProducer,
+	l logger.LoggerV1,
+	svc service.FeedService) *FollowEventConsumer {
+	return &FollowEventConsumer{
+		broker:   broker,
+		producer: producer,
+		svc:      svc,
+		l:        l,
+	}
+}
+
+func (c *FollowEventConsumer) Start() error {
+	cg, err := c.broker.NewConsumerGroup("feed_follow")
+	if err != nil {
+		return err
+	}
+	go func() {
+		er := cg.Consume(context.Background(),
+			[]string{follow.TopicFollowEvent, saramax.RetryTopic(follow.TopicFollowEvent)},
+			saramax.NewHandler[follow.FollowEvent](c.l, c.Consume).
+				WithDeadLetter(c.producer, retryPolicy()))
+		if er != nil {
+			c.l.Error("退出了消费循环异常", logger.Error(er))
+		}
+	}()
+	return nil
+}
+
+func (c *FollowEventConsumer) Consume(msg *sarama.ConsumerMessage, evt follow.FollowEvent) error {
+	if !evt.Cancel {
+		// 新关注的作者，只看得到之后发表的文章
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return c.svc.CancelFollow(ctx, evt.Follower, evt.Followee)
+}
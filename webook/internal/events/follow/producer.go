@@ -0,0 +1,51 @@
+package follow
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/IBM/sarama"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events"
+)
+
+const TopicFollowEvent = events.TopicFollowEvent
+
+type Producer interface {
+	ProduceFollowEvent(ctx context.Context, evt FollowEvent) error
+}
+
+type KafkaProducer struct {
+	producer sarama.SyncProducer
+}
+
+func NewKafkaProducer(pc sarama.SyncProducer) Producer {
+	return &KafkaProducer{
+		producer: pc,
+	}
+}
+
+func (k *KafkaProducer) ProduceFollowEvent(ctx context.Context, evt FollowEvent) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, _, err = k.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicFollowEvent,
+		// 同一个 follower 的事件落在同一个分区上，保证关注和取消关注的顺序
+		Key:   sarama.StringEncoder(strconv.FormatInt(evt.Follower, 10)),
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
+
+// FollowEvent 关注或者取消关注
+type FollowEvent struct {
+	Follower int64
+	Followee int64
+	// Cancel 为 true 的时候是取消关注
+	Cancel bool
+	// 毫秒数
+	Ctime int64
+}
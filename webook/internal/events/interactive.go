@@ -0,0 +1,20 @@
+package events
+
+// InteractiveCntEvent 异步计数模式下，点赞、取消点赞和收藏之后发出来，
+// 消费者合并一批事件之后再更新计数。
+// 和文章事件一样，在 DAO 的事务里面写进发件箱
+type InteractiveCntEvent struct {
+	Version int
+	Biz     string
+	BizId   int64
+	Uid     int64
+	// 计数的增量，取消的时候是 -1
+	LikeDelta    int64
+	CollectDelta int64
+	// 毫秒数
+	Ctime int64
+}
+
+func (e *InteractiveCntEvent) Upgrade() error {
+	return UpgradeVersion(&e.Version, InteractiveCntVersion)
+}
@@ -0,0 +1,81 @@
+package interactive
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/outbox"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/saramax"
+)
+
+const TopicCntEvent = events.TopicInteractiveCnt
+
+// CntEvent 点赞和收藏的计数事件，在 DAO 的事务里面写进发件箱，具体看 GORMInteractiveDAO.InsertLikeRelation
+type CntEvent = events.InteractiveCntEvent
+
+// retryPolicy 立刻重试失败之后，经过重试 topic 再重试几轮，间隔越来越长，最后进入死信 topic
+func retryPolicy() saramax.RetryPolicy {
+	return saramax.NewExponentialBackoff(time.Second*10, time.Minute*10, 5)
+}
+
+// CntEventConsumer 异步计数模式下，合并一批点赞和收藏事件，再更新数据库里面的计数
+type CntEventConsumer struct {
+	broker   saramax.Broker
+	producer sarama.SyncProducer
+	repo     repository.InteractiveRepository
+	l        logger.LoggerV1
+}
+
+func NewCntEventConsumer(broker saramax.Broker,
+	producer sarama.SyncProducer,
+	l logger.LoggerV1,
+	repo repository.InteractiveRepository) *CntEventConsumer {
+	return &CntEventConsumer{
+		broker:   broker,
+		producer: producer,
+		l:        l,
+		repo:     repo,
+	}
+}
+
+func (c *CntEventConsumer) Start() error {
+	cg, err := c.broker.NewConsumerGroup("interactive_cnt")
+	if err != nil {
+		return err
+	}
+	go func() {
+		// 转发到重试 topic 的时候发件箱的消息 ID 还在头部里面，重试的时候照样去重
+		er := cg.Consume(context.Background(),
+			[]string{TopicCntEvent, saramax.RetryTopic(TopicCntEvent)},
+			saramax.NewBatchHandler[CntEvent](c.l, c.BatchConsume).
+				WithDeadLetter(c.producer, retryPolicy()))
+		if er != nil {
+			c.l.Error("退出了消费循环异常", logger.Error(er))
+		}
+	}()
+	return nil
+}
+
+// BatchConsume 事件是从发件箱投递的，重复投递的 offset 不一样，所以按照发件箱的消息 ID 去重
+func (c *CntEventConsumer) BatchConsume(msgs []*sarama.ConsumerMessage, ts []CntEvent) error {
+	ids := make([]int64, 0, len(msgs))
+	deltas := make([]domain.InteractiveDelta, 0, len(ts))
+	for i, t := range ts {
+		ids = append(ids, outbox.MessageId(msgs[i]))
+		deltas = append(deltas, domain.InteractiveDelta{
+			Biz:        t.Biz,
+			BizId:      t.BizId,
+			LikeCnt:    t.LikeDelta,
+			CollectCnt: t.CollectDelta,
+		})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return c.repo.BatchIncrCnt(ctx, ids, deltas)
+}
@@ -0,0 +1,91 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/saramax"
+)
+
+// ArticleEvent 发表、修改和撤回事件里面，更新索引只需要文章 ID，
+// 每个版本的事件都有 Aid，所以三个 topic 用同一个结构体反序列化
+type ArticleEvent struct {
+	Aid int64
+}
+
+// ArticleEventConsumer 每个实例都有自己的内存索引，所以每个实例都要收到全部的事件。
+// 每个实例单独一个消费者组，名字带上配置的实例标识，重启之后还是同一个消费者组，从上次提交的地方继续。
+// 第一次启动的时候从最新的消息开始消费，之前的数据由 job.SearchIndexJob 重建。
+// 收到 TopicRebuildEvent 的时候也重建一次索引
+type ArticleEventConsumer struct {
+	broker   saramax.Broker
+	svc      service.SearchService
+	l        logger.LoggerV1
+	instance string
+	timeout  time.Duration
+}
+
+// NewArticleEventConsumer instance 是实例的标识，每个实例都不一样，并且重启之后不能变
+func NewArticleEventConsumer(broker saramax.Broker,
+	l logger.LoggerV1,
+	svc service.SearchService,
+	instance string) *ArticleEventConsumer {
+	return &ArticleEventConsumer{
+		broker:   broker,
+		svc:      svc,
+		l:        l,
+		instance: instance,
+		timeout:  time.Minute * 10,
+	}
+}
+
+func (c *ArticleEventConsumer) Start() error {
+	cg, err := c.broker.NewConsumerGroup("search_index_" + c.instance)
+	if err != nil {
+		return err
+	}
+	go func() {
+		// 重试 topic 是所有消费者组共用的，转发过去别的消费者组也会收到，所以这里不用，
+		// 只在本地多重试几次，还是失败就停止消费，不提交，重启之后从这一条继续
+		er := cg.Consume(context.Background(),
+			[]string{events.TopicArticlePublished, events.TopicArticleEdited,
+				events.TopicArticleWithdrawn, TopicRebuildEvent},
+			saramax.NewHandler[ArticleEvent](c.l, c.Consume).
+				WithRetryPolicy(saramax.NewExponentialBackoff(time.Millisecond*100, time.Second*10, 10)))
+		if er != nil {
+			c.l.Error("退出了消费循环异常", logger.Error(er))
+		}
+	}()
+	return nil
+}
+
+// Consume 按照线上库现在的数据更新索引，所以重复消费和乱序都没关系
+func (c *ArticleEventConsumer) Consume(msg *sarama.ConsumerMessage, evt ArticleEvent) error {
+	if msg.Topic == TopicRebuildEvent {
+		return c.rebuild()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return c.svc.SyncArticle(ctx, evt.Aid)
+}
+
+func (c *ArticleEventConsumer) rebuild() error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	cnt, err := c.svc.Rebuild(ctx)
+	if errors.Is(err, service.ErrSearchRebuilding) {
+		// 已经在重建了，结果是一样的
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	c.l.Info("重建搜索索引", logger.Int64("cnt", int64(cnt)))
+	return nil
+}
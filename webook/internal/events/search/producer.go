@@ -0,0 +1,31 @@
+package search
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events"
+)
+
+const TopicRebuildEvent = events.TopicSearchRebuild
+
+// RebuildEvent 让所有实例重建搜索索引，内容只是为了排查问题
+type RebuildEvent struct {
+	// 发出的时间，毫秒数
+	Ctime int64
+}
+
+// ProduceRebuildEvent 每个实例都是单独的消费者组，所以发一条就可以了
+func ProduceRebuildEvent(producer sarama.SyncProducer) error {
+	data, err := json.Marshal(RebuildEvent{Ctime: time.Now().UnixMilli()})
+	if err != nil {
+		return err
+	}
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicRebuildEvent,
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
@@ -0,0 +1,13 @@
+package events
+
+// 所有的 topic 都在这里登记，生产者和消费者用同一个常量，免得两边的名字对不上
+const (
+	TopicArticleRead      = "article_read"
+	TopicArticlePublished = "article_published"
+	TopicArticleWithdrawn = "article_withdrawn"
+	TopicArticleEdited    = "article_edited"
+	TopicFollowEvent      = "follow_event"
+	TopicInteractiveCnt   = "interactive_cnt"
+	// TopicSearchRebuild 每个实例都会收到，收到之后从线上库重建自己的搜索索引
+	TopicSearchRebuild = "search_rebuild"
+)
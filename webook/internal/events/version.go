@@ -0,0 +1,34 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrIncompatibleVersion = errors.New("事件的版本不兼容")
+
+// 每种事件当前的版本。
+// 加字段是兼容的，不用改版本；删了字段或者改了字段的含义，就要加一，
+// 并且在事件的 Upgrade 里面把老版本转换过来
+const (
+	ArticleReadVersion      = 1
+	ArticlePublishedVersion = 1
+	ArticleWithdrawnVersion = 1
+	ArticleEditedVersion    = 1
+	InteractiveCntVersion   = 1
+)
+
+// UpgradeVersion 检查事件的版本，兼容的话改成当前版本。
+// 没有版本号的是加版本号之前发出来的，和版本 1 一样；
+// 比当前版本还新，说明生产者先升级了，消费者看不懂，
+// 拒绝掉让它进死信，等消费者升级之后再重放
+func UpgradeVersion(version *int, current int) error {
+	if *version == 0 {
+		*version = 1
+	}
+	if *version > current {
+		return fmt.Errorf("%w: 版本 %d，最高支持 %d", ErrIncompatibleVersion, *version, current)
+	}
+	*version = current
+	return nil
+}
@@ -0,0 +1,43 @@
+package events
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArticlePublishedEvent_Upgrade(t *testing.T) {
+	testCases := []struct {
+		name    string
+		version int
+
+		wantVersion int
+		wantErr     error
+	}{
+		{
+			name:        "没有版本号的老消息",
+			version:     0,
+			wantVersion: ArticlePublishedVersion,
+		},
+		{
+			name:        "当前版本",
+			version:     ArticlePublishedVersion,
+			wantVersion: ArticlePublishedVersion,
+		},
+		{
+			name:        "比消费者新的版本",
+			version:     ArticlePublishedVersion + 1,
+			wantVersion: ArticlePublishedVersion + 1,
+			wantErr:     ErrIncompatibleVersion,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			evt := ArticlePublishedEvent{Version: tc.version, Aid: 1}
+			err := evt.Upgrade()
+			assert.True(t, errors.Is(err, tc.wantErr))
+			assert.Equal(t, tc.wantVersion, evt.Version)
+		})
+	}
+}
@@ -30,7 +30,7 @@ func (s *ArticleGORMHandlerTestSuite) SetupSuite() {
 	s.server = gin.Default()
 	s.server.Use(func(context *gin.Context) {
 		// 直接设置好
-		context.Set("claims", &ijwt.UserClaims{
+		context.Set("claims", ijwt.UserClaims{
 			Id: 123,
 		})
 		context.Next()
@@ -432,9 +432,3 @@ func (s *ArticleGORMHandlerTestSuite) TestArticle_Publish() {
 func TestGORMArticle(t *testing.T) {
 	suite.Run(t, new(ArticleGORMHandlerTestSuite))
 }
-
-type Article struct {
-	Id      int64  `json:"id"`
-	Title   string `json:"title"`
-	Content string `json:"content"`
-}
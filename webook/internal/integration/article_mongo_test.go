@@ -35,7 +35,7 @@ func (s *ArticleMongoHandlerTestSuite) SetupSuite() {
 	s.server = gin.Default()
 	s.server.Use(func(context *gin.Context) {
 		// 直接设置好
-		context.Set("claims", &ijwt.UserClaims{
+		context.Set("claims", ijwt.UserClaims{
 			Id: 123,
 		})
 		context.Next()
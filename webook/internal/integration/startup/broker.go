@@ -0,0 +1,16 @@
+package startup
+
+import (
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/saramax"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/saramax/memory"
+)
+
+var broker saramax.Broker
+
+// InitBroker 集成测试用内存实现，不需要启动 Kafka
+func InitBroker() saramax.Broker {
+	if broker == nil {
+		broker = memory.NewBroker(4)
+	}
+	return broker
+}
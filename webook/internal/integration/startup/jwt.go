@@ -1 +1,27 @@
 package startup
+
+import (
+	ijwt "github.com/xiaoshanjiang/my-geektime/webook/internal/web/jwt"
+)
+
+// InitJWTKeys 测试用固定的密钥，不读配置
+func InitJWTKeys() ijwt.Keys {
+	access, err := ijwt.NewKeyRing(ijwt.LegacyKid, ijwt.Key{
+		Kid:    ijwt.LegacyKid,
+		Secret: []byte("moyn8y9abnd7q4zkq2m73yw8tu9j5ixm"),
+	})
+	if err != nil {
+		panic(err)
+	}
+	refresh, err := ijwt.NewKeyRing(ijwt.LegacyKid, ijwt.Key{
+		Kid:    ijwt.LegacyKid,
+		Secret: []byte("95osj3fUD7fo0mlYdDbncXz4VD2igvfx"),
+	})
+	if err != nil {
+		panic(err)
+	}
+	return ijwt.Keys{
+		Access:  access,
+		Refresh: refresh,
+	}
+}
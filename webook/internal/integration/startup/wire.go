@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/article"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/follow"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
 	article2 "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache"
@@ -22,7 +23,8 @@ var thirdProvider = wire.NewSet(
 	InitTestDB,
 	InitLog,
 	ioc.NewSyncProducer,
-	ioc.InitKafka,
+	InitBroker,
+	InitJWTKeys,
 )
 var userSvcProvider = wire.NewSet(
 	dao.NewGORMUserDAO,
@@ -30,19 +32,68 @@ var userSvcProvider = wire.NewSet(
 	repository.NewCachedUserRepository,
 	service.NewUserService,
 )
+
+var passwordResetSvcProvider = wire.NewSet(
+	cache.NewRedisPasswordResetCache,
+	repository.NewCachedPasswordResetRepository,
+	// 集成测试不发邮件，直接打印出来
+	ioc.InitEmailMemoryService,
+	ioc.InitPasswordResetService,
+)
+
+var followSvcProvider = wire.NewSet(
+	dao.NewGORMFollowDAO,
+	cache.NewRedisFollowCache,
+	repository.NewCachedFollowRepository,
+	follow.NewKafkaProducer,
+	service.NewFollowService,
+)
+
+var feedSvcProvider = wire.NewSet(
+	dao.NewGORMFeedDAO,
+	repository.NewFeedRepository,
+	ioc.InitFeedService,
+)
+var rankingSvcProvider = wire.NewSet(
+	cache.NewRedisRankingCache,
+	cache.NewRankingLocalCache,
+	repository.NewCachedRankingRepository,
+	service.NewRankingService,
+)
+
 var articlSvcProvider = wire.NewSet(
 	article3.NewGORMArticleDAO,
 	article.NewKafkaProducer,
 	cache.NewRedisArticleCache,
 	article2.NewArticleRepository,
+	service.NewSearchService,
 	service.NewArticleService,
 )
 
+var commentRepoProvider = wire.NewSet(
+	repository.NewCachedCommentRepository,
+	dao.NewGORMCommentDAO,
+	cache.NewRedisCommentCache,
+)
+
+var collectionSvcProvider = wire.NewSet(
+	dao.NewGORMCollectionDAO,
+	repository.NewCollectionRepository,
+	service.NewCollectionService,
+)
+
+var historySvcProvider = wire.NewSet(
+	dao.NewGORMReadHistoryDAO,
+	repository.NewReadHistoryRepository,
+	ioc.InitHistoryService,
+)
+
 var interactiveSvcProvider = wire.NewSet(
 	service.NewInteractiveService,
 	repository.NewCachedInteractiveRepository,
 	dao.NewGORMInteractiveDAO,
 	cache.NewRedisInteractiveCache,
+	commentRepoProvider,
 )
 
 func InitWebServer() *gin.Engine {
@@ -51,7 +102,14 @@ func InitWebServer() *gin.Engine {
 		thirdProvider,
 		userSvcProvider,
 		articlSvcProvider,
-		// interactiveSvcProvider,
+		interactiveSvcProvider,
+		followSvcProvider,
+		feedSvcProvider,
+		rankingSvcProvider,
+		collectionSvcProvider,
+		historySvcProvider,
+		passwordResetSvcProvider,
+		service.NewCommentService,
 
 		// Cache 部分
 		cache.NewRedisCodeCache,
@@ -70,6 +128,16 @@ func InitWebServer() *gin.Engine {
 		web.NewUserHandler,
 		web.NewOAuth2WechatHandler,
 		web.NewArticleHandler,
+		web.NewSearchHandler,
+		web.NewCommentHandler,
+		web.NewFollowHandler,
+		web.NewFeedHandler,
+		web.NewRankingHandler,
+		web.NewCollectionHandler,
+		web.NewHistoryHandler,
+		web.NewJWKSHandler,
+		web.NewSessionHandler,
+		web.NewPasswordResetHandler,
 		ijwt.NewRedisJWTHandler,
 
 		// gin 的中间件
@@ -86,8 +154,10 @@ func InitArticleHandler(dao article3.ArticleDAO) *web.ArticleHandler {
 	wire.Build(thirdProvider,
 		article.NewKafkaProducer,
 		//wire.InterfaceValue(new(article.ArticleDAO), dao),
+		cache.NewRedisArticleCache,
 		article2.NewArticleRepository,
 		service.NewArticleService,
+		interactiveSvcProvider,
 		web.NewArticleHandler)
 	return new(web.ArticleHandler)
 }
@@ -99,10 +169,10 @@ func InitUserSvc() service.UserService {
 
 func InitJwtHdl() ijwt.Handler {
 	wire.Build(thirdProvider, ijwt.NewRedisJWTHandler)
-	return ijwt.NewRedisJWTHandler(nil)
+	return ijwt.NewRedisJWTHandler(nil, ijwt.Keys{})
 }
 
 func InitInteractiveService() service.InteractiveService {
 	wire.Build(thirdProvider, interactiveSvcProvider)
-	return service.NewInteractiveService(nil, nil)
+	return service.NewInteractiveService(nil, nil, nil)
 }
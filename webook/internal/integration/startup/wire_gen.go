@@ -10,6 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
 	article3 "github.com/xiaoshanjiang/my-geektime/webook/internal/events/article"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/follow"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
 	article2 "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache"
@@ -26,7 +27,8 @@ import (
 func InitWebServer() *gin.Engine {
 	cmdable := ioc.InitRedis()
 	loggerV1 := InitLog()
-	handler := jwt.NewRedisJWTHandler(cmdable)
+	keys := InitJWTKeys()
+	handler := jwt.NewRedisJWTHandler(cmdable, keys)
 	v := ioc.InitMiddlewares(cmdable, loggerV1, handler)
 	gormDB := InitTestDB()
 	userDAO := dao.NewGORMUserDAO(gormDB)
@@ -37,28 +39,80 @@ func InitWebServer() *gin.Engine {
 	codeCache := cache.NewRedisCodeCache(cmdable)
 	codeRepository := repository.NewCachedCodeRepository(codeCache)
 	codeService := service.NewSMSCodeService(smsService, codeRepository)
-	userHandler := web.NewUserHandler(userService, codeService, handler)
+	followDAO := dao.NewGORMFollowDAO(gormDB)
+	followCache := cache.NewRedisFollowCache(cmdable)
+	followRepository := repository.NewCachedFollowRepository(followDAO, followCache, loggerV1)
+	saramaxBroker := InitBroker()
+	syncProducer := ioc.NewSyncProducer(saramaxBroker)
+	producer := follow.NewKafkaProducer(syncProducer)
+	followService := service.NewFollowService(followRepository, userRepository, producer, loggerV1)
+	userHandler := web.NewUserHandler(userService, codeService, followService, handler)
 	wechatService := InitPhantomWechatService(loggerV1)
 	oAuth2WechatHandler := web.NewOAuth2WechatHandler(wechatService, userService, handler)
 	articleDAO := article.NewGORMArticleDAO(gormDB)
-	articleRepository := article2.NewArticleRepository(articleDAO, loggerV1)
-	client := ioc.InitKafka()
-	syncProducer := ioc.NewSyncProducer(client)
-	producer := article3.NewKafkaProducer(syncProducer)
-	articleService := service.NewArticleService(articleRepository, loggerV1, producer)
-	articleHandler := web.NewArticleHandler(articleService, loggerV1)
-	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler)
+	articleCache := cache.NewRedisArticleCache(cmdable)
+	articleRepository := article2.NewArticleRepository(articleDAO, articleCache, loggerV1)
+	articleProducer := article3.NewKafkaProducer(syncProducer)
+	articleService := service.NewArticleService(articleRepository, loggerV1, articleProducer)
+	interactiveDAO := dao.NewGORMInteractiveDAO(gormDB)
+	interactiveCache := cache.NewRedisInteractiveCache(cmdable)
+	interactiveRepository := repository.NewCachedInteractiveRepository(interactiveDAO, interactiveCache, loggerV1)
+	commentDAO := dao.NewGORMCommentDAO(gormDB)
+	commentCache := cache.NewRedisCommentCache(cmdable)
+	commentRepository := repository.NewCachedCommentRepository(commentDAO, commentCache, loggerV1)
+	interactiveService := service.NewInteractiveService(interactiveRepository, commentRepository, loggerV1)
+	articleHandler := web.NewArticleHandler(articleService, interactiveService, loggerV1)
+	searchService := service.NewSearchService(articleRepository, loggerV1)
+	searchHandler := web.NewSearchHandler(searchService, loggerV1)
+	commentService := service.NewCommentService(commentRepository, articleRepository, loggerV1)
+	commentHandler := web.NewCommentHandler(commentService, loggerV1)
+	followHandler := web.NewFollowHandler(followService, loggerV1)
+	feedDAO := dao.NewGORMFeedDAO(gormDB)
+	feedRepository := repository.NewFeedRepository(feedDAO)
+	feedService := ioc.InitFeedService(feedRepository, followRepository, articleRepository, loggerV1)
+	feedHandler := web.NewFeedHandler(feedService, loggerV1)
+	redisRankingCache := cache.NewRedisRankingCache(cmdable)
+	rankingLocalCache := cache.NewRankingLocalCache()
+	rankingRepository := repository.NewCachedRankingRepository(redisRankingCache, rankingLocalCache)
+	rankingService := service.NewRankingService(articleRepository, interactiveRepository, rankingRepository)
+	rankingHandler := web.NewRankingHandler(rankingService, interactiveService, loggerV1)
+	collectionDAO := dao.NewGORMCollectionDAO(gormDB)
+	collectionRepository := repository.NewCollectionRepository(collectionDAO)
+	collectionService := service.NewCollectionService(collectionRepository, interactiveRepository, articleRepository, loggerV1)
+	collectionHandler := web.NewCollectionHandler(collectionService, loggerV1)
+	readHistoryDAO := dao.NewGORMReadHistoryDAO(gormDB)
+	readHistoryRepository := repository.NewReadHistoryRepository(readHistoryDAO)
+	historyService := ioc.InitHistoryService(readHistoryRepository, interactiveRepository, articleRepository, loggerV1)
+	historyHandler := web.NewHistoryHandler(historyService, loggerV1)
+	jwksHandler := web.NewJWKSHandler(handler)
+	sessionHandler := web.NewSessionHandler(handler, loggerV1)
+	passwordResetCache := cache.NewRedisPasswordResetCache(cmdable)
+	passwordResetRepository := repository.NewCachedPasswordResetRepository(passwordResetCache)
+	emailService := ioc.InitEmailMemoryService()
+	passwordResetService := ioc.InitPasswordResetService(userRepository, passwordResetRepository, emailService, loggerV1)
+	passwordResetHandler := web.NewPasswordResetHandler(passwordResetService, handler, loggerV1)
+	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler, searchHandler, commentHandler, followHandler, feedHandler, rankingHandler, collectionHandler, historyHandler, jwksHandler, sessionHandler, passwordResetHandler)
 	return engine
 }
 
 func InitArticleHandler(dao2 article.ArticleDAO) *web.ArticleHandler {
+	cmdable := ioc.InitRedis()
+	articleCache := cache.NewRedisArticleCache(cmdable)
 	loggerV1 := InitLog()
-	articleRepository := article2.NewArticleRepository(dao2, loggerV1)
-	client := ioc.InitKafka()
-	syncProducer := ioc.NewSyncProducer(client)
+	articleRepository := article2.NewArticleRepository(dao2, articleCache, loggerV1)
+	saramaxBroker := InitBroker()
+	syncProducer := ioc.NewSyncProducer(saramaxBroker)
 	producer := article3.NewKafkaProducer(syncProducer)
 	articleService := service.NewArticleService(articleRepository, loggerV1, producer)
-	articleHandler := web.NewArticleHandler(articleService, loggerV1)
+	gormDB := InitTestDB()
+	interactiveDAO := dao.NewGORMInteractiveDAO(gormDB)
+	interactiveCache := cache.NewRedisInteractiveCache(cmdable)
+	interactiveRepository := repository.NewCachedInteractiveRepository(interactiveDAO, interactiveCache, loggerV1)
+	commentDAO := dao.NewGORMCommentDAO(gormDB)
+	commentCache := cache.NewRedisCommentCache(cmdable)
+	commentRepository := repository.NewCachedCommentRepository(commentDAO, commentCache, loggerV1)
+	interactiveService := service.NewInteractiveService(interactiveRepository, commentRepository, loggerV1)
+	articleHandler := web.NewArticleHandler(articleService, interactiveService, loggerV1)
 	return articleHandler
 }
 
@@ -75,7 +129,8 @@ func InitUserSvc() service.UserService {
 
 func InitJwtHdl() jwt.Handler {
 	cmdable := ioc.InitRedis()
-	handler := jwt.NewRedisJWTHandler(cmdable)
+	keys := InitJWTKeys()
+	handler := jwt.NewRedisJWTHandler(cmdable, keys)
 	return handler
 }
 
@@ -86,18 +141,36 @@ func InitInteractiveService() service.InteractiveService {
 	interactiveCache := cache.NewRedisInteractiveCache(cmdable)
 	loggerV1 := InitLog()
 	interactiveRepository := repository.NewCachedInteractiveRepository(interactiveDAO, interactiveCache, loggerV1)
-	interactiveService := service.NewInteractiveService(interactiveRepository, loggerV1)
+	commentDAO := dao.NewGORMCommentDAO(gormDB)
+	commentCache := cache.NewRedisCommentCache(cmdable)
+	commentRepository := repository.NewCachedCommentRepository(commentDAO, commentCache, loggerV1)
+	interactiveService := service.NewInteractiveService(interactiveRepository, commentRepository, loggerV1)
 	return interactiveService
 }
 
 // wire.go:
 
 var thirdProvider = wire.NewSet(ioc.InitRedis, InitTestDB,
-	InitLog, ioc.NewSyncProducer, ioc.InitKafka,
+	InitLog, ioc.NewSyncProducer, InitBroker,
+	InitJWTKeys,
 )
 
 var userSvcProvider = wire.NewSet(dao.NewGORMUserDAO, cache.NewRedisUserCache, repository.NewCachedUserRepository, service.NewUserService)
 
-var articlSvcProvider = wire.NewSet(article.NewGORMArticleDAO, article3.NewKafkaProducer, cache.NewRedisArticleCache, article2.NewArticleRepository, service.NewArticleService)
+var passwordResetSvcProvider = wire.NewSet(cache.NewRedisPasswordResetCache, repository.NewCachedPasswordResetRepository, ioc.InitEmailMemoryService, ioc.InitPasswordResetService)
+
+var followSvcProvider = wire.NewSet(dao.NewGORMFollowDAO, cache.NewRedisFollowCache, repository.NewCachedFollowRepository, follow.NewKafkaProducer, service.NewFollowService)
+
+var feedSvcProvider = wire.NewSet(dao.NewGORMFeedDAO, repository.NewFeedRepository, ioc.InitFeedService)
+
+var rankingSvcProvider = wire.NewSet(cache.NewRedisRankingCache, cache.NewRankingLocalCache, repository.NewCachedRankingRepository, service.NewRankingService)
+
+var articlSvcProvider = wire.NewSet(article.NewGORMArticleDAO, article3.NewKafkaProducer, cache.NewRedisArticleCache, article2.NewArticleRepository, service.NewSearchService, service.NewArticleService)
+
+var commentRepoProvider = wire.NewSet(repository.NewCachedCommentRepository, dao.NewGORMCommentDAO, cache.NewRedisCommentCache)
+
+var collectionSvcProvider = wire.NewSet(dao.NewGORMCollectionDAO, repository.NewCollectionRepository, service.NewCollectionService)
+
+var historySvcProvider = wire.NewSet(dao.NewGORMReadHistoryDAO, repository.NewReadHistoryRepository, ioc.InitHistoryService)
 
-var interactiveSvcProvider = wire.NewSet(service.NewInteractiveService, repository.NewCachedInteractiveRepository, dao.NewGORMInteractiveDAO, cache.NewRedisInteractiveCache)
+var interactiveSvcProvider = wire.NewSet(service.NewInteractiveService, repository.NewCachedInteractiveRepository, dao.NewGORMInteractiveDAO, cache.NewRedisInteractiveCache, commentRepoProvider)
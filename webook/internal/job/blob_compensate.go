@@ -0,0 +1,56 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao/article"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+// BlobCompensateJob 重试对象存储里面失败的上传和删除
+// 只有文章存储在对象存储里面的时候才会运行
+type BlobCompensateJob struct {
+	dao       article.ArticleDAO
+	l         logger.LoggerV1
+	interval  time.Duration
+	batchSize int
+	timeout   time.Duration
+}
+
+func NewBlobCompensateJob(dao article.ArticleDAO, l logger.LoggerV1) *BlobCompensateJob {
+	return &BlobCompensateJob{
+		dao:       dao,
+		l:         l,
+		interval:  time.Second * 10,
+		batchSize: 100,
+		timeout:   time.Minute,
+	}
+}
+
+func (j *BlobCompensateJob) Start() error {
+	c, ok := j.dao.(article.BlobCompensator)
+	if !ok {
+		return nil
+	}
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			j.run(c)
+		}
+	}()
+	return nil
+}
+
+func (j *BlobCompensateJob) run(c article.BlobCompensator) {
+	ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
+	defer cancel()
+	cnt, err := c.Compensate(ctx, j.batchSize)
+	if err != nil {
+		j.l.Error("对象存储补偿任务执行失败", logger.Error(err))
+	}
+	if cnt > 0 {
+		j.l.Info("对象存储补偿成功", logger.Int64("cnt", int64(cnt)))
+	}
+}
@@ -0,0 +1,68 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+// ReadHistoryCleanJob 定时删除过了保留期限的阅读记录。
+// 每个实例都会运行，删除是幂等的，多删几次也没有关系
+type ReadHistoryCleanJob struct {
+	repo      repository.ReadHistoryRepository
+	l         logger.LoggerV1
+	retention time.Duration
+	interval  time.Duration
+	batchSize int
+	timeout   time.Duration
+}
+
+func NewReadHistoryCleanJob(repo repository.ReadHistoryRepository,
+	l logger.LoggerV1, retention time.Duration) *ReadHistoryCleanJob {
+	return &ReadHistoryCleanJob{
+		repo:      repo,
+		l:         l,
+		retention: retention,
+		interval:  time.Hour,
+		batchSize: 1000,
+		timeout:   time.Second * 10,
+	}
+}
+
+func (j *ReadHistoryCleanJob) Start() error {
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			j.run()
+		}
+	}()
+	return nil
+}
+
+func (j *ReadHistoryCleanJob) run() {
+	before := time.Now().Add(-j.retention)
+	var total int64
+	for {
+		cnt, err := j.deleteOnce(before)
+		total += cnt
+		if err != nil {
+			j.l.Error("清理阅读记录失败", logger.Error(err))
+			break
+		}
+		if cnt < int64(j.batchSize) {
+			break
+		}
+	}
+	if total > 0 {
+		j.l.Info("清理阅读记录", logger.Int64("cnt", total))
+	}
+}
+
+func (j *ReadHistoryCleanJob) deleteOnce(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
+	defer cancel()
+	return j.repo.DeleteBefore(ctx, before, j.batchSize)
+}
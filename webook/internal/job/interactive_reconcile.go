@@ -0,0 +1,134 @@
+package job
+
+import (
+	"context"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+// InteractiveReconciler 点赞数和收藏数都是一点点加上去的，
+// 出过 bug 或者丢过事件就会和点赞、收藏记录对不上。
+// 这里分批扫描 interactives，按照记录重新统计，找出不一致的计数。
+// 不是定时任务，通过子命令 reconcile-interactive 手工执行
+type InteractiveReconciler struct {
+	dao       dao.InteractiveDAO
+	cache     cache.InteractiveCache
+	l         logger.LoggerV1
+	batchSize int
+}
+
+func NewInteractiveReconciler(dao dao.InteractiveDAO,
+	cache cache.InteractiveCache,
+	l logger.LoggerV1) *InteractiveReconciler {
+	return &InteractiveReconciler{
+		dao:       dao,
+		cache:     cache,
+		l:         l,
+		batchSize: 100,
+	}
+}
+
+// InteractiveDrift 一个资源的计数和记录对不上
+type InteractiveDrift struct {
+	Biz            string
+	BizId          int64
+	LikeCnt        int64
+	RealLikeCnt    int64
+	CollectCnt     int64
+	RealCollectCnt int64
+	// 修复了的话是 true，对账期间计数又变了的，这一次不修复
+	Repaired bool
+}
+
+type ReconcileResult struct {
+	Scanned  int64
+	Drifted  int64
+	Repaired int64
+}
+
+// Run 扫描全部计数，每发现一个不一致就调用一次 onDrift。
+// repair 为 false 的时候只报告，不修改任何数据。
+// 异步计数模式下，还没有被消费的事件也会表现为不一致，最好在消费者没有积压的时候执行
+func (r *InteractiveReconciler) Run(ctx context.Context, repair bool,
+	onDrift func(d InteractiveDrift)) (ReconcileResult, error) {
+	var (
+		res     ReconcileResult
+		startId int64
+	)
+	for {
+		intrs, err := r.dao.ListInteractives(ctx, startId, r.batchSize)
+		if err != nil {
+			return res, err
+		}
+		if len(intrs) == 0 {
+			return res, nil
+		}
+		startId = intrs[len(intrs)-1].Id
+		res.Scanned += int64(len(intrs))
+
+		// 一批里面可能有不同的 biz，按照 biz 分组统计
+		bizIds := make(map[string][]int64)
+		for _, intr := range intrs {
+			bizIds[intr.Biz] = append(bizIds[intr.Biz], intr.BizId)
+		}
+		likes := make(map[string]map[int64]int64, len(bizIds))
+		collects := make(map[string]map[int64]int64, len(bizIds))
+		for biz, ids := range bizIds {
+			likes[biz], err = r.dao.CountLikes(ctx, biz, ids)
+			if err != nil {
+				return res, err
+			}
+			collects[biz], err = r.dao.CountCollects(ctx, biz, ids)
+			if err != nil {
+				return res, err
+			}
+		}
+
+		for _, intr := range intrs {
+			d := InteractiveDrift{
+				Biz:            intr.Biz,
+				BizId:          intr.BizId,
+				LikeCnt:        intr.LikeCnt,
+				RealLikeCnt:    likes[intr.Biz][intr.BizId],
+				CollectCnt:     intr.CollectCnt,
+				RealCollectCnt: collects[intr.Biz][intr.BizId],
+			}
+			if d.LikeCnt == d.RealLikeCnt && d.CollectCnt == d.RealCollectCnt {
+				continue
+			}
+			res.Drifted++
+			if repair {
+				d.Repaired, err = r.repair(ctx, intr, d)
+				if err != nil {
+					return res, err
+				}
+				if d.Repaired {
+					res.Repaired++
+				}
+			}
+			onDrift(d)
+		}
+		if len(intrs) < r.batchSize {
+			return res, nil
+		}
+	}
+}
+
+func (r *InteractiveReconciler) repair(ctx context.Context,
+	intr dao.Interactive, d InteractiveDrift) (bool, error) {
+	ok, err := r.dao.FixCnt(ctx, intr, d.RealLikeCnt, d.RealCollectCnt)
+	if err != nil || !ok {
+		return false, err
+	}
+	// 数据库已经改了，缓存删不掉也只能等过期
+	err = r.cache.Del(ctx, intr.Biz, intr.BizId)
+	if err != nil {
+		r.l.Error("删除计数缓存失败",
+			logger.String("biz", intr.Biz),
+			logger.Int64("bizId", intr.BizId),
+			logger.Error(err))
+	}
+	return true, nil
+}
@@ -0,0 +1,102 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache"
+	cachemocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache/mocks"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao"
+	daomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao/mocks"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+func TestInteractiveReconciler_Run(t *testing.T) {
+	intrs := []dao.Interactive{
+		{Id: 1, Biz: "article", BizId: 11, LikeCnt: 3, CollectCnt: 1},
+		{Id: 2, Biz: "article", BizId: 12, LikeCnt: 5, CollectCnt: 0},
+	}
+	testCases := []struct {
+		name   string
+		mock   func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache)
+		repair bool
+
+		wantDrifts []InteractiveDrift
+		wantRes    ReconcileResult
+		wantErr    error
+	}{
+		{
+			name: "只报告",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().ListInteractives(gomock.Any(), int64(0), 2).Return(intrs, nil)
+				d.EXPECT().CountLikes(gomock.Any(), "article", []int64{11, 12}).
+					Return(map[int64]int64{11: 3, 12: 4}, nil)
+				d.EXPECT().CountCollects(gomock.Any(), "article", []int64{11, 12}).
+					Return(map[int64]int64{11: 1}, nil)
+				d.EXPECT().ListInteractives(gomock.Any(), int64(2), 2).Return(nil, nil)
+				return d, cachemocks.NewMockInteractiveCache(ctrl)
+			},
+			wantDrifts: []InteractiveDrift{
+				{Biz: "article", BizId: 12, LikeCnt: 5, RealLikeCnt: 4},
+			},
+			wantRes: ReconcileResult{Scanned: 2, Drifted: 1},
+		},
+		{
+			name:   "修复并且删除缓存",
+			repair: true,
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().ListInteractives(gomock.Any(), int64(0), 2).Return(intrs, nil)
+				d.EXPECT().CountLikes(gomock.Any(), "article", []int64{11, 12}).
+					Return(map[int64]int64{11: 3, 12: 4}, nil)
+				d.EXPECT().CountCollects(gomock.Any(), "article", []int64{11, 12}).
+					Return(map[int64]int64{11: 2}, nil)
+				// 11 对账期间计数变了，不修复
+				d.EXPECT().FixCnt(gomock.Any(), intrs[0], int64(3), int64(2)).Return(false, nil)
+				d.EXPECT().FixCnt(gomock.Any(), intrs[1], int64(4), int64(0)).Return(true, nil)
+				d.EXPECT().ListInteractives(gomock.Any(), int64(2), 2).Return(nil, nil)
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().Del(gomock.Any(), "article", int64(12)).Return(nil)
+				return d, c
+			},
+			wantDrifts: []InteractiveDrift{
+				{Biz: "article", BizId: 11, LikeCnt: 3, RealLikeCnt: 3, CollectCnt: 1, RealCollectCnt: 2},
+				{Biz: "article", BizId: 12, LikeCnt: 5, RealLikeCnt: 4, Repaired: true},
+			},
+			wantRes: ReconcileResult{Scanned: 2, Drifted: 2, Repaired: 1},
+		},
+		{
+			name: "统计失败",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().ListInteractives(gomock.Any(), int64(0), 2).Return(intrs, nil)
+				d.EXPECT().CountLikes(gomock.Any(), "article", []int64{11, 12}).
+					Return(nil, errors.New("mock db error"))
+				return d, cachemocks.NewMockInteractiveCache(ctrl)
+			},
+			wantRes: ReconcileResult{Scanned: 2},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tc.mock(ctrl)
+			r := NewInteractiveReconciler(d, c, &logger.NoOpLogger{})
+			r.batchSize = 2
+			var drifts []InteractiveDrift
+			res, err := r.Run(context.Background(), tc.repair, func(d InteractiveDrift) {
+				drifts = append(drifts, d)
+			})
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantDrifts, drifts)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/outbox"
+)
+
+// OutboxRelayJob 把发件箱里面的消息投递到 Kafka
+// 每个实例都会运行，靠租约保证同一时刻一条消息只被一个实例投递
+type OutboxRelayJob struct {
+	relay *outbox.Relay
+	l     logger.LoggerV1
+	// 发件箱空了之后，多久再检查一次
+	interval time.Duration
+	timeout  time.Duration
+}
+
+func NewOutboxRelayJob(relay *outbox.Relay, l logger.LoggerV1) *OutboxRelayJob {
+	return &OutboxRelayJob{
+		relay:    relay,
+		l:        l,
+		interval: time.Second,
+		timeout:  time.Second * 30,
+	}
+}
+
+func (j *OutboxRelayJob) Start() error {
+	go func() {
+		for {
+			cnt, err := j.run()
+			if err != nil {
+				j.l.Error("投递发件箱失败", logger.Error(err))
+			}
+			// 一批满了说明还有积压，不用等
+			if err != nil || cnt < j.relay.BatchSize() {
+				time.Sleep(j.interval)
+			}
+		}
+	}()
+	return nil
+}
+
+func (j *OutboxRelayJob) run() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
+	defer cancel()
+	return j.relay.RelayOnce(ctx)
+}
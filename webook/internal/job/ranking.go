@@ -0,0 +1,97 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/rlock"
+)
+
+// RankingJob 定时计算热榜
+// 每个实例都会运行，但是只有拿到分布式锁的实例才会计算，
+// 拿到锁之后一直续约，直到续约失败（比如说和 Redis 断开太久了）才换别的实例来计算
+type RankingJob struct {
+	svc    service.RankingService
+	client *rlock.Client
+	l      logger.LoggerV1
+
+	key string
+	// 多久计算一次
+	interval time.Duration
+	// 一次计算最多用多久
+	timeout time.Duration
+
+	mu   sync.Mutex
+	lock *rlock.Lock
+}
+
+func NewRankingJob(svc service.RankingService, client *rlock.Client,
+	l logger.LoggerV1) *RankingJob {
+	return &RankingJob{
+		svc:      svc,
+		client:   client,
+		l:        l,
+		key:      "job:ranking",
+		interval: time.Minute,
+		timeout:  time.Minute,
+	}
+}
+
+func (j *RankingJob) Start() error {
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			j.run()
+		}
+	}()
+	return nil
+}
+
+func (j *RankingJob) run() {
+	if !j.holdLock() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
+	defer cancel()
+	start := time.Now()
+	if err := j.svc.TopN(ctx); err != nil {
+		j.l.Error("计算热榜失败", logger.Error(err))
+		return
+	}
+	j.l.Debug("计算热榜", logger.String("duration", time.Since(start).String()))
+}
+
+// holdLock 没有锁的话尝试抢一下，返回当前实例是不是持有锁
+func (j *RankingJob) holdLock() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.lock != nil {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	// 过期时间比计算间隔长，续约偶尔失败一两次也不会丢锁
+	lock, err := j.client.TryLock(ctx, j.key, j.interval*2)
+	if err != nil {
+		if !errors.Is(err, rlock.ErrFailedToPreemptLock) {
+			j.l.Warn("抢热榜任务的锁失败", logger.Error(err))
+		}
+		return false
+	}
+	j.lock = lock
+	go func() {
+		err := lock.AutoRefresh(j.interval/2, time.Second)
+		if err != nil {
+			j.l.Warn("热榜任务的锁续约失败，释放锁", logger.Error(err))
+		}
+		j.mu.Lock()
+		j.lock = nil
+		j.mu.Unlock()
+	}()
+	return true
+}
@@ -0,0 +1,57 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+// ScheduledPublishJob 定时发表的任务
+// 每个实例都会运行，但是每篇文章只会被一个实例发表，具体看 ArticleService.PublishDue
+// 任务状态都在数据库里面，所以重启之后会继续处理没有发表的文章
+type ScheduledPublishJob struct {
+	svc service.ArticleService
+	l   logger.LoggerV1
+	// 多久扫描一次
+	interval time.Duration
+	// 一次最多发表多少篇
+	batchSize int
+	timeout   time.Duration
+}
+
+func NewScheduledPublishJob(svc service.ArticleService,
+	l logger.LoggerV1) *ScheduledPublishJob {
+	return &ScheduledPublishJob{
+		svc:       svc,
+		l:         l,
+		interval:  time.Second * 10,
+		batchSize: 100,
+		timeout:   time.Second * 30,
+	}
+}
+
+func (j *ScheduledPublishJob) Start() error {
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			j.run()
+		}
+	}()
+	return nil
+}
+
+func (j *ScheduledPublishJob) run() {
+	ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
+	defer cancel()
+	cnt, err := j.svc.PublishDue(ctx, j.batchSize)
+	if err != nil {
+		j.l.Error("定时发表任务执行失败", logger.Error(err))
+		return
+	}
+	if cnt > 0 {
+		j.l.Info("定时发表文章", logger.Int64("cnt", int64(cnt)))
+	}
+}
@@ -0,0 +1,58 @@
+package job
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+// SearchIndexJob 维护进程内的搜索索引
+// 启动的时候从线上库重建一次索引，之后由 search.ArticleEventConsumer 消费发表、修改和撤回事件更新索引。
+// 如果索引和线上库不一致了，可以用子命令通知所有实例重建，或者给某一个进程发送 SIGHUP：
+//
+//	webook rebuild-search --config config/dev.yaml
+//	kill -HUP <pid>
+type SearchIndexJob struct {
+	svc     service.SearchService
+	l       logger.LoggerV1
+	timeout time.Duration
+}
+
+func NewSearchIndexJob(svc service.SearchService, l logger.LoggerV1) *SearchIndexJob {
+	return &SearchIndexJob{
+		svc:     svc,
+		l:       l,
+		timeout: time.Minute * 10,
+	}
+}
+
+func (j *SearchIndexJob) Start() error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		j.rebuild()
+		for range signals {
+			j.rebuild()
+		}
+	}()
+	return nil
+}
+
+func (j *SearchIndexJob) rebuild() {
+	ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
+	defer cancel()
+	start := time.Now()
+	cnt, err := j.svc.Rebuild(ctx)
+	if err != nil {
+		j.l.Error("重建搜索索引失败", logger.Error(err))
+		return
+	}
+	j.l.Info("重建搜索索引",
+		logger.Int64("cnt", int64(cnt)),
+		logger.String("duration", time.Since(start).String()))
+}
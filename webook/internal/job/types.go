@@ -0,0 +1,6 @@
+package job
+
+// Job 后台定时任务，和 events.Consumer 一样，在 main 里面启动
+type Job interface {
+	Start() error
+}
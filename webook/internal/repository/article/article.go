@@ -16,6 +16,12 @@ import (
 // repository 还是要用来操作缓存和DAO
 // 事务概念应该在 DAO 这一层
 
+var (
+	ErrPossibleIncorrectAuthor = dao.ErrPossibleIncorrectAuthor
+	ErrRevisionNotFound        = dao.ErrRevisionNotFound
+	ErrArticleNotFound         = dao.ErrArticleNotFound
+)
+
 type ArticleRepository interface {
 	Create(ctx context.Context, art domain.Article) (int64, error)
 	Update(ctx context.Context, art domain.Article) error
@@ -26,6 +32,25 @@ type ArticleRepository interface {
 	GetByID(ctx context.Context, id int64) (domain.Article, error)
 	GetPublishedById(ctx context.Context, id int64) (domain.Article, error)
 	//FindById(ctx context.Context, id int64) domain.Article
+
+	ListRevisions(ctx context.Context, uid, id int64, offset, limit int) ([]domain.ArticleRevision, error)
+	GetRevision(ctx context.Context, uid, id, revId int64) (domain.ArticleRevision, error)
+	Restore(ctx context.Context, uid, id, revId int64) error
+
+	ListScheduled(ctx context.Context, uid int64, offset, limit int) ([]domain.Article, error)
+	Reschedule(ctx context.Context, uid, id int64, publishAt time.Time) error
+	CancelSchedule(ctx context.Context, uid, id int64) error
+	FindDueScheduled(ctx context.Context, now time.Time, limit int) ([]domain.Article, error)
+	// ClaimScheduled 抢占到期的定时文章，在 leaseUntil 之前别的实例不会再处理它
+	ClaimScheduled(ctx context.Context, art domain.Article, leaseUntil time.Time) (bool, error)
+
+	// ListPub 按照 ID 升序遍历公开的文章
+	ListPub(ctx context.Context, startId int64, limit int) ([]domain.Article, error)
+	// GetPubByIds 批量查询公开的文章，没有公开的和不存在的不在结果里面，不会组装作者
+	GetPubByIds(ctx context.Context, ids []int64) ([]domain.Article, error)
+	ListPubByTag(ctx context.Context, tag string, offset, limit int) ([]domain.Article, error)
+	SuggestTags(ctx context.Context, prefix string, limit int) ([]domain.Tag, error)
+	TagCounts(ctx context.Context, tags []string) ([]domain.Tag, error)
 }
 
 type CachedArticleRepository struct {
@@ -64,8 +89,10 @@ func (repo *CachedArticleRepository) GetPublishedById(
 			Id:   usr.Id,
 			Name: usr.Nickname,
 		},
-		Ctime: time.UnixMilli(art.Ctime),
-		Utime: time.UnixMilli(art.Utime),
+		Category: art.Category,
+		Tags:     art.Tags,
+		Ctime:    time.UnixMilli(art.Ctime),
+		Utime:    time.UnixMilli(art.Utime),
 	}
 	return res, nil
 }
@@ -107,8 +134,142 @@ func (c *CachedArticleRepository) List(ctx context.Context, uid int64, offset in
 	return data, nil
 }
 
+func (c *CachedArticleRepository) ListRevisions(ctx context.Context,
+	uid, id int64, offset, limit int) ([]domain.ArticleRevision, error) {
+	res, err := c.dao.ListRevisions(ctx, uid, id, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.ArticleRevision, domain.ArticleRevision](res,
+		func(idx int, src dao.ArticleRevision) domain.ArticleRevision {
+			return c.revisionToDomain(src)
+		}), nil
+}
+
+func (c *CachedArticleRepository) GetRevision(ctx context.Context,
+	uid, id, revId int64) (domain.ArticleRevision, error) {
+	rev, err := c.dao.GetRevision(ctx, uid, id, revId)
+	if err != nil {
+		return domain.ArticleRevision{}, err
+	}
+	return c.revisionToDomain(rev), nil
+}
+
+func (c *CachedArticleRepository) Restore(ctx context.Context, uid, id, revId int64) error {
+	err := c.dao.Restore(ctx, uid, id, revId)
+	if err == nil {
+		// 标题和状态都变了，第一页的缓存要清掉
+		er := c.cache.DelFirstPage(ctx, uid)
+		if er != nil {
+			c.l.Warn("删除第一页缓存失败", logger.Error(er))
+		}
+	}
+	return err
+}
+
+func (c *CachedArticleRepository) ListScheduled(ctx context.Context,
+	uid int64, offset, limit int) ([]domain.Article, error) {
+	res, err := c.dao.ListScheduled(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Article, domain.Article](res, func(idx int, src dao.Article) domain.Article {
+		return c.toDomain(src)
+	}), nil
+}
+
+func (c *CachedArticleRepository) Reschedule(ctx context.Context,
+	uid, id int64, publishAt time.Time) error {
+	return c.dao.Reschedule(ctx, uid, id, publishAt.UnixMilli())
+}
+
+func (c *CachedArticleRepository) CancelSchedule(ctx context.Context, uid, id int64) error {
+	err := c.dao.CancelSchedule(ctx, uid, id)
+	if err == nil {
+		// 状态变了
+		er := c.cache.DelFirstPage(ctx, uid)
+		if er != nil {
+			c.l.Warn("删除第一页缓存失败", logger.Error(er))
+		}
+	}
+	return err
+}
+
+func (c *CachedArticleRepository) FindDueScheduled(ctx context.Context,
+	now time.Time, limit int) ([]domain.Article, error) {
+	res, err := c.dao.FindDueScheduled(ctx, now.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Article, domain.Article](res, func(idx int, src dao.Article) domain.Article {
+		return c.toDomain(src)
+	}), nil
+}
+
+func (c *CachedArticleRepository) ClaimScheduled(ctx context.Context,
+	art domain.Article, leaseUntil time.Time) (bool, error) {
+	return c.dao.ClaimScheduled(ctx, art.Id, art.PublishAt.UnixMilli(), leaseUntil.UnixMilli())
+}
+
 func (c *CachedArticleRepository) SyncStatus(ctx context.Context, id int64, author int64, status domain.ArticleStatus) error {
-	return c.dao.SyncStatus(ctx, id, author, uint8(status))
+	return c.dao.SyncStatus(ctx, author, id, uint8(status))
+}
+
+func (c *CachedArticleRepository) ListPub(ctx context.Context,
+	startId int64, limit int) ([]domain.Article, error) {
+	res, err := c.dao.ListPub(ctx, startId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.PublishedArticle, domain.Article](res,
+		func(idx int, src dao.PublishedArticle) domain.Article {
+			return c.toDomain(dao.Article(src))
+		}), nil
+}
+
+func (c *CachedArticleRepository) GetPubByIds(ctx context.Context,
+	ids []int64) ([]domain.Article, error) {
+	res, err := c.dao.GetPubByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.PublishedArticle, domain.Article](res,
+		func(idx int, src dao.PublishedArticle) domain.Article {
+			return c.toDomain(dao.Article(src))
+		}), nil
+}
+
+func (c *CachedArticleRepository) ListPubByTag(ctx context.Context,
+	tag string, offset, limit int) ([]domain.Article, error) {
+	res, err := c.dao.ListPubByTag(ctx, tag, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.PublishedArticle, domain.Article](res,
+		func(idx int, src dao.PublishedArticle) domain.Article {
+			return c.toDomain(dao.Article(src))
+		}), nil
+}
+
+func (c *CachedArticleRepository) SuggestTags(ctx context.Context,
+	prefix string, limit int) ([]domain.Tag, error) {
+	res, err := c.dao.SuggestTags(ctx, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Tag, domain.Tag](res, func(idx int, src dao.Tag) domain.Tag {
+		return c.tagToDomain(src)
+	}), nil
+}
+
+func (c *CachedArticleRepository) TagCounts(ctx context.Context, tags []string) ([]domain.Tag, error) {
+	res, err := c.dao.TagCounts(ctx, tags)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Tag, domain.Tag](res, func(idx int, src dao.Tag) domain.Tag {
+		return c.tagToDomain(src)
+	}), nil
 }
 
 func (c *CachedArticleRepository) Sync(ctx context.Context, art domain.Article) (int64, error) {
@@ -195,12 +356,7 @@ func (c *CachedArticleRepository) Create(ctx context.Context, art domain.Article
 		// 清空缓存
 		c.cache.DelFirstPage(ctx, art.Author.Id)
 	}()
-	return c.dao.Insert(ctx, dao.Article{
-		Title:    art.Title,
-		Content:  art.Content,
-		AuthorId: art.Author.Id,
-		Status:   uint8(art.Status),
-	})
+	return c.dao.Insert(ctx, c.toEntity(art))
 }
 
 func (c *CachedArticleRepository) Update(ctx context.Context, art domain.Article) error {
@@ -208,27 +364,28 @@ func (c *CachedArticleRepository) Update(ctx context.Context, art domain.Article
 		// 清空缓存
 		c.cache.DelFirstPage(ctx, art.Author.Id)
 	}()
-	return c.dao.UpdateById(ctx, dao.Article{
-		Id:       art.Id,
-		Title:    art.Title,
-		Content:  art.Content,
-		AuthorId: art.Author.Id,
-		Status:   uint8(art.Status),
-	})
+	return c.dao.UpdateById(ctx, c.toEntity(art))
 }
 
 func (c *CachedArticleRepository) toEntity(art domain.Article) dao.Article {
+	var publishAt int64
+	if !art.PublishAt.IsZero() {
+		publishAt = art.PublishAt.UnixMilli()
+	}
 	return dao.Article{
-		Id:       art.Id,
-		Title:    art.Title,
-		Content:  art.Content,
-		AuthorId: art.Author.Id,
-		Status:   uint8(art.Status),
+		Id:        art.Id,
+		Title:     art.Title,
+		Content:   art.Content,
+		AuthorId:  art.Author.Id,
+		Status:    uint8(art.Status),
+		PublishAt: publishAt,
+		Category:  art.Category,
+		Tags:      art.Tags,
 	}
 }
 
 func (repo *CachedArticleRepository) toDomain(art dao.Article) domain.Article {
-	return domain.Article{
+	res := domain.Article{
 		Id:      art.Id,
 		Title:   art.Title,
 		Status:  domain.ArticleStatus(art.Status),
@@ -236,8 +393,37 @@ func (repo *CachedArticleRepository) toDomain(art dao.Article) domain.Article {
 		Author: domain.Author{
 			Id: art.AuthorId,
 		},
-		Ctime: time.UnixMilli(art.Ctime),
-		Utime: time.UnixMilli(art.Utime),
+		Category: art.Category,
+		Tags:     art.Tags,
+		Ctime:    time.UnixMilli(art.Ctime),
+		Utime:    time.UnixMilli(art.Utime),
+	}
+	if art.PublishAt > 0 {
+		res.PublishAt = time.UnixMilli(art.PublishAt)
+	}
+	return res
+}
+
+func (c *CachedArticleRepository) revisionToDomain(rev dao.ArticleRevision) domain.ArticleRevision {
+	return domain.ArticleRevision{
+		Id:        rev.Id,
+		ArticleId: rev.ArticleId,
+		Title:     rev.Title,
+		Content:   rev.Content,
+		Status:    domain.ArticleStatus(rev.Status),
+		Author: domain.Author{
+			Id: rev.AuthorId,
+		},
+		Category: rev.Category,
+		Tags:     rev.Tags,
+		Ctime:    time.UnixMilli(rev.Ctime),
+	}
+}
+
+func (c *CachedArticleRepository) tagToDomain(tag dao.Tag) domain.Tag {
+	return domain.Tag{
+		Name: tag.Name,
+		Cnt:  tag.Cnt,
 	}
 }
 
@@ -250,9 +436,12 @@ func (c *CachedArticleRepository) preCache(ctx context.Context, data []domain.Ar
 	}
 }
 
-func NewArticleRepository(dao dao.ArticleDAO, l logger.LoggerV1) ArticleRepository {
+func NewArticleRepository(dao dao.ArticleDAO,
+	c cache.ArticleCache,
+	l logger.LoggerV1) ArticleRepository {
 	return &CachedArticleRepository{
-		dao: dao,
-		l:   l,
+		dao:   dao,
+		cache: c,
+		l:     l,
 	}
 }
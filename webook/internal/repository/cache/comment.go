@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const fieldCommentCnt = "cnt"
+
+//go:generate mockgen -source=./comment.go -package=cachemocks -destination=mocks/comment.mock.go CommentCache
+type CommentCache interface {
+	// IncrCntIfPresent 如果缓存中有评论数，就加上 delta，删除的时候 delta 是负数
+	IncrCntIfPresent(ctx context.Context, biz string, bizId int64, delta int64) error
+	GetCnt(ctx context.Context, biz string, bizId int64) (int64, error)
+	SetCnt(ctx context.Context, biz string, bizId int64, cnt int64) error
+}
+
+type RedisCommentCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRedisCommentCache(client redis.Cmdable) CommentCache {
+	return &RedisCommentCache{
+		client:     client,
+		expiration: time.Minute * 15,
+	}
+}
+
+func (r *RedisCommentCache) IncrCntIfPresent(ctx context.Context,
+	biz string, bizId int64, delta int64) error {
+	// 和点赞数一样，只有缓存里面有的时候才更新，避免缓存里面出现一个不完整的计数
+	return r.client.Eval(ctx, luaIncrCnt,
+		[]string{r.key(biz, bizId)},
+		fieldCommentCnt, delta).Err()
+}
+
+func (r *RedisCommentCache) GetCnt(ctx context.Context, biz string, bizId int64) (int64, error) {
+	val, err := r.client.HGet(ctx, r.key(biz, bizId), fieldCommentCnt).Result()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(val, 10, 64)
+}
+
+func (r *RedisCommentCache) SetCnt(ctx context.Context, biz string, bizId int64, cnt int64) error {
+	key := r.key(biz, bizId)
+	err := r.client.HSet(ctx, key, fieldCommentCnt, cnt).Err()
+	if err != nil {
+		return err
+	}
+	return r.client.Expire(ctx, key, r.expiration).Err()
+}
+
+func (r *RedisCommentCache) key(biz string, bizId int64) string {
+	return fmt.Sprintf("comment:%s:%d", biz, bizId)
+}
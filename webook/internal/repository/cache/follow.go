@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+)
+
+const (
+	fieldFollowerCnt = "follower_cnt"
+	fieldFolloweeCnt = "followee_cnt"
+)
+
+//go:generate mockgen -source=./follow.go -package=cachemocks -destination=mocks/follow.mock.go FollowCache
+type FollowCache interface {
+	// Follow follower 的关注数 +1，followee 的粉丝数 +1，缓存中没有的就不管
+	Follow(ctx context.Context, follower, followee int64) error
+	CancelFollow(ctx context.Context, follower, followee int64) error
+	StaticsInfo(ctx context.Context, uid int64) (domain.FollowStatics, error)
+	SetStaticsInfo(ctx context.Context, uid int64, statics domain.FollowStatics) error
+}
+
+type RedisFollowCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRedisFollowCache(client redis.Cmdable) FollowCache {
+	return &RedisFollowCache{
+		client:     client,
+		expiration: time.Minute * 15,
+	}
+}
+
+func (r *RedisFollowCache) Follow(ctx context.Context, follower, followee int64) error {
+	return r.updateStaticsInfo(ctx, follower, followee, 1)
+}
+
+func (r *RedisFollowCache) CancelFollow(ctx context.Context, follower, followee int64) error {
+	return r.updateStaticsInfo(ctx, follower, followee, -1)
+}
+
+func (r *RedisFollowCache) updateStaticsInfo(ctx context.Context,
+	follower, followee int64, delta int64) error {
+	// 两个 key 不一定在同一个 slot 上，所以分两次调用
+	err := r.client.Eval(ctx, luaIncrCnt,
+		[]string{r.staticsKey(follower)},
+		fieldFolloweeCnt, delta).Err()
+	if err != nil {
+		return err
+	}
+	return r.client.Eval(ctx, luaIncrCnt,
+		[]string{r.staticsKey(followee)},
+		fieldFollowerCnt, delta).Err()
+}
+
+func (r *RedisFollowCache) StaticsInfo(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	data, err := r.client.HGetAll(ctx, r.staticsKey(uid)).Result()
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+	if len(data) == 0 {
+		return domain.FollowStatics{}, ErrKeyNotExist
+	}
+	followers, _ := strconv.ParseInt(data[fieldFollowerCnt], 10, 64)
+	followees, _ := strconv.ParseInt(data[fieldFolloweeCnt], 10, 64)
+	return domain.FollowStatics{
+		Followers: followers,
+		Followees: followees,
+	}, nil
+}
+
+func (r *RedisFollowCache) SetStaticsInfo(ctx context.Context,
+	uid int64, statics domain.FollowStatics) error {
+	key := r.staticsKey(uid)
+	err := r.client.HMSet(ctx, key,
+		fieldFollowerCnt, statics.Followers,
+		fieldFolloweeCnt, statics.Followees).Err()
+	if err != nil {
+		return err
+	}
+	return r.client.Expire(ctx, key, r.expiration).Err()
+}
+
+func (r *RedisFollowCache) staticsKey(uid int64) string {
+	return fmt.Sprintf("follow:statics:%d", uid)
+}
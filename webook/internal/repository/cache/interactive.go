@@ -31,10 +31,17 @@ type InteractiveCache interface {
 	IncrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error
 	DecrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error
 	IncrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	DecrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error
 	// Get 查询缓存中数据
 	// 事实上，这里 liked 和 collected 是不需要缓存的
 	Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error)
 	Set(ctx context.Context, biz string, bizId int64, intr domain.Interactive) error
+	// GetByIds 批量查询，一次网络往返。缓存里面没有的 bizId 不在结果里面
+	GetByIds(ctx context.Context, biz string, bizIds []int64) (map[int64]domain.Interactive, error)
+	// SetByIds 批量回写缓存，一次网络往返
+	SetByIds(ctx context.Context, biz string, intrs map[int64]domain.Interactive) error
+	// Del 删除缓存，下一次查询的时候从数据库里面加载
+	Del(ctx context.Context, biz string, bizId int64) error
 }
 
 type RedisInteractiveCache struct {
@@ -44,7 +51,14 @@ type RedisInteractiveCache struct {
 
 func (r *RedisInteractiveCache) IncrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error {
 	return r.client.Eval(ctx, luaIncrCnt,
-		[]string{r.key(biz, bizId), fieldCollectCnt}, 1).Err()
+		[]string{r.key(biz, bizId)},
+		fieldCollectCnt, 1).Err()
+}
+
+func (r *RedisInteractiveCache) DecrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	return r.client.Eval(ctx, luaIncrCnt,
+		[]string{r.key(biz, bizId)},
+		fieldCollectCnt, -1).Err()
 }
 
 func (r *RedisInteractiveCache) IncrReadCntIfPresent(ctx context.Context, biz string, bizId int64) error {
@@ -81,7 +95,35 @@ func (r *RedisInteractiveCache) Get(ctx context.Context, biz string, bizId int64
 		// 缓存不存在，系统错误，比如说你的同事，手贱设置了缓存，但是忘记任何 fields
 		return domain.Interactive{}, ErrKeyNotExist
 	}
+	return r.toDomain(data), nil
+}
+
+func (r *RedisInteractiveCache) GetByIds(ctx context.Context,
+	biz string, bizIds []int64) (map[int64]domain.Interactive, error) {
+	res := make(map[int64]domain.Interactive, len(bizIds))
+	if len(bizIds) == 0 {
+		return res, nil
+	}
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, 0, len(bizIds))
+	for _, bizId := range bizIds {
+		cmds = append(cmds, pipe.HGetAll(ctx, r.key(biz, bizId)))
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, cmd := range cmds {
+		data := cmd.Val()
+		if len(data) == 0 {
+			continue
+		}
+		res[bizIds[i]] = r.toDomain(data)
+	}
+	return res, nil
+}
 
+func (r *RedisInteractiveCache) toDomain(data map[string]string) domain.Interactive {
 	// 理论上来说，这里不可能有 error
 	collectCnt, _ := strconv.ParseInt(data[fieldCollectCnt], 10, 64)
 	likeCnt, _ := strconv.ParseInt(data[fieldLikeCnt], 10, 64)
@@ -92,7 +134,25 @@ func (r *RedisInteractiveCache) Get(ctx context.Context, biz string, bizId int64
 		CollectCnt: collectCnt,
 		LikeCnt:    likeCnt,
 		ReadCnt:    readCnt,
-	}, err
+	}
+}
+
+func (r *RedisInteractiveCache) SetByIds(ctx context.Context,
+	biz string, intrs map[int64]domain.Interactive) error {
+	if len(intrs) == 0 {
+		return nil
+	}
+	pipe := r.client.Pipeline()
+	for bizId, intr := range intrs {
+		key := r.key(biz, bizId)
+		pipe.HMSet(ctx, key,
+			fieldLikeCnt, intr.LikeCnt,
+			fieldCollectCnt, intr.CollectCnt,
+			fieldReadCnt, intr.ReadCnt)
+		pipe.Expire(ctx, key, time.Minute*15)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
 func (r *RedisInteractiveCache) Set(ctx context.Context, biz string, bizId int64, intr domain.Interactive) error {
@@ -107,6 +167,10 @@ func (r *RedisInteractiveCache) Set(ctx context.Context, biz string, bizId int64
 	return r.client.Expire(ctx, key, time.Minute*15).Err()
 }
 
+func (r *RedisInteractiveCache) Del(ctx context.Context, biz string, bizId int64) error {
+	return r.client.Del(ctx, r.key(biz, bizId)).Err()
+}
+
 func (r *RedisInteractiveCache) key(biz string, bizId int64) string {
 	return fmt.Sprintf("interactive:%s:%d", biz, bizId)
 }
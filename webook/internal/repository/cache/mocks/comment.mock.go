@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/cache/comment.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/cache/comment.go -package=cachemocks -destination=./webook/internal/repository/cache/mocks/comment.mock.go
+//
+// Package cachemocks is a generated GoMock package.
+package cachemocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCommentCache is a mock of CommentCache interface.
+type MockCommentCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentCacheMockRecorder
+}
+
+// MockCommentCacheMockRecorder is the mock recorder for MockCommentCache.
+type MockCommentCacheMockRecorder struct {
+	mock *MockCommentCache
+}
+
+// NewMockCommentCache creates a new mock instance.
+func NewMockCommentCache(ctrl *gomock.Controller) *MockCommentCache {
+	mock := &MockCommentCache{ctrl: ctrl}
+	mock.recorder = &MockCommentCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommentCache) EXPECT() *MockCommentCacheMockRecorder {
+	return m.recorder
+}
+
+// GetCnt mocks base method.
+func (m *MockCommentCache) GetCnt(ctx context.Context, biz string, bizId int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCnt", ctx, biz, bizId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCnt indicates an expected call of GetCnt.
+func (mr *MockCommentCacheMockRecorder) GetCnt(ctx, biz, bizId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCnt", reflect.TypeOf((*MockCommentCache)(nil).GetCnt), ctx, biz, bizId)
+}
+
+// IncrCntIfPresent mocks base method.
+func (m *MockCommentCache) IncrCntIfPresent(ctx context.Context, biz string, bizId, delta int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrCntIfPresent", ctx, biz, bizId, delta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrCntIfPresent indicates an expected call of IncrCntIfPresent.
+func (mr *MockCommentCacheMockRecorder) IncrCntIfPresent(ctx, biz, bizId, delta any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrCntIfPresent", reflect.TypeOf((*MockCommentCache)(nil).IncrCntIfPresent), ctx, biz, bizId, delta)
+}
+
+// SetCnt mocks base method.
+func (m *MockCommentCache) SetCnt(ctx context.Context, biz string, bizId, cnt int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCnt", ctx, biz, bizId, cnt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCnt indicates an expected call of SetCnt.
+func (mr *MockCommentCacheMockRecorder) SetCnt(ctx, biz, bizId, cnt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCnt", reflect.TypeOf((*MockCommentCache)(nil).SetCnt), ctx, biz, bizId, cnt)
+}
@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/cache/password_reset.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/cache/password_reset.go -package=cachemocks -destination=./webook/internal/repository/cache/mocks/password_reset.mock.go
+//
+// Package cachemocks is a generated GoMock package.
+package cachemocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPasswordResetCache is a mock of PasswordResetCache interface.
+type MockPasswordResetCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordResetCacheMockRecorder
+}
+
+// MockPasswordResetCacheMockRecorder is the mock recorder for MockPasswordResetCache.
+type MockPasswordResetCacheMockRecorder struct {
+	mock *MockPasswordResetCache
+}
+
+// NewMockPasswordResetCache creates a new mock instance.
+func NewMockPasswordResetCache(ctrl *gomock.Controller) *MockPasswordResetCache {
+	mock := &MockPasswordResetCache{ctrl: ctrl}
+	mock.recorder = &MockPasswordResetCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordResetCache) EXPECT() *MockPasswordResetCacheMockRecorder {
+	return m.recorder
+}
+
+// Consume mocks base method.
+func (m *MockPasswordResetCache) Consume(ctx context.Context, token string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consume", ctx, token)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockPasswordResetCacheMockRecorder) Consume(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockPasswordResetCache)(nil).Consume), ctx, token)
+}
+
+// LockSend mocks base method.
+func (m *MockPasswordResetCache) LockSend(ctx context.Context, email string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockSend", ctx, email)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockSend indicates an expected call of LockSend.
+func (mr *MockPasswordResetCacheMockRecorder) LockSend(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockSend", reflect.TypeOf((*MockPasswordResetCache)(nil).LockSend), ctx, email)
+}
+
+// Set mocks base method.
+func (m *MockPasswordResetCache) Set(ctx context.Context, token string, uid int64, expiration time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, token, uid, expiration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockPasswordResetCacheMockRecorder) Set(ctx, token, uid, expiration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockPasswordResetCache)(nil).Set), ctx, token, uid, expiration)
+}
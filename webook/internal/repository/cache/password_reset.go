@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrResetTokenNotFound = errors.New("重置密码的链接无效或者已经过期")
+
+//go:generate mockgen -source=./password_reset.go -package=cachemocks -destination=mocks/password_reset.mock.go PasswordResetCache
+type PasswordResetCache interface {
+	// LockSend 同一个邮箱一分钟内只能发一次，返回 false 说明发送太频繁
+	LockSend(ctx context.Context, email string) (bool, error)
+	Set(ctx context.Context, token string, uid int64, expiration time.Duration) error
+	// Consume 取出来的同时删掉，保证链接只能用一次
+	Consume(ctx context.Context, token string) (int64, error)
+}
+
+type RedisPasswordResetCache struct {
+	cmd redis.Cmdable
+}
+
+func NewRedisPasswordResetCache(cmd redis.Cmdable) PasswordResetCache {
+	return &RedisPasswordResetCache{
+		cmd: cmd,
+	}
+}
+
+func (c *RedisPasswordResetCache) LockSend(ctx context.Context, email string) (bool, error) {
+	return c.cmd.SetNX(ctx, fmt.Sprintf("password_reset:send:%s", email), "", time.Minute).Result()
+}
+
+func (c *RedisPasswordResetCache) Set(ctx context.Context, token string, uid int64, expiration time.Duration) error {
+	return c.cmd.Set(ctx, c.key(token), uid, expiration).Err()
+}
+
+func (c *RedisPasswordResetCache) Consume(ctx context.Context, token string) (int64, error) {
+	uid, err := c.cmd.GetDel(ctx, c.key(token)).Int64()
+	if err == redis.Nil {
+		return 0, ErrResetTokenNotFound
+	}
+	return uid, err
+}
+
+// key 只存 token 的摘要，Redis 里面的数据泄露了也拿不到能用的链接
+func (c *RedisPasswordResetCache) key(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return fmt.Sprintf("password_reset:token:%s", hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+)
+
+var ErrRankingExpired = errors.New("本地缓存的热榜已经过期")
+
+type RankingCache interface {
+	Set(ctx context.Context, arts []domain.Article) error
+	Get(ctx context.Context) ([]domain.Article, error)
+}
+
+type RedisRankingCache struct {
+	client redis.Cmdable
+	key    string
+	// 要比计算热榜的间隔长，不然计算慢一点热榜就空了
+	expiration time.Duration
+}
+
+func NewRedisRankingCache(client redis.Cmdable) *RedisRankingCache {
+	return &RedisRankingCache{
+		client:     client,
+		key:        "ranking:top_n",
+		expiration: time.Minute * 10,
+	}
+}
+
+func (r *RedisRankingCache) Set(ctx context.Context, arts []domain.Article) error {
+	val, err := json.Marshal(arts)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.key, val, r.expiration).Err()
+}
+
+func (r *RedisRankingCache) Get(ctx context.Context) ([]domain.Article, error) {
+	val, err := r.client.Get(ctx, r.key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var res []domain.Article
+	err = json.Unmarshal(val, &res)
+	return res, err
+}
+
+// RankingLocalCache 进程内的热榜。
+// 热榜是所有人都看的，每次都查 Redis 没有必要，
+// Redis 崩溃的时候，也可以用过期的数据兜底
+type RankingLocalCache struct {
+	topN       atomic.Pointer[rankingItem]
+	expiration time.Duration
+}
+
+type rankingItem struct {
+	arts []domain.Article
+	ddl  time.Time
+}
+
+func NewRankingLocalCache() *RankingLocalCache {
+	return &RankingLocalCache{
+		expiration: time.Minute * 10,
+	}
+}
+
+func (r *RankingLocalCache) Set(ctx context.Context, arts []domain.Article) error {
+	r.topN.Store(&rankingItem{
+		arts: arts,
+		ddl:  time.Now().Add(r.expiration),
+	})
+	return nil
+}
+
+func (r *RankingLocalCache) Get(ctx context.Context) ([]domain.Article, error) {
+	item := r.topN.Load()
+	if item == nil || item.ddl.Before(time.Now()) {
+		return nil, ErrRankingExpired
+	}
+	return item.arts, nil
+}
+
+// ForceGet 不管有没有过期都返回，兜底用
+func (r *RankingLocalCache) ForceGet(ctx context.Context) ([]domain.Article, error) {
+	item := r.topN.Load()
+	if item == nil {
+		return nil, ErrRankingExpired
+	}
+	return item.arts, nil
+}
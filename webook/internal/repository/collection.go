@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao"
+)
+
+var ErrCollectionNotFound = dao.ErrRecordNotFound
+
+//go:generate mockgen -source=./collection.go -package=repomocks -destination=mocks/collection.mock.go CollectionRepository
+type CollectionRepository interface {
+	Create(ctx context.Context, c domain.Collection) (int64, error)
+	Rename(ctx context.Context, uid, cid int64, name string) error
+	// Delete 只删除收藏夹本身，里面的东西要先取消收藏
+	Delete(ctx context.Context, uid, cid int64) error
+	GetById(ctx context.Context, uid, cid int64) (domain.Collection, error)
+	// List 用户创建的收藏夹，不包含默认收藏夹，ItemCnt 没有填充
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.Collection, error)
+	// ItemCnts 收藏夹里面有多少个东西，key 是 cid，空的收藏夹不在结果里面
+	ItemCnts(ctx context.Context, uid int64, cids []int64) (map[int64]int64, error)
+	// ListItems 收藏夹里面的东西，Title 没有填充
+	ListItems(ctx context.Context, uid, cid int64, offset, limit int) ([]domain.CollectionItem, error)
+	MoveItem(ctx context.Context, uid int64, biz string, bizId, cid int64) error
+}
+
+// collectionRepository 收藏夹不缓存，只有用户自己会看自己的收藏夹，访问量不大
+type collectionRepository struct {
+	dao dao.CollectionDAO
+}
+
+func NewCollectionRepository(d dao.CollectionDAO) CollectionRepository {
+	return &collectionRepository{
+		dao: d,
+	}
+}
+
+func (c *collectionRepository) Create(ctx context.Context, col domain.Collection) (int64, error) {
+	return c.dao.Insert(ctx, dao.Collection{
+		Name: col.Name,
+		Uid:  col.Uid,
+	})
+}
+
+func (c *collectionRepository) Rename(ctx context.Context, uid, cid int64, name string) error {
+	return c.dao.UpdateName(ctx, uid, cid, name)
+}
+
+func (c *collectionRepository) Delete(ctx context.Context, uid, cid int64) error {
+	return c.dao.Delete(ctx, uid, cid)
+}
+
+func (c *collectionRepository) GetById(ctx context.Context, uid, cid int64) (domain.Collection, error) {
+	col, err := c.dao.GetById(ctx, uid, cid)
+	if err != nil {
+		return domain.Collection{}, err
+	}
+	return c.toDomain(col), nil
+}
+
+func (c *collectionRepository) List(ctx context.Context,
+	uid int64, offset, limit int) ([]domain.Collection, error) {
+	cols, err := c.dao.ListByUid(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Collection, domain.Collection](cols,
+		func(idx int, src dao.Collection) domain.Collection {
+			return c.toDomain(src)
+		}), nil
+}
+
+func (c *collectionRepository) ItemCnts(ctx context.Context,
+	uid int64, cids []int64) (map[int64]int64, error) {
+	return c.dao.CountItems(ctx, uid, cids)
+}
+
+func (c *collectionRepository) ListItems(ctx context.Context,
+	uid, cid int64, offset, limit int) ([]domain.CollectionItem, error) {
+	items, err := c.dao.ListItems(ctx, uid, cid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.UserCollectionBiz, domain.CollectionItem](items,
+		func(idx int, src dao.UserCollectionBiz) domain.CollectionItem {
+			return domain.CollectionItem{
+				Cid:   src.Cid,
+				Biz:   src.Biz,
+				BizId: src.BizId,
+				Ctime: time.UnixMilli(src.Ctime),
+			}
+		}), nil
+}
+
+func (c *collectionRepository) MoveItem(ctx context.Context,
+	uid int64, biz string, bizId, cid int64) error {
+	return c.dao.MoveItem(ctx, uid, biz, bizId, cid)
+}
+
+func (c *collectionRepository) toDomain(col dao.Collection) domain.Collection {
+	return domain.Collection{
+		Id:    col.Id,
+		Name:  col.Name,
+		Uid:   col.Uid,
+		Ctime: time.UnixMilli(col.Ctime),
+		Utime: time.UnixMilli(col.Utime),
+	}
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+var ErrCommentNotFound = dao.ErrRecordNotFound
+
+//go:generate mockgen -source=./comment.go -package=repomocks -destination=mocks/comment.mock.go CommentRepository
+type CommentRepository interface {
+	Create(ctx context.Context, c domain.Comment) (int64, error)
+	FindById(ctx context.Context, id int64) (domain.Comment, error)
+	// FindTopLevel 顶级评论，带上回复数量
+	FindTopLevel(ctx context.Context, biz string, bizId, maxId int64, limit int) ([]domain.Comment, error)
+	FindReplies(ctx context.Context, rootId, minId int64, limit int) ([]domain.Comment, error)
+	Delete(ctx context.Context, c domain.Comment) error
+	Count(ctx context.Context, biz string, bizId int64) (int64, error)
+}
+
+type CachedCommentRepository struct {
+	dao   dao.CommentDAO
+	cache cache.CommentCache
+	l     logger.LoggerV1
+}
+
+func NewCachedCommentRepository(d dao.CommentDAO,
+	c cache.CommentCache, l logger.LoggerV1) CommentRepository {
+	return &CachedCommentRepository{
+		dao:   d,
+		cache: c,
+		l:     l,
+	}
+}
+
+func (c *CachedCommentRepository) Create(ctx context.Context, cmt domain.Comment) (int64, error) {
+	id, err := c.dao.Insert(ctx, c.toEntity(cmt))
+	if err != nil {
+		return 0, err
+	}
+	err = c.cache.IncrCntIfPresent(ctx, cmt.Biz, cmt.BizId, 1)
+	if err != nil {
+		// 评论已经成功了，计数不准问题不大，等缓存过期
+		c.l.Error("更新评论数缓存失败",
+			logger.String("biz", cmt.Biz),
+			logger.Int64("bizId", cmt.BizId),
+			logger.Error(err))
+	}
+	return id, nil
+}
+
+func (c *CachedCommentRepository) FindById(ctx context.Context, id int64) (domain.Comment, error) {
+	cmt, err := c.dao.FindById(ctx, id)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	return c.toDomain(cmt), nil
+}
+
+func (c *CachedCommentRepository) FindTopLevel(ctx context.Context,
+	biz string, bizId, maxId int64, limit int) ([]domain.Comment, error) {
+	cmts, err := c.dao.FindTopLevel(ctx, biz, bizId, maxId, limit)
+	if err != nil {
+		return nil, err
+	}
+	ids := slice.Map[dao.Comment, int64](cmts, func(idx int, src dao.Comment) int64 {
+		return src.Id
+	})
+	cnts, err := c.dao.CountReplies(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Comment, domain.Comment](cmts, func(idx int, src dao.Comment) domain.Comment {
+		res := c.toDomain(src)
+		res.ReplyCnt = cnts[src.Id]
+		return res
+	}), nil
+}
+
+func (c *CachedCommentRepository) FindReplies(ctx context.Context,
+	rootId, minId int64, limit int) ([]domain.Comment, error) {
+	cmts, err := c.dao.FindReplies(ctx, rootId, minId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Comment, domain.Comment](cmts, func(idx int, src dao.Comment) domain.Comment {
+		return c.toDomain(src)
+	}), nil
+}
+
+func (c *CachedCommentRepository) Delete(ctx context.Context, cmt domain.Comment) error {
+	cnt, err := c.dao.Delete(ctx, cmt.Id)
+	if err != nil {
+		return err
+	}
+	if cnt == 0 {
+		return nil
+	}
+	err = c.cache.IncrCntIfPresent(ctx, cmt.Biz, cmt.BizId, -cnt)
+	if err != nil {
+		c.l.Error("更新评论数缓存失败",
+			logger.String("biz", cmt.Biz),
+			logger.Int64("bizId", cmt.BizId),
+			logger.Error(err))
+	}
+	return nil
+}
+
+func (c *CachedCommentRepository) Count(ctx context.Context, biz string, bizId int64) (int64, error) {
+	cnt, err := c.cache.GetCnt(ctx, biz, bizId)
+	if err == nil {
+		return cnt, nil
+	}
+	cnt, err = c.dao.Count(ctx, biz, bizId)
+	if err != nil {
+		return 0, err
+	}
+	go func() {
+		er := c.cache.SetCnt(context.Background(), biz, bizId, cnt)
+		if er != nil {
+			c.l.Error("回写评论数缓存失败",
+				logger.String("biz", biz),
+				logger.Int64("bizId", bizId),
+				logger.Error(er))
+		}
+	}()
+	return cnt, nil
+}
+
+func (c *CachedCommentRepository) toEntity(cmt domain.Comment) dao.Comment {
+	return dao.Comment{
+		Id:       cmt.Id,
+		Biz:      cmt.Biz,
+		BizId:    cmt.BizId,
+		Uid:      cmt.Uid,
+		RootId:   cmt.RootId,
+		ParentId: cmt.ParentId,
+		Content:  cmt.Content,
+	}
+}
+
+func (c *CachedCommentRepository) toDomain(cmt dao.Comment) domain.Comment {
+	return domain.Comment{
+		Id:       cmt.Id,
+		Biz:      cmt.Biz,
+		BizId:    cmt.BizId,
+		Uid:      cmt.Uid,
+		RootId:   cmt.RootId,
+		ParentId: cmt.ParentId,
+		Content:  cmt.Content,
+		Ctime:    time.UnixMilli(cmt.Ctime),
+		Utime:    time.UnixMilli(cmt.Utime),
+	}
+}
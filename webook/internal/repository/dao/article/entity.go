@@ -8,9 +8,73 @@ type Article struct {
 	Content string `gorm:"type=BLOB" bson:"content,omitempty"`
 	// 作者
 	AuthorId int64 `gorm:"index" bson:"author_id,omitempty"`
-	Status   uint8 `bson:"status,omitempty"`
-	Ctime    int64 `bson:"ctime,omitempty"`
-	Utime    int64 `bson:"utime,omitempty"`
+	Status   uint8 `gorm:"index:status_publish_at" bson:"status,omitempty"`
+	// PublishAt 定时发表的时间，只有在 Status 是定时发表的时候才有意义
+	// 定时任务按照 status, publish_at 来查找到期的文章
+	PublishAt int64 `gorm:"index:status_publish_at" bson:"publish_at,omitempty"`
+	// Category 分类，一篇文章只能属于一个分类
+	Category string `gorm:"type:varchar(64)" bson:"category,omitempty"`
+	// Tags 在 MySQL 里面是单独的表，在 MongoDB 里面直接存成数组
+	Tags  []string `gorm:"-" bson:"tags,omitempty"`
+	Ctime int64    `bson:"ctime,omitempty"`
+	Utime int64    `bson:"utime,omitempty"`
+}
+
+// ArticleTag 制作库里面文章的标签
+type ArticleTag struct {
+	Id        int64  `gorm:"primaryKey,autoIncrement"`
+	ArticleId int64  `gorm:"uniqueIndex:aid_tag"`
+	Tag       string `gorm:"type:varchar(64);uniqueIndex:aid_tag"`
+	Ctime     int64
+}
+
+// PublishedArticleTag 线上库里面文章的标签
+// 只有公开可见的文章才会有，文章被设置为仅自己可见的时候就会删除
+type PublishedArticleTag struct {
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 按照标签查找文章
+	Tag       string `gorm:"type:varchar(64);uniqueIndex:tag_aid"`
+	ArticleId int64  `gorm:"uniqueIndex:tag_aid;index"`
+	Ctime     int64
+}
+
+// Tag 标签本身，用于自动补全和统计
+type Tag struct {
+	Id   int64  `gorm:"primaryKey,autoIncrement" bson:"-"`
+	Name string `gorm:"type:varchar(64);uniqueIndex" bson:"_id"`
+	// Cnt 带有这个标签的公开文章数量
+	// 和 PublishedArticleTag 在同一个事务里面更新
+	Cnt   int64 `bson:"cnt"`
+	Ctime int64 `bson:"-"`
+	Utime int64 `bson:"-"`
+}
+
+// ArticleRevision 文章的历史版本，只插入，不更新
+type ArticleRevision struct {
+	Id int64 `gorm:"primaryKey,autoIncrement" bson:"id,omitempty"`
+	// 按照文章查询历史版本，并且按照创建时间排序
+	ArticleId int64  `gorm:"index:aid_ctime" bson:"article_id,omitempty"`
+	Title     string `gorm:"type=varchar(4096)" bson:"title,omitempty"`
+	Content   string `gorm:"type=BLOB" bson:"content,omitempty"`
+	AuthorId  int64  `bson:"author_id,omitempty"`
+	Status    uint8  `bson:"status,omitempty"`
+	Category  string `gorm:"type:varchar(64)" bson:"category,omitempty"`
+	// Tags 历史版本只是快照，不需要按照标签查询，所以在 MySQL 里面直接存成 JSON
+	Tags  []string `gorm:"type:varchar(1024);serializer:json" bson:"tags,omitempty"`
+	Ctime int64    `gorm:"index:aid_ctime" bson:"ctime,omitempty"`
+}
+
+func newRevision(art Article) ArticleRevision {
+	return ArticleRevision{
+		ArticleId: art.Id,
+		Title:     art.Title,
+		Content:   art.Content,
+		AuthorId:  art.AuthorId,
+		Status:    art.Status,
+		Category:  art.Category,
+		Tags:      art.Tags,
+		Ctime:     art.Utime,
+	}
 }
 
 // PublishedArticle 衍生类型，偷个懒
@@ -23,6 +87,22 @@ type PublishedArticleV1 struct {
 	Title    string `gorm:"type=varchar(4096)" bson:"title,omitempty"`
 	AuthorId int64  `gorm:"index" bson:"author_id,omitempty"`
 	Status   uint8  `bson:"status,omitempty"`
+	Category string `gorm:"type:varchar(64)" bson:"category,omitempty"`
 	Ctime    int64  `bson:"ctime,omitempty"`
 	Utime    int64  `bson:"utime,omitempty"`
 }
+
+// BlobTask 对象存储的补偿任务，上传或者删除失败的时候写入
+// 每篇文章只保留最新的一个任务，后面的操作会覆盖前面的
+type BlobTask struct {
+	Id        int64 `gorm:"primaryKey,autoIncrement"`
+	ArticleId int64 `gorm:"uniqueIndex"`
+	Op        uint8
+	// Content 上传的内容。不能从制作库读，因为制作库可能已经被修改过了
+	Content string `gorm:"type=BLOB"`
+	Retries int
+	// NextTime 下一次重试的时间
+	NextTime int64 `gorm:"index"`
+	Ctime    int64
+	Utime    int64
+}
@@ -3,10 +3,14 @@ package article
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/outbox"
 )
 
 type GORMArticleDAO struct {
@@ -34,6 +38,10 @@ func (dao *GORMArticleDAO) GetByAuthor(ctx context.Context, author int64, offset
 		//	{Column: clause.Column{Name: "ctime"}, Desc: false},
 		//}}).
 		Find(&arts).Error
+	if err != nil {
+		return nil, err
+	}
+	err = fillTags(dao.db.WithContext(ctx), arts)
 	return arts, err
 }
 func (dao *GORMArticleDAO) GetPubById(ctx context.Context, id int64) (PublishedArticle, error) {
@@ -41,6 +49,13 @@ func (dao *GORMArticleDAO) GetPubById(ctx context.Context, id int64) (PublishedA
 	err := dao.db.WithContext(ctx).
 		Where("id = ?", id).
 		First(&pub).Error
+	if err != nil {
+		return pub, err
+	}
+	err = dao.db.WithContext(ctx).Model(&PublishedArticleTag{}).
+		Where("article_id = ?", id).
+		Order("id").
+		Pluck("tag", &pub.Tags).Error
 	return pub, err
 }
 
@@ -49,6 +64,13 @@ func (dao *GORMArticleDAO) GetById(ctx context.Context, id int64) (Article, erro
 	err := dao.db.WithContext(ctx).Model(&Article{}).
 		Where("id = ?", id).
 		First(&art).Error
+	if err != nil {
+		return art, err
+	}
+	err = dao.db.WithContext(ctx).Model(&ArticleTag{}).
+		Where("article_id = ?", id).
+		Order("id").
+		Pluck("tag", &art.Tags).Error
 	return art, err
 }
 
@@ -60,6 +82,7 @@ func NewGORMArticleDAO(db *gorm.DB) ArticleDAO {
 
 func (dao *GORMArticleDAO) SyncStatus(ctx context.Context, author, id int64, status uint8) error {
 	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
 		res := tx.Model(&Article{}).
 			Where("id=? AND author_id = ?", id, author).
 			Update("status", status)
@@ -70,6 +93,10 @@ func (dao *GORMArticleDAO) SyncStatus(ctx context.Context, author, id int64, sta
 			return ErrPossibleIncorrectAuthor
 		}
 
+		prev, err := pubStatus(tx, &PublishedArticle{}, id)
+		if err != nil {
+			return err
+		}
 		res = tx.Model(&PublishedArticle{}).
 			Where("id=? AND author_id = ?", id, author).Update("status", status)
 		if res.Error != nil {
@@ -78,10 +105,57 @@ func (dao *GORMArticleDAO) SyncStatus(ctx context.Context, author, id int64, sta
 		if res.RowsAffected != 1 {
 			return ErrPossibleIncorrectAuthor
 		}
-		return nil
+		// 仅自己可见的文章不能再被按照标签查到
+		var tags []string
+		if status == statusPublished {
+			err = tx.Model(&ArticleTag{}).
+				Where("article_id = ?", id).
+				Order("id").
+				Pluck("tag", &tags).Error
+			if err != nil {
+				return err
+			}
+		}
+		err = syncPubTags(tx, id, tags, now)
+		if err != nil {
+			return err
+		}
+		if prev == status {
+			return nil
+		}
+		art := Article{Id: id, AuthorId: author, Status: status}
+		if status == statusPublished {
+			// 重新公开的时候，事件里面要带上标题和摘要
+			var pub PublishedArticle
+			err = tx.Where("id = ?", id).First(&pub).Error
+			if err != nil {
+				return err
+			}
+			art = Article(pub)
+		}
+		msgs, err := syncMessages(prev, art, now)
+		if err != nil {
+			return err
+		}
+		return outbox.Save(tx, msgs...)
 	})
 }
 
+// pubStatus 线上库里面文章现在的状态，还没有发表过就是 0。
+// 加了锁，免得并发同步的时候都以为自己是第一次发表
+func pubStatus(tx *gorm.DB, model any, id int64) (uint8, error) {
+	var res struct {
+		Status uint8
+	}
+	err := tx.Model(model).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("status").
+		Where("id = ?", id).
+		Limit(1).
+		Scan(&res).Error
+	return res.Status, err
+}
+
 func (dao *GORMArticleDAO) Sync(ctx context.Context,
 	art Article) (int64, error) {
 	tx := dao.db.WithContext(ctx).Begin()
@@ -101,6 +175,10 @@ func (dao *GORMArticleDAO) Sync(ctx context.Context,
 		return 0, err
 	}
 	art.Id = id
+	prev, err := pubStatus(tx, &PublishedArticle{}, id)
+	if err != nil {
+		return 0, err
+	}
 	publishArt := PublishedArticle(art)
 	publishArt.Utime = now
 	publishArt.Ctime = now
@@ -108,15 +186,33 @@ func (dao *GORMArticleDAO) Sync(ctx context.Context,
 		// ID 冲突的时候。实际上，在 MYSQL 里面你写不写都可以
 		Columns: []clause.Column{{Name: "id"}},
 		DoUpdates: clause.Assignments(map[string]interface{}{
-			"title":   art.Title,
-			"content": art.Content,
-			"status":  art.Status,
-			"utime":   now,
+			"title":    art.Title,
+			"content":  art.Content,
+			"status":   art.Status,
+			"category": art.Category,
+			"utime":    now,
 		}),
 	}).Create(&publishArt).Error
 	if err != nil {
 		return 0, err
 	}
+	var pubTags []string
+	if art.Status == statusPublished {
+		pubTags = art.Tags
+	}
+	err = syncPubTags(tx, id, pubTags, now)
+	if err != nil {
+		return 0, err
+	}
+	// 事件和线上库一起提交，提交之后由 outbox.Relay 投递
+	msgs, err := syncMessages(prev, art, now)
+	if err != nil {
+		return 0, err
+	}
+	err = outbox.Save(tx, msgs...)
+	if err != nil {
+		return 0, err
+	}
 	tx.Commit()
 	return id, tx.Error
 }
@@ -160,29 +256,419 @@ func (dao *GORMArticleDAO) Insert(ctx context.Context,
 	now := time.Now().UnixMilli()
 	art.Ctime = now
 	art.Utime = now
-	err := dao.db.WithContext(ctx).Create(&art).Error
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&art).Error
+		if err != nil {
+			return err
+		}
+		err = replaceTags(tx, art.Id, art.Tags, now)
+		if err != nil {
+			return err
+		}
+		// 每一次保存都追加一个历史版本
+		rev := newRevision(art)
+		return tx.Create(&rev).Error
+	})
 	// 返回自增主键
 	return art.Id, err
 }
 
-// UpdateById 只更新标题、内容和状态
+// UpdateById 只更新标题、内容、状态、分类和标签
 func (dao *GORMArticleDAO) UpdateById(ctx context.Context,
 	art Article) error {
 	now := time.Now().UnixMilli()
-	res := dao.db.Model(&Article{}).WithContext(ctx).
-		Where("id=? AND author_id = ? ", art.Id, art.AuthorId).
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		kept, err := keepSchedule(tx, art, now)
+		if err != nil {
+			return err
+		}
+		if kept {
+			art.Status = statusScheduled
+		} else {
+			res := tx.Model(&Article{}).
+				Where("id=? AND author_id = ? ", art.Id, art.AuthorId).
+				Updates(map[string]any{
+					"title":      art.Title,
+					"content":    art.Content,
+					"status":     art.Status,
+					"publish_at": art.PublishAt,
+					"category":   art.Category,
+					"utime":      now,
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return errors.New("更新数据失败")
+			}
+		}
+		err = replaceTags(tx, art.Id, art.Tags, now)
+		if err != nil {
+			return err
+		}
+		art.Utime = now
+		rev := newRevision(art)
+		return tx.Create(&rev).Error
+	})
+}
+
+// keepSchedule 保存草稿的时候，如果文章还在等定时发表，只更新内容，不动状态和发表时间。
+// 条件和更新在一条语句里面，不会和 ClaimScheduled 互相覆盖
+func keepSchedule(tx *gorm.DB, art Article, now int64) (bool, error) {
+	if art.Status != statusUnpublished {
+		return false, nil
+	}
+	res := tx.Model(&Article{}).
+		Where("id = ? AND author_id = ? AND status = ?", art.Id, art.AuthorId, statusScheduled).
 		Updates(map[string]any{
-			"title":   art.Title,
-			"content": art.Content,
-			"status":  art.Status,
-			"utime":   now,
+			"title":    art.Title,
+			"content":  art.Content,
+			"category": art.Category,
+			"utime":    now,
 		})
-	err := res.Error
+	return res.RowsAffected == 1, res.Error
+}
+
+func (dao *GORMArticleDAO) ListScheduled(ctx context.Context,
+	author int64, offset, limit int) ([]Article, error) {
+	var arts []Article
+	err := dao.db.WithContext(ctx).
+		Where("author_id = ? AND status = ?", author, statusScheduled).
+		Offset(offset).
+		Limit(limit).
+		Order("publish_at ASC").
+		Find(&arts).Error
+	if err != nil {
+		return nil, err
+	}
+	err = fillTags(dao.db.WithContext(ctx), arts)
+	return arts, err
+}
+
+func (dao *GORMArticleDAO) Reschedule(ctx context.Context, author, id, publishAt int64) error {
+	res := dao.db.WithContext(ctx).Model(&Article{}).
+		Where("id = ? AND author_id = ? AND status = ?", id, author, statusScheduled).
+		Updates(map[string]any{
+			"publish_at": publishAt,
+			"utime":      time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected != 1 {
+		return ErrPossibleIncorrectAuthor
+	}
+	return nil
+}
+
+func (dao *GORMArticleDAO) CancelSchedule(ctx context.Context, author, id int64) error {
+	res := dao.db.WithContext(ctx).Model(&Article{}).
+		Where("id = ? AND author_id = ? AND status = ?", id, author, statusScheduled).
+		Updates(map[string]any{
+			"status":     domain.ArticleStatusUnpublished.ToUint8(),
+			"publish_at": 0,
+			"utime":      time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected != 1 {
+		return ErrPossibleIncorrectAuthor
+	}
+	return nil
+}
+
+func (dao *GORMArticleDAO) FindDueScheduled(ctx context.Context, now int64, limit int) ([]Article, error) {
+	var arts []Article
+	// 命中 status, publish_at 的联合索引
+	err := dao.db.WithContext(ctx).
+		Where("status = ? AND publish_at <= ?", statusScheduled, now).
+		Order("publish_at ASC").
+		Limit(limit).
+		Find(&arts).Error
+	if err != nil {
+		return nil, err
+	}
+	// 到期之后会整篇同步到线上库，所以标签也要带上
+	err = fillTags(dao.db.WithContext(ctx), arts)
+	return arts, err
+}
+
+func (dao *GORMArticleDAO) ClaimScheduled(ctx context.Context, id, publishAt, leaseUntil int64) (bool, error) {
+	// 乐观锁，publish_at 没有被别人修改过，才能抢占成功
+	res := dao.db.WithContext(ctx).Model(&Article{}).
+		Where("id = ? AND status = ? AND publish_at = ?", id, statusScheduled, publishAt).
+		Update("publish_at", leaseUntil)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (dao *GORMArticleDAO) ListRevisions(ctx context.Context,
+	author, id int64, offset, limit int) ([]ArticleRevision, error) {
+	var revs []ArticleRevision
+	// 命中 article_id, ctime 的联合索引
+	err := dao.db.WithContext(ctx).
+		Where("article_id = ? AND author_id = ?", id, author).
+		Offset(offset).
+		Limit(limit).
+		Order("ctime DESC").
+		Order("id DESC").
+		Find(&revs).Error
+	return revs, err
+}
+
+func (dao *GORMArticleDAO) GetRevision(ctx context.Context,
+	author, id, revId int64) (ArticleRevision, error) {
+	var rev ArticleRevision
+	err := dao.db.WithContext(ctx).
+		Where("id = ? AND article_id = ? AND author_id = ?", revId, id, author).
+		First(&rev).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return rev, ErrRevisionNotFound
+	}
+	return rev, err
+}
+
+// Restore 把历史版本的标题、内容、分类和标签作为新的草稿写回制作库，同时再追加一个历史版本
+// 线上库不受影响，作者需要重新发表
+func (dao *GORMArticleDAO) Restore(ctx context.Context, author, id, revId int64) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rev ArticleRevision
+		err := tx.Where("id = ? AND article_id = ?", revId, id).
+			First(&rev).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRevisionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		status := domain.ArticleStatusUnpublished.ToUint8()
+		// 和 SyncStatus 一样，依赖 author_id 来校验是不是作者本人
+		res := tx.Model(&Article{}).
+			Where("id = ? AND author_id = ?", id, author).
+			Updates(map[string]any{
+				"title":    rev.Title,
+				"content":  rev.Content,
+				"status":   status,
+				"category": rev.Category,
+				"utime":    now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return ErrPossibleIncorrectAuthor
+		}
+		err = replaceTags(tx, id, rev.Tags, now)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&ArticleRevision{
+			ArticleId: id,
+			Title:     rev.Title,
+			Content:   rev.Content,
+			AuthorId:  author,
+			Status:    status,
+			Category:  rev.Category,
+			Tags:      rev.Tags,
+			Ctime:     now,
+		}).Error
+	})
+}
+
+func (dao *GORMArticleDAO) ListPub(ctx context.Context,
+	startId int64, limit int) ([]PublishedArticle, error) {
+	var arts []PublishedArticle
+	// 按照主键翻页，不会因为 offset 太大而变慢
+	err := dao.db.WithContext(ctx).
+		Where("id > ? AND status = ?", startId, statusPublished).
+		Order("id ASC").
+		Limit(limit).
+		Find(&arts).Error
+	return arts, err
+}
+
+func (dao *GORMArticleDAO) GetPubByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error) {
+	var arts []PublishedArticle
+	if len(ids) == 0 {
+		return arts, nil
+	}
+	err := dao.db.WithContext(ctx).
+		Where("id IN ? AND status = ?", ids, statusPublished).
+		Find(&arts).Error
+	return arts, err
+}
+
+func (dao *GORMArticleDAO) ListPubByTag(ctx context.Context,
+	tag string, offset, limit int) ([]PublishedArticle, error) {
+	var arts []PublishedArticle
+	err := dao.db.WithContext(ctx).Model(&PublishedArticle{}).
+		Select("published_articles.*").
+		Joins("JOIN published_article_tags t ON t.article_id = published_articles.id").
+		Where("t.tag = ? AND published_articles.status = ?", tag, statusPublished).
+		Offset(offset).
+		Limit(limit).
+		Order("published_articles.utime DESC").
+		Find(&arts).Error
+	if err != nil || len(arts) == 0 {
+		return arts, err
+	}
+	ids := make([]int64, 0, len(arts))
+	for _, art := range arts {
+		ids = append(ids, art.Id)
+	}
+	var tags []PublishedArticleTag
+	err = dao.db.WithContext(ctx).
+		Where("article_id IN ?", ids).
+		Order("id").
+		Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	tagMap := make(map[int64][]string, len(arts))
+	for _, t := range tags {
+		tagMap[t.ArticleId] = append(tagMap[t.ArticleId], t.Tag)
+	}
+	for i := range arts {
+		arts[i].Tags = tagMap[arts[i].Id]
+	}
+	return arts, nil
+}
+
+func (dao *GORMArticleDAO) SuggestTags(ctx context.Context, prefix string, limit int) ([]Tag, error) {
+	var tags []Tag
+	// 前缀匹配可以用上 name 上的索引
+	err := dao.db.WithContext(ctx).
+		Where("name LIKE ? AND cnt > 0", escapeLike(prefix)+"%").
+		Order("cnt DESC").
+		Limit(limit).
+		Find(&tags).Error
+	return tags, err
+}
+
+func (dao *GORMArticleDAO) TagCounts(ctx context.Context, tags []string) ([]Tag, error) {
+	var res []Tag
+	err := dao.db.WithContext(ctx).
+		Where("name IN ? AND cnt > 0", tags).
+		Find(&res).Error
+	return res, err
+}
+
+// replaceTags 用 tags 整体覆盖制作库里面文章的标签
+func replaceTags(tx *gorm.DB, artId int64, tags []string, now int64) error {
+	err := tx.Where("article_id = ?", artId).Delete(&ArticleTag{}).Error
+	if err != nil || len(tags) == 0 {
+		return err
+	}
+	rows := make([]ArticleTag, 0, len(tags))
+	for _, tag := range tags {
+		rows = append(rows, ArticleTag{ArticleId: artId, Tag: tag, Ctime: now})
+	}
+	return tx.Create(&rows).Error
+}
+
+// syncPubTags 把线上库的标签更新为 tags，并且按照增减的标签调整计数
+// 必须在事务里面调用，这样计数和标签才是一致的
+func syncPubTags(tx *gorm.DB, artId int64, tags []string, now int64) error {
+	var old []string
+	err := tx.Model(&PublishedArticleTag{}).
+		Where("article_id = ?", artId).
+		Pluck("tag", &old).Error
 	if err != nil {
 		return err
 	}
-	if res.RowsAffected == 0 {
-		return errors.New("更新数据失败")
+	added, removed := diffTags(old, tags)
+	if len(removed) > 0 {
+		err = tx.Where("article_id = ? AND tag IN ?", artId, removed).
+			Delete(&PublishedArticleTag{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&Tag{}).Where("name IN ?", removed).
+			Updates(map[string]any{
+				"cnt":   gorm.Expr("cnt - 1"),
+				"utime": now,
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	rows := make([]PublishedArticleTag, 0, len(added))
+	for _, tag := range added {
+		rows = append(rows, PublishedArticleTag{ArticleId: artId, Tag: tag, Ctime: now})
+	}
+	err = tx.Create(&rows).Error
+	if err != nil {
+		return err
+	}
+	for _, tag := range added {
+		err = tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "name"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"cnt":   gorm.Expr("cnt + 1"),
+				"utime": now,
+			}),
+		}).Create(&Tag{Name: tag, Cnt: 1, Ctime: now, Utime: now}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillTags 批量查询制作库文章的标签
+func fillTags(db *gorm.DB, arts []Article) error {
+	if len(arts) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(arts))
+	for _, art := range arts {
+		ids = append(ids, art.Id)
+	}
+	var tags []ArticleTag
+	err := db.Where("article_id IN ?", ids).
+		Order("id").
+		Find(&tags).Error
+	if err != nil {
+		return err
+	}
+	tagMap := make(map[int64][]string, len(arts))
+	for _, t := range tags {
+		tagMap[t.ArticleId] = append(tagMap[t.ArticleId], t.Tag)
+	}
+	for i := range arts {
+		arts[i].Tags = tagMap[arts[i].Id]
 	}
 	return nil
 }
+
+// diffTags 计算从 old 变成 cur 需要新增和删除的标签
+func diffTags(old, cur []string) (added, removed []string) {
+	oldSet := make(map[string]struct{}, len(old))
+	for _, t := range old {
+		oldSet[t] = struct{}{}
+	}
+	curSet := make(map[string]struct{}, len(cur))
+	for _, t := range cur {
+		curSet[t] = struct{}{}
+		if _, ok := oldSet[t]; !ok {
+			added = append(added, t)
+		}
+	}
+	for _, t := range old {
+		if _, ok := curSet[t]; !ok {
+			removed = append(removed, t)
+		}
+	}
+	return added, removed
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
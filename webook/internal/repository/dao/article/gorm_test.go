@@ -0,0 +1,174 @@
+package article
+
+import (
+	"context"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGORMArticleDAO_Restore(t *testing.T) {
+	cols := []string{"id", "article_id", "title", "content", "author_id", "status", "category", "tags", "ctime"}
+	testCases := []struct {
+		name    string
+		sqlmock func(mock sqlmock.Sqlmock)
+
+		wantErr error
+	}{
+		{
+			name: "恢复分类和标签",
+			sqlmock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `article_revisions` WHERE id = \\? AND article_id = \\?").
+					WillReturnRows(sqlmock.NewRows(cols).
+						AddRow(2, 1, "旧标题", "旧内容", 123, statusPublished, "后端", `["Go","Kafka"]`, 100))
+				mock.ExpectExec("UPDATE `articles` SET `category`=\\?,`content`=\\?,`status`=\\?,`title`=\\?,`utime`=\\? "+
+					"WHERE id = \\? AND author_id = \\?").
+					WithArgs("后端", "旧内容", sqlmock.AnyArg(), "旧标题", sqlmock.AnyArg(), int64(1), int64(123)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `article_tags` WHERE article_id = \\?").
+					WithArgs(int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `article_tags`").
+					WithArgs(int64(1), "Go", sqlmock.AnyArg(), int64(1), "Kafka", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 2))
+				mock.ExpectExec("INSERT INTO `article_revisions`").
+					WithArgs(int64(1), "旧标题", "旧内容", int64(123), sqlmock.AnyArg(),
+						"后端", `["Go","Kafka"]`, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "不是作者本人",
+			sqlmock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `article_revisions` WHERE id = \\? AND article_id = \\?").
+					WillReturnRows(sqlmock.NewRows(cols).
+						AddRow(2, 1, "旧标题", "旧内容", 123, statusPublished, "后端", `["Go"]`, 100))
+				mock.ExpectExec("UPDATE `articles`").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			wantErr: ErrPossibleIncorrectAuthor,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			tc.sqlmock(mock)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			dao := NewGORMArticleDAO(db)
+			err = dao.Restore(context.Background(), 123, 1, 2)
+			assert.Equal(t, tc.wantErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGORMArticleDAO_UpdateById(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sqlmock func(mock sqlmock.Sqlmock)
+		art     Article
+
+		wantErr error
+	}{
+		{
+			// 保存草稿不会取消定时发表
+			name: "定时发表的文章保存草稿",
+			sqlmock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` SET `category`=\\?,`content`=\\?,`title`=\\?,`utime`=\\? "+
+					"WHERE id = \\? AND author_id = \\? AND status = \\?").
+					WithArgs("后端", "新内容", "新标题", sqlmock.AnyArg(), int64(1), int64(123), statusScheduled).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `article_tags` WHERE article_id = \\?").
+					WithArgs(int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO `article_revisions`").
+					WithArgs(int64(1), "新标题", "新内容", int64(123), statusScheduled,
+						"后端", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectCommit()
+			},
+			art: Article{Id: 1, Title: "新标题", Content: "新内容", AuthorId: 123,
+				Status: statusUnpublished, Category: "后端"},
+		},
+		{
+			name: "不是定时发表的文章保存草稿",
+			sqlmock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` SET `category`=\\?,`content`=\\?,`title`=\\?,`utime`=\\? " +
+					"WHERE id = \\? AND author_id = \\? AND status = \\?").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("UPDATE `articles` SET `category`=\\?,`content`=\\?,`publish_at`=\\?,`status`=\\?,`title`=\\?,`utime`=\\? "+
+					"WHERE id=\\? AND author_id = \\?").
+					WithArgs("后端", "新内容", int64(0), statusUnpublished, "新标题", sqlmock.AnyArg(),
+						int64(1), int64(123)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `article_tags` WHERE article_id = \\?").
+					WithArgs(int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO `article_revisions`").
+					WithArgs(int64(1), "新标题", "新内容", int64(123), statusUnpublished,
+						"后端", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectCommit()
+			},
+			art: Article{Id: 1, Title: "新标题", Content: "新内容", AuthorId: 123,
+				Status: statusUnpublished, Category: "后端"},
+		},
+		{
+			// 重新设置定时发表，直接更新状态和发表时间
+			name: "定时发表",
+			sqlmock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` SET `category`=\\?,`content`=\\?,`publish_at`=\\?,`status`=\\?,`title`=\\?,`utime`=\\? "+
+					"WHERE id=\\? AND author_id = \\?").
+					WithArgs("后端", "新内容", int64(2000), statusScheduled, "新标题", sqlmock.AnyArg(),
+						int64(1), int64(123)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `article_tags` WHERE article_id = \\?").
+					WithArgs(int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO `article_revisions`").
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectCommit()
+			},
+			art: Article{Id: 1, Title: "新标题", Content: "新内容", AuthorId: 123,
+				Status: statusScheduled, PublishAt: 2000, Category: "后端"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			tc.sqlmock(mock)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			dao := NewGORMArticleDAO(db)
+			err = dao.UpdateById(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -39,6 +39,50 @@ func (m *MockArticleDAO) EXPECT() *MockArticleDAOMockRecorder {
 	return m.recorder
 }
 
+// CancelSchedule mocks base method.
+func (m *MockArticleDAO) CancelSchedule(ctx context.Context, author, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelSchedule", ctx, author, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelSchedule indicates an expected call of CancelSchedule.
+func (mr *MockArticleDAOMockRecorder) CancelSchedule(ctx, author, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelSchedule", reflect.TypeOf((*MockArticleDAO)(nil).CancelSchedule), ctx, author, id)
+}
+
+// ClaimScheduled mocks base method.
+func (m *MockArticleDAO) ClaimScheduled(ctx context.Context, id, publishAt, leaseUntil int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimScheduled", ctx, id, publishAt, leaseUntil)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimScheduled indicates an expected call of ClaimScheduled.
+func (mr *MockArticleDAOMockRecorder) ClaimScheduled(ctx, id, publishAt, leaseUntil any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimScheduled", reflect.TypeOf((*MockArticleDAO)(nil).ClaimScheduled), ctx, id, publishAt, leaseUntil)
+}
+
+// FindDueScheduled mocks base method.
+func (m *MockArticleDAO) FindDueScheduled(ctx context.Context, now int64, limit int) ([]article.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDueScheduled", ctx, now, limit)
+	ret0, _ := ret[0].([]article.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDueScheduled indicates an expected call of FindDueScheduled.
+func (mr *MockArticleDAOMockRecorder) FindDueScheduled(ctx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDueScheduled", reflect.TypeOf((*MockArticleDAO)(nil).FindDueScheduled), ctx, now, limit)
+}
+
 // GetByAuthor mocks base method.
 func (m *MockArticleDAO) GetByAuthor(ctx context.Context, author int64, offset, limit int) ([]article.Article, error) {
 	m.ctrl.T.Helper()
@@ -84,6 +128,36 @@ func (mr *MockArticleDAOMockRecorder) GetPubById(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPubById", reflect.TypeOf((*MockArticleDAO)(nil).GetPubById), ctx, id)
 }
 
+// GetPubByIds mocks base method.
+func (m *MockArticleDAO) GetPubByIds(ctx context.Context, ids []int64) ([]article.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPubByIds", ctx, ids)
+	ret0, _ := ret[0].([]article.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPubByIds indicates an expected call of GetPubByIds.
+func (mr *MockArticleDAOMockRecorder) GetPubByIds(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPubByIds", reflect.TypeOf((*MockArticleDAO)(nil).GetPubByIds), ctx, ids)
+}
+
+// GetRevision mocks base method.
+func (m *MockArticleDAO) GetRevision(ctx context.Context, author, id, revId int64) (article.ArticleRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", ctx, author, id, revId)
+	ret0, _ := ret[0].(article.ArticleRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision.
+func (mr *MockArticleDAOMockRecorder) GetRevision(ctx, author, id, revId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockArticleDAO)(nil).GetRevision), ctx, author, id, revId)
+}
+
 // Insert mocks base method.
 func (m *MockArticleDAO) Insert(ctx context.Context, art article.Article) (int64, error) {
 	m.ctrl.T.Helper()
@@ -99,6 +173,109 @@ func (mr *MockArticleDAOMockRecorder) Insert(ctx, art any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockArticleDAO)(nil).Insert), ctx, art)
 }
 
+// ListPub mocks base method.
+func (m *MockArticleDAO) ListPub(ctx context.Context, startId int64, limit int) ([]article.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPub", ctx, startId, limit)
+	ret0, _ := ret[0].([]article.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPub indicates an expected call of ListPub.
+func (mr *MockArticleDAOMockRecorder) ListPub(ctx, startId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPub", reflect.TypeOf((*MockArticleDAO)(nil).ListPub), ctx, startId, limit)
+}
+
+// ListPubByTag mocks base method.
+func (m *MockArticleDAO) ListPubByTag(ctx context.Context, tag string, offset, limit int) ([]article.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubByTag", ctx, tag, offset, limit)
+	ret0, _ := ret[0].([]article.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPubByTag indicates an expected call of ListPubByTag.
+func (mr *MockArticleDAOMockRecorder) ListPubByTag(ctx, tag, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubByTag", reflect.TypeOf((*MockArticleDAO)(nil).ListPubByTag), ctx, tag, offset, limit)
+}
+
+// ListRevisions mocks base method.
+func (m *MockArticleDAO) ListRevisions(ctx context.Context, author, id int64, offset, limit int) ([]article.ArticleRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRevisions", ctx, author, id, offset, limit)
+	ret0, _ := ret[0].([]article.ArticleRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRevisions indicates an expected call of ListRevisions.
+func (mr *MockArticleDAOMockRecorder) ListRevisions(ctx, author, id, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRevisions", reflect.TypeOf((*MockArticleDAO)(nil).ListRevisions), ctx, author, id, offset, limit)
+}
+
+// ListScheduled mocks base method.
+func (m *MockArticleDAO) ListScheduled(ctx context.Context, author int64, offset, limit int) ([]article.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduled", ctx, author, offset, limit)
+	ret0, _ := ret[0].([]article.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduled indicates an expected call of ListScheduled.
+func (mr *MockArticleDAOMockRecorder) ListScheduled(ctx, author, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduled", reflect.TypeOf((*MockArticleDAO)(nil).ListScheduled), ctx, author, offset, limit)
+}
+
+// Reschedule mocks base method.
+func (m *MockArticleDAO) Reschedule(ctx context.Context, author, id, publishAt int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reschedule", ctx, author, id, publishAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reschedule indicates an expected call of Reschedule.
+func (mr *MockArticleDAOMockRecorder) Reschedule(ctx, author, id, publishAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reschedule", reflect.TypeOf((*MockArticleDAO)(nil).Reschedule), ctx, author, id, publishAt)
+}
+
+// Restore mocks base method.
+func (m *MockArticleDAO) Restore(ctx context.Context, author, id, revId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, author, id, revId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockArticleDAOMockRecorder) Restore(ctx, author, id, revId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockArticleDAO)(nil).Restore), ctx, author, id, revId)
+}
+
+// SuggestTags mocks base method.
+func (m *MockArticleDAO) SuggestTags(ctx context.Context, prefix string, limit int) ([]article.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuggestTags", ctx, prefix, limit)
+	ret0, _ := ret[0].([]article.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SuggestTags indicates an expected call of SuggestTags.
+func (mr *MockArticleDAOMockRecorder) SuggestTags(ctx, prefix, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuggestTags", reflect.TypeOf((*MockArticleDAO)(nil).SuggestTags), ctx, prefix, limit)
+}
+
 // Sync mocks base method.
 func (m *MockArticleDAO) Sync(ctx context.Context, art article.Article) (int64, error) {
 	m.ctrl.T.Helper()
@@ -128,6 +305,21 @@ func (mr *MockArticleDAOMockRecorder) SyncStatus(ctx, author, id, status any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncStatus", reflect.TypeOf((*MockArticleDAO)(nil).SyncStatus), ctx, author, id, status)
 }
 
+// TagCounts mocks base method.
+func (m *MockArticleDAO) TagCounts(ctx context.Context, tags []string) ([]article.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagCounts", ctx, tags)
+	ret0, _ := ret[0].([]article.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TagCounts indicates an expected call of TagCounts.
+func (mr *MockArticleDAOMockRecorder) TagCounts(ctx, tags any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagCounts", reflect.TypeOf((*MockArticleDAO)(nil).TagCounts), ctx, tags)
+}
+
 // UpdateById mocks base method.
 func (m *MockArticleDAO) UpdateById(ctx context.Context, art article.Article) error {
 	m.ctrl.T.Helper()
@@ -3,12 +3,17 @@ package article
 import (
 	"context"
 	"errors"
+	"regexp"
 	"time"
 
 	"github.com/bwmarrin/snowflake"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/outbox"
 )
 
 type MongoDBDAO struct {
@@ -19,47 +24,96 @@ type MongoDBDAO struct {
 	col *mongo.Collection
 	// 代表的是线上库
 	liveCol *mongo.Collection
-	node    *snowflake.Node
+	// 历史版本
+	revCol *mongo.Collection
+	node   *snowflake.Node
 
 	idGen IDGenerator
+	// 发件箱在 MySQL 里面
+	outboxDB *gorm.DB
 }
 
 func (m *MongoDBDAO) GetPubById(ctx context.Context, id int64) (PublishedArticle, error) {
-	//TODO implement me
-	panic("implement me")
+	var pub PublishedArticle
+	err := m.liveCol.FindOne(ctx, bson.M{"id": id}).Decode(&pub)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return pub, ErrArticleNotFound
+	}
+	return pub, err
 }
 
 func (m *MongoDBDAO) GetByAuthor(ctx context.Context, author int64, offset, limit int) ([]Article, error) {
-	//TODO implement me
-	panic("implement me")
+	// 和 GORM 的实现一样，按照更新时间倒序，命中 author_id, utime 的索引
+	opts := options.Find().
+		SetSort(bson.D{bson.E{Key: "utime", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	cursor, err := m.col.Find(ctx, bson.M{"author_id": author}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var arts []Article
+	err = cursor.All(ctx, &arts)
+	return arts, err
 }
 
 func (m *MongoDBDAO) GetById(ctx context.Context, id int64) (Article, error) {
-	//TODO implement me
-	panic("implement me")
+	var art Article
+	err := m.col.FindOne(ctx, bson.M{"id": id}).Decode(&art)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return art, ErrArticleNotFound
+	}
+	return art, err
 }
 
 func (m *MongoDBDAO) Insert(ctx context.Context, art Article) (int64, error) {
 	now := time.Now().UnixMilli()
 	art.Ctime = now
 	art.Utime = now
-	//id := m.idGen()
-	id := m.node.Generate().Int64()
+	id := m.nextId()
 	art.Id = id
 	_, err := m.col.InsertOne(ctx, art)
 	// 你没有自增主键
 	// GLOBAL UNIFY ID (GUID，全局唯一ID）
-	return id, err
+	if err != nil {
+		return id, err
+	}
+	// 没有事务，历史版本写失败了也只能返回错误，让用户重新保存
+	return id, m.insertRevision(ctx, newRevision(art))
 }
 
 func (m *MongoDBDAO) UpdateById(ctx context.Context, art Article) error {
 	// 操作制作库
+	now := time.Now().UnixMilli()
+	if art.Status == statusUnpublished {
+		// 保存草稿的时候，还在等定时发表的文章只更新内容，不动状态和发表时间
+		res, err := m.col.UpdateOne(ctx,
+			bson.M{"id": art.Id, "author_id": art.AuthorId, "status": statusScheduled},
+			bson.M{"$set": bson.M{
+				"title":    art.Title,
+				"content":  art.Content,
+				"utime":    now,
+				"category": art.Category,
+				"tags":     art.Tags,
+			}})
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount == 1 {
+			art.Status = statusScheduled
+			art.Utime = now
+			return m.insertRevision(ctx, newRevision(art))
+		}
+	}
 	filter := bson.M{"id": art.Id, "author_id": art.AuthorId}
 	update := bson.D{bson.E{Key: "$set", Value: bson.M{
-		"title":   art.Title,
-		"content": art.Content,
-		"utime":   time.Now().UnixMilli(),
-		"status":  art.Status,
+		"title":      art.Title,
+		"content":    art.Content,
+		"utime":      now,
+		"status":     art.Status,
+		"publish_at": art.PublishAt,
+		"category":   art.Category,
+		"tags":       art.Tags,
 	}}}
 	res, err := m.col.UpdateOne(ctx, filter, update)
 	if err != nil {
@@ -70,9 +124,169 @@ func (m *MongoDBDAO) UpdateById(ctx context.Context, art Article) error {
 	if res.ModifiedCount == 0 {
 		return errors.New("更新数据失败")
 	}
+	art.Utime = now
+	return m.insertRevision(ctx, newRevision(art))
+}
+
+func (m *MongoDBDAO) ListScheduled(ctx context.Context,
+	author int64, offset, limit int) ([]Article, error) {
+	filter := bson.M{"author_id": author, "status": statusScheduled}
+	opts := options.Find().
+		SetSort(bson.D{bson.E{Key: "publish_at", Value: 1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	cursor, err := m.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var arts []Article
+	err = cursor.All(ctx, &arts)
+	return arts, err
+}
+
+func (m *MongoDBDAO) Reschedule(ctx context.Context, author, id, publishAt int64) error {
+	res, err := m.col.UpdateOne(ctx,
+		bson.M{"id": id, "author_id": author, "status": statusScheduled},
+		bson.M{"$set": bson.M{
+			"publish_at": publishAt,
+			"utime":      time.Now().UnixMilli(),
+		}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount != 1 {
+		return ErrPossibleIncorrectAuthor
+	}
+	return nil
+}
+
+func (m *MongoDBDAO) CancelSchedule(ctx context.Context, author, id int64) error {
+	res, err := m.col.UpdateOne(ctx,
+		bson.M{"id": id, "author_id": author, "status": statusScheduled},
+		bson.M{"$set": bson.M{
+			"status":     domain.ArticleStatusUnpublished.ToUint8(),
+			"publish_at": 0,
+			"utime":      time.Now().UnixMilli(),
+		}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount != 1 {
+		return ErrPossibleIncorrectAuthor
+	}
 	return nil
 }
 
+func (m *MongoDBDAO) FindDueScheduled(ctx context.Context, now int64, limit int) ([]Article, error) {
+	filter := bson.M{"status": statusScheduled, "publish_at": bson.M{"$lte": now}}
+	opts := options.Find().
+		SetSort(bson.D{bson.E{Key: "publish_at", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := m.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var arts []Article
+	err = cursor.All(ctx, &arts)
+	return arts, err
+}
+
+func (m *MongoDBDAO) ClaimScheduled(ctx context.Context, id, publishAt, leaseUntil int64) (bool, error) {
+	// 单文档的更新是原子的，所以只有一个实例能够匹配上
+	res, err := m.col.UpdateOne(ctx,
+		bson.M{"id": id, "status": statusScheduled, "publish_at": publishAt},
+		bson.M{"$set": bson.M{"publish_at": leaseUntil}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (m *MongoDBDAO) ListRevisions(ctx context.Context,
+	author, id int64, offset, limit int) ([]ArticleRevision, error) {
+	filter := bson.M{"article_id": id, "author_id": author}
+	opts := options.Find().
+		SetSort(bson.D{bson.E{Key: "ctime", Value: -1}, bson.E{Key: "id", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	cursor, err := m.revCol.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var revs []ArticleRevision
+	err = cursor.All(ctx, &revs)
+	return revs, err
+}
+
+func (m *MongoDBDAO) GetRevision(ctx context.Context,
+	author, id, revId int64) (ArticleRevision, error) {
+	var rev ArticleRevision
+	filter := bson.M{"id": revId, "article_id": id, "author_id": author}
+	err := m.revCol.FindOne(ctx, filter).Decode(&rev)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return rev, ErrRevisionNotFound
+	}
+	return rev, err
+}
+
+func (m *MongoDBDAO) Restore(ctx context.Context, author, id, revId int64) error {
+	var rev ArticleRevision
+	err := m.revCol.FindOne(ctx, bson.M{"id": revId, "article_id": id}).Decode(&rev)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrRevisionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	now := time.Now().UnixMilli()
+	status := domain.ArticleStatusUnpublished.ToUint8()
+	set := bson.M{
+		"title":    rev.Title,
+		"content":  rev.Content,
+		"status":   status,
+		"category": rev.Category,
+		"utime":    now,
+	}
+	update := bson.M{"$set": set}
+	if len(rev.Tags) > 0 {
+		set["tags"] = rev.Tags
+	} else {
+		// 和 Sync 一样，没有标签的时候要清空原本的标签
+		update["$unset"] = bson.M{"tags": ""}
+	}
+	res, err := m.col.UpdateOne(ctx, bson.M{"id": id, "author_id": author}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount != 1 {
+		return ErrPossibleIncorrectAuthor
+	}
+	return m.insertRevision(ctx, ArticleRevision{
+		ArticleId: id,
+		Title:     rev.Title,
+		Content:   rev.Content,
+		AuthorId:  author,
+		Status:    status,
+		Category:  rev.Category,
+		Tags:      rev.Tags,
+		Ctime:     now,
+	})
+}
+
+// nextId 优先使用雪花算法生成 ID，没有 node 的时候才使用 idGen
+func (m *MongoDBDAO) nextId() int64 {
+	if m.node != nil {
+		return m.node.Generate().Int64()
+	}
+	return m.idGen()
+}
+
+func (m *MongoDBDAO) insertRevision(ctx context.Context, rev ArticleRevision) error {
+	rev.Id = m.nextId()
+	_, err := m.revCol.InsertOne(ctx, rev)
+	return err
+}
+
 func (m *MongoDBDAO) Sync(ctx context.Context, art Article) (int64, error) {
 	// 没法子引入事务的概念
 	// 首先第一步，保存制作库
@@ -100,16 +314,154 @@ func (m *MongoDBDAO) Sync(ctx context.Context, art Article) (int64, error) {
 		// 在插入的时候，要插入 ctime
 		"$setOnInsert": bson.M{"ctime": now},
 	}
+	if len(art.Tags) == 0 {
+		// tags 是 omitempty 的，$set 不会清空原本的标签
+		updateV1["$unset"] = bson.M{"tags": ""}
+	}
 	filter := bson.M{"id": art.Id}
-	_, err = m.liveCol.UpdateOne(ctx, filter,
+	// 拿到更新之前的状态，用来区分第一次发表和修改
+	prev, err := m.findOneAndUpdateStatus(ctx, filter,
 		//bson.D{update, upsert},
-		updateV1,
-		options.Update().SetUpsert(true))
-	return id, err
+		updateV1, true)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, err
+	}
+	msgs, err := syncMessages(prev.Status, art, now)
+	if err != nil {
+		return 0, err
+	}
+	return id, m.saveMessages(ctx, msgs)
 }
 
 func (m *MongoDBDAO) SyncStatus(ctx context.Context, author, id int64, status uint8) error {
-	panic("implement me")
+	// 没有事务，先改制作库，再改线上库
+	// 线上库失败的时候，制作库已经改了，调用方重试就可以，这个操作是幂等的
+	now := time.Now().UnixMilli()
+	filter := bson.M{"id": id, "author_id": author}
+	update := bson.M{"$set": bson.M{
+		"status": status,
+		"utime":  now,
+	}}
+	res, err := m.col.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount != 1 {
+		return ErrPossibleIncorrectAuthor
+	}
+	prev, err := m.findOneAndUpdateStatus(ctx, filter, update, false)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrPossibleIncorrectAuthor
+	}
+	if err != nil || prev.Status == status {
+		return err
+	}
+	art := Article(prev)
+	art.Status = status
+	msgs, err := syncMessages(prev.Status, art, now)
+	if err != nil {
+		return err
+	}
+	return m.saveMessages(ctx, msgs)
+}
+
+// saveMessages 写发件箱，没有设置 outboxDB 的时候不发事件
+func (m *MongoDBDAO) saveMessages(ctx context.Context, msgs []outbox.Message) error {
+	if m.outboxDB == nil {
+		return nil
+	}
+	return outbox.Save(m.outboxDB.WithContext(ctx), msgs...)
+}
+
+// findOneAndUpdateStatus 更新线上库，返回更新之前的文章
+func (m *MongoDBDAO) findOneAndUpdateStatus(ctx context.Context,
+	filter, update bson.M, upsert bool) (PublishedArticle, error) {
+	var prev PublishedArticle
+	err := m.liveCol.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().
+			SetUpsert(upsert).
+			SetReturnDocument(options.Before)).Decode(&prev)
+	return prev, err
+}
+
+func (m *MongoDBDAO) ListPub(ctx context.Context,
+	startId int64, limit int) ([]PublishedArticle, error) {
+	filter := bson.M{"id": bson.M{"$gt": startId}, "status": statusPublished}
+	opts := options.Find().
+		SetSort(bson.D{bson.E{Key: "id", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := m.liveCol.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var arts []PublishedArticle
+	err = cursor.All(ctx, &arts)
+	return arts, err
+}
+
+func (m *MongoDBDAO) GetPubByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error) {
+	var arts []PublishedArticle
+	if len(ids) == 0 {
+		return arts, nil
+	}
+	filter := bson.M{"id": bson.M{"$in": ids}, "status": statusPublished}
+	cursor, err := m.liveCol.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(ctx, &arts)
+	return arts, err
+}
+
+func (m *MongoDBDAO) ListPubByTag(ctx context.Context,
+	tag string, offset, limit int) ([]PublishedArticle, error) {
+	filter := bson.M{"tags": tag, "status": statusPublished}
+	opts := options.Find().
+		SetSort(bson.D{bson.E{Key: "utime", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	cursor, err := m.liveCol.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var arts []PublishedArticle
+	err = cursor.All(ctx, &arts)
+	return arts, err
+}
+
+func (m *MongoDBDAO) SuggestTags(ctx context.Context, prefix string, limit int) ([]Tag, error) {
+	// MongoDB 里面没有单独的标签表，直接从线上库聚合
+	pattern := "^" + regexp.QuoteMeta(prefix)
+	return m.aggregateTags(ctx, bson.M{"tags": bson.M{"$regex": pattern}},
+		bson.D{bson.E{Key: "$sort", Value: bson.D{bson.E{Key: "cnt", Value: -1}}}},
+		bson.D{bson.E{Key: "$limit", Value: limit}})
+}
+
+func (m *MongoDBDAO) TagCounts(ctx context.Context, tags []string) ([]Tag, error) {
+	return m.aggregateTags(ctx, bson.M{"tags": bson.M{"$in": tags}})
+}
+
+// aggregateTags 统计公开文章里面满足 tagFilter 的标签，以及每个标签的文章数量
+func (m *MongoDBDAO) aggregateTags(ctx context.Context,
+	tagFilter bson.M, stages ...bson.D) ([]Tag, error) {
+	pipeline := mongo.Pipeline{
+		{bson.E{Key: "$match", Value: bson.M{"status": statusPublished}}},
+		{bson.E{Key: "$match", Value: tagFilter}},
+		{bson.E{Key: "$unwind", Value: "$tags"}},
+		{bson.E{Key: "$match", Value: tagFilter}},
+		{bson.E{Key: "$group", Value: bson.M{
+			"_id": "$tags",
+			"cnt": bson.M{"$sum": 1},
+		}}},
+	}
+	pipeline = append(pipeline, stages...)
+	cursor, err := m.liveCol.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var res []Tag
+	err = cursor.All(ctx, &res)
+	return res, err
 }
 
 func InitCollections(db *mongo.Database) error {
@@ -121,8 +473,9 @@ func InitCollections(db *mongo.Database) error {
 			Options: options.Index().SetUnique(true),
 		},
 		{
+			// 作者查询自己的文章列表，按照更新时间倒序
 			Keys: bson.D{bson.E{Key: "author_id", Value: 1},
-				bson.E{Key: "ctime", Value: 1},
+				bson.E{Key: "utime", Value: -1},
 			},
 			Options: options.Index(),
 		},
@@ -134,6 +487,44 @@ func InitCollections(db *mongo.Database) error {
 	}
 	_, err = db.Collection("published_articles").Indexes().
 		CreateMany(ctx, index)
+	if err != nil {
+		return err
+	}
+	_, err = db.Collection("article_revisions").Indexes().
+		CreateMany(ctx, []mongo.IndexModel{
+			{
+				Keys:    bson.D{bson.E{Key: "id", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys: bson.D{bson.E{Key: "article_id", Value: 1},
+					bson.E{Key: "ctime", Value: -1},
+				},
+				Options: options.Index(),
+			},
+		})
+	if err != nil {
+		return err
+	}
+	// 定时发表的任务按照这个索引来查找到期的文章
+	_, err = db.Collection("articles").Indexes().
+		CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{bson.E{Key: "status", Value: 1},
+				bson.E{Key: "publish_at", Value: 1},
+			},
+			Options: options.Index(),
+		})
+	if err != nil {
+		return err
+	}
+	// 按照标签查询公开文章
+	_, err = db.Collection("published_articles").Indexes().
+		CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{bson.E{Key: "tags", Value: 1},
+				bson.E{Key: "utime", Value: -1},
+			},
+			Options: options.Index(),
+		})
 	return err
 }
 
@@ -143,16 +534,27 @@ func NewMongoDBDAOV1(db *mongo.Database, idGen IDGenerator) ArticleDAO {
 	return &MongoDBDAO{
 		col:     db.Collection("articles"),
 		liveCol: db.Collection("published_articles"),
+		revCol:  db.Collection("article_revisions"),
 		//node:    node,
 		idGen: idGen,
 	}
 }
 
+// NewMongoDBDAO 没有发件箱，发表和撤回都不会发出事件，
+// 依赖这些事件的 feed、搜索之类的功能都不会更新，线上要用 NewMongoDBDAOWithOutbox
 func NewMongoDBDAO(db *mongo.Database, node *snowflake.Node) ArticleDAO {
+	return NewMongoDBDAOWithOutbox(db, node, nil)
+}
+
+// NewMongoDBDAOWithOutbox outboxDB 用来写发件箱，MongoDB 和 MySQL 之间没有事务，
+// 所以是线上库同步成功之后再写，这中间崩溃了会丢失发表事件
+func NewMongoDBDAOWithOutbox(db *mongo.Database, node *snowflake.Node, outboxDB *gorm.DB) ArticleDAO {
 	return &MongoDBDAO{
-		col:     db.Collection("articles"),
-		liveCol: db.Collection("published_articles"),
-		node:    node,
+		col:      db.Collection("articles"),
+		liveCol:  db.Collection("published_articles"),
+		revCol:   db.Collection("article_revisions"),
+		node:     node,
+		outboxDB: outboxDB,
 	}
 }
 
@@ -0,0 +1,64 @@
+package article
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/outbox"
+)
+
+// syncMessages 同步到线上库的时候要发送的事件，prev 是同步之前线上库的状态，没有发表过就是 0。
+//   - 第一次公开发表，或者撤回之后再公开，是发表事件
+//   - 已经公开的文章再次发表，是修改事件
+//   - 公开的文章改成了别的状态，是撤回事件
+//
+// 仅自己可见的文章之间的同步，不需要通知下游。
+// 三种事件在不同的 topic，Relay 也不保证顺序，所以下游不能依赖事件的先后，要以线上库为准，
+// 比如 service.SearchService 和 service.FeedService
+func syncMessages(prev uint8, art Article, now int64) ([]outbox.Message, error) {
+	switch {
+	case art.Status == statusPublished && prev == statusPublished:
+		return newMessages(events.TopicArticleEdited, art.Id, events.ArticleEditedEvent{
+			Version:  events.ArticleEditedVersion,
+			Aid:      art.Id,
+			Uid:      art.AuthorId,
+			Title:    art.Title,
+			Abstract: domain.Article{Content: art.Content}.Abstract(),
+			Utime:    now,
+		}, now)
+	case art.Status == statusPublished:
+		return newMessages(events.TopicArticlePublished, art.Id, events.ArticlePublishedEvent{
+			Version:  events.ArticlePublishedVersion,
+			Aid:      art.Id,
+			Uid:      art.AuthorId,
+			Title:    art.Title,
+			Abstract: domain.Article{Content: art.Content}.Abstract(),
+			Ctime:    now,
+		}, now)
+	case prev == statusPublished:
+		return newMessages(events.TopicArticleWithdrawn, art.Id, events.ArticleWithdrawnEvent{
+			Version: events.ArticleWithdrawnVersion,
+			Aid:     art.Id,
+			Uid:     art.AuthorId,
+			Ctime:   now,
+		}, now)
+	default:
+		return nil, nil
+	}
+}
+
+func newMessages(topic string, aid int64, evt any, now int64) ([]outbox.Message, error) {
+	val, err := json.Marshal(evt)
+	if err != nil {
+		return nil, err
+	}
+	return []outbox.Message{{
+		Topic: topic,
+		// 同一篇文章的事件进同一个分区，方便下游按照文章分开处理
+		MsgKey: strconv.FormatInt(aid, 10),
+		Value:  val,
+		Ctime:  now,
+	}}, nil
+}
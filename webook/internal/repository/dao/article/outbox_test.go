@@ -0,0 +1,90 @@
+package article
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events"
+)
+
+func Test_syncMessages(t *testing.T) {
+	art := Article{
+		Id:       1,
+		Title:    "我的标题",
+		Content:  "我的内容",
+		AuthorId: 123,
+	}
+	testCases := []struct {
+		name   string
+		prev   uint8
+		status uint8
+
+		wantTopic string
+		wantEvt   any
+	}{
+		{
+			name:      "第一次发表",
+			status:    statusPublished,
+			wantTopic: events.TopicArticlePublished,
+			wantEvt: events.ArticlePublishedEvent{
+				Version: events.ArticlePublishedVersion, Aid: 1, Uid: 123,
+				Title: "我的标题", Abstract: "我的内容", Ctime: 1000,
+			},
+		},
+		{
+			name:      "撤回之后重新发表",
+			prev:      statusPrivate,
+			status:    statusPublished,
+			wantTopic: events.TopicArticlePublished,
+			wantEvt: events.ArticlePublishedEvent{
+				Version: events.ArticlePublishedVersion, Aid: 1, Uid: 123,
+				Title: "我的标题", Abstract: "我的内容", Ctime: 1000,
+			},
+		},
+		{
+			name:      "修改已经发表的文章",
+			prev:      statusPublished,
+			status:    statusPublished,
+			wantTopic: events.TopicArticleEdited,
+			wantEvt: events.ArticleEditedEvent{
+				Version: events.ArticleEditedVersion, Aid: 1, Uid: 123,
+				Title: "我的标题", Abstract: "我的内容", Utime: 1000,
+			},
+		},
+		{
+			name:      "撤回",
+			prev:      statusPublished,
+			status:    statusPrivate,
+			wantTopic: events.TopicArticleWithdrawn,
+			wantEvt: events.ArticleWithdrawnEvent{
+				Version: events.ArticleWithdrawnVersion, Aid: 1, Uid: 123, Ctime: 1000,
+			},
+		},
+		{
+			name:   "仅自己可见的文章同步",
+			prev:   statusPrivate,
+			status: statusPrivate,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := art
+			a.Status = tc.status
+			msgs, err := syncMessages(tc.prev, a, 1000)
+			require.NoError(t, err)
+			if tc.wantEvt == nil {
+				assert.Empty(t, msgs)
+				return
+			}
+			require.Len(t, msgs, 1)
+			assert.Equal(t, tc.wantTopic, msgs[0].Topic)
+			assert.Equal(t, "1", msgs[0].MsgKey)
+			want, err := json.Marshal(tc.wantEvt)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(want), string(msgs[0].Value))
+		})
+	}
+}
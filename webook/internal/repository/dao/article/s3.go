@@ -1,39 +1,51 @@
 package article
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"strconv"
 	"time"
 
-	_ "github.com/aws/aws-sdk-go"
-	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/ecodeclub/ekit"
-	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/blob"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/outbox"
 )
 
-var statusPrivate = domain.ArticleStatusPrivate.ToUint8()
+const (
+	blobOpPut uint8 = iota + 1
+	blobOpDelete
+
+	// maxBlobRetries 超过这个次数就不再重试，任务留在表里面等人工处理
+	maxBlobRetries = 10
+	// maxBlobBackoff 重试间隔的上限
+	maxBlobBackoff = time.Minute * 10
+)
 
+// BlobCompensator 对象存储的补偿，把失败的上传和删除重新执行一遍
+type BlobCompensator interface {
+	// Compensate 执行最多 limit 个到期的任务，返回成功的数量
+	Compensate(ctx context.Context, limit int) (int, error)
+}
+
+var _ BlobCompensator = (*S3DAO)(nil)
+
+// S3DAO 线上库的内容保存在对象存储里面，数据库只保存元数据
 type S3DAO struct {
-	oss *s3.S3
 	// 通过组合 GORMArticleDAO 来简化操作
 	// 当然在实践中，你是不太会有组合的机会
 	// 你操作制作库总是一样的
 	// 你就是操作线上库的时候不一样
 	GORMArticleDAO
-	bucket *string
+	blob blob.Storage
 }
 
 // NewOssDAO 因为组合 GORMArticleDAO 是一个内部实现细节
 // 所以这里要直接传入 DB
-func NewOssDAO(oss *s3.S3, db *gorm.DB) ArticleDAO {
+func NewOssDAO(storage blob.Storage, db *gorm.DB) ArticleDAO {
 	return &S3DAO{
-		oss: oss,
-		// 你也可以考虑利用依赖注入来传入。
-		// 但是事实上这个很少变，所以你可以延迟到必要的时候再注入
-		bucket: ekit.ToPtr[string]("webook-1314583317"),
+		blob: storage,
 		GORMArticleDAO: GORMArticleDAO{
 			db: db,
 		},
@@ -49,7 +61,7 @@ func (o *S3DAO) Sync(ctx context.Context, art Article) (int64, error) {
 	)
 	// 制作库流量不大，并发不高，你就保存到数据库就可以
 	// 当然，有钱或者体量大，就还是考虑 OSS
-	err := o.db.Transaction(func(tx *gorm.DB) error {
+	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var err error
 		now := time.Now().UnixMilli()
 		// 制作库
@@ -63,41 +75,350 @@ func (o *S3DAO) Sync(ctx context.Context, art Article) (int64, error) {
 			return err
 		}
 		art.Id = id
+		prev, err := pubStatus(tx, &PublishedArticleV1{}, id)
+		if err != nil {
+			return err
+		}
 		publishArt := PublishedArticleV1{
 			Id:       art.Id,
 			Title:    art.Title,
 			AuthorId: art.AuthorId,
 			Status:   art.Status,
+			Category: art.Category,
 			Ctime:    now,
 			Utime:    now,
 		}
 		// 线上库不保存 Content,要准备上传到 OSS 里面
-		return tx.Clauses(clause.OnConflict{
+		err = tx.Clauses(clause.OnConflict{
 			// ID 冲突的时候。实际上，在 MYSQL 里面你写不写都可以
 			Columns: []clause.Column{{Name: "id"}},
 			DoUpdates: clause.Assignments(map[string]interface{}{
-				"title":  art.Title,
-				"utime":  now,
-				"status": art.Status,
+				"title":    art.Title,
+				"utime":    now,
+				"status":   art.Status,
+				"category": art.Category,
 				// 要参与 SQL 运算的
 			}),
 		}).Create(&publishArt).Error
+		if err != nil {
+			return err
+		}
+		var pubTags []string
+		if art.Status == statusPublished {
+			pubTags = art.Tags
+		}
+		err = syncPubTags(tx, id, pubTags, now)
+		if err != nil {
+			return err
+		}
+		msgs, err := syncMessages(prev, art, now)
+		if err != nil {
+			return err
+		}
+		return outbox.Save(tx, msgs...)
 	})
 	// 说明保存到数据库的时候失败了
 	if err != nil {
 		return 0, err
 	}
-	// 接下来就是保存到 OSS 里面
-	// 你要有监控，你要有重试，你要有补偿机制
-	_, err = o.oss.PutObjectWithContext(ctx, &s3.PutObjectInput{
-		Bucket:      o.bucket,
-		Key:         ekit.ToPtr[string](strconv.FormatInt(art.Id, 10)),
-		Body:        bytes.NewReader([]byte(art.Content)),
-		ContentType: ekit.ToPtr[string]("text/plain;charset=utf-8"),
-	})
-	return id, err
+	// 数据库已经提交了，上传失败就交给补偿任务，不再返回错误
+	return id, o.apply(ctx, BlobTask{ArticleId: id, Op: blobOpPut, Content: art.Content})
 }
 
 func (o *S3DAO) SyncStatus(ctx context.Context, author, id int64, status uint8) error {
-	panic("implement me")
+	var content string
+	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
+		res := tx.Model(&Article{}).
+			Where("id = ? AND author_id = ?", id, author).
+			Updates(map[string]any{
+				"status": status,
+				"utime":  now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return ErrPossibleIncorrectAuthor
+		}
+		prev, err := pubStatus(tx, &PublishedArticleV1{}, id)
+		if err != nil {
+			return err
+		}
+		res = tx.Model(&PublishedArticleV1{}).
+			Where("id = ? AND author_id = ?", id, author).
+			Updates(map[string]any{
+				"status": status,
+				"utime":  now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return ErrPossibleIncorrectAuthor
+		}
+		var tags []string
+		art := Article{Id: id, AuthorId: author, Status: status}
+		if status == statusPublished {
+			err = tx.Where("id = ?", id).First(&art).Error
+			if err != nil {
+				return err
+			}
+			content = art.Content
+			err = tx.Model(&ArticleTag{}).
+				Where("article_id = ?", id).
+				Order("id").
+				Pluck("tag", &tags).Error
+			if err != nil {
+				return err
+			}
+		}
+		err = syncPubTags(tx, id, tags, now)
+		if err != nil || prev == status {
+			return err
+		}
+		msgs, err := syncMessages(prev, art, now)
+		if err != nil {
+			return err
+		}
+		return outbox.Save(tx, msgs...)
+	})
+	if err != nil {
+		return err
+	}
+	if status == statusPublished {
+		return o.apply(ctx, BlobTask{ArticleId: id, Op: blobOpPut, Content: content})
+	}
+	// 不再公开的文章，内容也要从对象存储里面删掉，避免被直接访问到
+	return o.apply(ctx, BlobTask{ArticleId: id, Op: blobOpDelete})
+}
+
+func (o *S3DAO) GetPubById(ctx context.Context, id int64) (PublishedArticle, error) {
+	var art PublishedArticleV1
+	err := o.db.WithContext(ctx).Where("id = ?", id).First(&art).Error
+	if err != nil {
+		return PublishedArticle{}, err
+	}
+	res := o.fromV1(art)
+	err = o.db.WithContext(ctx).Model(&PublishedArticleTag{}).
+		Where("article_id = ?", id).
+		Order("id").
+		Pluck("tag", &res.Tags).Error
+	if err != nil {
+		return PublishedArticle{}, err
+	}
+	if art.Status != statusPublished {
+		// 内容已经删除了
+		return res, nil
+	}
+	res.Content, err = o.content(ctx, id)
+	return res, err
+}
+
+func (o *S3DAO) ListPub(ctx context.Context, startId int64, limit int) ([]PublishedArticle, error) {
+	var arts []PublishedArticleV1
+	err := o.db.WithContext(ctx).
+		Where("id > ? AND status = ?", startId, statusPublished).
+		Order("id ASC").
+		Limit(limit).
+		Find(&arts).Error
+	if err != nil {
+		return nil, err
+	}
+	return o.fillContent(ctx, arts)
+}
+
+func (o *S3DAO) GetPubByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error) {
+	if len(ids) == 0 {
+		return []PublishedArticle{}, nil
+	}
+	var arts []PublishedArticleV1
+	err := o.db.WithContext(ctx).
+		Where("id IN ? AND status = ?", ids, statusPublished).
+		Find(&arts).Error
+	if err != nil {
+		return nil, err
+	}
+	return o.fillContent(ctx, arts)
+}
+
+func (o *S3DAO) ListPubByTag(ctx context.Context,
+	tag string, offset, limit int) ([]PublishedArticle, error) {
+	var arts []PublishedArticleV1
+	err := o.db.WithContext(ctx).Model(&PublishedArticleV1{}).
+		Select("published_article_v1.*").
+		Joins("JOIN published_article_tags t ON t.article_id = published_article_v1.id").
+		Where("t.tag = ? AND published_article_v1.status = ?", tag, statusPublished).
+		Offset(offset).
+		Limit(limit).
+		Order("published_article_v1.utime DESC").
+		Find(&arts).Error
+	if err != nil {
+		return nil, err
+	}
+	res, err := o.fillContent(ctx, arts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range res {
+		err = o.db.WithContext(ctx).Model(&PublishedArticleTag{}).
+			Where("article_id = ?", res[i].Id).
+			Order("id").
+			Pluck("tag", &res[i].Tags).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (o *S3DAO) Compensate(ctx context.Context, limit int) (int, error) {
+	var tasks []BlobTask
+	now := time.Now().UnixMilli()
+	err := o.db.WithContext(ctx).
+		Where("next_time <= ? AND retries < ?", now, maxBlobRetries).
+		Order("next_time ASC").
+		Limit(limit).
+		Find(&tasks).Error
+	if err != nil {
+		return 0, err
+	}
+	cnt := 0
+	for _, task := range tasks {
+		ok, err := o.compensate(ctx, task)
+		if err != nil {
+			return cnt, err
+		}
+		if ok {
+			cnt++
+		}
+	}
+	return cnt, nil
+}
+
+// compensate 锁住任务再执行，和 apply 互斥。
+// 锁住之后任务的 utime 变了或者任务已经没了，说明有新的同步覆盖了它，旧内容不能再上传
+func (o *S3DAO) compensate(ctx context.Context, task BlobTask) (bool, error) {
+	done := false
+	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var cur BlobTask
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND utime = ?", task.Id, task.Utime).
+			First(&cur).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = o.do(ctx, cur)
+		if err == nil {
+			done = true
+			return tx.Where("id = ?", cur.Id).Delete(&BlobTask{}).Error
+		}
+		backoff := time.Second << cur.Retries
+		if backoff > maxBlobBackoff {
+			backoff = maxBlobBackoff
+		}
+		now := time.Now().UnixMilli()
+		return tx.Model(&BlobTask{}).
+			Where("id = ?", cur.Id).
+			Updates(map[string]any{
+				"retries":   cur.Retries + 1,
+				"next_time": now + backoff.Milliseconds(),
+				"utime":     now,
+			}).Error
+	})
+	return done, err
+}
+
+// apply 执行对象存储的操作。
+// 执行期间锁住这篇文章的补偿任务，正在执行的补偿任务会先执行完，
+// 不然补偿任务的旧内容可能在我们之后写进去，覆盖新内容。
+// 成功的时候删掉这篇文章之前没有完成的任务；失败的时候写入补偿任务
+func (o *S3DAO) apply(ctx context.Context, task BlobTask) error {
+	return o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var prev []BlobTask
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("article_id = ?", task.ArticleId).
+			Find(&prev).Error
+		if err != nil {
+			return err
+		}
+		err = o.do(ctx, task)
+		if err == nil {
+			if len(prev) == 0 {
+				return nil
+			}
+			return tx.Where("article_id = ?", task.ArticleId).
+				Delete(&BlobTask{}).Error
+		}
+		now := time.Now().UnixMilli()
+		task.Ctime = now
+		task.Utime = now
+		task.NextTime = now + time.Second.Milliseconds()
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "article_id"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"op":        task.Op,
+				"content":   task.Content,
+				"retries":   0,
+				"next_time": task.NextTime,
+				"utime":     now,
+			}),
+		}).Create(&task).Error
+	})
+}
+
+func (o *S3DAO) do(ctx context.Context, task BlobTask) error {
+	key := strconv.FormatInt(task.ArticleId, 10)
+	if task.Op == blobOpDelete {
+		return o.blob.Delete(ctx, key)
+	}
+	return o.blob.Put(ctx, key, []byte(task.Content), "text/plain;charset=utf-8")
+}
+
+// content 从对象存储读取内容，如果上传还在补偿中，就用补偿任务里面的内容
+func (o *S3DAO) content(ctx context.Context, id int64) (string, error) {
+	data, err := o.blob.Get(ctx, strconv.FormatInt(id, 10))
+	if err == nil {
+		return string(data), nil
+	}
+	if !errors.Is(err, blob.ErrNotFound) {
+		return "", err
+	}
+	var task BlobTask
+	er := o.db.WithContext(ctx).
+		Where("article_id = ? AND op = ?", id, blobOpPut).
+		First(&task).Error
+	if er != nil {
+		return "", err
+	}
+	return task.Content, nil
+}
+
+func (o *S3DAO) fillContent(ctx context.Context, arts []PublishedArticleV1) ([]PublishedArticle, error) {
+	res := make([]PublishedArticle, 0, len(arts))
+	for _, art := range arts {
+		pub := o.fromV1(art)
+		content, err := o.content(ctx, art.Id)
+		if err != nil {
+			return nil, err
+		}
+		pub.Content = content
+		res = append(res, pub)
+	}
+	return res, nil
+}
+
+func (o *S3DAO) fromV1(art PublishedArticleV1) PublishedArticle {
+	return PublishedArticle{
+		Id:       art.Id,
+		Title:    art.Title,
+		AuthorId: art.AuthorId,
+		Status:   art.Status,
+		Category: art.Category,
+		Ctime:    art.Ctime,
+		Utime:    art.Utime,
+	}
 }
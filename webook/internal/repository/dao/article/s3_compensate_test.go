@@ -0,0 +1,87 @@
+package article
+
+import (
+	"context"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/blob"
+)
+
+func TestS3DAO_Compensate(t *testing.T) {
+	cols := []string{"id", "article_id", "op", "content", "retries", "next_time", "ctime", "utime"}
+	testCases := []struct {
+		name    string
+		sqlmock func(mock sqlmock.Sqlmock)
+
+		wantCnt int
+		wantPut map[string]string
+	}{
+		{
+			name: "补偿成功",
+			sqlmock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `blob_tasks` WHERE next_time <= \\? AND retries < \\?").
+					WillReturnRows(sqlmock.NewRows(cols).AddRow(1, 10, blobOpPut, "旧内容", 0, 0, 0, 100))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `blob_tasks` WHERE id = \\? AND utime = \\? .* FOR UPDATE").
+					WithArgs(int64(1), int64(100)).
+					WillReturnRows(sqlmock.NewRows(cols).AddRow(1, 10, blobOpPut, "旧内容", 0, 0, 0, 100))
+				mock.ExpectExec("DELETE FROM `blob_tasks` WHERE id = \\?").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantCnt: 1,
+			wantPut: map[string]string{"10": "旧内容"},
+		},
+		{
+			// 查出来之后，新的同步已经上传成功并且删掉了任务，旧内容不能再上传
+			name: "任务已经被覆盖",
+			sqlmock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `blob_tasks` WHERE next_time <= \\? AND retries < \\?").
+					WillReturnRows(sqlmock.NewRows(cols).AddRow(1, 10, blobOpPut, "旧内容", 0, 0, 0, 100))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `blob_tasks` WHERE id = \\? AND utime = \\? .* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows(cols))
+				mock.ExpectCommit()
+			},
+			wantPut: map[string]string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			tc.sqlmock(mock)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			storage := &fakeStorage{data: map[string]string{}}
+			dao := NewOssDAO(storage, db).(*S3DAO)
+			cnt, err := dao.Compensate(context.Background(), 10)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantCnt, cnt)
+			assert.Equal(t, tc.wantPut, storage.data)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+type fakeStorage struct {
+	blob.Storage
+	data map[string]string
+}
+
+func (f *fakeStorage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	f.data[key] = string(data)
+	return nil
+}
@@ -3,16 +3,64 @@ package article
 import (
 	"context"
 	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
 )
 
-var ErrPossibleIncorrectAuthor = errors.New("用户在尝试操作非本人数据")
+var (
+	ErrPossibleIncorrectAuthor = errors.New("用户在尝试操作非本人数据")
+	// ErrRevisionNotFound 历史版本不存在，或者不是这个作者的
+	ErrRevisionNotFound = errors.New("历史版本不存在")
+	// ErrArticleNotFound 文章不存在，不管用的是哪种存储都返回这个
+	ErrArticleNotFound = gorm.ErrRecordNotFound
+)
+
+var (
+	statusUnpublished = domain.ArticleStatusUnpublished.ToUint8()
+	statusPublished   = domain.ArticleStatusPublished.ToUint8()
+	statusPrivate     = domain.ArticleStatusPrivate.ToUint8()
+	statusScheduled   = domain.ArticleStatusScheduled.ToUint8()
+)
 
 type ArticleDAO interface {
 	Insert(ctx context.Context, art Article) (int64, error)
+	// UpdateById 保存草稿，也就是状态是未发表的时候，定时发表的文章保留原来的状态和发表时间，
+	// 作者要取消定时发表得调用 CancelSchedule
 	UpdateById(ctx context.Context, art Article) error
 	GetByAuthor(ctx context.Context, author int64, offset, limit int) ([]Article, error)
 	GetById(ctx context.Context, id int64) (Article, error)
 	GetPubById(ctx context.Context, id int64) (PublishedArticle, error)
 	Sync(ctx context.Context, art Article) (int64, error)
 	SyncStatus(ctx context.Context, author, id int64, status uint8) error
+	// ListRevisions 按照创建时间倒序返回某篇文章的历史版本
+	ListRevisions(ctx context.Context, author, id int64, offset, limit int) ([]ArticleRevision, error)
+	GetRevision(ctx context.Context, author, id, revId int64) (ArticleRevision, error)
+	// Restore 用历史版本的标题、内容、分类和标签覆盖制作库，并且把文章重置为未发表
+	Restore(ctx context.Context, author, id, revId int64) error
+
+	// ListScheduled 按照发表时间升序返回作者还没有发表的定时文章
+	ListScheduled(ctx context.Context, author int64, offset, limit int) ([]Article, error)
+	// Reschedule 修改定时发表的时间，只有定时发表状态的文章才能修改
+	Reschedule(ctx context.Context, author, id, publishAt int64) error
+	// CancelSchedule 取消定时发表，文章重新变为未发表
+	CancelSchedule(ctx context.Context, author, id int64) error
+	// FindDueScheduled 找到已经到了发表时间的定时文章
+	FindDueScheduled(ctx context.Context, now int64, limit int) ([]Article, error)
+	// ClaimScheduled 抢占一篇定时文章，把 publish_at 从 publishAt 推迟到 leaseUntil
+	// 返回 true 代表抢占成功，多个实例里面只有一个能够抢占成功
+	// 如果抢占成功之后实例崩溃了，那么到了 leaseUntil，别的实例就可以重新抢占
+	ClaimScheduled(ctx context.Context, id, publishAt, leaseUntil int64) (bool, error)
+
+	// ListPub 按照 ID 升序遍历公开的文章，返回 ID 大于 startId 的 limit 篇
+	ListPub(ctx context.Context, startId int64, limit int) ([]PublishedArticle, error)
+	// GetPubByIds 批量查询公开的文章，没有公开的和不存在的不在结果里面，也不带标签
+	GetPubByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error)
+	// ListPubByTag 按照更新时间倒序返回带有某个标签的公开文章
+	ListPubByTag(ctx context.Context, tag string, offset, limit int) ([]PublishedArticle, error)
+	// SuggestTags 返回以 prefix 开头的标签，文章多的排在前面
+	SuggestTags(ctx context.Context, prefix string, limit int) ([]Tag, error)
+	// TagCounts 返回这些标签下的公开文章数量，没有文章的标签不会返回
+	TagCounts(ctx context.Context, tags []string) ([]Tag, error)
 }
@@ -0,0 +1,145 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -source=./collection.go -package=daomocks -destination=mocks/collection.mock.go CollectionDAO
+type CollectionDAO interface {
+	Insert(ctx context.Context, c Collection) (int64, error)
+	// UpdateName 重命名，不是 uid 的收藏夹返回 ErrRecordNotFound
+	UpdateName(ctx context.Context, uid, cid int64, name string) error
+	// Delete 只删除收藏夹本身，里面的东西要先取消收藏。不是 uid 的收藏夹返回 ErrRecordNotFound
+	Delete(ctx context.Context, uid, cid int64) error
+	GetById(ctx context.Context, uid, cid int64) (Collection, error)
+	// ListByUid 用户创建的收藏夹，最近创建的在前面
+	ListByUid(ctx context.Context, uid int64, offset, limit int) ([]Collection, error)
+	// CountItems 统计收藏夹里面有多少个东西，key 是 cid，空的收藏夹不在结果里面
+	CountItems(ctx context.Context, uid int64, cids []int64) (map[int64]int64, error)
+	// ListItems 收藏夹里面的东西，最近收藏的在前面
+	ListItems(ctx context.Context, uid, cid int64, offset, limit int) ([]UserCollectionBiz, error)
+	// MoveItem 把收藏的东西挪到 cid 收藏夹，没有收藏过的返回 ErrRecordNotFound
+	MoveItem(ctx context.Context, uid int64, biz string, bizId, cid int64) error
+}
+
+type GORMCollectionDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMCollectionDAO(db *gorm.DB) CollectionDAO {
+	return &GORMCollectionDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMCollectionDAO) Insert(ctx context.Context, c Collection) (int64, error) {
+	now := time.Now().UnixMilli()
+	c.Ctime = now
+	c.Utime = now
+	err := dao.db.WithContext(ctx).Create(&c).Error
+	return c.Id, err
+}
+
+func (dao *GORMCollectionDAO) UpdateName(ctx context.Context, uid, cid int64, name string) error {
+	res := dao.db.WithContext(ctx).Model(&Collection{}).
+		Where("id = ? AND uid = ?", cid, uid).
+		Updates(map[string]any{
+			"name":  name,
+			"utime": time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (dao *GORMCollectionDAO) Delete(ctx context.Context, uid, cid int64) error {
+	res := dao.db.WithContext(ctx).
+		Where("id = ? AND uid = ?", cid, uid).
+		Delete(&Collection{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (dao *GORMCollectionDAO) GetById(ctx context.Context, uid, cid int64) (Collection, error) {
+	var res Collection
+	err := dao.db.WithContext(ctx).
+		Where("id = ? AND uid = ?", cid, uid).
+		First(&res).Error
+	return res, err
+}
+
+func (dao *GORMCollectionDAO) ListByUid(ctx context.Context,
+	uid int64, offset, limit int) ([]Collection, error) {
+	var res []Collection
+	err := dao.db.WithContext(ctx).
+		Where("uid = ?", uid).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMCollectionDAO) CountItems(ctx context.Context,
+	uid int64, cids []int64) (map[int64]int64, error) {
+	res := make(map[int64]int64, len(cids))
+	if len(cids) == 0 {
+		return res, nil
+	}
+	var rows []struct {
+		Cid int64
+		Cnt int64
+	}
+	err := dao.db.WithContext(ctx).Model(&UserCollectionBiz{}).
+		Select("cid, COUNT(*) AS cnt").
+		Where("uid = ? AND cid IN ?", uid, cids).
+		Group("cid").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		res[row.Cid] = row.Cnt
+	}
+	return res, nil
+}
+
+func (dao *GORMCollectionDAO) ListItems(ctx context.Context,
+	uid, cid int64, offset, limit int) ([]UserCollectionBiz, error) {
+	var res []UserCollectionBiz
+	err := dao.db.WithContext(ctx).
+		Where("uid = ? AND cid = ?", uid, cid).
+		Order("ctime DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMCollectionDAO) MoveItem(ctx context.Context,
+	uid int64, biz string, bizId, cid int64) error {
+	// 一个东西只会在一个收藏夹里面，挪动就是改 cid，收藏数不变
+	res := dao.db.WithContext(ctx).Model(&UserCollectionBiz{}).
+		Where("uid = ? AND biz = ? AND biz_id = ?", uid, biz, bizId).
+		Updates(map[string]any{
+			"cid":   cid,
+			"utime": time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
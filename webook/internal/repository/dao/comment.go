@@ -0,0 +1,125 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -source=./comment.go -package=daomocks -destination=mocks/comment.mock.go CommentDAO
+type CommentDAO interface {
+	Insert(ctx context.Context, c Comment) (int64, error)
+	FindById(ctx context.Context, id int64) (Comment, error)
+	// FindTopLevel 按照 ID 倒序查找 ID 小于 maxId 的顶级评论
+	FindTopLevel(ctx context.Context, biz string, bizId, maxId int64, limit int) ([]Comment, error)
+	// FindReplies 按照 ID 升序查找 ID 大于 minId 的回复
+	FindReplies(ctx context.Context, rootId, minId int64, limit int) ([]Comment, error)
+	// CountReplies 统计这些顶级评论下面的回复数量
+	CountReplies(ctx context.Context, rootIds []int64) (map[int64]int64, error)
+	Count(ctx context.Context, biz string, bizId int64) (int64, error)
+	// Delete 删除评论。如果是顶级评论，那么下面的回复也一起删掉。
+	// 返回一共删除了多少条
+	Delete(ctx context.Context, id int64) (int64, error)
+}
+
+type GORMCommentDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMCommentDAO(db *gorm.DB) CommentDAO {
+	return &GORMCommentDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMCommentDAO) Insert(ctx context.Context, c Comment) (int64, error) {
+	now := time.Now().UnixMilli()
+	c.Ctime = now
+	c.Utime = now
+	err := dao.db.WithContext(ctx).Create(&c).Error
+	return c.Id, err
+}
+
+func (dao *GORMCommentDAO) FindById(ctx context.Context, id int64) (Comment, error) {
+	var res Comment
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&res).Error
+	return res, err
+}
+
+func (dao *GORMCommentDAO) FindTopLevel(ctx context.Context,
+	biz string, bizId, maxId int64, limit int) ([]Comment, error) {
+	var res []Comment
+	// 命中 biz, biz_id, root_id 的联合索引，再按照主键倒序
+	err := dao.db.WithContext(ctx).
+		Where("biz = ? AND biz_id = ? AND root_id = 0 AND id < ?", biz, bizId, maxId).
+		Order("id DESC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMCommentDAO) FindReplies(ctx context.Context,
+	rootId, minId int64, limit int) ([]Comment, error) {
+	var res []Comment
+	err := dao.db.WithContext(ctx).
+		Where("root_id = ? AND id > ?", rootId, minId).
+		Order("id ASC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMCommentDAO) CountReplies(ctx context.Context, rootIds []int64) (map[int64]int64, error) {
+	res := make(map[int64]int64, len(rootIds))
+	if len(rootIds) == 0 {
+		return res, nil
+	}
+	var rows []struct {
+		RootId int64
+		Cnt    int64
+	}
+	err := dao.db.WithContext(ctx).Model(&Comment{}).
+		Select("root_id, COUNT(*) AS cnt").
+		Where("root_id IN ?", rootIds).
+		Group("root_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		res[row.RootId] = row.Cnt
+	}
+	return res, nil
+}
+
+func (dao *GORMCommentDAO) Count(ctx context.Context, biz string, bizId int64) (int64, error) {
+	var res int64
+	err := dao.db.WithContext(ctx).Model(&Comment{}).
+		Where("biz = ? AND biz_id = ?", biz, bizId).
+		Count(&res).Error
+	return res, err
+}
+
+func (dao *GORMCommentDAO) Delete(ctx context.Context, id int64) (int64, error) {
+	// 顶级评论的回复 root_id 就是它的 id，回复本身没有别的评论以它为 root
+	res := dao.db.WithContext(ctx).
+		Where("id = ? OR root_id = ?", id, id).
+		Delete(&Comment{})
+	return res.RowsAffected, res.Error
+}
+
+type Comment struct {
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 查询顶级评论的时候，WHERE biz = ? AND biz_id = ? AND root_id = 0
+	// 所以联合索引是 biz, biz_id, root_id
+	Biz   string `gorm:"index:biz_type_id_root;type:varchar(128)"`
+	BizId int64  `gorm:"index:biz_type_id_root"`
+	Uid   int64
+	// 顶级评论为 0，查询回复的时候单独使用这个索引
+	RootId   int64 `gorm:"index:biz_type_id_root;index"`
+	ParentId int64
+	Content  string `gorm:"type:text"`
+	Ctime    int64
+	Utime    int64
+}
@@ -0,0 +1,123 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockgen -source=./feed.go -package=daomocks -destination=mocks/feed.mock.go FeedDAO
+type FeedDAO interface {
+	// CreatePushEvents 写入粉丝的收件箱，重复写入会被忽略
+	CreatePushEvents(ctx context.Context, evts []FeedPushEvent) error
+	// CreatePullEvent 写入作者的发件箱，重复写入会被忽略
+	CreatePullEvent(ctx context.Context, evt FeedPullEvent) error
+	// FindPushEvents 查询收件箱中排在游标后面的事件。ctime 为 0 的时候从头开始
+	FindPushEvents(ctx context.Context, uid int64, ctime, aid int64, limit int) ([]FeedPushEvent, error)
+	// FindPullEvents 查询这些作者的发件箱中排在游标后面的事件
+	FindPullEvents(ctx context.Context, authors []int64, ctime, aid int64, limit int) ([]FeedPullEvent, error)
+	// DeletePushEvents 删掉收件箱中某个作者的文章，取消关注的时候用
+	DeletePushEvents(ctx context.Context, uid, author int64) error
+	// DeleteEventsByAid 文章撤回之后，从所有的收件箱和发件箱里面删掉
+	DeleteEventsByAid(ctx context.Context, aid int64) error
+}
+
+type GORMFeedDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMFeedDAO(db *gorm.DB) FeedDAO {
+	return &GORMFeedDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMFeedDAO) CreatePushEvents(ctx context.Context, evts []FeedPushEvent) error {
+	if len(evts) == 0 {
+		return nil
+	}
+	// 消息可能重复消费，靠唯一索引去重
+	return dao.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&evts).Error
+}
+
+func (dao *GORMFeedDAO) CreatePullEvent(ctx context.Context, evt FeedPullEvent) error {
+	return dao.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&evt).Error
+}
+
+func (dao *GORMFeedDAO) FindPushEvents(ctx context.Context,
+	uid int64, ctime, aid int64, limit int) ([]FeedPushEvent, error) {
+	var res []FeedPushEvent
+	err := dao.afterCursor(dao.db.WithContext(ctx).Where("uid = ?", uid), ctime, aid).
+		Order("ctime DESC, aid DESC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMFeedDAO) FindPullEvents(ctx context.Context,
+	authors []int64, ctime, aid int64, limit int) ([]FeedPullEvent, error) {
+	if len(authors) == 0 {
+		return nil, nil
+	}
+	var res []FeedPullEvent
+	err := dao.afterCursor(dao.db.WithContext(ctx).Where("author IN ?", authors), ctime, aid).
+		Order("ctime DESC, aid DESC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+// afterCursor 按照 (ctime, aid) 倒序，只要游标后面的
+func (dao *GORMFeedDAO) afterCursor(db *gorm.DB, ctime, aid int64) *gorm.DB {
+	if ctime == 0 {
+		return db
+	}
+	return db.Where("(ctime < ? OR (ctime = ? AND aid < ?))", ctime, ctime, aid)
+}
+
+func (dao *GORMFeedDAO) DeletePushEvents(ctx context.Context, uid, author int64) error {
+	return dao.db.WithContext(ctx).
+		Where("uid = ? AND author = ?", uid, author).
+		Delete(&FeedPushEvent{}).Error
+}
+
+func (dao *GORMFeedDAO) DeleteEventsByAid(ctx context.Context, aid int64) error {
+	// 作者可能在发表之后才变成大 V，所以两张表都要删
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("aid = ?", aid).Delete(&FeedPushEvent{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("aid = ?", aid).Delete(&FeedPullEvent{}).Error
+	})
+}
+
+// FeedPushEvent 推模型，也就是收件箱。作者发表文章的时候给每个粉丝写一条
+type FeedPushEvent struct {
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 收件人
+	Uid int64 `gorm:"uniqueIndex:uid_aid;index:uid_ctime"`
+	// 撤回文章的时候按照 aid 删除，所以单独建一个索引
+	Aid int64 `gorm:"uniqueIndex:uid_aid;index"`
+	// 作者，取消关注的时候按照作者删除
+	Author   int64
+	Title    string `gorm:"type:varchar(1024)"`
+	Abstract string `gorm:"type:varchar(1024)"`
+	// 发表时间
+	Ctime int64 `gorm:"index:uid_ctime"`
+}
+
+// FeedPullEvent 拉模型，也就是发件箱。粉丝很多的作者只写这一条，读的时候再合并
+type FeedPullEvent struct {
+	Id       int64  `gorm:"primaryKey,autoIncrement"`
+	Aid      int64  `gorm:"unique"`
+	Author   int64  `gorm:"index:author_ctime"`
+	Title    string `gorm:"type:varchar(1024)"`
+	Abstract string `gorm:"type:varchar(1024)"`
+	Ctime    int64  `gorm:"index:author_ctime"`
+}
@@ -0,0 +1,74 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGORMFeedDAO_DeleteEventsByAid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sqlmock func(t *testing.T) *sql.DB
+
+		aid int64
+
+		wantErr error
+	}{
+		{
+			name: "收件箱和发件箱都删掉",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `feed_push_events` WHERE aid = ?").
+					WithArgs(int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec("DELETE FROM `feed_pull_events` WHERE aid = ?").
+					WithArgs(int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				return db
+			},
+			aid: 1,
+		},
+		{
+			name: "删除收件箱失败",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `feed_push_events` WHERE aid = ?").
+					WithArgs(int64(1)).
+					WillReturnError(errors.New("mock db error"))
+				mock.ExpectRollback()
+				return db
+			},
+			aid:     1,
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlmock(t)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			assert.NoError(t, err)
+			dao := NewGORMFeedDAO(db)
+			err = dao.DeleteEventsByAid(context.Background(), tc.aid)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	followStatusUnknown uint8 = iota
+	followStatusActive
+	followStatusInactive
+)
+
+//go:generate mockgen -source=./follow.go -package=daomocks -destination=mocks/follow.mock.go FollowDAO
+type FollowDAO interface {
+	// Follow 关注，返回关注关系是否发生了变化。已经关注过的，返回 false
+	Follow(ctx context.Context, follower, followee int64) (bool, error)
+	// CancelFollow 取消关注，返回关注关系是否发生了变化。本来就没关注的，返回 false
+	CancelFollow(ctx context.Context, follower, followee int64) (bool, error)
+	// FollowerList 关注了 followee 的人，最近关注的在前面
+	FollowerList(ctx context.Context, followee int64, offset, limit int) ([]FollowRelation, error)
+	// FolloweeList follower 关注的人，最近关注的在前面
+	FolloweeList(ctx context.Context, follower int64, offset, limit int) ([]FollowRelation, error)
+	FollowRelationDetail(ctx context.Context, follower, followee int64) (FollowRelation, error)
+	GetStatics(ctx context.Context, uid int64) (FollowStatics, error)
+}
+
+type GORMFollowDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMFollowDAO(db *gorm.DB) FollowDAO {
+	return &GORMFollowDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMFollowDAO) Follow(ctx context.Context, follower, followee int64) (bool, error) {
+	now := time.Now().UnixMilli()
+	changed := false
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 先看看是不是之前取消过关注，这种情况下把状态改回来就可以
+		res := tx.Model(&FollowRelation{}).
+			Where("follower = ? AND followee = ? AND status = ?",
+				follower, followee, followStatusInactive).
+			Updates(map[string]any{
+				"status": followStatusActive,
+				"utime":  now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			// 要么从来没关注过，要么已经关注了。
+			// 已经关注了的话唯一索引冲突，什么都不做
+			res = tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&FollowRelation{
+					Follower: follower,
+					Followee: followee,
+					Status:   followStatusActive,
+					Ctime:    now,
+					Utime:    now,
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		changed = true
+		return dao.incrStatics(tx, follower, followee, 1, now)
+	})
+	return changed, err
+}
+
+func (dao *GORMFollowDAO) CancelFollow(ctx context.Context, follower, followee int64) (bool, error) {
+	now := time.Now().UnixMilli()
+	changed := false
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 软删除，这样再次关注的时候不需要插入新的数据
+		res := tx.Model(&FollowRelation{}).
+			Where("follower = ? AND followee = ? AND status = ?",
+				follower, followee, followStatusActive).
+			Updates(map[string]any{
+				"status": followStatusInactive,
+				"utime":  now,
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		changed = true
+		return dao.incrStatics(tx, follower, followee, -1, now)
+	})
+	return changed, err
+}
+
+// incrStatics follower 的关注数和 followee 的粉丝数同时加上 delta
+func (dao *GORMFollowDAO) incrStatics(tx *gorm.DB, follower, followee int64, delta int64, now int64) error {
+	err := tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"followees": gorm.Expr("followees + ?", delta),
+			"utime":     now,
+		}),
+	}).Create(&FollowStatics{
+		Uid:       follower,
+		Followees: delta,
+		Ctime:     now,
+		Utime:     now,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"followers": gorm.Expr("followers + ?", delta),
+			"utime":     now,
+		}),
+	}).Create(&FollowStatics{
+		Uid:       followee,
+		Followers: delta,
+		Ctime:     now,
+		Utime:     now,
+	}).Error
+}
+
+func (dao *GORMFollowDAO) FollowerList(ctx context.Context,
+	followee int64, offset, limit int) ([]FollowRelation, error) {
+	var res []FollowRelation
+	err := dao.db.WithContext(ctx).
+		Where("followee = ? AND status = ?", followee, followStatusActive).
+		Order("utime DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMFollowDAO) FolloweeList(ctx context.Context,
+	follower int64, offset, limit int) ([]FollowRelation, error) {
+	var res []FollowRelation
+	err := dao.db.WithContext(ctx).
+		Where("follower = ? AND status = ?", follower, followStatusActive).
+		Order("utime DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMFollowDAO) FollowRelationDetail(ctx context.Context,
+	follower, followee int64) (FollowRelation, error) {
+	var res FollowRelation
+	err := dao.db.WithContext(ctx).
+		Where("follower = ? AND followee = ? AND status = ?",
+			follower, followee, followStatusActive).
+		First(&res).Error
+	return res, err
+}
+
+func (dao *GORMFollowDAO) GetStatics(ctx context.Context, uid int64) (FollowStatics, error) {
+	var res FollowStatics
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).First(&res).Error
+	return res, err
+}
+
+// FollowRelation 关注关系
+type FollowRelation struct {
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 查我关注了谁：WHERE follower = ?，所以 follower 在前
+	Follower int64 `gorm:"uniqueIndex:follower_followee"`
+	Followee int64 `gorm:"uniqueIndex:follower_followee;index:followee_status"`
+	// 查谁关注了我：WHERE followee = ? AND status = ?
+	Status uint8 `gorm:"index:followee_status"`
+	Ctime  int64
+	Utime  int64
+}
+
+// FollowStatics 关注数和粉丝数，和 Interactive 一样，是为了读的时候不需要 COUNT
+type FollowStatics struct {
+	Id  int64 `gorm:"primaryKey,autoIncrement"`
+	Uid int64 `gorm:"unique"`
+	// 粉丝数
+	Followers int64
+	// 关注数
+	Followees int64
+	Ctime     int64
+	Utime     int64
+}
@@ -0,0 +1,85 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockgen -source=./history.go -package=daomocks -destination=mocks/history.mock.go ReadHistoryDAO
+type ReadHistoryDAO interface {
+	// BatchUpsert 同一个用户同一个资源只保留一条，再次阅读的时候只会把 utime 往后推。
+	// 重复消费同一条阅读事件不会有副作用
+	BatchUpsert(ctx context.Context, hs []ReadHistory) error
+	// List 按照 (utime, biz_id) 倒序，只要游标后面的，utime 为 0 是第一页。
+	// 早于 since 的记录已经过了保留期限，不会返回
+	List(ctx context.Context, uid int64, biz string, since, utime, bizId int64, limit int) ([]ReadHistory, error)
+	DeleteByUid(ctx context.Context, uid int64) error
+	// DeleteBefore 删除 utime 早于 before 的记录，最多删除 limit 条，返回删除了多少条
+	DeleteBefore(ctx context.Context, before int64, limit int) (int64, error)
+}
+
+type GORMReadHistoryDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMReadHistoryDAO(db *gorm.DB) ReadHistoryDAO {
+	return &GORMReadHistoryDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMReadHistoryDAO) BatchUpsert(ctx context.Context, hs []ReadHistory) error {
+	if len(hs) == 0 {
+		return nil
+	}
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			// 消息可能乱序，也可能重复，所以取大的
+			"utime": gorm.Expr("GREATEST(`utime`, VALUES(`utime`))"),
+		}),
+	}).Create(&hs).Error
+}
+
+func (dao *GORMReadHistoryDAO) List(ctx context.Context,
+	uid int64, biz string, since, utime, bizId int64, limit int) ([]ReadHistory, error) {
+	var res []ReadHistory
+	db := dao.db.WithContext(ctx).
+		Where("uid = ? AND biz = ? AND utime >= ?", uid, biz, since)
+	if utime > 0 {
+		db = db.Where("(utime < ? OR (utime = ? AND biz_id < ?))", utime, utime, bizId)
+	}
+	err := db.Order("utime DESC, biz_id DESC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMReadHistoryDAO) DeleteByUid(ctx context.Context, uid int64) error {
+	return dao.db.WithContext(ctx).
+		Where("uid = ?", uid).
+		Delete(&ReadHistory{}).Error
+}
+
+func (dao *GORMReadHistoryDAO) DeleteBefore(ctx context.Context, before int64, limit int) (int64, error) {
+	// 分批删除，免得一个大事务锁住整张表
+	res := dao.db.WithContext(ctx).
+		Where("utime < ?", before).
+		Limit(limit).
+		Delete(&ReadHistory{})
+	return res.RowsAffected, res.Error
+}
+
+// ReadHistory 阅读记录
+type ReadHistory struct {
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 查我的阅读记录：WHERE uid = ? AND biz = ? ORDER BY utime DESC
+	Uid   int64  `gorm:"uniqueIndex:uid_biz_id;index:uid_biz_utime,priority:1"`
+	Biz   string `gorm:"type:varchar(128);uniqueIndex:uid_biz_id;index:uid_biz_utime,priority:2"`
+	BizId int64  `gorm:"uniqueIndex:uid_biz_id"`
+	// 第一次阅读的时间，再次阅读的时候不会修改
+	Ctime int64
+	// 最近一次阅读的时间，清理过期记录的时候也按照它来
+	Utime int64 `gorm:"index:uid_biz_utime,priority:3;index"`
+}
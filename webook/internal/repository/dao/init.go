@@ -4,15 +4,31 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao/article"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/outbox"
 )
 
 func InitTables(db *gorm.DB) error {
 	return db.AutoMigrate(&User{},
 		&article.Article{},
 		&article.PublishedArticle{},
+		&article.ArticleRevision{},
+		&article.ArticleTag{},
+		&article.PublishedArticleTag{},
+		&article.Tag{},
+		&article.PublishedArticleV1{},
+		&article.BlobTask{},
 		&Interactive{},
+		&ConsumedOffset{},
+		&ConsumedEvent{},
 		&UserLikeBiz{},
 		&Collection{},
 		&UserCollectionBiz{},
+		&ReadHistory{},
+		&Comment{},
+		&FollowRelation{},
+		&FollowStatics{},
+		&FeedPushEvent{},
+		&FeedPullEvent{},
+		&outbox.Message{},
 	)
 }
@@ -2,10 +2,16 @@ package dao
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
 	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/outbox"
 )
 
 var ErrRecordNotFound = gorm.ErrRecordNotFound
@@ -13,12 +19,53 @@ var ErrRecordNotFound = gorm.ErrRecordNotFound
 //go:generate mockgen -source=./interactive.go -package=daomocks -destination=mocks/interactive.mock.go InteractiveDAO
 type InteractiveDAO interface {
 	IncrReadCnt(ctx context.Context, biz string, bizId int64) error
+	// BatchIncrReadCnt 在一个事务里面批量增加阅读计数，并且推进 topic 在 partition 上已经消费的偏移量。
+	// offsets、bizs 和 bizIds 一一对应，偏移量不大于已经记录的偏移量的消息，之前已经处理过了，直接跳过。
+	// 返回的是之前记录的偏移量，比它大的才真正计数了
+	BatchIncrReadCnt(ctx context.Context, topic string, partition int32,
+		offsets []int64, bizs []string, bizIds []int64) (int64, error)
+	// BatchIncrCnt 批量更新计数，deltas 里面的 ReadCnt、LikeCnt 和 CollectCnt 都是增量，可以是负数。
+	// 事件是从发件箱投递的，重复投递的时候 offset 会变，所以不能按照 offset 去重，
+	// 而是在同一个事务里面记录处理过的 eventIds，已经处理过的跳过。eventIds 为 0 的不去重
+	BatchIncrCnt(ctx context.Context, eventIds []int64, deltas []Interactive) error
 	InsertLikeInfo(ctx context.Context, biz string, bizId, uid int64) error
 	GetLikeInfo(ctx context.Context, biz string, bizId, uid int64) (UserLikeBiz, error)
 	DeleteLikeInfo(ctx context.Context, biz string, bizId, uid int64) error
 	Get(ctx context.Context, biz string, bizId int64) (Interactive, error)
-	InsertCollectionBiz(ctx context.Context, cb UserCollectionBiz) error
+	// GetByIds 批量查询，没有数据的 bizId 不会出现在结果里面
+	GetByIds(ctx context.Context, biz string, bizIds []int64) ([]Interactive, error)
+	// InsertCollectionBiz 插入收藏记录，并且更新计数，返回是不是真的插入了。
+	// 一个资源只能在用户的一个收藏夹里面，已经收藏过的返回 false
+	InsertCollectionBiz(ctx context.Context, cb UserCollectionBiz) (bool, error)
+	// DeleteCollectionBiz 删除收藏记录，并且更新计数，返回是不是真的删除了
+	DeleteCollectionBiz(ctx context.Context, biz string, bizId, uid int64) (bool, error)
 	GetCollectionInfo(ctx context.Context, biz string, bizId, uid int64) (UserCollectionBiz, error)
+	// GetLikeInfos 批量查询 uid 对这些资源的点赞，没有点赞的不在结果里面
+	GetLikeInfos(ctx context.Context, biz string, uid int64, bizIds []int64) ([]UserLikeBiz, error)
+	// GetCollectionInfos 批量查询 uid 对这些资源的收藏，没有收藏的不在结果里面
+	GetCollectionInfos(ctx context.Context, biz string, uid int64, bizIds []int64) ([]UserCollectionBiz, error)
+	// ListLikes uid 的点赞记录，按照 (utime, biz_id) 倒序，只要游标后面的，utime 为 0 是第一页
+	ListLikes(ctx context.Context, biz string, uid, utime, bizId int64, limit int) ([]UserLikeBiz, error)
+
+	// 下面是异步计数模式，只写用户和资源的关系，不更新计数，
+	// 关系真的变了的时候，在同一个事务里面把计数事件写进发件箱，并且返回 true
+
+	InsertLikeRelation(ctx context.Context, biz string, bizId, uid int64) (bool, error)
+	DeleteLikeRelation(ctx context.Context, biz string, bizId, uid int64) (bool, error)
+	InsertCollectionRelation(ctx context.Context, cb UserCollectionBiz) (bool, error)
+	DeleteCollectionRelation(ctx context.Context, biz string, bizId, uid int64) (bool, error)
+
+	// 下面是对账用的
+
+	// ListInteractives 按照 ID 的顺序分批扫描
+	ListInteractives(ctx context.Context, startId int64, limit int) ([]Interactive, error)
+	// CountLikes 按照点赞记录统计真实的点赞数，key 是 bizId，没有人点赞的不在结果里面
+	CountLikes(ctx context.Context, biz string, bizIds []int64) (map[int64]int64, error)
+	// CountCollects 按照收藏记录统计真实的收藏数，key 是 bizId，没有人收藏的不在结果里面
+	CountCollects(ctx context.Context, biz string, bizIds []int64) (map[int64]int64, error)
+	// FixCnt 把计数改成 likeCnt 和 collectCnt。
+	// 只有计数还是 old 里面的值的时候才会修改，不会覆盖掉对账期间的点赞和收藏，返回是不是修改了
+	FixCnt(ctx context.Context, old Interactive, likeCnt, collectCnt int64) (bool, error)
 }
 
 type GORMInteractiveDAO struct {
@@ -40,16 +87,59 @@ func (dao *GORMInteractiveDAO) GetCollectionInfo(ctx context.Context, biz string
 	return res, err
 }
 
+func (dao *GORMInteractiveDAO) GetLikeInfos(ctx context.Context,
+	biz string, uid int64, bizIds []int64) ([]UserLikeBiz, error) {
+	var res []UserLikeBiz
+	if len(bizIds) == 0 {
+		return res, nil
+	}
+	err := dao.db.WithContext(ctx).
+		Where("uid = ? AND biz = ? AND biz_id IN ? AND status = ?", uid, biz, bizIds, 1).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMInteractiveDAO) GetCollectionInfos(ctx context.Context,
+	biz string, uid int64, bizIds []int64) ([]UserCollectionBiz, error) {
+	var res []UserCollectionBiz
+	if len(bizIds) == 0 {
+		return res, nil
+	}
+	err := dao.db.WithContext(ctx).
+		Where("uid = ? AND biz = ? AND biz_id IN ?", uid, biz, bizIds).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMInteractiveDAO) ListLikes(ctx context.Context,
+	biz string, uid, utime, bizId int64, limit int) ([]UserLikeBiz, error) {
+	var res []UserLikeBiz
+	db := dao.db.WithContext(ctx).
+		Where("uid = ? AND biz = ? AND status = ?", uid, biz, 1)
+	if utime > 0 {
+		db = db.Where("(utime < ? OR (utime = ? AND biz_id < ?))", utime, utime, bizId)
+	}
+	err := db.Order("utime DESC, biz_id DESC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
 // InsertCollectionBiz 插入收藏记录，并且更新计数
-func (dao *GORMInteractiveDAO) InsertCollectionBiz(ctx context.Context, cb UserCollectionBiz) error {
+func (dao *GORMInteractiveDAO) InsertCollectionBiz(ctx context.Context, cb UserCollectionBiz) (bool, error) {
 	now := time.Now().UnixMilli()
 	cb.Utime = now
 	cb.Ctime = now
-	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 插入收藏项目
-		err := dao.db.WithContext(ctx).Create(&cb).Error
-		if err != nil {
-			return err
+	var changed bool
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 插入收藏项目，已经收藏过的（不管在哪个收藏夹）什么都不做
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&cb)
+		if res.Error != nil {
+			return res.Error
+		}
+		changed = res.RowsAffected == 1
+		if !changed {
+			return nil
 		}
 		// 这边就是更新数量
 		return tx.Clauses(clause.OnConflict{
@@ -65,6 +155,37 @@ func (dao *GORMInteractiveDAO) InsertCollectionBiz(ctx context.Context, cb UserC
 			BizId:      cb.BizId,
 		}).Error
 	})
+	if err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+func (dao *GORMInteractiveDAO) DeleteCollectionBiz(ctx context.Context,
+	biz string, bizId, uid int64) (bool, error) {
+	now := time.Now().UnixMilli()
+	var changed bool
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("biz = ? AND biz_id = ? AND uid = ?", biz, bizId, uid).
+			Delete(&UserCollectionBiz{})
+		if res.Error != nil {
+			return res.Error
+		}
+		changed = res.RowsAffected == 1
+		if !changed {
+			return nil
+		}
+		return tx.Model(&Interactive{}).
+			Where("biz = ? AND biz_id = ?", biz, bizId).
+			Updates(map[string]any{
+				"collect_cnt": gorm.Expr("`collect_cnt`-1"),
+				"utime":       now,
+			}).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return changed, nil
 }
 
 func (dao *GORMInteractiveDAO) InsertLikeInfo(ctx context.Context, biz string, bizId, uid int64) error {
@@ -134,6 +255,142 @@ func (dao *GORMInteractiveDAO) DeleteLikeInfo(ctx context.Context, biz string, b
 	})
 }
 
+func (dao *GORMInteractiveDAO) InsertLikeRelation(ctx context.Context,
+	biz string, bizId, uid int64) (bool, error) {
+	now := time.Now().UnixMilli()
+	var changed bool
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 之前取消过点赞的，恢复过来
+		res := tx.Model(&UserLikeBiz{}).
+			Where("biz = ? AND biz_id = ? AND uid = ? AND status = ?", biz, bizId, uid, 0).
+			Updates(map[string]any{
+				"utime":  now,
+				"status": 1,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			// 已经点过赞的，唯一索引冲突，什么也不做
+			res = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&UserLikeBiz{
+				Biz:    biz,
+				BizId:  bizId,
+				Uid:    uid,
+				Status: 1,
+				Ctime:  now,
+				Utime:  now,
+			})
+			if res.Error != nil {
+				return res.Error
+			}
+		}
+		changed = res.RowsAffected == 1
+		if !changed {
+			return nil
+		}
+		return saveCntEvent(tx, biz, bizId, uid, 1, 0, now)
+	})
+	if err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+func (dao *GORMInteractiveDAO) DeleteLikeRelation(ctx context.Context,
+	biz string, bizId, uid int64) (bool, error) {
+	now := time.Now().UnixMilli()
+	var changed bool
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&UserLikeBiz{}).
+			Where("biz = ? AND biz_id = ? AND uid = ? AND status = ?", biz, bizId, uid, 1).
+			Updates(map[string]any{
+				"utime":  now,
+				"status": 0,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		changed = res.RowsAffected == 1
+		if !changed {
+			return nil
+		}
+		return saveCntEvent(tx, biz, bizId, uid, -1, 0, now)
+	})
+	if err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+func (dao *GORMInteractiveDAO) InsertCollectionRelation(ctx context.Context,
+	cb UserCollectionBiz) (bool, error) {
+	now := time.Now().UnixMilli()
+	cb.Utime = now
+	cb.Ctime = now
+	var changed bool
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&cb)
+		if res.Error != nil {
+			return res.Error
+		}
+		changed = res.RowsAffected == 1
+		if !changed {
+			return nil
+		}
+		return saveCntEvent(tx, cb.Biz, cb.BizId, cb.Uid, 0, 1, now)
+	})
+	if err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+func (dao *GORMInteractiveDAO) DeleteCollectionRelation(ctx context.Context,
+	biz string, bizId, uid int64) (bool, error) {
+	now := time.Now().UnixMilli()
+	var changed bool
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("biz = ? AND biz_id = ? AND uid = ?", biz, bizId, uid).
+			Delete(&UserCollectionBiz{})
+		if res.Error != nil {
+			return res.Error
+		}
+		changed = res.RowsAffected == 1
+		if !changed {
+			return nil
+		}
+		return saveCntEvent(tx, biz, bizId, uid, 0, -1, now)
+	})
+	if err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+// saveCntEvent 计数事件和关系一起提交，提交之后由 outbox.Relay 投递
+func saveCntEvent(tx *gorm.DB, biz string, bizId, uid int64,
+	likeDelta, collectDelta int64, now int64) error {
+	val, err := json.Marshal(events.InteractiveCntEvent{
+		Version:      events.InteractiveCntVersion,
+		Biz:          biz,
+		BizId:        bizId,
+		Uid:          uid,
+		LikeDelta:    likeDelta,
+		CollectDelta: collectDelta,
+		Ctime:        now,
+	})
+	if err != nil {
+		return err
+	}
+	return outbox.Save(tx, outbox.Message{
+		Topic: events.TopicInteractiveCnt,
+		// 同一个资源的事件进同一个分区，点赞和取消点赞的顺序不会乱
+		MsgKey: fmt.Sprintf("%s:%d", biz, bizId),
+		Value:  val,
+		Ctime:  now,
+	})
+}
+
 func NewGORMInteractiveDAO(db *gorm.DB) InteractiveDAO {
 	return &GORMInteractiveDAO{
 		db: db,
@@ -180,6 +437,176 @@ func (dao *GORMInteractiveDAO) IncrReadCnt(ctx context.Context,
 	}).Error
 }
 
+func (dao *GORMInteractiveDAO) BatchIncrReadCnt(ctx context.Context, topic string, partition int32,
+	offsets []int64, bizs []string, bizIds []int64) (int64, error) {
+	now := time.Now().UnixMilli()
+	var prev int64
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁住偏移量这一行，再均衡的时候两个实例可能同时在处理同一个 partition
+		co, err := dao.lockConsumedOffset(tx, topic, partition)
+		if err != nil {
+			return err
+		}
+		prev = co.Offset
+		deltas := make([]Interactive, 0, len(bizIds))
+		maxOffset := prev
+		for i, offset := range offsets {
+			if offset <= prev {
+				continue
+			}
+			if offset > maxOffset {
+				maxOffset = offset
+			}
+			deltas = append(deltas, Interactive{Biz: bizs[i], BizId: bizIds[i], ReadCnt: 1})
+		}
+		if maxOffset == prev {
+			return nil
+		}
+		err = dao.incrCnt(tx, deltas, now)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&ConsumedOffset{}).
+			Where("id = ?", co.Id).
+			Updates(map[string]any{
+				"offset": maxOffset,
+				"utime":  now,
+			}).Error
+	})
+	return prev, err
+}
+
+func (dao *GORMInteractiveDAO) BatchIncrCnt(ctx context.Context,
+	eventIds []int64, deltas []Interactive) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := make([]int64, 0, len(eventIds))
+		for _, id := range eventIds {
+			if id != 0 {
+				ids = append(ids, id)
+			}
+		}
+		seen := make(map[int64]struct{}, len(ids))
+		if len(ids) > 0 {
+			var consumed []int64
+			err := tx.Model(&ConsumedEvent{}).
+				Where("event_id IN ?", ids).
+				Pluck("event_id", &consumed).Error
+			if err != nil {
+				return err
+			}
+			for _, id := range consumed {
+				seen[id] = struct{}{}
+			}
+		}
+		fresh := make([]Interactive, 0, len(deltas))
+		events := make([]ConsumedEvent, 0, len(ids))
+		for i, id := range eventIds {
+			if id != 0 {
+				// 同一批里面也可能有重复投递的
+				if _, ok := seen[id]; ok {
+					continue
+				}
+				seen[id] = struct{}{}
+				events = append(events, ConsumedEvent{EventId: id, Ctime: now})
+			}
+			fresh = append(fresh, deltas[i])
+		}
+		if len(events) > 0 {
+			// 再均衡的时候两个实例同时处理同一批事件，后提交的会违反唯一索引，整个事务回滚，
+			// 重试的时候就能查到已经处理过了
+			err := tx.Create(&events).Error
+			if err != nil {
+				return err
+			}
+		}
+		return dao.incrCnt(tx, fresh, now)
+	})
+}
+
+// incrCnt 合并 deltas 里面同一个资源的增量，再更新计数，必须在事务里面调用
+func (dao *GORMInteractiveDAO) incrCnt(tx *gorm.DB, deltas []Interactive, now int64) error {
+	type bizKey struct {
+		biz   string
+		bizId int64
+	}
+	merged := make(map[bizKey]*Interactive, len(deltas))
+	keys := make([]bizKey, 0, len(deltas))
+	for _, d := range deltas {
+		key := bizKey{biz: d.Biz, bizId: d.BizId}
+		m, ok := merged[key]
+		if !ok {
+			m = &Interactive{Biz: key.biz, BizId: key.bizId}
+			merged[key] = m
+			keys = append(keys, key)
+		}
+		m.ReadCnt += d.ReadCnt
+		m.LikeCnt += d.LikeCnt
+		m.CollectCnt += d.CollectCnt
+	}
+	// 按照固定的顺序更新，避免两个事务互相等待对方的行锁
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].biz != keys[j].biz {
+			return keys[i].biz < keys[j].biz
+		}
+		return keys[i].bizId < keys[j].bizId
+	})
+	for _, key := range keys {
+		m := merged[key]
+		assignments := map[string]any{"utime": now}
+		if m.ReadCnt != 0 {
+			assignments["read_cnt"] = gorm.Expr("read_cnt + ?", m.ReadCnt)
+		}
+		if m.LikeCnt != 0 {
+			assignments["like_cnt"] = gorm.Expr("like_cnt + ?", m.LikeCnt)
+		}
+		if m.CollectCnt != 0 {
+			assignments["collect_cnt"] = gorm.Expr("collect_cnt + ?", m.CollectCnt)
+		}
+		if len(assignments) == 1 {
+			// 点赞之后又取消了，抵消掉了
+			continue
+		}
+		m.Ctime = now
+		m.Utime = now
+		err := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(assignments),
+		}).Create(m).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockConsumedOffset 查询并锁住偏移量，没有的话先插入一条
+func (dao *GORMInteractiveDAO) lockConsumedOffset(tx *gorm.DB,
+	topic string, partition int32) (ConsumedOffset, error) {
+	var co ConsumedOffset
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("topic = ? AND `partition` = ?", topic, partition).
+		First(&co).Error
+	if err != gorm.ErrRecordNotFound {
+		return co, err
+	}
+	// 第一次消费这个 partition，偏移量从 -1 开始，这样 0 号消息也会被处理
+	now := time.Now().UnixMilli()
+	err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&ConsumedOffset{
+		Topic:     topic,
+		Partition: partition,
+		Offset:    -1,
+		Ctime:     now,
+		Utime:     now,
+	}).Error
+	if err != nil {
+		return co, err
+	}
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("topic = ? AND `partition` = ?", topic, partition).
+		First(&co).Error
+	return co, err
+}
+
 func (dao *GORMInteractiveDAO) Get(ctx context.Context, biz string, bizId int64) (Interactive, error) {
 	var res Interactive
 	err := dao.db.WithContext(ctx).
@@ -188,6 +615,17 @@ func (dao *GORMInteractiveDAO) Get(ctx context.Context, biz string, bizId int64)
 	return res, err
 }
 
+func (dao *GORMInteractiveDAO) GetByIds(ctx context.Context, biz string, bizIds []int64) ([]Interactive, error) {
+	var res []Interactive
+	if len(bizIds) == 0 {
+		return res, nil
+	}
+	err := dao.db.WithContext(ctx).
+		Where("biz = ? AND biz_id IN ?", biz, bizIds).
+		Find(&res).Error
+	return res, err
+}
+
 // Interactive 正常来说，一张主表和与它有关联关系的表会共用一个DAO，
 // 所以我们就用一个 DAO 来操作
 // 假如说我要查找点赞数量前 100 的，
@@ -202,6 +640,63 @@ func (dao *GORMInteractiveDAO) Get(ctx context.Context, biz string, bizId int64)
 // 1.1 定时计算 + 本地缓存
 // 2. 优化版的 zset，定时筛选 + 实时 zset 计算
 // 还要别的方案你们也可以考虑
+func (dao *GORMInteractiveDAO) ListInteractives(ctx context.Context,
+	startId int64, limit int) ([]Interactive, error) {
+	var res []Interactive
+	err := dao.db.WithContext(ctx).
+		Where("id > ?", startId).
+		Order("id").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMInteractiveDAO) CountLikes(ctx context.Context,
+	biz string, bizIds []int64) (map[int64]int64, error) {
+	return dao.countByBizId(ctx, &UserLikeBiz{},
+		"biz = ? AND biz_id IN ? AND status = ?", biz, bizIds, 1)
+}
+
+func (dao *GORMInteractiveDAO) CountCollects(ctx context.Context,
+	biz string, bizIds []int64) (map[int64]int64, error) {
+	return dao.countByBizId(ctx, &UserCollectionBiz{},
+		"biz = ? AND biz_id IN ?", biz, bizIds)
+}
+
+func (dao *GORMInteractiveDAO) countByBizId(ctx context.Context,
+	model any, query string, args ...any) (map[int64]int64, error) {
+	var rows []struct {
+		BizId int64
+		Cnt   int64
+	}
+	err := dao.db.WithContext(ctx).Model(model).
+		Select("biz_id, COUNT(*) AS cnt").
+		Where(query, args...).
+		Group("biz_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		res[row.BizId] = row.Cnt
+	}
+	return res, nil
+}
+
+func (dao *GORMInteractiveDAO) FixCnt(ctx context.Context,
+	old Interactive, likeCnt, collectCnt int64) (bool, error) {
+	res := dao.db.WithContext(ctx).Model(&Interactive{}).
+		Where("id = ? AND like_cnt = ? AND collect_cnt = ?",
+			old.Id, old.LikeCnt, old.CollectCnt).
+		Updates(map[string]any{
+			"like_cnt":    likeCnt,
+			"collect_cnt": collectCnt,
+			"utime":       time.Now().UnixMilli(),
+		})
+	return res.RowsAffected == 1, res.Error
+}
+
 type Interactive struct {
 	Id int64 `gorm:"primaryKey,autoIncrement"`
 	// 业务标识符
@@ -226,6 +721,25 @@ type Interactive struct {
 	Utime      int64
 }
 
+// ConsumedOffset 消费者在某个 topic 的某个 partition 上已经处理到哪里了
+// 和计数在同一个事务里面更新，Kafka 重复投递的消息，偏移量不会比这里记录的大，直接跳过
+type ConsumedOffset struct {
+	Id        int64  `gorm:"primaryKey,autoIncrement"`
+	Topic     string `gorm:"uniqueIndex:topic_partition;type:varchar(128)"`
+	Partition int32  `gorm:"uniqueIndex:topic_partition"`
+	Offset    int64
+	Ctime     int64
+	Utime     int64
+}
+
+// ConsumedEvent 已经处理过的事件，EventId 是发件箱里面的消息 ID。
+// 重复投递只会发生在投递之后的一段时间里面，很久以前的记录可以定期删掉
+type ConsumedEvent struct {
+	Id      int64 `gorm:"primaryKey,autoIncrement"`
+	EventId int64 `gorm:"unique"`
+	Ctime   int64 `gorm:"index"`
+}
+
 // InteractiveV1 对写更友好
 // Interactive 对读更加友好
 type InteractiveV1 struct {
@@ -255,20 +769,22 @@ type UserLikeBiz struct {
 	// 2. 如果你的场景是，我的点赞数量，需要通过这里来比较/纠正
 	// biz_id 和 biz 在前
 	// select count(*) where biz = ? and biz_id = ?
-	Biz   string `gorm:"uniqueIndex:uid_biz_id_type;type:varchar(128)"`
+	// 我的点赞列表是第一种场景，所以另外有一个 uid 在前的索引
+	// WHERE uid = ? AND biz = ? AND status = 1 ORDER BY utime DESC
+	Biz   string `gorm:"uniqueIndex:uid_biz_id_type;type:varchar(128);index:uid_biz_status_utime,priority:2"`
 	BizId int64  `gorm:"uniqueIndex:uid_biz_id_type"`
 
 	// 谁的操作
-	Uid int64 `gorm:"uniqueIndex:uid_biz_id_type"`
+	Uid int64 `gorm:"uniqueIndex:uid_biz_id_type;index:uid_biz_status_utime,priority:1"`
 
 	Ctime int64
-	Utime int64
+	Utime int64 `gorm:"index:uid_biz_status_utime,priority:4"`
 	// 如果这样设计，那么，取消点赞的时候，怎么办？
 	// 我删了这个数据
 	// 你就软删除
 	// 这个状态是存储状态，纯纯用于软删除的，业务层面上没有感知
 	// 0-代表删除，1 代表有效
-	Status uint8
+	Status uint8 `gorm:"index:uid_biz_status_utime,priority:3"`
 
 	// 有效/无效
 	//Type string
@@ -278,7 +794,8 @@ type UserLikeBiz struct {
 type Collection struct {
 	Id   int64  `gorm:"primaryKey,autoIncrement"`
 	Name string `gorm:"type=varchar(1024)"`
-	Uid  int64  `gorm:""`
+	// 按照用户查询收藏夹列表
+	Uid int64 `gorm:"index"`
 
 	Ctime int64
 	Utime int64
@@ -298,16 +815,3 @@ type UserCollectionBiz struct {
 	Ctime int64
 	Utime int64
 }
-
-type CollectionItem struct {
-	Cid   int64
-	Cname string
-	BizId int64
-	Biz   string
-}
-
-func (dao *GORMInteractiveDAO) GetItems() ([]CollectionItem, error) {
-	var items []CollectionItem
-	err := dao.db.Raw("", 1, 2, 3).Find(&items).Error
-	return items, err
-}
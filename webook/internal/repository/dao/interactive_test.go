@@ -0,0 +1,250 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGORMInteractiveDAO_BatchIncrReadCnt(t *testing.T) {
+	offsetCols := []string{"id", "topic", "partition", "offset", "ctime", "utime"}
+	testCases := []struct {
+		name    string
+		sqlmock func(t *testing.T) *sql.DB
+
+		offsets []int64
+		bizs    []string
+		bizIds  []int64
+
+		wantPrev int64
+		wantErr  error
+	}{
+		{
+			name: "合并计数",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `consumed_offsets` .* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows(offsetCols).
+						AddRow(1, "article_read", 0, 9, 0, 0))
+				// 1 号读了两次，合并成一条
+				mock.ExpectExec("INSERT INTO `interactives` .*").
+					WithArgs(int64(1), "article", int64(2), sqlmock.AnyArg(), sqlmock.AnyArg(),
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO `interactives` .*").
+					WithArgs(int64(2), "article", int64(1), sqlmock.AnyArg(), sqlmock.AnyArg(),
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec("UPDATE `consumed_offsets` SET .*").
+					WithArgs(int64(12), sqlmock.AnyArg(), int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			offsets:  []int64{10, 11, 12},
+			bizs:     []string{"article", "article", "article"},
+			bizIds:   []int64{1, 2, 1},
+			wantPrev: 9,
+		},
+		{
+			name: "全部都处理过了",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `consumed_offsets` .* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows(offsetCols).
+						AddRow(1, "article_read", 0, 12, 0, 0))
+				mock.ExpectCommit()
+				return db
+			},
+			offsets:  []int64{10, 11, 12},
+			bizs:     []string{"article", "article", "article"},
+			bizIds:   []int64{1, 2, 1},
+			wantPrev: 12,
+		},
+		{
+			name: "更新计数失败",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `consumed_offsets` .* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows(offsetCols).
+						AddRow(1, "article_read", 0, 9, 0, 0))
+				mock.ExpectExec("INSERT INTO `interactives` .*").
+					WillReturnError(errors.New("mock db error"))
+				mock.ExpectRollback()
+				return db
+			},
+			offsets:  []int64{10},
+			bizs:     []string{"article"},
+			bizIds:   []int64{1},
+			wantPrev: 9,
+			wantErr:  errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlmock(t)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			assert.NoError(t, err)
+			dao := NewGORMInteractiveDAO(db)
+			prev, err := dao.BatchIncrReadCnt(context.Background(), "article_read", 0,
+				tc.offsets, tc.bizs, tc.bizIds)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantPrev, prev)
+		})
+	}
+}
+
+func TestGORMInteractiveDAO_BatchIncrCnt(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	mock.ExpectBegin()
+	// 10 之前已经处理过了
+	mock.ExpectQuery("SELECT `event_id` FROM `consumed_events` WHERE event_id IN \\(\\?,\\?,\\?,\\?\\)").
+		WithArgs(int64(10), int64(11), int64(12), int64(11)).
+		WillReturnRows(sqlmock.NewRows([]string{"event_id"}).AddRow(10))
+	mock.ExpectExec("INSERT INTO `consumed_events`").
+		WithArgs(int64(11), sqlmock.AnyArg(), int64(12), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	// 没有事件 ID 的不去重
+	mock.ExpectExec("INSERT INTO `interactives` .* `like_cnt`=like_cnt \\+ \\?.*").
+		WithArgs(int64(1), "article", int64(0), int64(1), int64(0), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			int64(1), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// 11 重复投递了两次，只算一次
+	mock.ExpectExec("INSERT INTO `interactives` .* `collect_cnt`=collect_cnt \\+ \\?,`like_cnt`=like_cnt \\+ \\?.*").
+		WithArgs(int64(2), "article", int64(0), int64(1), int64(1), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			int64(1), int64(1), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sqlDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	assert.NoError(t, err)
+	dao := NewGORMInteractiveDAO(db)
+	err = dao.BatchIncrCnt(context.Background(),
+		[]int64{10, 11, 12, 11, 0}, []Interactive{
+			{Biz: "article", BizId: 3, LikeCnt: 1},
+			{Biz: "article", BizId: 2, LikeCnt: 1},
+			{Biz: "article", BizId: 2, CollectCnt: 1},
+			{Biz: "article", BizId: 2, LikeCnt: 1},
+			{Biz: "article", BizId: 1, LikeCnt: 1},
+		})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGORMInteractiveDAO_InsertLikeRelation(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sqlmock func(t *testing.T) *sql.DB
+
+		wantChanged bool
+		wantErr     error
+	}{
+		{
+			name: "第一次点赞",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `user_like_bizs` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO `user_like_bizs` .* ON DUPLICATE KEY UPDATE `id`=`id`").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO `outbox_messages` .*").
+					WithArgs("interactive_cnt", "article:1", sqlmock.AnyArg(), sqlmock.AnyArg(),
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			wantChanged: true,
+		},
+		{
+			name: "取消之后重新点赞",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `user_like_bizs` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `outbox_messages` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			wantChanged: true,
+		},
+		{
+			name: "已经点过赞了",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `user_like_bizs` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO `user_like_bizs` .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		{
+			name: "写发件箱失败",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `user_like_bizs` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `outbox_messages` .*").
+					WillReturnError(errors.New("mock db error"))
+				mock.ExpectRollback()
+				return db
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlmock(t)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			assert.NoError(t, err)
+			dao := NewGORMInteractiveDAO(db)
+			changed, err := dao.InsertLikeRelation(context.Background(), "article", 1, 123)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantChanged, changed)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/dao/comment.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/dao/comment.go -package=daomocks -destination=./webook/internal/repository/dao/mocks/comment.mock.go
+//
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dao "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCommentDAO is a mock of CommentDAO interface.
+type MockCommentDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentDAOMockRecorder
+}
+
+// MockCommentDAOMockRecorder is the mock recorder for MockCommentDAO.
+type MockCommentDAOMockRecorder struct {
+	mock *MockCommentDAO
+}
+
+// NewMockCommentDAO creates a new mock instance.
+func NewMockCommentDAO(ctrl *gomock.Controller) *MockCommentDAO {
+	mock := &MockCommentDAO{ctrl: ctrl}
+	mock.recorder = &MockCommentDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommentDAO) EXPECT() *MockCommentDAOMockRecorder {
+	return m.recorder
+}
+
+// Count mocks base method.
+func (m *MockCommentDAO) Count(ctx context.Context, biz string, bizId int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, biz, bizId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockCommentDAOMockRecorder) Count(ctx, biz, bizId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockCommentDAO)(nil).Count), ctx, biz, bizId)
+}
+
+// CountReplies mocks base method.
+func (m *MockCommentDAO) CountReplies(ctx context.Context, rootIds []int64) (map[int64]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountReplies", ctx, rootIds)
+	ret0, _ := ret[0].(map[int64]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountReplies indicates an expected call of CountReplies.
+func (mr *MockCommentDAOMockRecorder) CountReplies(ctx, rootIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountReplies", reflect.TypeOf((*MockCommentDAO)(nil).CountReplies), ctx, rootIds)
+}
+
+// Delete mocks base method.
+func (m *MockCommentDAO) Delete(ctx context.Context, id int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCommentDAOMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCommentDAO)(nil).Delete), ctx, id)
+}
+
+// FindById mocks base method.
+func (m *MockCommentDAO) FindById(ctx context.Context, id int64) (dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", ctx, id)
+	ret0, _ := ret[0].(dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockCommentDAOMockRecorder) FindById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockCommentDAO)(nil).FindById), ctx, id)
+}
+
+// FindReplies mocks base method.
+func (m *MockCommentDAO) FindReplies(ctx context.Context, rootId, minId int64, limit int) ([]dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindReplies", ctx, rootId, minId, limit)
+	ret0, _ := ret[0].([]dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindReplies indicates an expected call of FindReplies.
+func (mr *MockCommentDAOMockRecorder) FindReplies(ctx, rootId, minId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindReplies", reflect.TypeOf((*MockCommentDAO)(nil).FindReplies), ctx, rootId, minId, limit)
+}
+
+// FindTopLevel mocks base method.
+func (m *MockCommentDAO) FindTopLevel(ctx context.Context, biz string, bizId, maxId int64, limit int) ([]dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTopLevel", ctx, biz, bizId, maxId, limit)
+	ret0, _ := ret[0].([]dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTopLevel indicates an expected call of FindTopLevel.
+func (mr *MockCommentDAOMockRecorder) FindTopLevel(ctx, biz, bizId, maxId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTopLevel", reflect.TypeOf((*MockCommentDAO)(nil).FindTopLevel), ctx, biz, bizId, maxId, limit)
+}
+
+// Insert mocks base method.
+func (m *MockCommentDAO) Insert(ctx context.Context, c dao.Comment) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, c)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockCommentDAOMockRecorder) Insert(ctx, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockCommentDAO)(nil).Insert), ctx, c)
+}
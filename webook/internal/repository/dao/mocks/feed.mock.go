@@ -0,0 +1,126 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/dao/feed.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/dao/feed.go -package=daomocks -destination=./webook/internal/repository/dao/mocks/feed.mock.go
+//
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dao "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFeedDAO is a mock of FeedDAO interface.
+type MockFeedDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedDAOMockRecorder
+}
+
+// MockFeedDAOMockRecorder is the mock recorder for MockFeedDAO.
+type MockFeedDAOMockRecorder struct {
+	mock *MockFeedDAO
+}
+
+// NewMockFeedDAO creates a new mock instance.
+func NewMockFeedDAO(ctrl *gomock.Controller) *MockFeedDAO {
+	mock := &MockFeedDAO{ctrl: ctrl}
+	mock.recorder = &MockFeedDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedDAO) EXPECT() *MockFeedDAOMockRecorder {
+	return m.recorder
+}
+
+// CreatePullEvent mocks base method.
+func (m *MockFeedDAO) CreatePullEvent(ctx context.Context, evt dao.FeedPullEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePullEvent", ctx, evt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePullEvent indicates an expected call of CreatePullEvent.
+func (mr *MockFeedDAOMockRecorder) CreatePullEvent(ctx, evt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePullEvent", reflect.TypeOf((*MockFeedDAO)(nil).CreatePullEvent), ctx, evt)
+}
+
+// CreatePushEvents mocks base method.
+func (m *MockFeedDAO) CreatePushEvents(ctx context.Context, evts []dao.FeedPushEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePushEvents", ctx, evts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePushEvents indicates an expected call of CreatePushEvents.
+func (mr *MockFeedDAOMockRecorder) CreatePushEvents(ctx, evts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePushEvents", reflect.TypeOf((*MockFeedDAO)(nil).CreatePushEvents), ctx, evts)
+}
+
+// DeleteEventsByAid mocks base method.
+func (m *MockFeedDAO) DeleteEventsByAid(ctx context.Context, aid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEventsByAid", ctx, aid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEventsByAid indicates an expected call of DeleteEventsByAid.
+func (mr *MockFeedDAOMockRecorder) DeleteEventsByAid(ctx, aid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEventsByAid", reflect.TypeOf((*MockFeedDAO)(nil).DeleteEventsByAid), ctx, aid)
+}
+
+// DeletePushEvents mocks base method.
+func (m *MockFeedDAO) DeletePushEvents(ctx context.Context, uid, author int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePushEvents", ctx, uid, author)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePushEvents indicates an expected call of DeletePushEvents.
+func (mr *MockFeedDAOMockRecorder) DeletePushEvents(ctx, uid, author any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePushEvents", reflect.TypeOf((*MockFeedDAO)(nil).DeletePushEvents), ctx, uid, author)
+}
+
+// FindPullEvents mocks base method.
+func (m *MockFeedDAO) FindPullEvents(ctx context.Context, authors []int64, ctime, aid int64, limit int) ([]dao.FeedPullEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPullEvents", ctx, authors, ctime, aid, limit)
+	ret0, _ := ret[0].([]dao.FeedPullEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPullEvents indicates an expected call of FindPullEvents.
+func (mr *MockFeedDAOMockRecorder) FindPullEvents(ctx, authors, ctime, aid, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPullEvents", reflect.TypeOf((*MockFeedDAO)(nil).FindPullEvents), ctx, authors, ctime, aid, limit)
+}
+
+// FindPushEvents mocks base method.
+func (m *MockFeedDAO) FindPushEvents(ctx context.Context, uid, ctime, aid int64, limit int) ([]dao.FeedPushEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPushEvents", ctx, uid, ctime, aid, limit)
+	ret0, _ := ret[0].([]dao.FeedPushEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPushEvents indicates an expected call of FindPushEvents.
+func (mr *MockFeedDAOMockRecorder) FindPushEvents(ctx, uid, ctime, aid, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPushEvents", reflect.TypeOf((*MockFeedDAO)(nil).FindPushEvents), ctx, uid, ctime, aid, limit)
+}
@@ -0,0 +1,98 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/dao/history.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/dao/history.go -package=daomocks -destination=./webook/internal/repository/dao/mocks/history.mock.go
+//
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dao "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReadHistoryDAO is a mock of ReadHistoryDAO interface.
+type MockReadHistoryDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockReadHistoryDAOMockRecorder
+}
+
+// MockReadHistoryDAOMockRecorder is the mock recorder for MockReadHistoryDAO.
+type MockReadHistoryDAOMockRecorder struct {
+	mock *MockReadHistoryDAO
+}
+
+// NewMockReadHistoryDAO creates a new mock instance.
+func NewMockReadHistoryDAO(ctrl *gomock.Controller) *MockReadHistoryDAO {
+	mock := &MockReadHistoryDAO{ctrl: ctrl}
+	mock.recorder = &MockReadHistoryDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReadHistoryDAO) EXPECT() *MockReadHistoryDAOMockRecorder {
+	return m.recorder
+}
+
+// BatchUpsert mocks base method.
+func (m *MockReadHistoryDAO) BatchUpsert(ctx context.Context, hs []dao.ReadHistory) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchUpsert", ctx, hs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BatchUpsert indicates an expected call of BatchUpsert.
+func (mr *MockReadHistoryDAOMockRecorder) BatchUpsert(ctx, hs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchUpsert", reflect.TypeOf((*MockReadHistoryDAO)(nil).BatchUpsert), ctx, hs)
+}
+
+// DeleteBefore mocks base method.
+func (m *MockReadHistoryDAO) DeleteBefore(ctx context.Context, before int64, limit int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBefore", ctx, before, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBefore indicates an expected call of DeleteBefore.
+func (mr *MockReadHistoryDAOMockRecorder) DeleteBefore(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBefore", reflect.TypeOf((*MockReadHistoryDAO)(nil).DeleteBefore), ctx, before, limit)
+}
+
+// DeleteByUid mocks base method.
+func (m *MockReadHistoryDAO) DeleteByUid(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUid", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByUid indicates an expected call of DeleteByUid.
+func (mr *MockReadHistoryDAOMockRecorder) DeleteByUid(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUid", reflect.TypeOf((*MockReadHistoryDAO)(nil).DeleteByUid), ctx, uid)
+}
+
+// List mocks base method.
+func (m *MockReadHistoryDAO) List(ctx context.Context, uid int64, biz string, since, utime, bizId int64, limit int) ([]dao.ReadHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, uid, biz, since, utime, bizId, limit)
+	ret0, _ := ret[0].([]dao.ReadHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockReadHistoryDAOMockRecorder) List(ctx, uid, biz, since, utime, bizId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockReadHistoryDAO)(nil).List), ctx, uid, biz, since, utime, bizId, limit)
+}
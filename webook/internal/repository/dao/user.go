@@ -19,6 +19,8 @@ var ErrUserDuplicate = errors.New("用户邮箱或者手机号冲突")
 type UserDAO interface {
 	Insert(ctx context.Context, u User) error
 	UpdateNonZeroFields(ctx context.Context, u User) error
+	// UpdatePassword password 是已经加密过的，用户不存在的时候返回 ErrDataNotFound
+	UpdatePassword(ctx context.Context, id int64, password string) error
 	FindByPhone(ctx context.Context, phone string) (User, error)
 	FindByEmail(ctx context.Context, email string) (User, error)
 	FindById(ctx context.Context, id int64) (User, error)
@@ -51,6 +53,22 @@ func (ud *GORMUserDAO) UpdateNonZeroFields(ctx context.Context, u User) error {
 	return ud.db.Updates(&u).Error
 }
 
+func (ud *GORMUserDAO) UpdatePassword(ctx context.Context, id int64, password string) error {
+	res := ud.db.WithContext(ctx).Model(&User{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"password": password,
+			"utime":    time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrDataNotFound
+	}
+	return nil
+}
+
 func (ud *GORMUserDAO) Insert(ctx context.Context, u User) error {
 	now := time.Now().UnixMilli()
 	u.Ctime = now
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao"
+)
+
+//go:generate mockgen -source=./feed.go -package=repomocks -destination=mocks/feed.mock.go FeedRepository
+type FeedRepository interface {
+	// CreatePushItems 推模型，写到每个粉丝的收件箱
+	CreatePushItems(ctx context.Context, item domain.FeedItem, followers []int64) error
+	// CreatePullItem 拉模型，只写作者的发件箱
+	CreatePullItem(ctx context.Context, item domain.FeedItem) error
+	FindPushItems(ctx context.Context, uid int64, cursor domain.FeedCursor, limit int) ([]domain.FeedItem, error)
+	FindPullItems(ctx context.Context, authors []int64, cursor domain.FeedCursor, limit int) ([]domain.FeedItem, error)
+	// DeletePushItems 取消关注之后，把这个作者的文章从收件箱里面拿掉
+	DeletePushItems(ctx context.Context, uid, author int64) error
+	// DeleteArticleItems 文章撤回之后，把它从收件箱和发件箱里面拿掉
+	DeleteArticleItems(ctx context.Context, aid int64) error
+}
+
+type feedRepository struct {
+	dao dao.FeedDAO
+}
+
+func NewFeedRepository(d dao.FeedDAO) FeedRepository {
+	return &feedRepository{
+		dao: d,
+	}
+}
+
+func (f *feedRepository) CreatePushItems(ctx context.Context,
+	item domain.FeedItem, followers []int64) error {
+	evts := slice.Map[int64, dao.FeedPushEvent](followers, func(idx int, src int64) dao.FeedPushEvent {
+		return dao.FeedPushEvent{
+			Uid:      src,
+			Aid:      item.Aid,
+			Author:   item.Uid,
+			Title:    item.Title,
+			Abstract: item.Abstract,
+			Ctime:    item.Ctime.UnixMilli(),
+		}
+	})
+	return f.dao.CreatePushEvents(ctx, evts)
+}
+
+func (f *feedRepository) CreatePullItem(ctx context.Context, item domain.FeedItem) error {
+	return f.dao.CreatePullEvent(ctx, dao.FeedPullEvent{
+		Aid:      item.Aid,
+		Author:   item.Uid,
+		Title:    item.Title,
+		Abstract: item.Abstract,
+		Ctime:    item.Ctime.UnixMilli(),
+	})
+}
+
+func (f *feedRepository) FindPushItems(ctx context.Context,
+	uid int64, cursor domain.FeedCursor, limit int) ([]domain.FeedItem, error) {
+	evts, err := f.dao.FindPushEvents(ctx, uid, cursor.Ctime, cursor.Aid, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.FeedPushEvent, domain.FeedItem](evts,
+		func(idx int, src dao.FeedPushEvent) domain.FeedItem {
+			return domain.FeedItem{
+				Aid:      src.Aid,
+				Uid:      src.Author,
+				Title:    src.Title,
+				Abstract: src.Abstract,
+				Ctime:    time.UnixMilli(src.Ctime),
+			}
+		}), nil
+}
+
+func (f *feedRepository) FindPullItems(ctx context.Context,
+	authors []int64, cursor domain.FeedCursor, limit int) ([]domain.FeedItem, error) {
+	evts, err := f.dao.FindPullEvents(ctx, authors, cursor.Ctime, cursor.Aid, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.FeedPullEvent, domain.FeedItem](evts,
+		func(idx int, src dao.FeedPullEvent) domain.FeedItem {
+			return domain.FeedItem{
+				Aid:      src.Aid,
+				Uid:      src.Author,
+				Title:    src.Title,
+				Abstract: src.Abstract,
+				Ctime:    time.UnixMilli(src.Ctime),
+			}
+		}), nil
+}
+
+func (f *feedRepository) DeletePushItems(ctx context.Context, uid, author int64) error {
+	return f.dao.DeletePushEvents(ctx, uid, author)
+}
+
+func (f *feedRepository) DeleteArticleItems(ctx context.Context, aid int64) error {
+	return f.dao.DeleteEventsByAid(ctx, aid)
+}
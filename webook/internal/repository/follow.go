@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+var ErrFollowRelationNotFound = dao.ErrRecordNotFound
+
+//go:generate mockgen -source=./follow.go -package=repomocks -destination=mocks/follow.mock.go FollowRepository
+type FollowRepository interface {
+	// Follow 关注，返回关注关系是否发生了变化
+	Follow(ctx context.Context, follower, followee int64) (bool, error)
+	// CancelFollow 取消关注，返回关注关系是否发生了变化
+	CancelFollow(ctx context.Context, follower, followee int64) (bool, error)
+	GetFollowers(ctx context.Context, followee int64, offset, limit int) ([]domain.FollowRelation, error)
+	GetFollowees(ctx context.Context, follower int64, offset, limit int) ([]domain.FollowRelation, error)
+	FollowInfo(ctx context.Context, follower, followee int64) (domain.FollowRelation, error)
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+}
+
+type CachedFollowRepository struct {
+	dao   dao.FollowDAO
+	cache cache.FollowCache
+	l     logger.LoggerV1
+}
+
+func NewCachedFollowRepository(d dao.FollowDAO,
+	c cache.FollowCache, l logger.LoggerV1) FollowRepository {
+	return &CachedFollowRepository{
+		dao:   d,
+		cache: c,
+		l:     l,
+	}
+}
+
+func (c *CachedFollowRepository) Follow(ctx context.Context, follower, followee int64) (bool, error) {
+	changed, err := c.dao.Follow(ctx, follower, followee)
+	if err != nil || !changed {
+		return changed, err
+	}
+	c.updateCache(c.cache.Follow(ctx, follower, followee), follower, followee)
+	return true, nil
+}
+
+func (c *CachedFollowRepository) CancelFollow(ctx context.Context, follower, followee int64) (bool, error) {
+	changed, err := c.dao.CancelFollow(ctx, follower, followee)
+	if err != nil || !changed {
+		return changed, err
+	}
+	c.updateCache(c.cache.CancelFollow(ctx, follower, followee), follower, followee)
+	return true, nil
+}
+
+// updateCache 数据库已经成功了，缓存失败只记录日志，等缓存过期
+func (c *CachedFollowRepository) updateCache(err error, follower, followee int64) {
+	if err != nil {
+		c.l.Error("更新关注数缓存失败",
+			logger.Int64("follower", follower),
+			logger.Int64("followee", followee),
+			logger.Error(err))
+	}
+}
+
+func (c *CachedFollowRepository) GetFollowers(ctx context.Context,
+	followee int64, offset, limit int) ([]domain.FollowRelation, error) {
+	res, err := c.dao.FollowerList(ctx, followee, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.FollowRelation, domain.FollowRelation](res, c.toDomain), nil
+}
+
+func (c *CachedFollowRepository) GetFollowees(ctx context.Context,
+	follower int64, offset, limit int) ([]domain.FollowRelation, error) {
+	res, err := c.dao.FolloweeList(ctx, follower, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.FollowRelation, domain.FollowRelation](res, c.toDomain), nil
+}
+
+func (c *CachedFollowRepository) FollowInfo(ctx context.Context,
+	follower, followee int64) (domain.FollowRelation, error) {
+	res, err := c.dao.FollowRelationDetail(ctx, follower, followee)
+	if err != nil {
+		return domain.FollowRelation{}, err
+	}
+	return c.toDomain(0, res), nil
+}
+
+func (c *CachedFollowRepository) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	res, err := c.cache.StaticsInfo(ctx, uid)
+	if err == nil {
+		return res, nil
+	}
+	statics, err := c.dao.GetStatics(ctx, uid)
+	switch err {
+	case nil:
+		res = domain.FollowStatics{
+			Followers: statics.Followers,
+			Followees: statics.Followees,
+		}
+	case dao.ErrRecordNotFound:
+		// 从来没有关注过别人，也没有被人关注过
+		res = domain.FollowStatics{}
+	default:
+		return domain.FollowStatics{}, err
+	}
+	go func() {
+		er := c.cache.SetStaticsInfo(context.Background(), uid, res)
+		if er != nil {
+			c.l.Error("回写关注数缓存失败",
+				logger.Int64("uid", uid),
+				logger.Error(er))
+		}
+	}()
+	return res, nil
+}
+
+func (c *CachedFollowRepository) toDomain(idx int, fr dao.FollowRelation) domain.FollowRelation {
+	return domain.FollowRelation{
+		Follower: fr.Follower,
+		Followee: fr.Followee,
+		// 重新关注只会更新 utime，所以关注的时间是 utime
+		Ctime: time.UnixMilli(fr.Utime),
+	}
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao"
+)
+
+//go:generate mockgen -source=./history.go -package=repomocks -destination=mocks/history.mock.go ReadHistoryRepository
+type ReadHistoryRepository interface {
+	// BatchAdd 记录阅读，同一个资源只保留最近一次
+	BatchAdd(ctx context.Context, hs []domain.UserBizRecord) error
+	// List 阅读记录，最近阅读的在前面，早于 since 的不返回。Title 和 Abstract 没有填充
+	List(ctx context.Context, uid int64, biz string, since time.Time,
+		cursor domain.UserBizCursor, limit int) ([]domain.UserBizRecord, error)
+	// Clear 清空 uid 的阅读记录
+	Clear(ctx context.Context, uid int64) error
+	// DeleteBefore 删除早于 before 的阅读记录，最多删除 limit 条，返回删除了多少条
+	DeleteBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// readHistoryRepository 阅读记录只有用户自己看，不缓存
+type readHistoryRepository struct {
+	dao dao.ReadHistoryDAO
+}
+
+func NewReadHistoryRepository(d dao.ReadHistoryDAO) ReadHistoryRepository {
+	return &readHistoryRepository{
+		dao: d,
+	}
+}
+
+func (r *readHistoryRepository) BatchAdd(ctx context.Context, hs []domain.UserBizRecord) error {
+	return r.dao.BatchUpsert(ctx, slice.Map[domain.UserBizRecord, dao.ReadHistory](hs,
+		func(idx int, src domain.UserBizRecord) dao.ReadHistory {
+			utime := src.Utime.UnixMilli()
+			return dao.ReadHistory{
+				Uid:   src.Uid,
+				Biz:   src.Biz,
+				BizId: src.BizId,
+				Ctime: utime,
+				Utime: utime,
+			}
+		}))
+}
+
+func (r *readHistoryRepository) List(ctx context.Context, uid int64, biz string,
+	since time.Time, cursor domain.UserBizCursor, limit int) ([]domain.UserBizRecord, error) {
+	hs, err := r.dao.List(ctx, uid, biz, since.UnixMilli(), cursor.Utime, cursor.BizId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.ReadHistory, domain.UserBizRecord](hs,
+		func(idx int, src dao.ReadHistory) domain.UserBizRecord {
+			return domain.UserBizRecord{
+				Uid:   src.Uid,
+				Biz:   src.Biz,
+				BizId: src.BizId,
+				Utime: time.UnixMilli(src.Utime),
+			}
+		}), nil
+}
+
+func (r *readHistoryRepository) Clear(ctx context.Context, uid int64) error {
+	return r.dao.DeleteByUid(ctx, uid)
+}
+
+func (r *readHistoryRepository) DeleteBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return r.dao.DeleteBefore(ctx, before.UnixMilli(), limit)
+}
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
 
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache"
@@ -14,16 +17,42 @@ type InteractiveRepository interface {
 	IncrReadCnt(ctx context.Context, biz string, bizId int64) error
 	IncrLike(ctx context.Context, biz string, bizId, uid int64) error
 	DecrLike(ctx context.Context, biz string, bizId, uid int64) error
+	// AddCollectionItem 收藏到 cid 收藏夹，已经收藏过的什么都不做
 	AddCollectionItem(ctx context.Context, biz string, bizId, cid int64, uid int64) error
+	// RemoveCollectionItem 取消收藏，没有收藏过的什么都不做
+	RemoveCollectionItem(ctx context.Context, biz string, bizId, uid int64) error
+	// BatchIncrReadCnt 批量增加阅读计数，按照 topic、partition 和 offset 去重，
+	// 重复消费同一批消息不会重复计数
+	BatchIncrReadCnt(ctx context.Context, topic string, partition int32,
+		offsets []int64, bizs []string, bizIds []int64) error
+	// BatchIncrCnt 异步计数模式下，批量更新数据库里面的计数。
+	// eventIds 是发件箱里面的消息 ID，重复投递的事件 ID 不变，按照它去重。
+	// 缓存在点赞的时候已经更新过了，这里不再更新
+	BatchIncrCnt(ctx context.Context, eventIds []int64, deltas []domain.InteractiveDelta) error
 	Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error)
+	// GetByIds 批量查询计数，key 是 bizId，没有数据的 bizId 计数都是 0
+	GetByIds(ctx context.Context, biz string, bizIds []int64) (map[int64]domain.Interactive, error)
+	// BatchGet 给列表页用的批量查询，先查缓存，缓存里面没有的再查数据库并且回写缓存。
+	// key 是 bizId，没有数据的 bizId 计数都是 0
+	BatchGet(ctx context.Context, biz string, bizIds []int64) (map[int64]domain.Interactive, error)
 	Liked(ctx context.Context, biz string, id int64, uid int64) (bool, error)
 	Collected(ctx context.Context, biz string, id int64, uid int64) (bool, error)
+	// BatchLiked uid 点赞了这些资源里面的哪些，没有点赞的不在结果里面
+	BatchLiked(ctx context.Context, biz string, uid int64, bizIds []int64) (map[int64]bool, error)
+	// ListLiked uid 的点赞记录，最近点赞的在前面。Title 和 Abstract 没有填充
+	ListLiked(ctx context.Context, biz string, uid int64,
+		cursor domain.UserBizCursor, limit int) ([]domain.UserBizRecord, error)
+	// BatchCollected uid 收藏了这些资源里面的哪些，没有收藏的不在结果里面
+	BatchCollected(ctx context.Context, biz string, uid int64, bizIds []int64) (map[int64]bool, error)
 }
 
 type CachedReadCntRepository struct {
 	cache cache.InteractiveCache
 	dao   dao.InteractiveDAO
 	l     logger.LoggerV1
+	// 这些 biz 的点赞和收藏只写关系，计数由消费者异步批量更新，
+	// 热点资源不会因为 interactives 的行锁排队
+	asyncBizs map[string]struct{}
 }
 
 func (c *CachedReadCntRepository) Liked(ctx context.Context, biz string, id int64, uid int64) (bool, error) {
@@ -52,7 +81,58 @@ func (c *CachedReadCntRepository) Collected(ctx context.Context, biz string, id
 	}
 }
 
+func (c *CachedReadCntRepository) BatchLiked(ctx context.Context,
+	biz string, uid int64, bizIds []int64) (map[int64]bool, error) {
+	likes, err := c.dao.GetLikeInfos(ctx, biz, uid, bizIds)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]bool, len(likes))
+	for _, like := range likes {
+		res[like.BizId] = true
+	}
+	return res, nil
+}
+
+func (c *CachedReadCntRepository) ListLiked(ctx context.Context, biz string, uid int64,
+	cursor domain.UserBizCursor, limit int) ([]domain.UserBizRecord, error) {
+	likes, err := c.dao.ListLikes(ctx, biz, uid, cursor.Utime, cursor.BizId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.UserLikeBiz, domain.UserBizRecord](likes,
+		func(idx int, src dao.UserLikeBiz) domain.UserBizRecord {
+			return domain.UserBizRecord{
+				Uid:   src.Uid,
+				Biz:   src.Biz,
+				BizId: src.BizId,
+				Utime: time.UnixMilli(src.Utime),
+			}
+		}), nil
+}
+
+func (c *CachedReadCntRepository) BatchCollected(ctx context.Context,
+	biz string, uid int64, bizIds []int64) (map[int64]bool, error) {
+	cbs, err := c.dao.GetCollectionInfos(ctx, biz, uid, bizIds)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]bool, len(cbs))
+	for _, cb := range cbs {
+		res[cb.BizId] = true
+	}
+	return res, nil
+}
+
 func (c *CachedReadCntRepository) IncrLike(ctx context.Context, biz string, bizId int64, uid int64) error {
+	if c.isAsync(biz) {
+		ok, err := c.dao.InsertLikeRelation(ctx, biz, bizId, uid)
+		if err != nil || !ok {
+			return err
+		}
+		// 数据库里面的计数要等消费者更新，缓存先改了，用户马上就能看到自己的点赞
+		return c.cache.IncrLikeCntIfPresent(ctx, biz, bizId)
+	}
 	// 先插入点赞，然后更新点赞计数，更新缓存
 	err := c.dao.InsertLikeInfo(ctx, biz, bizId, uid)
 	if err != nil {
@@ -65,6 +145,13 @@ func (c *CachedReadCntRepository) IncrLike(ctx context.Context, biz string, bizI
 
 func (c *CachedReadCntRepository) DecrLike(ctx context.Context,
 	biz string, bizId int64, uid int64) error {
+	if c.isAsync(biz) {
+		ok, err := c.dao.DeleteLikeRelation(ctx, biz, bizId, uid)
+		if err != nil || !ok {
+			return err
+		}
+		return c.cache.DecrLikeCntIfPresent(ctx, biz, bizId)
+	}
 	err := c.dao.DeleteLikeInfo(ctx, biz, bizId, uid)
 	if err != nil {
 		return err
@@ -88,25 +175,92 @@ func (c *CachedReadCntRepository) IncrReadCnt(ctx context.Context,
 	return c.cache.IncrReadCntIfPresent(ctx, biz, bizId)
 }
 
+func (c *CachedReadCntRepository) BatchIncrReadCnt(ctx context.Context,
+	topic string, partition int32, offsets []int64, bizs []string, bizIds []int64) error {
+	prev, err := c.dao.BatchIncrReadCnt(ctx, topic, partition, offsets, bizs, bizIds)
+	if err != nil {
+		return err
+	}
+	// 只有数据库里面真正计数了的，才更新缓存
+	// 数据库已经提交了，缓存失败了也只能等过期
+	for i, offset := range offsets {
+		if offset <= prev {
+			continue
+		}
+		er := c.cache.IncrReadCntIfPresent(ctx, bizs[i], bizIds[i])
+		if er != nil {
+			c.l.Error("更新缓存的阅读计数失败",
+				logger.String("biz", bizs[i]),
+				logger.Int64("bizId", bizIds[i]),
+				logger.Error(er))
+		}
+	}
+	return nil
+}
+
+func (c *CachedReadCntRepository) BatchIncrCnt(ctx context.Context,
+	eventIds []int64, deltas []domain.InteractiveDelta) error {
+	daoDeltas := make([]dao.Interactive, 0, len(deltas))
+	for _, d := range deltas {
+		daoDeltas = append(daoDeltas, dao.Interactive{
+			Biz:        d.Biz,
+			BizId:      d.BizId,
+			LikeCnt:    d.LikeCnt,
+			CollectCnt: d.CollectCnt,
+		})
+	}
+	return c.dao.BatchIncrCnt(ctx, eventIds, daoDeltas)
+}
+
+func (c *CachedReadCntRepository) isAsync(biz string) bool {
+	_, ok := c.asyncBizs[biz]
+	return ok
+}
+
 func (c *CachedReadCntRepository) AddCollectionItem(ctx context.Context,
 	biz string, bizId, cid, uid int64) error {
 	// 这个地方，你要不要考虑缓存收藏夹？
 	// 以及收藏夹里面的内容
 	// 用户会频繁访问他的收藏夹，那么你就应该缓存，不然你就不需要
 	// 一个东西要不要缓存，你就看用户会不会频繁访问（反复访问）
-	err := c.dao.InsertCollectionBiz(ctx, dao.UserCollectionBiz{
+	cb := dao.UserCollectionBiz{
 		Cid:   cid,
 		Biz:   biz,
 		BizId: bizId,
 		Uid:   uid,
-	})
-	if err != nil {
+	}
+	if c.isAsync(biz) {
+		ok, err := c.dao.InsertCollectionRelation(ctx, cb)
+		if err != nil || !ok {
+			return err
+		}
+		return c.cache.IncrCollectCntIfPresent(ctx, biz, bizId)
+	}
+	ok, err := c.dao.InsertCollectionBiz(ctx, cb)
+	if err != nil || !ok {
 		return err
 	}
 	// 收藏个数（有多少个人收藏了这个 biz + bizId)
 	return c.cache.IncrCollectCntIfPresent(ctx, biz, bizId)
 }
 
+func (c *CachedReadCntRepository) RemoveCollectionItem(ctx context.Context,
+	biz string, bizId, uid int64) error {
+	var (
+		ok  bool
+		err error
+	)
+	if c.isAsync(biz) {
+		ok, err = c.dao.DeleteCollectionRelation(ctx, biz, bizId, uid)
+	} else {
+		ok, err = c.dao.DeleteCollectionBiz(ctx, biz, bizId, uid)
+	}
+	if err != nil || !ok {
+		return err
+	}
+	return c.cache.DecrCollectCntIfPresent(ctx, biz, bizId)
+}
+
 func (c *CachedReadCntRepository) Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error) {
 	// 要从缓存拿出来阅读数，点赞数和收藏数
 	intr, err := c.cache.Get(ctx, biz, bizId)
@@ -137,6 +291,63 @@ func (c *CachedReadCntRepository) Get(ctx context.Context, biz string, bizId int
 	return intr, nil
 }
 
+func (c *CachedReadCntRepository) GetByIds(ctx context.Context,
+	biz string, bizIds []int64) (map[int64]domain.Interactive, error) {
+	// 批量查询一般是给后台任务用的，直接查数据库
+	intrs, err := c.dao.GetByIds(ctx, biz, bizIds)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]domain.Interactive, len(intrs))
+	for _, intr := range intrs {
+		res[intr.BizId] = c.toDomain(intr)
+	}
+	return res, nil
+}
+
+func (c *CachedReadCntRepository) BatchGet(ctx context.Context,
+	biz string, bizIds []int64) (map[int64]domain.Interactive, error) {
+	res, err := c.cache.GetByIds(ctx, biz, bizIds)
+	if err != nil {
+		// Redis 出问题了，全部查数据库
+		c.l.Error("批量查询计数缓存失败",
+			logger.String("biz", biz),
+			logger.Error(err))
+		res = make(map[int64]domain.Interactive, len(bizIds))
+	}
+	missed := make([]int64, 0, len(bizIds)-len(res))
+	for _, bizId := range bizIds {
+		if _, ok := res[bizId]; !ok {
+			missed = append(missed, bizId)
+		}
+	}
+	if len(missed) == 0 {
+		return res, nil
+	}
+	intrs, err := c.dao.GetByIds(ctx, biz, missed)
+	if err != nil {
+		return nil, err
+	}
+	// 数据库里面也没有的，说明还没有人看过，回写 0，免得每次都查数据库
+	loaded := make(map[int64]domain.Interactive, len(missed))
+	for _, bizId := range missed {
+		loaded[bizId] = domain.Interactive{}
+	}
+	for _, intr := range intrs {
+		loaded[intr.BizId] = c.toDomain(intr)
+	}
+	for bizId, intr := range loaded {
+		res[bizId] = intr
+	}
+	err = c.cache.SetByIds(ctx, biz, loaded)
+	if err != nil {
+		c.l.Error("批量回写计数缓存失败",
+			logger.String("biz", biz),
+			logger.Error(err))
+	}
+	return res, nil
+}
+
 // 正常来说，参数必然不用指针：方法不要修改参数，通过返回值来修改参数
 // 返回值就看情况。如果是指针实现了接口，那么就返回指针
 // 如果返回值很大，你不想值传递引发复制问题，那么还是返回指针
@@ -153,22 +364,24 @@ func (c *CachedReadCntRepository) toDomain(intr dao.Interactive) domain.Interact
 	}
 }
 
-func (c *CachedReadCntRepository) GetCollection() (domain.Collection, error) {
-	items, err := c.dao.(*dao.GORMInteractiveDAO).GetItems()
-	if err != nil {
-		return domain.Collection{}, err
-	}
-	// 用 items 来构造一个 Collection
-	return domain.Collection{
-		Name: items[0].Cname,
-	}, nil
-}
-
 func NewCachedInteractiveRepository(dao dao.InteractiveDAO,
 	cache cache.InteractiveCache, l logger.LoggerV1) InteractiveRepository {
-	return &CachedReadCntRepository{
-		dao:   dao,
-		cache: cache,
-		l:     l,
+	return NewCachedInteractiveRepositoryV1(dao, cache, l, nil)
+}
+
+// NewCachedInteractiveRepositoryV1 asyncBizs 里面的 biz 使用异步计数模式，
+// 这时候要启动 interactive.CntEventConsumer
+func NewCachedInteractiveRepositoryV1(dao dao.InteractiveDAO,
+	cache cache.InteractiveCache, l logger.LoggerV1,
+	asyncBizs []string) InteractiveRepository {
+	res := &CachedReadCntRepository{
+		dao:       dao,
+		cache:     cache,
+		l:         l,
+		asyncBizs: make(map[string]struct{}, len(asyncBizs)),
+	}
+	for _, biz := range asyncBizs {
+		res.asyncBizs[biz] = struct{}{}
 	}
+	return res
 }
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache"
+	cachemocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache/mocks"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao"
+	daomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao/mocks"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+func TestCachedReadCntRepository_BatchIncrReadCnt(t *testing.T) {
+	offsets := []int64{10, 11, 12}
+	bizs := []string{"article", "article", "article"}
+	bizIds := []int64{1, 2, 1}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache)
+
+		wantErr error
+	}{
+		{
+			name: "全部都是新消息",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().BatchIncrReadCnt(gomock.Any(), "article_read", int32(0),
+					offsets, bizs, bizIds).Return(int64(9), nil)
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().IncrReadCntIfPresent(gomock.Any(), "article", int64(1)).
+					Times(2).Return(nil)
+				c.EXPECT().IncrReadCntIfPresent(gomock.Any(), "article", int64(2)).
+					Return(nil)
+				return d, c
+			},
+		},
+		{
+			name: "部分消息已经处理过",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().BatchIncrReadCnt(gomock.Any(), "article_read", int32(0),
+					offsets, bizs, bizIds).Return(int64(11), nil)
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				// 只有 12 是新的
+				c.EXPECT().IncrReadCntIfPresent(gomock.Any(), "article", int64(1)).
+					Return(nil)
+				return d, c
+			},
+		},
+		{
+			name: "缓存失败不影响结果",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().BatchIncrReadCnt(gomock.Any(), "article_read", int32(0),
+					offsets, bizs, bizIds).Return(int64(11), nil)
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().IncrReadCntIfPresent(gomock.Any(), "article", int64(1)).
+					Return(errors.New("mock redis error"))
+				return d, c
+			},
+		},
+		{
+			name: "数据库失败",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().BatchIncrReadCnt(gomock.Any(), "article_read", int32(0),
+					offsets, bizs, bizIds).Return(int64(0), errors.New("mock db error"))
+				return d, cachemocks.NewMockInteractiveCache(ctrl)
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tc.mock(ctrl)
+			repo := NewCachedInteractiveRepository(d, c, &logger.NoOpLogger{})
+			err := repo.BatchIncrReadCnt(context.Background(), "article_read", 0,
+				offsets, bizs, bizIds)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestCachedReadCntRepository_IncrLike(t *testing.T) {
+	testCases := []struct {
+		name      string
+		asyncBizs []string
+		mock      func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache)
+
+		wantErr error
+	}{
+		{
+			name: "同步模式",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().InsertLikeInfo(gomock.Any(), "article", int64(1), int64(123)).
+					Return(nil)
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().IncrLikeCntIfPresent(gomock.Any(), "article", int64(1)).
+					Return(nil)
+				return d, c
+			},
+		},
+		{
+			name:      "异步模式",
+			asyncBizs: []string{"article"},
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().InsertLikeRelation(gomock.Any(), "article", int64(1), int64(123)).
+					Return(true, nil)
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().IncrLikeCntIfPresent(gomock.Any(), "article", int64(1)).
+					Return(nil)
+				return d, c
+			},
+		},
+		{
+			name:      "异步模式，已经点过赞了",
+			asyncBizs: []string{"article"},
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().InsertLikeRelation(gomock.Any(), "article", int64(1), int64(123)).
+					Return(false, nil)
+				return d, cachemocks.NewMockInteractiveCache(ctrl)
+			},
+		},
+		{
+			name:      "别的 biz 异步",
+			asyncBizs: []string{"video"},
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().InsertLikeInfo(gomock.Any(), "article", int64(1), int64(123)).
+					Return(errors.New("mock db error"))
+				return d, cachemocks.NewMockInteractiveCache(ctrl)
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tc.mock(ctrl)
+			repo := NewCachedInteractiveRepositoryV1(d, c, &logger.NoOpLogger{}, tc.asyncBizs)
+			err := repo.IncrLike(context.Background(), "article", 1, 123)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestCachedReadCntRepository_BatchGet(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache)
+
+		wantRes map[int64]domain.Interactive
+		wantErr error
+	}{
+		{
+			name: "全部命中缓存",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().GetByIds(gomock.Any(), "article", []int64{1, 2, 3}).
+					Return(map[int64]domain.Interactive{
+						1: {ReadCnt: 1}, 2: {ReadCnt: 2}, 3: {ReadCnt: 3},
+					}, nil)
+				return daomocks.NewMockInteractiveDAO(ctrl), c
+			},
+			wantRes: map[int64]domain.Interactive{
+				1: {ReadCnt: 1}, 2: {ReadCnt: 2}, 3: {ReadCnt: 3},
+			},
+		},
+		{
+			// 3 数据库里面也没有，回写 0
+			name: "部分命中缓存",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().GetByIds(gomock.Any(), "article", []int64{1, 2, 3}).
+					Return(map[int64]domain.Interactive{1: {ReadCnt: 1}}, nil)
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().GetByIds(gomock.Any(), "article", []int64{2, 3}).
+					Return([]dao.Interactive{{BizId: 2, ReadCnt: 2, LikeCnt: 1}}, nil)
+				c.EXPECT().SetByIds(gomock.Any(), "article", map[int64]domain.Interactive{
+					2: {ReadCnt: 2, LikeCnt: 1}, 3: {},
+				}).Return(errors.New("mock redis error"))
+				return d, c
+			},
+			wantRes: map[int64]domain.Interactive{
+				1: {ReadCnt: 1}, 2: {ReadCnt: 2, LikeCnt: 1}, 3: {},
+			},
+		},
+		{
+			name: "缓存出错全部查数据库",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().GetByIds(gomock.Any(), "article", []int64{1, 2, 3}).
+					Return(nil, errors.New("mock redis error"))
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().GetByIds(gomock.Any(), "article", []int64{1, 2, 3}).
+					Return(nil, errors.New("mock db error"))
+				return d, c
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tc.mock(ctrl)
+			repo := NewCachedInteractiveRepository(d, c, &logger.NoOpLogger{})
+			res, err := repo.BatchGet(context.Background(), "article", []int64{1, 2, 3})
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/feed.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/feed.go -package=repomocks -destination=./webook/internal/repository/mocks/feed.mock.go
+//
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFeedRepository is a mock of FeedRepository interface.
+type MockFeedRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedRepositoryMockRecorder
+}
+
+// MockFeedRepositoryMockRecorder is the mock recorder for MockFeedRepository.
+type MockFeedRepositoryMockRecorder struct {
+	mock *MockFeedRepository
+}
+
+// NewMockFeedRepository creates a new mock instance.
+func NewMockFeedRepository(ctrl *gomock.Controller) *MockFeedRepository {
+	mock := &MockFeedRepository{ctrl: ctrl}
+	mock.recorder = &MockFeedRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedRepository) EXPECT() *MockFeedRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreatePullItem mocks base method.
+func (m *MockFeedRepository) CreatePullItem(ctx context.Context, item domain.FeedItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePullItem", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePullItem indicates an expected call of CreatePullItem.
+func (mr *MockFeedRepositoryMockRecorder) CreatePullItem(ctx, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePullItem", reflect.TypeOf((*MockFeedRepository)(nil).CreatePullItem), ctx, item)
+}
+
+// CreatePushItems mocks base method.
+func (m *MockFeedRepository) CreatePushItems(ctx context.Context, item domain.FeedItem, followers []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePushItems", ctx, item, followers)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePushItems indicates an expected call of CreatePushItems.
+func (mr *MockFeedRepositoryMockRecorder) CreatePushItems(ctx, item, followers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePushItems", reflect.TypeOf((*MockFeedRepository)(nil).CreatePushItems), ctx, item, followers)
+}
+
+// DeleteArticleItems mocks base method.
+func (m *MockFeedRepository) DeleteArticleItems(ctx context.Context, aid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteArticleItems", ctx, aid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteArticleItems indicates an expected call of DeleteArticleItems.
+func (mr *MockFeedRepositoryMockRecorder) DeleteArticleItems(ctx, aid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteArticleItems", reflect.TypeOf((*MockFeedRepository)(nil).DeleteArticleItems), ctx, aid)
+}
+
+// DeletePushItems mocks base method.
+func (m *MockFeedRepository) DeletePushItems(ctx context.Context, uid, author int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePushItems", ctx, uid, author)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePushItems indicates an expected call of DeletePushItems.
+func (mr *MockFeedRepositoryMockRecorder) DeletePushItems(ctx, uid, author any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePushItems", reflect.TypeOf((*MockFeedRepository)(nil).DeletePushItems), ctx, uid, author)
+}
+
+// FindPullItems mocks base method.
+func (m *MockFeedRepository) FindPullItems(ctx context.Context, authors []int64, cursor domain.FeedCursor, limit int) ([]domain.FeedItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPullItems", ctx, authors, cursor, limit)
+	ret0, _ := ret[0].([]domain.FeedItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPullItems indicates an expected call of FindPullItems.
+func (mr *MockFeedRepositoryMockRecorder) FindPullItems(ctx, authors, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPullItems", reflect.TypeOf((*MockFeedRepository)(nil).FindPullItems), ctx, authors, cursor, limit)
+}
+
+// FindPushItems mocks base method.
+func (m *MockFeedRepository) FindPushItems(ctx context.Context, uid int64, cursor domain.FeedCursor, limit int) ([]domain.FeedItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPushItems", ctx, uid, cursor, limit)
+	ret0, _ := ret[0].([]domain.FeedItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPushItems indicates an expected call of FindPushItems.
+func (mr *MockFeedRepositoryMockRecorder) FindPushItems(ctx, uid, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPushItems", reflect.TypeOf((*MockFeedRepository)(nil).FindPushItems), ctx, uid, cursor, limit)
+}
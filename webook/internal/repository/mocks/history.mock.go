@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/history.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/history.go -package=repomocks -destination=./webook/internal/repository/mocks/history.mock.go
+//
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReadHistoryRepository is a mock of ReadHistoryRepository interface.
+type MockReadHistoryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReadHistoryRepositoryMockRecorder
+}
+
+// MockReadHistoryRepositoryMockRecorder is the mock recorder for MockReadHistoryRepository.
+type MockReadHistoryRepositoryMockRecorder struct {
+	mock *MockReadHistoryRepository
+}
+
+// NewMockReadHistoryRepository creates a new mock instance.
+func NewMockReadHistoryRepository(ctrl *gomock.Controller) *MockReadHistoryRepository {
+	mock := &MockReadHistoryRepository{ctrl: ctrl}
+	mock.recorder = &MockReadHistoryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReadHistoryRepository) EXPECT() *MockReadHistoryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// BatchAdd mocks base method.
+func (m *MockReadHistoryRepository) BatchAdd(ctx context.Context, hs []domain.UserBizRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchAdd", ctx, hs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BatchAdd indicates an expected call of BatchAdd.
+func (mr *MockReadHistoryRepositoryMockRecorder) BatchAdd(ctx, hs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchAdd", reflect.TypeOf((*MockReadHistoryRepository)(nil).BatchAdd), ctx, hs)
+}
+
+// Clear mocks base method.
+func (m *MockReadHistoryRepository) Clear(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Clear", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Clear indicates an expected call of Clear.
+func (mr *MockReadHistoryRepositoryMockRecorder) Clear(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockReadHistoryRepository)(nil).Clear), ctx, uid)
+}
+
+// DeleteBefore mocks base method.
+func (m *MockReadHistoryRepository) DeleteBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBefore", ctx, before, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBefore indicates an expected call of DeleteBefore.
+func (mr *MockReadHistoryRepositoryMockRecorder) DeleteBefore(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBefore", reflect.TypeOf((*MockReadHistoryRepository)(nil).DeleteBefore), ctx, before, limit)
+}
+
+// List mocks base method.
+func (m *MockReadHistoryRepository) List(ctx context.Context, uid int64, biz string, since time.Time, cursor domain.UserBizCursor, limit int) ([]domain.UserBizRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, uid, biz, since, cursor, limit)
+	ret0, _ := ret[0].([]domain.UserBizRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockReadHistoryRepositoryMockRecorder) List(ctx, uid, biz, since, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockReadHistoryRepository)(nil).List), ctx, uid, biz, since, cursor, limit)
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache"
+)
+
+var ErrResetTokenNotFound = cache.ErrResetTokenNotFound
+
+//go:generate mockgen -source=./password_reset.go -package=repomocks -destination=mocks/password_reset.mock.go PasswordResetRepository
+type PasswordResetRepository interface {
+	// LockSend 同一个邮箱一分钟内只能发一次，返回 false 说明发送太频繁
+	LockSend(ctx context.Context, email string) (bool, error)
+	// Store 记录重置密码的链接里面的 token 是谁的，过了 expiration 就失效
+	Store(ctx context.Context, token string, uid int64, expiration time.Duration) error
+	// Consume 链接只能用一次，用过了或者过期了返回 ErrResetTokenNotFound
+	Consume(ctx context.Context, token string) (int64, error)
+}
+
+type CachedPasswordResetRepository struct {
+	cache cache.PasswordResetCache
+}
+
+func NewCachedPasswordResetRepository(c cache.PasswordResetCache) PasswordResetRepository {
+	return &CachedPasswordResetRepository{
+		cache: c,
+	}
+}
+
+func (repo *CachedPasswordResetRepository) LockSend(ctx context.Context, email string) (bool, error) {
+	return repo.cache.LockSend(ctx, email)
+}
+
+func (repo *CachedPasswordResetRepository) Store(ctx context.Context,
+	token string, uid int64, expiration time.Duration) error {
+	return repo.cache.Set(ctx, token, uid, expiration)
+}
+
+func (repo *CachedPasswordResetRepository) Consume(ctx context.Context, token string) (int64, error) {
+	return repo.cache.Consume(ctx, token)
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache"
+)
+
+//go:generate mockgen -source=./ranking.go -package=repomocks -destination=mocks/ranking.mock.go RankingRepository
+type RankingRepository interface {
+	ReplaceTopN(ctx context.Context, arts []domain.Article) error
+	GetTopN(ctx context.Context) ([]domain.Article, error)
+}
+
+// CachedRankingRepository 热榜只放在缓存里面，本地缓存 + Redis
+type CachedRankingRepository struct {
+	redis *cache.RedisRankingCache
+	local *cache.RankingLocalCache
+}
+
+func NewCachedRankingRepository(redis *cache.RedisRankingCache,
+	local *cache.RankingLocalCache) RankingRepository {
+	return &CachedRankingRepository{
+		redis: redis,
+		local: local,
+	}
+}
+
+func (c *CachedRankingRepository) ReplaceTopN(ctx context.Context, arts []domain.Article) error {
+	// 热榜只需要展示标题和摘要，本地缓存和 Redis 里面都不放全文，
+	// 否则 leader 和其它实例返回的数据不一样
+	data := make([]domain.Article, len(arts))
+	for i, art := range arts {
+		art.Content = art.Abstract()
+		data[i] = art
+	}
+	arts = data
+	// 本地缓存几乎不可能失败，先更新本地的
+	_ = c.local.Set(ctx, arts)
+	return c.redis.Set(ctx, arts)
+}
+
+func (c *CachedRankingRepository) GetTopN(ctx context.Context) ([]domain.Article, error) {
+	arts, err := c.local.Get(ctx)
+	if err == nil {
+		return arts, nil
+	}
+	arts, err = c.redis.Get(ctx)
+	if err != nil {
+		// Redis 出问题了，用本地过期的数据兜底
+		return c.local.ForceGet(ctx)
+	}
+	_ = c.local.Set(ctx, arts)
+	return arts, nil
+}
@@ -17,6 +17,8 @@ type UserRepository interface {
 	Create(ctx context.Context, u domain.User) error
 	// Update 更新数据，只有非 0 值才会更新
 	Update(ctx context.Context, u domain.User) error
+	// UpdatePassword password 是已经加密过的
+	UpdatePassword(ctx context.Context, id int64, password string) error
 	FindByPhone(ctx context.Context, phone string) (domain.User, error)
 	FindByEmail(ctx context.Context, email string) (domain.User, error)
 	FindById(ctx context.Context, id int64) (domain.User, error)
@@ -54,6 +56,14 @@ func (ur *CachedUserRepository) Update(ctx context.Context, u domain.User) error
 	return ur.cache.Delete(ctx, u.Id)
 }
 
+func (ur *CachedUserRepository) UpdatePassword(ctx context.Context, id int64, password string) error {
+	err := ur.dao.UpdatePassword(ctx, id, password)
+	if err != nil {
+		return err
+	}
+	return ur.cache.Delete(ctx, id)
+}
+
 func (ur *CachedUserRepository) Create(ctx context.Context, u domain.User) error {
 	return ur.dao.Insert(ctx, dao.User{
 		Email: sql.NullString{
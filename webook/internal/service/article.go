@@ -2,14 +2,40 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"golang.org/x/sync/errgroup"
 
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
 	events "github.com/xiaoshanjiang/my-geektime/webook/internal/events/article"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
 	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/textdiff"
+)
+
+var (
+	ErrPossibleIncorrectAuthor = article.ErrPossibleIncorrectAuthor
+	ErrRevisionNotFound        = article.ErrRevisionNotFound
+	ErrInvalidPublishTime      = errors.New("定时发表的时间必须晚于当前时间")
+	ErrInvalidTags             = errors.New("标签或者分类不合法")
+)
+
+const (
+	// maxTagCnt 一篇文章最多的标签数量
+	maxTagCnt = 5
+	// maxTagLen 标签和分类的最大长度，按字符算
+	maxTagLen = 32
+	// maxPageSize 公开的列表接口一页最多返回的数量
+	maxPageSize = 100
 )
 
+// scheduleLease 定时发表的任务抢占到一篇文章之后，最多占用这么久
+// 超过这个时间还没有发表成功，别的实例就会重试
+const scheduleLease = time.Minute
+
 type ArticleService interface {
 	Save(ctx context.Context, art domain.Article) (int64, error)
 	Withdraw(ctx context.Context, art domain.Article) error
@@ -18,6 +44,29 @@ type ArticleService interface {
 	List(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error)
 	GetById(ctx context.Context, id int64) (domain.Article, error)
 	GetPublishedById(ctx context.Context, id, uid int64) (domain.Article, error)
+
+	// ListRevisions 列出文章的历史版本，只有作者本人可以看
+	ListRevisions(ctx context.Context, uid, id int64, offset, limit int) ([]domain.ArticleRevision, error)
+	// DiffRevisions 比较两个历史版本，from 是旧版本，to 是新版本
+	DiffRevisions(ctx context.Context, uid, id, from, to int64,
+		mode domain.ArticleDiffMode) (domain.ArticleDiff, error)
+	// RestoreRevision 把历史版本恢复成一个新的草稿
+	RestoreRevision(ctx context.Context, uid, id, revId int64) error
+
+	// ListScheduled 列出还没有到时间的定时发表
+	ListScheduled(ctx context.Context, uid int64, offset, limit int) ([]domain.Article, error)
+	Reschedule(ctx context.Context, uid, id int64, publishAt time.Time) error
+	CancelSchedule(ctx context.Context, uid, id int64) error
+	// PublishDue 发表已经到时间的定时文章，返回这一次发表了多少篇
+	// 多个实例同时调用也是安全的，每篇文章只会被一个实例发表
+	PublishDue(ctx context.Context, limit int) (int, error)
+
+	// ListPubByTag 按照标签列出公开的文章
+	ListPubByTag(ctx context.Context, tag string, offset, limit int) ([]domain.Article, error)
+	// SuggestTags 标签的自动补全
+	SuggestTags(ctx context.Context, prefix string, limit int) ([]domain.Tag, error)
+	// TagCounts 按照 tags 的顺序返回每个标签下公开文章的数量
+	TagCounts(ctx context.Context, tags []string) ([]domain.Tag, error)
 }
 
 type articleService struct {
@@ -35,23 +84,69 @@ func (svc *articleService) GetPublishedById(ctx context.Context, id, uid int64)
 	// 另一个选项，在这里组装 Author，调用 UserService
 	art, err := svc.repo.GetPublishedById(ctx, id)
 	if err == nil {
+		// 阅读量太大了，不走发件箱，丢了几个阅读事件也可以接受
 		go func() {
 			er := svc.producer.ProduceReadEvent(
 				ctx,
 				events.ReadEvent{
 					// 即便你的消费者要用 art 的里面的数据，
 					// 让它去查询，你不要在 event 里面带
-					Uid: uid,
-					Aid: id,
+					Uid:   uid,
+					Aid:   id,
+					Ctime: time.Now().UnixMilli(),
 				})
-			if er == nil {
-				svc.l.Error("发送读者阅读事件失败")
+			if er != nil {
+				svc.l.Error("发送读者阅读事件失败",
+					logger.Int64("art_id", id),
+					logger.Error(er))
 			}
 		}()
 	}
 	return art, err
 }
 
+func (a *articleService) ListRevisions(ctx context.Context,
+	uid, id int64, offset, limit int) ([]domain.ArticleRevision, error) {
+	return a.repo.ListRevisions(ctx, uid, id, offset, limit)
+}
+
+func (a *articleService) DiffRevisions(ctx context.Context,
+	uid, id, from, to int64, mode domain.ArticleDiffMode) (domain.ArticleDiff, error) {
+	var (
+		eg       errgroup.Group
+		fromRev  domain.ArticleRevision
+		toRev    domain.ArticleRevision
+		diffFunc = textdiff.Lines
+	)
+	if mode == domain.ArticleDiffModeWord {
+		diffFunc = textdiff.Words
+	}
+	eg.Go(func() error {
+		var err error
+		fromRev, err = a.repo.GetRevision(ctx, uid, id, from)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		toRev, err = a.repo.GetRevision(ctx, uid, id, to)
+		return err
+	})
+	if err := eg.Wait(); err != nil {
+		return domain.ArticleDiff{}, err
+	}
+	return domain.ArticleDiff{
+		From: fromRev,
+		To:   toRev,
+		// 标题一般就一行，所以总是按词比较
+		Title:   textdiff.Words(fromRev.Title, toRev.Title),
+		Content: diffFunc(fromRev.Content, toRev.Content),
+	}, nil
+}
+
+func (a *articleService) RestoreRevision(ctx context.Context, uid, id, revId int64) error {
+	return a.repo.Restore(ctx, uid, id, revId)
+}
+
 func (a *articleService) GetById(ctx context.Context, id int64) (domain.Article, error) {
 	return a.repo.GetByID(ctx, id)
 }
@@ -62,16 +157,179 @@ func (a *articleService) List(ctx context.Context, uid int64, offset int, limit
 
 func (a *articleService) Withdraw(ctx context.Context, art domain.Article) error {
 	// art.Status = domain.ArticleStatusPrivate 然后直接把整个 art 往下传
+	// 撤回事件在 SyncStatus 的事务里面写进了发件箱
+	// 搜索索引由各个实例消费撤回事件之后更新
 	return a.repo.SyncStatus(ctx, art.Id, art.Author.Id, domain.ArticleStatusPrivate)
 }
 
 func (a *articleService) Publish(ctx context.Context, art domain.Article) (int64, error) {
+	art, err := normalizeArticle(art)
+	if err != nil {
+		return 0, err
+	}
+	if art.PublishAt.After(time.Now()) {
+		return a.schedule(ctx, art)
+	}
+	art.PublishAt = time.Time{}
 	art.Status = domain.ArticleStatusPublished
 	// 制作库
 	// id, err := a.repo.Create(ctx, art)
 	// 线上库
 	// a.repo.SyncToLiveDB(ctx, art)
-	return a.repo.Sync(ctx, art)
+	id, err := a.repo.Sync(ctx, art)
+	if err != nil {
+		return 0, err
+	}
+	// 发表或者修改事件在 Sync 的事务里面写进了发件箱，
+	// 搜索索引由各个实例消费这些事件之后更新
+	return id, nil
+}
+
+// schedule 定时发表只保存到制作库，到时间了再由 PublishDue 同步到线上库
+func (a *articleService) schedule(ctx context.Context, art domain.Article) (int64, error) {
+	art.Status = domain.ArticleStatusScheduled
+	if art.Id > 0 {
+		err := a.repo.Update(ctx, art)
+		return art.Id, err
+	}
+	return a.repo.Create(ctx, art)
+}
+
+func (a *articleService) ListScheduled(ctx context.Context,
+	uid int64, offset, limit int) ([]domain.Article, error) {
+	return a.repo.ListScheduled(ctx, uid, offset, limit)
+}
+
+func (a *articleService) Reschedule(ctx context.Context, uid, id int64, publishAt time.Time) error {
+	if !publishAt.After(time.Now()) {
+		return ErrInvalidPublishTime
+	}
+	return a.repo.Reschedule(ctx, uid, id, publishAt)
+}
+
+func (a *articleService) CancelSchedule(ctx context.Context, uid, id int64) error {
+	return a.repo.CancelSchedule(ctx, uid, id)
+}
+
+func (a *articleService) PublishDue(ctx context.Context, limit int) (int, error) {
+	now := time.Now()
+	arts, err := a.repo.FindDueScheduled(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+	cnt := 0
+	for _, art := range arts {
+		ok, err := a.repo.ClaimScheduled(ctx, art, now.Add(scheduleLease))
+		if err != nil {
+			a.l.Error("抢占定时发表的文章失败",
+				logger.Int64("art_id", art.Id),
+				logger.Error(err))
+			continue
+		}
+		if !ok {
+			// 别的实例抢到了，或者作者刚刚修改了时间
+			continue
+		}
+		// FindDueScheduled 之后作者可能又保存过，同步抢占之后最新的内容
+		cur, err := a.repo.GetByID(ctx, art.Id)
+		if err != nil {
+			// 租约到期之后，会有实例重试
+			a.l.Error("查询定时发表的文章失败",
+				logger.Int64("art_id", art.Id),
+				logger.Error(err))
+			continue
+		}
+		if cur.Status != domain.ArticleStatusScheduled {
+			// 抢占之后作者取消了定时发表
+			continue
+		}
+		art = cur
+		art.Status = domain.ArticleStatusPublished
+		art.PublishAt = time.Time{}
+		_, err = a.repo.Sync(ctx, art)
+		if err != nil {
+			// 租约到期之后，会有实例重试
+			a.l.Error("定时发表文章失败",
+				logger.Int64("art_id", art.Id),
+				logger.Error(err))
+			continue
+		}
+		cnt++
+	}
+	return cnt, nil
+}
+
+func (a *articleService) ListPubByTag(ctx context.Context,
+	tag string, offset, limit int) ([]domain.Article, error) {
+	if limit <= 0 || limit > maxPageSize {
+		limit = maxPageSize
+	}
+	return a.repo.ListPubByTag(ctx, normalizeTag(tag), offset, limit)
+}
+
+func (a *articleService) SuggestTags(ctx context.Context, prefix string, limit int) ([]domain.Tag, error) {
+	if limit <= 0 || limit > maxPageSize {
+		limit = 10
+	}
+	return a.repo.SuggestTags(ctx, normalizeTag(prefix), limit)
+}
+
+func (a *articleService) TagCounts(ctx context.Context, tags []string) ([]domain.Tag, error) {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, normalizeTag(tag))
+	}
+	found, err := a.repo.TagCounts(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	cnts := make(map[string]int64, len(found))
+	for _, tag := range found {
+		cnts[tag.Name] = tag.Cnt
+	}
+	// 没有公开文章的标签，数量就是 0
+	res := make([]domain.Tag, 0, len(names))
+	for _, name := range names {
+		res = append(res, domain.Tag{Name: name, Cnt: cnts[name]})
+	}
+	return res, nil
+}
+
+// normalizeArticle 规范化分类和标签，去掉空白和重复的标签
+func normalizeArticle(art domain.Article) (domain.Article, error) {
+	art.Category = strings.TrimSpace(art.Category)
+	if utf8.RuneCountInString(art.Category) > maxTagLen {
+		return art, ErrInvalidTags
+	}
+	if len(art.Tags) == 0 {
+		return art, nil
+	}
+	tags := make([]string, 0, len(art.Tags))
+	seen := make(map[string]struct{}, len(art.Tags))
+	for _, tag := range art.Tags {
+		tag = normalizeTag(tag)
+		if tag == "" {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLen {
+			return art, ErrInvalidTags
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxTagCnt {
+		return art, ErrInvalidTags
+	}
+	art.Tags = tags
+	return art, nil
+}
+
+// normalizeTag 标签不区分大小写
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
 }
 
 func (a *articleService) PublishV1(ctx context.Context, art domain.Article) (int64, error) {
@@ -129,7 +387,12 @@ func NewArticleServiceV1(author article.ArticleAuthorRepository,
 	}
 }
 
+// Save 保存草稿。还在等定时发表的文章保存之后依旧是定时发表，取消要调用 CancelSchedule
 func (a *articleService) Save(ctx context.Context, art domain.Article) (int64, error) {
+	art, err := normalizeArticle(art)
+	if err != nil {
+		return 0, err
+	}
 	art.Status = domain.ArticleStatusUnpublished
 	if art.Id > 0 {
 		err := a.repo.Update(ctx, art)
@@ -3,15 +3,20 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	events "github.com/xiaoshanjiang/my-geektime/webook/internal/events/article"
+	evtmocks "github.com/xiaoshanjiang/my-geektime/webook/internal/events/article/mocks"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
 	artrepomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article/mocks"
 	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/textdiff"
 )
 
 func Test_articleService_Publish(t *testing.T) {
@@ -219,3 +224,336 @@ func Test_articleService_Publish(t *testing.T) {
 		})
 	}
 }
+
+func Test_articleService_DiffRevisions(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) article.ArticleRepository
+		mode domain.ArticleDiffMode
+
+		wantErr     error
+		wantTitle   []textdiff.Edit
+		wantContent []textdiff.Edit
+	}{
+		{
+			name: "按行比较",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(123), int64(1), int64(10)).
+					Return(domain.ArticleRevision{
+						Id:      10,
+						Title:   "旧标题",
+						Content: "第一行\n第二行\n",
+					}, nil)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(123), int64(1), int64(11)).
+					Return(domain.ArticleRevision{
+						Id:      11,
+						Title:   "新标题",
+						Content: "第一行\n第三行\n",
+					}, nil)
+				return repo
+			},
+			mode: domain.ArticleDiffModeLine,
+			wantTitle: []textdiff.Edit{
+				{Op: textdiff.OpDelete, Text: "旧"},
+				{Op: textdiff.OpInsert, Text: "新"},
+				{Op: textdiff.OpEqual, Text: "标题"},
+			},
+			wantContent: []textdiff.Edit{
+				{Op: textdiff.OpEqual, Text: "第一行\n"},
+				{Op: textdiff.OpDelete, Text: "第二行\n"},
+				{Op: textdiff.OpInsert, Text: "第三行\n"},
+			},
+		},
+		{
+			name: "按词比较",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(123), int64(1), int64(10)).
+					Return(domain.ArticleRevision{
+						Id:      10,
+						Title:   "标题",
+						Content: "hello world",
+					}, nil)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(123), int64(1), int64(11)).
+					Return(domain.ArticleRevision{
+						Id:      11,
+						Title:   "标题",
+						Content: "hello go",
+					}, nil)
+				return repo
+			},
+			mode: domain.ArticleDiffModeWord,
+			wantTitle: []textdiff.Edit{
+				{Op: textdiff.OpEqual, Text: "标题"},
+			},
+			wantContent: []textdiff.Edit{
+				{Op: textdiff.OpEqual, Text: "hello "},
+				{Op: textdiff.OpDelete, Text: "world"},
+				{Op: textdiff.OpInsert, Text: "go"},
+			},
+		},
+		{
+			name: "不是作者本人",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(123), int64(1), gomock.Any()).
+					AnyTimes().
+					Return(domain.ArticleRevision{}, ErrRevisionNotFound)
+				return repo
+			},
+			mode:    domain.ArticleDiffModeLine,
+			wantErr: ErrRevisionNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewArticleService(tc.mock(ctrl), &logger.NoOpLogger{}, nil)
+			diff, err := svc.DiffRevisions(context.Background(), 123, 1, 10, 11, tc.mode)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantTitle, diff.Title)
+			assert.Equal(t, tc.wantContent, diff.Content)
+		})
+	}
+}
+
+func Test_articleService_PublishDue(t *testing.T) {
+	publishAt := time.UnixMilli(1000)
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (article.ArticleRepository, events.Producer)
+
+		wantErr error
+		wantCnt int
+	}{
+		{
+			name: "抢占成功并发表",
+			mock: func(ctrl *gomock.Controller) (article.ArticleRepository, events.Producer) {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				art := domain.Article{
+					Id:        1,
+					Title:     "我的标题",
+					Status:    domain.ArticleStatusScheduled,
+					PublishAt: publishAt,
+					Author:    domain.Author{Id: 123},
+				}
+				repo.EXPECT().FindDueScheduled(gomock.Any(), gomock.Any(), 10).
+					Return([]domain.Article{art}, nil)
+				repo.EXPECT().ClaimScheduled(gomock.Any(), art, gomock.Any()).
+					Return(true, nil)
+				// 查询之后作者又保存过，发表的是最新的内容
+				repo.EXPECT().GetByID(gomock.Any(), int64(1)).
+					Return(domain.Article{
+						Id:        1,
+						Title:     "新标题",
+						Status:    domain.ArticleStatusScheduled,
+						PublishAt: time.UnixMilli(2000),
+						Author:    domain.Author{Id: 123},
+					}, nil)
+				repo.EXPECT().Sync(gomock.Any(), domain.Article{
+					Id:     1,
+					Title:  "新标题",
+					Status: domain.ArticleStatusPublished,
+					Author: domain.Author{Id: 123},
+				}).Return(int64(1), nil)
+				// 发表事件由 DAO 写进发件箱，这里不会直接发送
+				return repo, evtmocks.NewMockProducer(ctrl)
+			},
+			wantCnt: 1,
+		},
+		{
+			name: "被别的实例抢占了",
+			mock: func(ctrl *gomock.Controller) (article.ArticleRepository, events.Producer) {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				art := domain.Article{Id: 1, PublishAt: publishAt,
+					Status: domain.ArticleStatusScheduled}
+				repo.EXPECT().FindDueScheduled(gomock.Any(), gomock.Any(), 10).
+					Return([]domain.Article{art}, nil)
+				repo.EXPECT().ClaimScheduled(gomock.Any(), art, gomock.Any()).
+					Return(false, nil)
+				return repo, evtmocks.NewMockProducer(ctrl)
+			},
+			wantCnt: 0,
+		},
+		{
+			name: "抢占之后取消了",
+			mock: func(ctrl *gomock.Controller) (article.ArticleRepository, events.Producer) {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				art := domain.Article{Id: 1, PublishAt: publishAt,
+					Status: domain.ArticleStatusScheduled}
+				repo.EXPECT().FindDueScheduled(gomock.Any(), gomock.Any(), 10).
+					Return([]domain.Article{art}, nil)
+				repo.EXPECT().ClaimScheduled(gomock.Any(), art, gomock.Any()).
+					Return(true, nil)
+				repo.EXPECT().GetByID(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Status: domain.ArticleStatusUnpublished}, nil)
+				return repo, evtmocks.NewMockProducer(ctrl)
+			},
+			wantCnt: 0,
+		},
+		{
+			name: "发表失败，等待重试",
+			mock: func(ctrl *gomock.Controller) (article.ArticleRepository, events.Producer) {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				art1 := domain.Article{Id: 1, PublishAt: publishAt,
+					Status: domain.ArticleStatusScheduled}
+				art2 := domain.Article{Id: 2, PublishAt: publishAt,
+					Status: domain.ArticleStatusScheduled}
+				repo.EXPECT().FindDueScheduled(gomock.Any(), gomock.Any(), 10).
+					Return([]domain.Article{art1, art2}, nil)
+				repo.EXPECT().ClaimScheduled(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(2).Return(true, nil)
+				repo.EXPECT().GetByID(gomock.Any(), int64(1)).Return(art1, nil)
+				repo.EXPECT().GetByID(gomock.Any(), int64(2)).Return(art2, nil)
+				repo.EXPECT().Sync(gomock.Any(), gomock.Any()).
+					Return(int64(0), errors.New("mock db error"))
+				repo.EXPECT().Sync(gomock.Any(), gomock.Any()).
+					Return(int64(2), nil)
+				return repo, evtmocks.NewMockProducer(ctrl)
+			},
+			wantCnt: 1,
+		},
+		{
+			name: "查询失败",
+			mock: func(ctrl *gomock.Controller) (article.ArticleRepository, events.Producer) {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().FindDueScheduled(gomock.Any(), gomock.Any(), 10).
+					Return(nil, errors.New("mock db error"))
+				return repo, evtmocks.NewMockProducer(ctrl)
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, producer := tc.mock(ctrl)
+			svc := NewArticleService(repo, &logger.NoOpLogger{}, producer)
+			cnt, err := svc.PublishDue(context.Background(), 10)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCnt, cnt)
+		})
+	}
+}
+
+func Test_articleService_Save(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) article.ArticleRepository
+		art  domain.Article
+
+		wantErr error
+		wantId  int64
+	}{
+		{
+			name: "规范化标签和分类",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().Create(gomock.Any(), domain.Article{
+					Title:    "我的标题",
+					Status:   domain.ArticleStatusUnpublished,
+					Author:   domain.Author{Id: 123},
+					Category: "后端",
+					Tags:     []string{"go", "数据库"},
+				}).Return(int64(1), nil)
+				return repo
+			},
+			art: domain.Article{
+				Title:    "我的标题",
+				Author:   domain.Author{Id: 123},
+				Category: " 后端 ",
+				Tags:     []string{"Go", " go", "", "数据库 "},
+			},
+			wantId: 1,
+		},
+		{
+			name: "标签太多",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				return artrepomocks.NewMockArticleRepository(ctrl)
+			},
+			art: domain.Article{
+				Title:  "我的标题",
+				Author: domain.Author{Id: 123},
+				Tags:   []string{"a", "b", "c", "d", "e", "f"},
+			},
+			wantErr: ErrInvalidTags,
+		},
+		{
+			name: "标签太长",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				return artrepomocks.NewMockArticleRepository(ctrl)
+			},
+			art: domain.Article{
+				Title:  "我的标题",
+				Author: domain.Author{Id: 123},
+				Tags:   []string{strings.Repeat("长", 33)},
+			},
+			wantErr: ErrInvalidTags,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewArticleService(tc.mock(ctrl), &logger.NoOpLogger{}, nil)
+			id, err := svc.Save(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+		})
+	}
+}
+
+func Test_articleService_TagCounts(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) article.ArticleRepository
+		tags []string
+
+		wantErr  error
+		wantTags []domain.Tag
+	}{
+		{
+			name: "没有文章的标签补零",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().TagCounts(gomock.Any(), []string{"go", "mysql"}).
+					Return([]domain.Tag{{Name: "go", Cnt: 3}}, nil)
+				return repo
+			},
+			tags: []string{"Go", "mysql"},
+			wantTags: []domain.Tag{
+				{Name: "go", Cnt: 3},
+				{Name: "mysql", Cnt: 0},
+			},
+		},
+		{
+			name: "查询失败",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().TagCounts(gomock.Any(), []string{"go"}).
+					Return(nil, errors.New("mock db error"))
+				return repo
+			},
+			tags:    []string{"go"},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewArticleService(tc.mock(ctrl), &logger.NoOpLogger{}, nil)
+			tags, err := svc.TagCounts(context.Background(), tc.tags)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantTags, tags)
+		})
+	}
+}
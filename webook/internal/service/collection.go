@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+const (
+	maxCollectionNameLen  = 64
+	defaultCollectionName = "默认收藏夹"
+)
+
+var (
+	ErrCollectionNotFound     = repository.ErrCollectionNotFound
+	ErrCollectionItemNotFound = errors.New("没有收藏过")
+	ErrInvalidCollectionName  = errors.New("收藏夹名字不合法")
+	ErrDefaultCollection      = errors.New("默认收藏夹不能修改")
+)
+
+// CollectionService 收藏夹。
+// cid 为 0 的是每个用户都有的默认收藏夹，不能重命名也不能删除。
+// 一个东西只能在用户的一个收藏夹里面
+type CollectionService interface {
+	Create(ctx context.Context, uid int64, name string) (int64, error)
+	Rename(ctx context.Context, uid, cid int64, name string) error
+	// Delete 删除收藏夹，里面的东西都会取消收藏
+	Delete(ctx context.Context, uid, cid int64) error
+	// Collect 收藏到 cid 收藏夹，已经收藏过的什么都不做，换收藏夹用 Move
+	Collect(ctx context.Context, uid, cid int64, biz string, bizId int64) error
+	// Uncollect 取消收藏，没有收藏过也不会报错
+	Uncollect(ctx context.Context, uid int64, biz string, bizId int64) error
+	// Move 把收藏的东西挪到 cid 收藏夹
+	Move(ctx context.Context, uid int64, biz string, bizId, cid int64) error
+	// List 用户的收藏夹，第一页的最前面是默认收藏夹
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.Collection, error)
+	// ListItems 收藏夹里面的东西，文章会带上标题
+	ListItems(ctx context.Context, uid, cid int64, offset, limit int) ([]domain.CollectionItem, error)
+}
+
+type collectionService struct {
+	repo     repository.CollectionRepository
+	intrRepo repository.InteractiveRepository
+	artRepo  article.ArticleRepository
+	l        logger.LoggerV1
+	// 删除收藏夹的时候，每一批取消收藏多少个
+	batchSize int
+}
+
+func NewCollectionService(repo repository.CollectionRepository,
+	intrRepo repository.InteractiveRepository,
+	artRepo article.ArticleRepository, l logger.LoggerV1) CollectionService {
+	return &collectionService{
+		repo:      repo,
+		intrRepo:  intrRepo,
+		artRepo:   artRepo,
+		l:         l,
+		batchSize: 100,
+	}
+}
+
+func (s *collectionService) Create(ctx context.Context, uid int64, name string) (int64, error) {
+	name, err := s.checkName(name)
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.Create(ctx, domain.Collection{
+		Name: name,
+		Uid:  uid,
+	})
+}
+
+func (s *collectionService) Rename(ctx context.Context, uid, cid int64, name string) error {
+	if cid == 0 {
+		return ErrDefaultCollection
+	}
+	name, err := s.checkName(name)
+	if err != nil {
+		return err
+	}
+	return s.repo.Rename(ctx, uid, cid, name)
+}
+
+func (s *collectionService) checkName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxCollectionNameLen {
+		return "", ErrInvalidCollectionName
+	}
+	return name, nil
+}
+
+func (s *collectionService) Delete(ctx context.Context, uid, cid int64) error {
+	if cid == 0 {
+		return ErrDefaultCollection
+	}
+	_, err := s.repo.GetById(ctx, uid, cid)
+	if err != nil {
+		return err
+	}
+	// 一个一个取消收藏，这样收藏数才是对的。
+	// 中途失败了收藏夹还在，用户可以重新删除
+	for {
+		items, err := s.repo.ListItems(ctx, uid, cid, 0, s.batchSize)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			err = s.intrRepo.RemoveCollectionItem(ctx, item.Biz, item.BizId, uid)
+			if err != nil {
+				return err
+			}
+		}
+		if len(items) < s.batchSize {
+			break
+		}
+	}
+	return s.repo.Delete(ctx, uid, cid)
+}
+
+func (s *collectionService) Collect(ctx context.Context,
+	uid, cid int64, biz string, bizId int64) error {
+	err := s.checkCollection(ctx, uid, cid)
+	if err != nil {
+		return err
+	}
+	return s.intrRepo.AddCollectionItem(ctx, biz, bizId, cid, uid)
+}
+
+func (s *collectionService) Uncollect(ctx context.Context, uid int64, biz string, bizId int64) error {
+	return s.intrRepo.RemoveCollectionItem(ctx, biz, bizId, uid)
+}
+
+func (s *collectionService) Move(ctx context.Context,
+	uid int64, biz string, bizId, cid int64) error {
+	err := s.checkCollection(ctx, uid, cid)
+	if err != nil {
+		return err
+	}
+	err = s.repo.MoveItem(ctx, uid, biz, bizId, cid)
+	if errors.Is(err, repository.ErrCollectionNotFound) {
+		return ErrCollectionItemNotFound
+	}
+	return err
+}
+
+// checkCollection 确认 cid 是 uid 的收藏夹，默认收藏夹总是存在的
+func (s *collectionService) checkCollection(ctx context.Context, uid, cid int64) error {
+	if cid == 0 {
+		return nil
+	}
+	_, err := s.repo.GetById(ctx, uid, cid)
+	return err
+}
+
+func (s *collectionService) List(ctx context.Context,
+	uid int64, offset, limit int) ([]domain.Collection, error) {
+	cols, err := s.repo.List(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	if offset == 0 {
+		cols = append([]domain.Collection{{Name: defaultCollectionName, Uid: uid}}, cols...)
+	}
+	if len(cols) == 0 {
+		return cols, nil
+	}
+	cids := make([]int64, 0, len(cols))
+	for _, col := range cols {
+		cids = append(cids, col.Id)
+	}
+	cnts, err := s.repo.ItemCnts(ctx, uid, cids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cols {
+		cols[i].ItemCnt = cnts[cols[i].Id]
+	}
+	return cols, nil
+}
+
+func (s *collectionService) ListItems(ctx context.Context,
+	uid, cid int64, offset, limit int) ([]domain.CollectionItem, error) {
+	err := s.checkCollection(ctx, uid, cid)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.ListItems(ctx, uid, cid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	var aids []int64
+	for _, item := range items {
+		if item.Biz == domain.BizArrticle {
+			aids = append(aids, item.BizId)
+		}
+	}
+	if len(aids) == 0 {
+		return items, nil
+	}
+	arts, err := s.artRepo.GetPubByIds(ctx, aids)
+	if err != nil {
+		return nil, err
+	}
+	titles := make(map[int64]string, len(arts))
+	for _, art := range arts {
+		titles[art.Id] = art.Title
+	}
+	for i := range items {
+		if items[i].Biz == domain.BizArrticle {
+			items[i].Title = titles[items[i].BizId]
+		}
+	}
+	return items, nil
+}
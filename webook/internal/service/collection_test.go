@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
+	artrepomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article/mocks"
+	repomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/mocks"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+func Test_collectionService_Delete(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.CollectionRepository,
+			repository.InteractiveRepository)
+
+		cid int64
+
+		wantErr error
+	}{
+		{
+			// 两批，第二批不满，取消收藏之后再删除收藏夹
+			name: "删除成功",
+			mock: func(ctrl *gomock.Controller) (repository.CollectionRepository,
+				repository.InteractiveRepository) {
+				repo := repomocks.NewMockCollectionRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1), int64(3)).
+					Return(domain.Collection{Id: 3, Uid: 1}, nil)
+				repo.EXPECT().ListItems(gomock.Any(), int64(1), int64(3), 0, 2).
+					Return([]domain.CollectionItem{
+						{Cid: 3, Biz: "article", BizId: 11},
+						{Cid: 3, Biz: "article", BizId: 12},
+					}, nil)
+				repo.EXPECT().ListItems(gomock.Any(), int64(1), int64(3), 0, 2).
+					Return([]domain.CollectionItem{
+						{Cid: 3, Biz: "article", BizId: 13},
+					}, nil)
+				repo.EXPECT().Delete(gomock.Any(), int64(1), int64(3)).Return(nil)
+				intrRepo := repomocks.NewMockInteractiveRepository(ctrl)
+				for _, bizId := range []int64{11, 12, 13} {
+					intrRepo.EXPECT().RemoveCollectionItem(gomock.Any(), "article", bizId, int64(1)).
+						Return(nil)
+				}
+				return repo, intrRepo
+			},
+			cid: 3,
+		},
+		{
+			name: "默认收藏夹",
+			mock: func(ctrl *gomock.Controller) (repository.CollectionRepository,
+				repository.InteractiveRepository) {
+				return repomocks.NewMockCollectionRepository(ctrl),
+					repomocks.NewMockInteractiveRepository(ctrl)
+			},
+			cid:     0,
+			wantErr: ErrDefaultCollection,
+		},
+		{
+			name: "不是自己的收藏夹",
+			mock: func(ctrl *gomock.Controller) (repository.CollectionRepository,
+				repository.InteractiveRepository) {
+				repo := repomocks.NewMockCollectionRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1), int64(3)).
+					Return(domain.Collection{}, repository.ErrCollectionNotFound)
+				return repo, repomocks.NewMockInteractiveRepository(ctrl)
+			},
+			cid:     3,
+			wantErr: ErrCollectionNotFound,
+		},
+		{
+			// 收藏夹不能删，不然剩下的东西就找不到了
+			name: "取消收藏失败",
+			mock: func(ctrl *gomock.Controller) (repository.CollectionRepository,
+				repository.InteractiveRepository) {
+				repo := repomocks.NewMockCollectionRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1), int64(3)).
+					Return(domain.Collection{Id: 3, Uid: 1}, nil)
+				repo.EXPECT().ListItems(gomock.Any(), int64(1), int64(3), 0, 2).
+					Return([]domain.CollectionItem{
+						{Cid: 3, Biz: "article", BizId: 11},
+					}, nil)
+				intrRepo := repomocks.NewMockInteractiveRepository(ctrl)
+				intrRepo.EXPECT().RemoveCollectionItem(gomock.Any(), "article", int64(11), int64(1)).
+					Return(errors.New("mock db error"))
+				return repo, intrRepo
+			},
+			cid:     3,
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, intrRepo := tc.mock(ctrl)
+			svc := NewCollectionService(repo, intrRepo,
+				artrepomocks.NewMockArticleRepository(ctrl), &logger.NoOpLogger{}).(*collectionService)
+			svc.batchSize = 2
+			err := svc.Delete(context.Background(), 1, tc.cid)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_collectionService_Move(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.CollectionRepository
+
+		cid int64
+
+		wantErr error
+	}{
+		{
+			name: "挪到默认收藏夹",
+			mock: func(ctrl *gomock.Controller) repository.CollectionRepository {
+				repo := repomocks.NewMockCollectionRepository(ctrl)
+				repo.EXPECT().MoveItem(gomock.Any(), int64(1), "article", int64(11), int64(0)).
+					Return(nil)
+				return repo
+			},
+			cid: 0,
+		},
+		{
+			name: "目标收藏夹不存在",
+			mock: func(ctrl *gomock.Controller) repository.CollectionRepository {
+				repo := repomocks.NewMockCollectionRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1), int64(3)).
+					Return(domain.Collection{}, repository.ErrCollectionNotFound)
+				return repo
+			},
+			cid:     3,
+			wantErr: ErrCollectionNotFound,
+		},
+		{
+			name: "没有收藏过",
+			mock: func(ctrl *gomock.Controller) repository.CollectionRepository {
+				repo := repomocks.NewMockCollectionRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1), int64(3)).
+					Return(domain.Collection{Id: 3, Uid: 1}, nil)
+				repo.EXPECT().MoveItem(gomock.Any(), int64(1), "article", int64(11), int64(3)).
+					Return(repository.ErrCollectionNotFound)
+				return repo
+			},
+			cid:     3,
+			wantErr: ErrCollectionItemNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewCollectionService(tc.mock(ctrl),
+				repomocks.NewMockInteractiveRepository(ctrl),
+				artrepomocks.NewMockArticleRepository(ctrl), &logger.NoOpLogger{})
+			err := svc.Move(context.Background(), 1, "article", 11, tc.cid)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_collectionService_ListItems(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.CollectionRepository,
+			article.ArticleRepository)
+
+		wantItems []domain.CollectionItem
+		wantErr   error
+	}{
+		{
+			// 12 撤回了，查不到标题
+			name: "带上文章标题",
+			mock: func(ctrl *gomock.Controller) (repository.CollectionRepository,
+				article.ArticleRepository) {
+				repo := repomocks.NewMockCollectionRepository(ctrl)
+				repo.EXPECT().ListItems(gomock.Any(), int64(1), int64(0), 0, 10).
+					Return([]domain.CollectionItem{
+						{Biz: "article", BizId: 11, Ctime: now},
+						{Biz: "article", BizId: 12, Ctime: now},
+						{Biz: "video", BizId: 11, Ctime: now},
+					}, nil)
+				artRepo := artrepomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubByIds(gomock.Any(), []int64{11, 12}).
+					Return([]domain.Article{{Id: 11, Title: "标题11"}}, nil)
+				return repo, artRepo
+			},
+			wantItems: []domain.CollectionItem{
+				{Biz: "article", BizId: 11, Title: "标题11", Ctime: now},
+				{Biz: "article", BizId: 12, Ctime: now},
+				{Biz: "video", BizId: 11, Ctime: now},
+			},
+		},
+		{
+			name: "查询文章失败",
+			mock: func(ctrl *gomock.Controller) (repository.CollectionRepository,
+				article.ArticleRepository) {
+				repo := repomocks.NewMockCollectionRepository(ctrl)
+				repo.EXPECT().ListItems(gomock.Any(), int64(1), int64(0), 0, 10).
+					Return([]domain.CollectionItem{
+						{Biz: "article", BizId: 11, Ctime: now},
+					}, nil)
+				artRepo := artrepomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubByIds(gomock.Any(), []int64{11}).
+					Return(nil, errors.New("mock db error"))
+				return repo, artRepo
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artRepo := tc.mock(ctrl)
+			svc := NewCollectionService(repo,
+				repomocks.NewMockInteractiveRepository(ctrl), artRepo, &logger.NoOpLogger{})
+			items, err := svc.ListItems(context.Background(), 1, 0, 0, 10)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantItems, items)
+		})
+	}
+}
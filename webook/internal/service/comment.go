@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+var (
+	ErrCommentNotFound     = repository.ErrCommentNotFound
+	ErrInvalidComment      = errors.New("评论内容不合法")
+	ErrCommentTargetClosed = errors.New("评论的资源不存在或者不允许评论")
+	ErrCommentNoPermission = errors.New("没有权限删除这条评论")
+)
+
+// maxCommentLen 评论的最大长度，按字符算
+const maxCommentLen = 1024
+
+type CommentService interface {
+	// Post 发表评论，ParentId 不为 0 的时候就是回复
+	Post(ctx context.Context, c domain.Comment) (int64, error)
+	// Delete 删除评论，评论者本人和资源的作者都可以删除
+	Delete(ctx context.Context, uid, id int64) error
+	// ListTopLevel 顶级评论，按照时间倒序。maxId 是上一页最后一条的 ID，第一页传 0
+	ListTopLevel(ctx context.Context, biz string, bizId, maxId int64, limit int) ([]domain.Comment, error)
+	// ListReplies 某条顶级评论下的回复，按照时间正序。minId 是上一页最后一条的 ID，第一页传 0
+	ListReplies(ctx context.Context, rootId, minId int64, limit int) ([]domain.Comment, error)
+	Count(ctx context.Context, biz string, bizId int64) (int64, error)
+}
+
+type commentService struct {
+	repo    repository.CommentRepository
+	artRepo article.ArticleRepository
+	l       logger.LoggerV1
+}
+
+func NewCommentService(repo repository.CommentRepository,
+	artRepo article.ArticleRepository, l logger.LoggerV1) CommentService {
+	return &commentService{
+		repo:    repo,
+		artRepo: artRepo,
+		l:       l,
+	}
+}
+
+func (s *commentService) Post(ctx context.Context, c domain.Comment) (int64, error) {
+	c.Content = strings.TrimSpace(c.Content)
+	if c.Content == "" || utf8.RuneCountInString(c.Content) > maxCommentLen {
+		return 0, ErrInvalidComment
+	}
+	c.RootId = 0
+	if c.ParentId > 0 {
+		parent, err := s.repo.FindById(ctx, c.ParentId)
+		if err != nil {
+			return 0, err
+		}
+		if parent.Biz != c.Biz || parent.BizId != c.BizId {
+			return 0, ErrInvalidComment
+		}
+		// 只有两级，回复的回复也挂在同一个顶级评论下面
+		c.RootId = parent.RootId
+		if parent.IsRoot() {
+			c.RootId = parent.Id
+		}
+	} else {
+		// 回复的时候父评论存在就说明资源是可以评论的，不需要重复检查
+		err := s.checkTarget(ctx, c.Biz, c.BizId)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return s.repo.Create(ctx, c)
+}
+
+// checkTarget 检查资源能不能评论，目前只知道怎么检查文章
+func (s *commentService) checkTarget(ctx context.Context, biz string, bizId int64) error {
+	if biz != domain.BizArrticle {
+		return nil
+	}
+	// 看线上库，作者编辑已经发表的文章的时候制作库是未发表，但是读者看到的依旧是发表的。
+	// 只需要知道是不是公开的，不需要组装作者，撤回之后仅自己可见的文章查不出来
+	arts, err := s.artRepo.GetPubByIds(ctx, []int64{bizId})
+	if err != nil {
+		return err
+	}
+	if len(arts) == 0 {
+		return ErrCommentTargetClosed
+	}
+	return nil
+}
+
+func (s *commentService) Delete(ctx context.Context, uid, id int64) error {
+	c, err := s.repo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if c.Uid != uid {
+		// 不是自己的评论，那么要看看是不是资源的作者在管理评论
+		owner, err := s.owner(ctx, c.Biz, c.BizId)
+		if err != nil {
+			return err
+		}
+		if owner != uid {
+			s.l.Warn("非法删除评论",
+				logger.Int64("uid", uid),
+				logger.Int64("cid", id))
+			return ErrCommentNoPermission
+		}
+	}
+	return s.repo.Delete(ctx, c)
+}
+
+// owner 资源的作者，不认识的资源返回 0，也就是只有评论者自己能删
+func (s *commentService) owner(ctx context.Context, biz string, bizId int64) (int64, error) {
+	if biz != domain.BizArrticle {
+		return 0, nil
+	}
+	// 这里用制作库，文章撤回之后作者依旧可以管理评论
+	art, err := s.artRepo.GetByID(ctx, bizId)
+	if err != nil {
+		return 0, err
+	}
+	return art.Author.Id, nil
+}
+
+func (s *commentService) ListTopLevel(ctx context.Context,
+	biz string, bizId, maxId int64, limit int) ([]domain.Comment, error) {
+	if maxId <= 0 {
+		maxId = math.MaxInt64
+	}
+	return s.repo.FindTopLevel(ctx, biz, bizId, maxId, s.pageSize(limit))
+}
+
+func (s *commentService) ListReplies(ctx context.Context,
+	rootId, minId int64, limit int) ([]domain.Comment, error) {
+	return s.repo.FindReplies(ctx, rootId, minId, s.pageSize(limit))
+}
+
+func (s *commentService) Count(ctx context.Context, biz string, bizId int64) (int64, error) {
+	return s.repo.Count(ctx, biz, bizId)
+}
+
+func (s *commentService) pageSize(limit int) int {
+	if limit <= 0 {
+		return 20
+	}
+	if limit > maxPageSize {
+		return maxPageSize
+	}
+	return limit
+}
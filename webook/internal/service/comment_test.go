@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
+	artrepomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article/mocks"
+	artdao "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao/article"
+	artdaomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao/article/mocks"
+	repomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/mocks"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+func Test_commentService_Post(t *testing.T) {
+	testCases := []struct {
+		name string
+		// 文章用真实的 repository，只 mock DAO，这样能发现 repository 里面依赖没有注入的问题
+		mock func(ctrl *gomock.Controller) (repository.CommentRepository,
+			artdao.ArticleDAO)
+
+		cmt domain.Comment
+
+		wantErr error
+		wantId  int64
+	}{
+		{
+			name: "发表顶级评论",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository,
+				artdao.ArticleDAO) {
+				artDAO := artdaomocks.NewMockArticleDAO(ctrl)
+				artDAO.EXPECT().GetPubByIds(gomock.Any(), []int64{1}).
+					Return([]artdao.PublishedArticle{
+						{Id: 1, Status: domain.ArticleStatusPublished.ToUint8()},
+					}, nil)
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().Create(gomock.Any(), domain.Comment{
+					Biz:     domain.BizArrticle,
+					BizId:   1,
+					Uid:     123,
+					Content: "写得好",
+				}).Return(int64(10), nil)
+				return repo, artDAO
+			},
+			cmt: domain.Comment{
+				Biz:     domain.BizArrticle,
+				BizId:   1,
+				Uid:     123,
+				Content: "  写得好 ",
+			},
+			wantId: 10,
+		},
+		{
+			name: "文章没有发表",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository,
+				artdao.ArticleDAO) {
+				artDAO := artdaomocks.NewMockArticleDAO(ctrl)
+				artDAO.EXPECT().GetPubByIds(gomock.Any(), []int64{1}).
+					Return(nil, nil)
+				return repomocks.NewMockCommentRepository(ctrl), artDAO
+			},
+			cmt: domain.Comment{
+				Biz:     domain.BizArrticle,
+				BizId:   1,
+				Uid:     123,
+				Content: "写得好",
+			},
+			wantErr: ErrCommentTargetClosed,
+		},
+		{
+			name: "文章已经撤回",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository,
+				artdao.ArticleDAO) {
+				// 线上库的状态是仅自己可见，查询的时候就过滤掉了
+				artDAO := artdaomocks.NewMockArticleDAO(ctrl)
+				artDAO.EXPECT().GetPubByIds(gomock.Any(), []int64{1}).
+					Return([]artdao.PublishedArticle{}, nil)
+				return repomocks.NewMockCommentRepository(ctrl), artDAO
+			},
+			cmt: domain.Comment{
+				Biz:     domain.BizArrticle,
+				BizId:   1,
+				Uid:     123,
+				Content: "写得好",
+			},
+			wantErr: ErrCommentTargetClosed,
+		},
+		{
+			name: "回复顶级评论",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository,
+				artdao.ArticleDAO) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(10)).
+					Return(domain.Comment{
+						Id:    10,
+						Biz:   domain.BizArrticle,
+						BizId: 1,
+					}, nil)
+				repo.EXPECT().Create(gomock.Any(), domain.Comment{
+					Biz:      domain.BizArrticle,
+					BizId:    1,
+					Uid:      123,
+					RootId:   10,
+					ParentId: 10,
+					Content:  "同意",
+				}).Return(int64(11), nil)
+				return repo, artdaomocks.NewMockArticleDAO(ctrl)
+			},
+			cmt: domain.Comment{
+				Biz:      domain.BizArrticle,
+				BizId:    1,
+				Uid:      123,
+				ParentId: 10,
+				Content:  "同意",
+			},
+			wantId: 11,
+		},
+		{
+			name: "回复的回复挂在顶级评论下面",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository,
+				artdao.ArticleDAO) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(11)).
+					Return(domain.Comment{
+						Id:       11,
+						Biz:      domain.BizArrticle,
+						BizId:    1,
+						RootId:   10,
+						ParentId: 10,
+					}, nil)
+				repo.EXPECT().Create(gomock.Any(), domain.Comment{
+					Biz:      domain.BizArrticle,
+					BizId:    1,
+					Uid:      123,
+					RootId:   10,
+					ParentId: 11,
+					Content:  "不同意",
+				}).Return(int64(12), nil)
+				return repo, artdaomocks.NewMockArticleDAO(ctrl)
+			},
+			cmt: domain.Comment{
+				Biz:      domain.BizArrticle,
+				BizId:    1,
+				Uid:      123,
+				ParentId: 11,
+				Content:  "不同意",
+			},
+			wantId: 12,
+		},
+		{
+			name: "回复的评论不是这篇文章的",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository,
+				artdao.ArticleDAO) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(10)).
+					Return(domain.Comment{
+						Id:    10,
+						Biz:   domain.BizArrticle,
+						BizId: 2,
+					}, nil)
+				return repo, artdaomocks.NewMockArticleDAO(ctrl)
+			},
+			cmt: domain.Comment{
+				Biz:      domain.BizArrticle,
+				BizId:    1,
+				Uid:      123,
+				ParentId: 10,
+				Content:  "同意",
+			},
+			wantErr: ErrInvalidComment,
+		},
+		{
+			name: "内容为空",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository,
+				artdao.ArticleDAO) {
+				return repomocks.NewMockCommentRepository(ctrl),
+					artdaomocks.NewMockArticleDAO(ctrl)
+			},
+			cmt: domain.Comment{
+				Biz:     domain.BizArrticle,
+				BizId:   1,
+				Content: "   ",
+			},
+			wantErr: ErrInvalidComment,
+		},
+		{
+			name: "内容太长",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository,
+				artdao.ArticleDAO) {
+				return repomocks.NewMockCommentRepository(ctrl),
+					artdaomocks.NewMockArticleDAO(ctrl)
+			},
+			cmt: domain.Comment{
+				Biz:     domain.BizArrticle,
+				BizId:   1,
+				Content: strings.Repeat("长", maxCommentLen+1),
+			},
+			wantErr: ErrInvalidComment,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artDAO := tc.mock(ctrl)
+			artRepo := article.NewArticleRepository(artDAO, nil, &logger.NoOpLogger{})
+			svc := NewCommentService(repo, artRepo, &logger.NoOpLogger{})
+			id, err := svc.Post(context.Background(), tc.cmt)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+		})
+	}
+}
+
+func Test_commentService_Delete(t *testing.T) {
+	cmt := domain.Comment{
+		Id:    10,
+		Biz:   domain.BizArrticle,
+		BizId: 1,
+		Uid:   123,
+	}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.CommentRepository,
+			article.ArticleRepository)
+
+		uid int64
+
+		wantErr error
+	}{
+		{
+			name: "评论者删除",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository,
+				article.ArticleRepository) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(10)).Return(cmt, nil)
+				repo.EXPECT().Delete(gomock.Any(), cmt).Return(nil)
+				return repo, artrepomocks.NewMockArticleRepository(ctrl)
+			},
+			uid: 123,
+		},
+		{
+			name: "文章作者删除",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository,
+				article.ArticleRepository) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(10)).Return(cmt, nil)
+				repo.EXPECT().Delete(gomock.Any(), cmt).Return(nil)
+				artRepo := artrepomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetByID(gomock.Any(), int64(1)).
+					Return(domain.Article{
+						Id:     1,
+						Author: domain.Author{Id: 456},
+					}, nil)
+				return repo, artRepo
+			},
+			uid: 456,
+		},
+		{
+			name: "别人不能删除",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository,
+				article.ArticleRepository) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(10)).Return(cmt, nil)
+				artRepo := artrepomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetByID(gomock.Any(), int64(1)).
+					Return(domain.Article{
+						Id:     1,
+						Author: domain.Author{Id: 456},
+					}, nil)
+				return repo, artRepo
+			},
+			uid:     789,
+			wantErr: ErrCommentNoPermission,
+		},
+		{
+			name: "评论不存在",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository,
+				article.ArticleRepository) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(10)).
+					Return(domain.Comment{}, ErrCommentNotFound)
+				return repo, artrepomocks.NewMockArticleRepository(ctrl)
+			},
+			uid:     123,
+			wantErr: ErrCommentNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artRepo := tc.mock(ctrl)
+			svc := NewCommentService(repo, artRepo, &logger.NoOpLogger{})
+			err := svc.Delete(context.Background(), tc.uid, 10)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package localemail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Service 本地开发用的实现，不会真的发邮件。
+// 指定了文件的话追加写到文件里面，不然就打印出来
+type Service struct {
+	file string
+	mu   sync.Mutex
+}
+
+func NewService(file string) *Service {
+	return &Service{
+		file: file,
+	}
+}
+
+func (s *Service) Send(ctx context.Context, subject, content string, to ...string) error {
+	msg := fmt.Sprintf("时间：%s\n收件人：%s\n主题：%s\n\n%s\n\n",
+		time.Now().Format(time.DateTime), strings.Join(to, ", "), subject, content)
+	if s.file == "" {
+		log.Println("发送邮件", msg)
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 默认写到 ./data 下面，目录不存在的话先创建
+	err := os.MkdirAll(filepath.Dir(s.file), 0700)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(msg)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,8 @@
+package email
+
+import "context"
+
+// Service 发送邮件的抽象，和 sms.Service 一样，是为了适配不同的邮件供应商
+type Service interface {
+	Send(ctx context.Context, subject, content string, to ...string) error
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ecodeclub/ekit/slice"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+const (
+	// fanOutBatchSize 推模型每次写多少个粉丝
+	fanOutBatchSize = 500
+	// maxPullFollowees 拉模型最多合并多少个关注的人
+	maxPullFollowees = 2000
+)
+
+type FeedService interface {
+	// PublishArticle 新文章发表了。
+	// 粉丝数小于阈值的作者，写到每个粉丝的收件箱；否则只写到作者的发件箱，读的时候再拉。
+	// 发表事件和撤回事件的先后顺序没有保证，所以以线上库为准，文章已经不是公开的就不写
+	PublishArticle(ctx context.Context, item domain.FeedItem) error
+	// CancelFollow 取消关注之后，收件箱里面不应该再有这个作者的文章
+	CancelFollow(ctx context.Context, follower, followee int64) error
+	// WithdrawArticle 文章撤回之后，谁的 feed 里面都不应该再有它。
+	// 文章又公开了的话，这是一个过期的撤回事件，不删
+	WithdrawArticle(ctx context.Context, aid int64) error
+	// GetFeed 按照发表时间倒序返回 uid 关注的作者发表的文章
+	GetFeed(ctx context.Context, uid int64, cursor domain.FeedCursor, limit int) ([]domain.FeedItem, error)
+}
+
+type feedService struct {
+	repo       repository.FeedRepository
+	followRepo repository.FollowRepository
+	artRepo    article.ArticleRepository
+	l          logger.LoggerV1
+	// threshold 粉丝数达到这个值就从推模型切换到拉模型
+	threshold int64
+}
+
+func NewFeedService(repo repository.FeedRepository,
+	followRepo repository.FollowRepository,
+	artRepo article.ArticleRepository,
+	l logger.LoggerV1, threshold int64) FeedService {
+	return &feedService{
+		repo:       repo,
+		followRepo: followRepo,
+		artRepo:    artRepo,
+		l:          l,
+		threshold:  threshold,
+	}
+}
+
+func (f *feedService) PublishArticle(ctx context.Context, item domain.FeedItem) error {
+	published, err := f.published(ctx, item.Aid)
+	if err != nil || !published {
+		return err
+	}
+	err = f.writeFeed(ctx, item)
+	if err != nil {
+		return err
+	}
+	// 写的过程中文章可能被撤回了，撤回事件又先处理完了，这里再检查一次
+	published, err = f.published(ctx, item.Aid)
+	if err != nil || published {
+		return err
+	}
+	return f.repo.DeleteArticleItems(ctx, item.Aid)
+}
+
+func (f *feedService) writeFeed(ctx context.Context, item domain.FeedItem) error {
+	statics, err := f.followRepo.GetStatics(ctx, item.Uid)
+	if err != nil {
+		return err
+	}
+	if statics.Followers >= f.threshold {
+		// 大 V，写扩散的代价太大
+		return f.repo.CreatePullItem(ctx, item)
+	}
+	for offset := 0; ; offset += fanOutBatchSize {
+		followers, err := f.followRepo.GetFollowers(ctx, item.Uid, offset, fanOutBatchSize)
+		if err != nil {
+			return err
+		}
+		uids := slice.Map[domain.FollowRelation, int64](followers,
+			func(idx int, src domain.FollowRelation) int64 {
+				return src.Follower
+			})
+		// 重复消费的时候会重复写，靠唯一索引去重
+		err = f.repo.CreatePushItems(ctx, item, uids)
+		if err != nil {
+			return err
+		}
+		if len(followers) < fanOutBatchSize {
+			return nil
+		}
+	}
+}
+
+func (f *feedService) CancelFollow(ctx context.Context, follower, followee int64) error {
+	return f.repo.DeletePushItems(ctx, follower, followee)
+}
+
+func (f *feedService) WithdrawArticle(ctx context.Context, aid int64) error {
+	published, err := f.published(ctx, aid)
+	if err != nil || published {
+		return err
+	}
+	return f.repo.DeleteArticleItems(ctx, aid)
+}
+
+// published 线上库里面这篇文章现在是不是公开的
+func (f *feedService) published(ctx context.Context, aid int64) (bool, error) {
+	arts, err := f.artRepo.GetPubByIds(ctx, []int64{aid})
+	if err != nil {
+		return false, err
+	}
+	return len(arts) > 0, nil
+}
+
+func (f *feedService) GetFeed(ctx context.Context,
+	uid int64, cursor domain.FeedCursor, limit int) ([]domain.FeedItem, error) {
+	if limit <= 0 || limit > maxPageSize {
+		limit = 20
+	}
+	var (
+		eg   errgroup.Group
+		push []domain.FeedItem
+		pull []domain.FeedItem
+	)
+	eg.Go(func() error {
+		var err error
+		push, err = f.repo.FindPushItems(ctx, uid, cursor, limit)
+		return err
+	})
+	eg.Go(func() error {
+		followees, err := f.followees(ctx, uid)
+		if err != nil {
+			return err
+		}
+		// 发件箱里面只有大 V 的文章，所以不需要先筛选出大 V
+		pull, err = f.repo.FindPullItems(ctx, followees, cursor, limit)
+		return err
+	})
+	err := eg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	return mergeFeed(push, pull, limit), nil
+}
+
+func (f *feedService) followees(ctx context.Context, uid int64) ([]int64, error) {
+	res := make([]int64, 0, 64)
+	for offset := 0; offset < maxPullFollowees; offset += maxPageSize {
+		followees, err := f.followRepo.GetFollowees(ctx, uid, offset, maxPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, fr := range followees {
+			res = append(res, fr.Followee)
+		}
+		if len(followees) < maxPageSize {
+			break
+		}
+	}
+	return res, nil
+}
+
+// mergeFeed 两个列表都已经按照 (ctime, aid) 倒序排好了，归并出前 limit 个
+func mergeFeed(push, pull []domain.FeedItem, limit int) []domain.FeedItem {
+	res := make([]domain.FeedItem, 0, limit)
+	i, j := 0, 0
+	for len(res) < limit && (i < len(push) || j < len(pull)) {
+		if j >= len(pull) || (i < len(push) && feedBefore(push[i], pull[j])) {
+			res = append(res, push[i])
+			i++
+		} else {
+			res = append(res, pull[j])
+			j++
+		}
+	}
+	return res
+}
+
+// feedBefore a 是否应该排在 b 前面
+func feedBefore(a, b domain.FeedItem) bool {
+	if !a.Ctime.Equal(b.Ctime) {
+		return a.Ctime.After(b.Ctime)
+	}
+	return a.Aid > b.Aid
+}
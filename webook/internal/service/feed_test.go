@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
+	artdao "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao/article"
+	artdaomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao/article/mocks"
+	repomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/mocks"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+func Test_feedService_PublishArticle(t *testing.T) {
+	item := domain.FeedItem{
+		Aid:   1,
+		Uid:   123,
+		Title: "我的标题",
+		Ctime: time.UnixMilli(1000),
+	}
+	pub := []artdao.PublishedArticle{{Id: 1, AuthorId: 123,
+		Status: domain.ArticleStatusPublished.ToUint8()}}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.FeedRepository,
+			repository.FollowRepository, artdao.ArticleDAO)
+
+		wantErr error
+	}{
+		{
+			name: "粉丝少，写收件箱",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository,
+				repository.FollowRepository, artdao.ArticleDAO) {
+				artDAO := artdaomocks.NewMockArticleDAO(ctrl)
+				artDAO.EXPECT().GetPubByIds(gomock.Any(), []int64{1}).
+					Return(pub, nil).Times(2)
+				followRepo := repomocks.NewMockFollowRepository(ctrl)
+				followRepo.EXPECT().GetStatics(gomock.Any(), int64(123)).
+					Return(domain.FollowStatics{Followers: 2}, nil)
+				followRepo.EXPECT().GetFollowers(gomock.Any(), int64(123), 0, fanOutBatchSize).
+					Return([]domain.FollowRelation{
+						{Follower: 4, Followee: 123},
+						{Follower: 5, Followee: 123},
+					}, nil)
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().CreatePushItems(gomock.Any(), item, []int64{4, 5}).
+					Return(nil)
+				return repo, followRepo, artDAO
+			},
+		},
+		{
+			name: "粉丝多，写发件箱",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository,
+				repository.FollowRepository, artdao.ArticleDAO) {
+				artDAO := artdaomocks.NewMockArticleDAO(ctrl)
+				artDAO.EXPECT().GetPubByIds(gomock.Any(), []int64{1}).
+					Return(pub, nil).Times(2)
+				followRepo := repomocks.NewMockFollowRepository(ctrl)
+				followRepo.EXPECT().GetStatics(gomock.Any(), int64(123)).
+					Return(domain.FollowStatics{Followers: 10}, nil)
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().CreatePullItem(gomock.Any(), item).Return(nil)
+				return repo, followRepo, artDAO
+			},
+		},
+		{
+			// 撤回事件先处理了，发表事件过期了
+			name: "文章已经撤回",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository,
+				repository.FollowRepository, artdao.ArticleDAO) {
+				artDAO := artdaomocks.NewMockArticleDAO(ctrl)
+				artDAO.EXPECT().GetPubByIds(gomock.Any(), []int64{1}).
+					Return([]artdao.PublishedArticle{}, nil)
+				return repomocks.NewMockFeedRepository(ctrl),
+					repomocks.NewMockFollowRepository(ctrl), artDAO
+			},
+		},
+		{
+			name: "写的过程中撤回了",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository,
+				repository.FollowRepository, artdao.ArticleDAO) {
+				artDAO := artdaomocks.NewMockArticleDAO(ctrl)
+				first := artDAO.EXPECT().GetPubByIds(gomock.Any(), []int64{1}).
+					Return(pub, nil)
+				artDAO.EXPECT().GetPubByIds(gomock.Any(), []int64{1}).
+					Return([]artdao.PublishedArticle{}, nil).After(first)
+				followRepo := repomocks.NewMockFollowRepository(ctrl)
+				followRepo.EXPECT().GetStatics(gomock.Any(), int64(123)).
+					Return(domain.FollowStatics{Followers: 10}, nil)
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().CreatePullItem(gomock.Any(), item).Return(nil)
+				repo.EXPECT().DeleteArticleItems(gomock.Any(), int64(1)).Return(nil)
+				return repo, followRepo, artDAO
+			},
+		},
+		{
+			name: "查询粉丝失败",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository,
+				repository.FollowRepository, artdao.ArticleDAO) {
+				artDAO := artdaomocks.NewMockArticleDAO(ctrl)
+				artDAO.EXPECT().GetPubByIds(gomock.Any(), []int64{1}).
+					Return(pub, nil)
+				followRepo := repomocks.NewMockFollowRepository(ctrl)
+				followRepo.EXPECT().GetStatics(gomock.Any(), int64(123)).
+					Return(domain.FollowStatics{Followers: 2}, nil)
+				followRepo.EXPECT().GetFollowers(gomock.Any(), int64(123), 0, fanOutBatchSize).
+					Return(nil, errors.New("mock db error"))
+				return repomocks.NewMockFeedRepository(ctrl), followRepo, artDAO
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, followRepo, artDAO := tc.mock(ctrl)
+			artRepo := article.NewArticleRepository(artDAO, nil, &logger.NoOpLogger{})
+			svc := NewFeedService(repo, followRepo, artRepo, &logger.NoOpLogger{}, 10)
+			err := svc.PublishArticle(context.Background(), item)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_feedService_WithdrawArticle(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.FeedRepository, artdao.ArticleDAO)
+
+		wantErr error
+	}{
+		{
+			name: "删除 feed",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository, artdao.ArticleDAO) {
+				artDAO := artdaomocks.NewMockArticleDAO(ctrl)
+				artDAO.EXPECT().GetPubByIds(gomock.Any(), []int64{1}).
+					Return([]artdao.PublishedArticle{}, nil)
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().DeleteArticleItems(gomock.Any(), int64(1)).Return(nil)
+				return repo, artDAO
+			},
+		},
+		{
+			// 撤回之后又发表了，撤回事件过期了
+			name: "文章又公开了",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository, artdao.ArticleDAO) {
+				artDAO := artdaomocks.NewMockArticleDAO(ctrl)
+				artDAO.EXPECT().GetPubByIds(gomock.Any(), []int64{1}).
+					Return([]artdao.PublishedArticle{{Id: 1,
+						Status: domain.ArticleStatusPublished.ToUint8()}}, nil)
+				return repomocks.NewMockFeedRepository(ctrl), artDAO
+			},
+		},
+		{
+			name: "查询文章失败",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository, artdao.ArticleDAO) {
+				artDAO := artdaomocks.NewMockArticleDAO(ctrl)
+				artDAO.EXPECT().GetPubByIds(gomock.Any(), []int64{1}).
+					Return(nil, errors.New("mock db error"))
+				return repomocks.NewMockFeedRepository(ctrl), artDAO
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artDAO := tc.mock(ctrl)
+			artRepo := article.NewArticleRepository(artDAO, nil, &logger.NoOpLogger{})
+			svc := NewFeedService(repo, repomocks.NewMockFollowRepository(ctrl), artRepo,
+				&logger.NoOpLogger{}, 10)
+			err := svc.WithdrawArticle(context.Background(), 1)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_feedService_GetFeed(t *testing.T) {
+	feedItem := func(aid, ctime int64) domain.FeedItem {
+		return domain.FeedItem{Aid: aid, Ctime: time.UnixMilli(ctime)}
+	}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.FeedRepository,
+			repository.FollowRepository)
+
+		limit int
+
+		wantItems []domain.FeedItem
+		wantErr   error
+	}{
+		{
+			name: "合并收件箱和发件箱",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository,
+				repository.FollowRepository) {
+				followRepo := repomocks.NewMockFollowRepository(ctrl)
+				followRepo.EXPECT().GetFollowees(gomock.Any(), int64(1), 0, maxPageSize).
+					Return([]domain.FollowRelation{
+						{Follower: 1, Followee: 100},
+						{Follower: 1, Followee: 200},
+					}, nil)
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().FindPushItems(gomock.Any(), int64(1), domain.FeedCursor{}, 3).
+					Return([]domain.FeedItem{feedItem(5, 500), feedItem(3, 300), feedItem(2, 300)}, nil)
+				repo.EXPECT().FindPullItems(gomock.Any(), []int64{100, 200}, domain.FeedCursor{}, 3).
+					Return([]domain.FeedItem{feedItem(4, 400), feedItem(6, 300)}, nil)
+				return repo, followRepo
+			},
+			limit: 3,
+			// 同一时间发表的，ID 大的在前面
+			wantItems: []domain.FeedItem{feedItem(5, 500), feedItem(4, 400), feedItem(6, 300)},
+		},
+		{
+			name: "没有关注任何人",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository,
+				repository.FollowRepository) {
+				followRepo := repomocks.NewMockFollowRepository(ctrl)
+				followRepo.EXPECT().GetFollowees(gomock.Any(), int64(1), 0, maxPageSize).
+					Return(nil, nil)
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().FindPushItems(gomock.Any(), int64(1), domain.FeedCursor{}, 3).
+					Return(nil, nil)
+				repo.EXPECT().FindPullItems(gomock.Any(), []int64{}, domain.FeedCursor{}, 3).
+					Return(nil, nil)
+				return repo, followRepo
+			},
+			limit:     3,
+			wantItems: []domain.FeedItem{},
+		},
+		{
+			name: "查询收件箱失败",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository,
+				repository.FollowRepository) {
+				followRepo := repomocks.NewMockFollowRepository(ctrl)
+				followRepo.EXPECT().GetFollowees(gomock.Any(), int64(1), 0, maxPageSize).
+					Return(nil, nil)
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().FindPushItems(gomock.Any(), int64(1), domain.FeedCursor{}, 3).
+					Return(nil, errors.New("mock db error"))
+				repo.EXPECT().FindPullItems(gomock.Any(), gomock.Any(), domain.FeedCursor{}, 3).
+					Return(nil, nil)
+				return repo, followRepo
+			},
+			limit:   3,
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, followRepo := tc.mock(ctrl)
+			svc := NewFeedService(repo, followRepo, nil, &logger.NoOpLogger{}, 10)
+			items, err := svc.GetFeed(context.Background(), 1, domain.FeedCursor{}, tc.limit)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantItems, items)
+		})
+	}
+}
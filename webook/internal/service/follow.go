@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	events "github.com/xiaoshanjiang/my-geektime/webook/internal/events/follow"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+var (
+	ErrFollowSelf       = errors.New("不能关注自己")
+	ErrFolloweeNotFound = repository.ErrUserNotFound
+)
+
+type FollowService interface {
+	// Follow 关注，重复关注不会报错
+	Follow(ctx context.Context, follower, followee int64) error
+	// CancelFollow 取消关注，没有关注过也不会报错
+	CancelFollow(ctx context.Context, follower, followee int64) error
+	// GetFollowers 粉丝列表
+	GetFollowers(ctx context.Context, uid int64, offset, limit int) ([]domain.FollowRelation, error)
+	// GetFollowees 关注列表
+	GetFollowees(ctx context.Context, uid int64, offset, limit int) ([]domain.FollowRelation, error)
+	// GetStatics 关注数和粉丝数
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+}
+
+type followService struct {
+	repo     repository.FollowRepository
+	userRepo repository.UserRepository
+	producer events.Producer
+	l        logger.LoggerV1
+}
+
+func NewFollowService(repo repository.FollowRepository,
+	userRepo repository.UserRepository,
+	producer events.Producer,
+	l logger.LoggerV1) FollowService {
+	return &followService{
+		repo:     repo,
+		userRepo: userRepo,
+		producer: producer,
+		l:        l,
+	}
+}
+
+func (f *followService) Follow(ctx context.Context, follower, followee int64) error {
+	if follower == followee {
+		return ErrFollowSelf
+	}
+	// 确认被关注的人是存在的
+	_, err := f.userRepo.FindById(ctx, followee)
+	if err != nil {
+		return err
+	}
+	changed, err := f.repo.Follow(ctx, follower, followee)
+	if err != nil || !changed {
+		return err
+	}
+	f.produce(events.FollowEvent{
+		Follower: follower,
+		Followee: followee,
+		Ctime:    time.Now().UnixMilli(),
+	})
+	return nil
+}
+
+func (f *followService) CancelFollow(ctx context.Context, follower, followee int64) error {
+	changed, err := f.repo.CancelFollow(ctx, follower, followee)
+	if err != nil || !changed {
+		return err
+	}
+	f.produce(events.FollowEvent{
+		Follower: follower,
+		Followee: followee,
+		Cancel:   true,
+		Ctime:    time.Now().UnixMilli(),
+	})
+	return nil
+}
+
+// produce 关注已经成功了，事件发送失败不影响用户
+func (f *followService) produce(evt events.FollowEvent) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		er := f.producer.ProduceFollowEvent(ctx, evt)
+		if er != nil {
+			f.l.Error("发送关注事件失败",
+				logger.Int64("follower", evt.Follower),
+				logger.Int64("followee", evt.Followee),
+				logger.Error(er))
+		}
+	}()
+}
+
+func (f *followService) GetFollowers(ctx context.Context,
+	uid int64, offset, limit int) ([]domain.FollowRelation, error) {
+	if limit <= 0 || limit > maxPageSize {
+		limit = maxPageSize
+	}
+	return f.repo.GetFollowers(ctx, uid, offset, limit)
+}
+
+func (f *followService) GetFollowees(ctx context.Context,
+	uid int64, offset, limit int) ([]domain.FollowRelation, error) {
+	if limit <= 0 || limit > maxPageSize {
+		limit = maxPageSize
+	}
+	return f.repo.GetFollowees(ctx, uid, offset, limit)
+}
+
+func (f *followService) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	return f.repo.GetStatics(ctx, uid)
+}
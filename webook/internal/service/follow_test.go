@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	repomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/mocks"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+func Test_followService_Follow(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.FollowRepository,
+			repository.UserRepository)
+
+		follower int64
+		followee int64
+
+		wantErr error
+	}{
+		{
+			// 关系没有变化，所以也不会发送事件
+			name: "已经关注过了",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository,
+				repository.UserRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(2)).
+					Return(domain.User{Id: 2}, nil)
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().Follow(gomock.Any(), int64(1), int64(2)).
+					Return(false, nil)
+				return repo, userRepo
+			},
+			follower: 1,
+			followee: 2,
+		},
+		{
+			name: "关注自己",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository,
+				repository.UserRepository) {
+				return repomocks.NewMockFollowRepository(ctrl),
+					repomocks.NewMockUserRepository(ctrl)
+			},
+			follower: 1,
+			followee: 1,
+			wantErr:  ErrFollowSelf,
+		},
+		{
+			name: "被关注的人不存在",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository,
+				repository.UserRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(2)).
+					Return(domain.User{}, repository.ErrUserNotFound)
+				return repomocks.NewMockFollowRepository(ctrl), userRepo
+			},
+			follower: 1,
+			followee: 2,
+			wantErr:  ErrFolloweeNotFound,
+		},
+		{
+			name: "数据库错误",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository,
+				repository.UserRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(2)).
+					Return(domain.User{Id: 2}, nil)
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().Follow(gomock.Any(), int64(1), int64(2)).
+					Return(false, errors.New("mock db error"))
+				return repo, userRepo
+			},
+			follower: 1,
+			followee: 2,
+			wantErr:  errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, userRepo := tc.mock(ctrl)
+			svc := NewFollowService(repo, userRepo, nil, &logger.NoOpLogger{})
+			err := svc.Follow(context.Background(), tc.follower, tc.followee)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+// HistoryService 我的阅读记录和我的点赞，目前只有文章。
+// 阅读记录是 HistoryReadEventConsumer 根据阅读事件写进去的
+type HistoryService interface {
+	// ListReadHistory 阅读记录，最近阅读的在前面，过了保留期限的不返回
+	ListReadHistory(ctx context.Context, uid int64,
+		cursor domain.UserBizCursor, limit int) ([]domain.UserBizRecord, error)
+	ClearReadHistory(ctx context.Context, uid int64) error
+	// ListLiked 点赞过的文章，最近点赞的在前面
+	ListLiked(ctx context.Context, uid int64,
+		cursor domain.UserBizCursor, limit int) ([]domain.UserBizRecord, error)
+}
+
+type historyService struct {
+	repo     repository.ReadHistoryRepository
+	intrRepo repository.InteractiveRepository
+	artRepo  article.ArticleRepository
+	l        logger.LoggerV1
+	// 阅读记录保留多久，过期的记录由 ReadHistoryCleanJob 删除
+	retention time.Duration
+}
+
+func NewHistoryService(repo repository.ReadHistoryRepository,
+	intrRepo repository.InteractiveRepository,
+	artRepo article.ArticleRepository,
+	l logger.LoggerV1, retention time.Duration) HistoryService {
+	return &historyService{
+		repo:      repo,
+		intrRepo:  intrRepo,
+		artRepo:   artRepo,
+		l:         l,
+		retention: retention,
+	}
+}
+
+func (s *historyService) ListReadHistory(ctx context.Context, uid int64,
+	cursor domain.UserBizCursor, limit int) ([]domain.UserBizRecord, error) {
+	// 清理任务是定时跑的，还没来得及删除的过期记录也不能展示
+	since := time.Now().Add(-s.retention)
+	hs, err := s.repo.List(ctx, uid, domain.BizArrticle, since, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.fillArticles(ctx, hs)
+}
+
+func (s *historyService) ClearReadHistory(ctx context.Context, uid int64) error {
+	return s.repo.Clear(ctx, uid)
+}
+
+func (s *historyService) ListLiked(ctx context.Context, uid int64,
+	cursor domain.UserBizCursor, limit int) ([]domain.UserBizRecord, error) {
+	likes, err := s.intrRepo.ListLiked(ctx, domain.BizArrticle, uid, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.fillArticles(ctx, likes)
+}
+
+// fillArticles 带上文章的标题和摘要。
+// 撤回了的文章也保留在列表里面，只是没有标题，不然游标就对不上了
+func (s *historyService) fillArticles(ctx context.Context,
+	records []domain.UserBizRecord) ([]domain.UserBizRecord, error) {
+	if len(records) == 0 {
+		return records, nil
+	}
+	aids := make([]int64, 0, len(records))
+	for _, r := range records {
+		aids = append(aids, r.BizId)
+	}
+	arts, err := s.artRepo.GetPubByIds(ctx, aids)
+	if err != nil {
+		return nil, err
+	}
+	artMap := make(map[int64]domain.Article, len(arts))
+	for _, art := range arts {
+		artMap[art.Id] = art
+	}
+	for i := range records {
+		art, ok := artMap[records[i].BizId]
+		if !ok {
+			continue
+		}
+		records[i].Title = art.Title
+		records[i].Abstract = art.Abstract()
+	}
+	return records, nil
+}
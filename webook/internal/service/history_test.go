@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
+	artrepomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article/mocks"
+	repomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/mocks"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+func Test_historyService_ListReadHistory(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	cursor := domain.UserBizCursor{Utime: now.UnixMilli(), BizId: 10}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.ReadHistoryRepository,
+			article.ArticleRepository)
+
+		wantRecords []domain.UserBizRecord
+		wantErr     error
+	}{
+		{
+			// 12 撤回了，保留在列表里面，但是没有标题
+			name: "带上标题和摘要",
+			mock: func(ctrl *gomock.Controller) (repository.ReadHistoryRepository,
+				article.ArticleRepository) {
+				repo := repomocks.NewMockReadHistoryRepository(ctrl)
+				repo.EXPECT().List(gomock.Any(), int64(1), domain.BizArrticle,
+					gomock.Any(), cursor, 2).
+					DoAndReturn(func(ctx context.Context, uid int64, biz string, since time.Time,
+						cursor domain.UserBizCursor, limit int) ([]domain.UserBizRecord, error) {
+						// 只查保留期限之内的
+						assert.WithinDuration(t, time.Now().Add(-time.Hour), since, time.Second)
+						return []domain.UserBizRecord{
+							{Uid: 1, Biz: domain.BizArrticle, BizId: 11, Utime: now},
+							{Uid: 1, Biz: domain.BizArrticle, BizId: 12, Utime: now},
+						}, nil
+					})
+				artRepo := artrepomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubByIds(gomock.Any(), []int64{11, 12}).
+					Return([]domain.Article{{Id: 11, Title: "标题", Content: "内容"}}, nil)
+				return repo, artRepo
+			},
+			wantRecords: []domain.UserBizRecord{
+				{Uid: 1, Biz: domain.BizArrticle, BizId: 11, Utime: now, Title: "标题", Abstract: "内容"},
+				{Uid: 1, Biz: domain.BizArrticle, BizId: 12, Utime: now},
+			},
+		},
+		{
+			name: "没有阅读记录",
+			mock: func(ctrl *gomock.Controller) (repository.ReadHistoryRepository,
+				article.ArticleRepository) {
+				repo := repomocks.NewMockReadHistoryRepository(ctrl)
+				repo.EXPECT().List(gomock.Any(), int64(1), domain.BizArrticle,
+					gomock.Any(), cursor, 2).
+					Return([]domain.UserBizRecord{}, nil)
+				return repo, artrepomocks.NewMockArticleRepository(ctrl)
+			},
+			wantRecords: []domain.UserBizRecord{},
+		},
+		{
+			name: "查询文章失败",
+			mock: func(ctrl *gomock.Controller) (repository.ReadHistoryRepository,
+				article.ArticleRepository) {
+				repo := repomocks.NewMockReadHistoryRepository(ctrl)
+				repo.EXPECT().List(gomock.Any(), int64(1), domain.BizArrticle,
+					gomock.Any(), cursor, 2).
+					Return([]domain.UserBizRecord{{Uid: 1, Biz: domain.BizArrticle, BizId: 11}}, nil)
+				artRepo := artrepomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubByIds(gomock.Any(), []int64{11}).
+					Return(nil, errors.New("mock db error"))
+				return repo, artRepo
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artRepo := tc.mock(ctrl)
+			svc := NewHistoryService(repo, repomocks.NewMockInteractiveRepository(ctrl),
+				artRepo, &logger.NoOpLogger{}, time.Hour)
+			records, err := svc.ListReadHistory(context.Background(), 1, cursor, 2)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRecords, records)
+		})
+	}
+}
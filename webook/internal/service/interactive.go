@@ -20,11 +20,16 @@ type InteractiveService interface {
 	// cid 不一定有，或者说 0 对应的是该用户的默认收藏夹
 	Collect(ctx context.Context, biz string, bizId, cid, uid int64) error
 	Get(ctx context.Context, biz string, bizId, uid int64) (domain.Interactive, error)
+	// GetByIds 给列表页用的批量查询，key 是 bizId，每个 bizId 都有结果。
+	// uid 为 0 的时候（没有登录）不查点赞和收藏。评论数不在这里面
+	GetByIds(ctx context.Context, biz string, bizIds []int64, uid int64) (map[int64]domain.Interactive, error)
 }
 
 type interactiveService struct {
 	repo repository.InteractiveRepository
-	l    logger.LoggerV1
+	// 评论数在评论模块里面维护，这里只是合并进来
+	commentRepo repository.CommentRepository
+	l           logger.LoggerV1
 }
 
 func (i *interactiveService) IncrReadCnt(ctx context.Context, biz string, bizId int64) error {
@@ -35,10 +40,11 @@ func (i *interactiveService) Get(ctx context.Context,
 	biz string, bizId, uid int64) (domain.Interactive, error) {
 	// 按照 repository 的语义(完成 domain.Interactive 的完整构造)，你这里拿到的就应该是包含全部字段的
 	var (
-		eg        errgroup.Group
-		intr      domain.Interactive
-		liked     bool
-		collected bool
+		eg         errgroup.Group
+		intr       domain.Interactive
+		liked      bool
+		collected  bool
+		commentCnt int64
 	)
 	eg.Go(func() error {
 		var err error
@@ -52,7 +58,12 @@ func (i *interactiveService) Get(ctx context.Context,
 	})
 	eg.Go(func() error {
 		var err error
-		liked, err = i.repo.Collected(ctx, biz, bizId, uid)
+		collected, err = i.repo.Collected(ctx, biz, bizId, uid)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		commentCnt, err = i.commentRepo.Count(ctx, biz, bizId)
 		return err
 	})
 	err := eg.Wait()
@@ -61,9 +72,49 @@ func (i *interactiveService) Get(ctx context.Context,
 	}
 	intr.Liked = liked
 	intr.Collected = collected
+	intr.CommentCnt = commentCnt
 	return intr, err
 }
 
+func (i *interactiveService) GetByIds(ctx context.Context,
+	biz string, bizIds []int64, uid int64) (map[int64]domain.Interactive, error) {
+	var (
+		eg        errgroup.Group
+		intrs     map[int64]domain.Interactive
+		liked     map[int64]bool
+		collected map[int64]bool
+	)
+	eg.Go(func() error {
+		var err error
+		intrs, err = i.repo.BatchGet(ctx, biz, bizIds)
+		return err
+	})
+	if uid > 0 {
+		eg.Go(func() error {
+			var err error
+			liked, err = i.repo.BatchLiked(ctx, biz, uid, bizIds)
+			return err
+		})
+		eg.Go(func() error {
+			var err error
+			collected, err = i.repo.BatchCollected(ctx, biz, uid, bizIds)
+			return err
+		})
+	}
+	err := eg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]domain.Interactive, len(bizIds))
+	for _, bizId := range bizIds {
+		intr := intrs[bizId]
+		intr.Liked = liked[bizId]
+		intr.Collected = collected[bizId]
+		res[bizId] = intr
+	}
+	return res, nil
+}
+
 func (i *interactiveService) Like(ctx context.Context, biz string, bizId int64, uid int64) error {
 	// 点赞
 	return i.repo.IncrLike(ctx, biz, bizId, uid)
@@ -82,9 +133,11 @@ func (i *interactiveService) Collect(ctx context.Context,
 }
 
 func NewInteractiveService(repo repository.InteractiveRepository,
+	commentRepo repository.CommentRepository,
 	l logger.LoggerV1) InteractiveService {
 	return &interactiveService{
-		repo: repo,
-		l:    l,
+		repo:        repo,
+		commentRepo: commentRepo,
+		l:           l,
 	}
 }
@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/service/history.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/service/history.go -package=svcmocks -destination=./webook/internal/service/mocks/history.mock.go
+//
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockHistoryService is a mock of HistoryService interface.
+type MockHistoryService struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistoryServiceMockRecorder
+}
+
+// MockHistoryServiceMockRecorder is the mock recorder for MockHistoryService.
+type MockHistoryServiceMockRecorder struct {
+	mock *MockHistoryService
+}
+
+// NewMockHistoryService creates a new mock instance.
+func NewMockHistoryService(ctrl *gomock.Controller) *MockHistoryService {
+	mock := &MockHistoryService{ctrl: ctrl}
+	mock.recorder = &MockHistoryServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHistoryService) EXPECT() *MockHistoryServiceMockRecorder {
+	return m.recorder
+}
+
+// ClearReadHistory mocks base method.
+func (m *MockHistoryService) ClearReadHistory(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearReadHistory", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearReadHistory indicates an expected call of ClearReadHistory.
+func (mr *MockHistoryServiceMockRecorder) ClearReadHistory(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearReadHistory", reflect.TypeOf((*MockHistoryService)(nil).ClearReadHistory), ctx, uid)
+}
+
+// ListLiked mocks base method.
+func (m *MockHistoryService) ListLiked(ctx context.Context, uid int64, cursor domain.UserBizCursor, limit int) ([]domain.UserBizRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLiked", ctx, uid, cursor, limit)
+	ret0, _ := ret[0].([]domain.UserBizRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLiked indicates an expected call of ListLiked.
+func (mr *MockHistoryServiceMockRecorder) ListLiked(ctx, uid, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLiked", reflect.TypeOf((*MockHistoryService)(nil).ListLiked), ctx, uid, cursor, limit)
+}
+
+// ListReadHistory mocks base method.
+func (m *MockHistoryService) ListReadHistory(ctx context.Context, uid int64, cursor domain.UserBizCursor, limit int) ([]domain.UserBizRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReadHistory", ctx, uid, cursor, limit)
+	ret0, _ := ret[0].([]domain.UserBizRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReadHistory indicates an expected call of ListReadHistory.
+func (mr *MockHistoryServiceMockRecorder) ListReadHistory(ctx, uid, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReadHistory", reflect.TypeOf((*MockHistoryService)(nil).ListReadHistory), ctx, uid, cursor, limit)
+}
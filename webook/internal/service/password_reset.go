@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service/email"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+var (
+	ErrPasswordResetTooMany = errors.New("重置密码的邮件发送太频繁")
+	ErrInvalidResetToken    = repository.ErrResetTokenNotFound
+)
+
+// PasswordResetService 忘记密码的时候，通过邮件里面的链接重置密码
+type PasswordResetService interface {
+	// SendResetLink 邮箱没有注册也返回 nil，不能让人借这个接口试探哪些邮箱注册了
+	SendResetLink(ctx context.Context, email string) error
+	// Reset 校验链接里面的 token 并且修改密码，返回用户 ID。
+	// password 是明文，调用者要先校验密码的格式
+	Reset(ctx context.Context, token, password string) (int64, error)
+}
+
+type emailPasswordResetService struct {
+	userRepo repository.UserRepository
+	repo     repository.PasswordResetRepository
+	email    email.Service
+	l        logger.LoggerV1
+	// 邮件里面的链接，后面会带上 token 参数，一般是前端重置密码的页面
+	link string
+	// 链接多久过期
+	expiration time.Duration
+}
+
+func NewEmailPasswordResetService(userRepo repository.UserRepository,
+	repo repository.PasswordResetRepository,
+	emailSvc email.Service, l logger.LoggerV1,
+	link string, expiration time.Duration) PasswordResetService {
+	return &emailPasswordResetService{
+		userRepo:   userRepo,
+		repo:       repo,
+		email:      emailSvc,
+		l:          l,
+		link:       link,
+		expiration: expiration,
+	}
+}
+
+func (s *emailPasswordResetService) SendResetLink(ctx context.Context, email string) error {
+	// 先限流再查用户，不然注册了的邮箱会提示太频繁，没注册的不会
+	ok, err := s.repo.LockSend(ctx, email)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPasswordResetTooMany
+	}
+	u, err := s.userRepo.FindByEmail(ctx, email)
+	if err == repository.ErrUserNotFound {
+		s.l.Info("重置密码的邮箱没有注册")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = s.send(ctx, u.Id, email)
+	if err != nil {
+		// 和没有注册的邮箱返回一样的结果，不然看响应就知道这个邮箱注册了
+		s.l.Error("发送重置密码的邮件失败",
+			logger.Int64("uid", u.Id),
+			logger.Error(err))
+	}
+	return nil
+}
+
+// send 生成 token 并且发送带链接的邮件
+func (s *emailPasswordResetService) send(ctx context.Context, uid int64, email string) error {
+	token, err := s.generateToken()
+	if err != nil {
+		return err
+	}
+	err = s.repo.Store(ctx, token, uid, s.expiration)
+	if err != nil {
+		return err
+	}
+	link, err := s.resetLink(token)
+	if err != nil {
+		return err
+	}
+	content := fmt.Sprintf("你正在重置 webook 的密码，请在 %d 分钟内打开下面的链接设置新密码，链接只能使用一次：\n%s\n"+
+		"如果不是你本人的操作，请忽略这封邮件。", int(s.expiration.Minutes()), link)
+	return s.email.Send(ctx, "重置 webook 密码", content, email)
+}
+
+func (s *emailPasswordResetService) Reset(ctx context.Context, token, password string) (int64, error) {
+	// 不管后面成功没有，链接都已经用掉了
+	uid, err := s.repo.Consume(ctx, token)
+	if err != nil {
+		return 0, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, err
+	}
+	err = s.userRepo.UpdatePassword(ctx, uid, string(hash))
+	if err != nil {
+		return 0, err
+	}
+	return uid, nil
+}
+
+// generateToken 256 位的随机数，猜不出来
+func (s *emailPasswordResetService) generateToken() (string, error) {
+	buf := make([]byte, 32)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func (s *emailPasswordResetService) resetLink(token string) (string, error) {
+	u, err := url.Parse(s.link)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	repomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/mocks"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service/email"
+	emailmocks "github.com/xiaoshanjiang/my-geektime/webook/internal/service/email/mocks"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+var resetLinkRegexp = regexp.MustCompile(`http://localhost:3000/users/password/reset\?token=\S+`)
+
+func Test_emailPasswordResetService_SendResetLink(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.UserRepository,
+			repository.PasswordResetRepository, email.Service)
+
+		wantErr error
+	}{
+		{
+			// 邮件里面的链接带的 token 就是存起来的 token
+			name: "发送成功",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository,
+				repository.PasswordResetRepository, email.Service) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				repo := repomocks.NewMockPasswordResetRepository(ctrl)
+				emailSvc := emailmocks.NewMockService(ctrl)
+				repo.EXPECT().LockSend(gomock.Any(), "123@qq.com").Return(true, nil)
+				userRepo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 123, Email: "123@qq.com"}, nil)
+				var token string
+				repo.EXPECT().Store(gomock.Any(), gomock.Any(), int64(123), time.Minute*30).
+					DoAndReturn(func(ctx context.Context, tk string, uid int64, expiration time.Duration) error {
+						token = tk
+						return nil
+					})
+				emailSvc.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), "123@qq.com").
+					DoAndReturn(func(ctx context.Context, subject, content string, to ...string) error {
+						link, err := url.Parse(resetLinkRegexp.FindString(content))
+						require.NoError(t, err)
+						assert.NotEmpty(t, token)
+						assert.Equal(t, token, link.Query().Get("token"))
+						return nil
+					})
+				return userRepo, repo, emailSvc
+			},
+		},
+		{
+			// 不能让人知道这个邮箱没有注册
+			name: "邮箱没有注册",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository,
+				repository.PasswordResetRepository, email.Service) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				repo := repomocks.NewMockPasswordResetRepository(ctrl)
+				repo.EXPECT().LockSend(gomock.Any(), "123@qq.com").Return(true, nil)
+				userRepo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{}, repository.ErrUserNotFound)
+				return userRepo, repo, emailmocks.NewMockService(ctrl)
+			},
+		},
+		{
+			// 没有注册的邮箱也一样限流，所以先限流再查用户
+			name: "发送太频繁",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository,
+				repository.PasswordResetRepository, email.Service) {
+				repo := repomocks.NewMockPasswordResetRepository(ctrl)
+				repo.EXPECT().LockSend(gomock.Any(), "123@qq.com").Return(false, nil)
+				return repomocks.NewMockUserRepository(ctrl), repo, emailmocks.NewMockService(ctrl)
+			},
+			wantErr: ErrPasswordResetTooMany,
+		},
+		{
+			// 和没有注册的邮箱一样返回 nil，只记录日志
+			name: "发送失败",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository,
+				repository.PasswordResetRepository, email.Service) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				repo := repomocks.NewMockPasswordResetRepository(ctrl)
+				emailSvc := emailmocks.NewMockService(ctrl)
+				repo.EXPECT().LockSend(gomock.Any(), "123@qq.com").Return(true, nil)
+				userRepo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 123, Email: "123@qq.com"}, nil)
+				repo.EXPECT().Store(gomock.Any(), gomock.Any(), int64(123), time.Minute*30).Return(nil)
+				emailSvc.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), "123@qq.com").
+					Return(errors.New("mock email error"))
+				return userRepo, repo, emailSvc
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userRepo, repo, emailSvc := tc.mock(ctrl)
+			svc := NewEmailPasswordResetService(userRepo, repo, emailSvc, &logger.NoOpLogger{},
+				"http://localhost:3000/users/password/reset", time.Minute*30)
+			err := svc.SendResetLink(context.Background(), "123@qq.com")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_emailPasswordResetService_Reset(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.UserRepository,
+			repository.PasswordResetRepository)
+
+		wantUid int64
+		wantErr error
+	}{
+		{
+			name: "重置成功",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository,
+				repository.PasswordResetRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				repo := repomocks.NewMockPasswordResetRepository(ctrl)
+				repo.EXPECT().Consume(gomock.Any(), "token-1").Return(int64(123), nil)
+				userRepo.EXPECT().UpdatePassword(gomock.Any(), int64(123), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, id int64, password string) error {
+						// 存的是加密之后的密码
+						return bcrypt.CompareHashAndPassword([]byte(password), []byte("hello#world123"))
+					})
+				return userRepo, repo
+			},
+			wantUid: 123,
+		},
+		{
+			// 用过了或者过期了
+			name: "链接已经失效",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository,
+				repository.PasswordResetRepository) {
+				repo := repomocks.NewMockPasswordResetRepository(ctrl)
+				repo.EXPECT().Consume(gomock.Any(), "token-1").
+					Return(int64(0), repository.ErrResetTokenNotFound)
+				return repomocks.NewMockUserRepository(ctrl), repo
+			},
+			wantErr: ErrInvalidResetToken,
+		},
+		{
+			name: "修改密码失败",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository,
+				repository.PasswordResetRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				repo := repomocks.NewMockPasswordResetRepository(ctrl)
+				repo.EXPECT().Consume(gomock.Any(), "token-1").Return(int64(123), nil)
+				userRepo.EXPECT().UpdatePassword(gomock.Any(), int64(123), gomock.Any()).
+					Return(errors.New("mock db error"))
+				return userRepo, repo
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userRepo, repo := tc.mock(ctrl)
+			svc := NewEmailPasswordResetService(userRepo, repo, emailmocks.NewMockService(ctrl),
+				&logger.NoOpLogger{}, "http://localhost:3000/users/password/reset", time.Minute*30)
+			uid, err := svc.Reset(context.Background(), "token-1", "hello#world123")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantUid, uid)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/ecodeclub/ekit/queue"
+	"github.com/ecodeclub/ekit/slice"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
+)
+
+// RankingService 热榜
+// 热度 = (点赞 * 权重 + 阅读 * 权重 + 收藏 * 权重 + 1) / (发表了多少小时 + 2) ^ gravity
+// 越新的文章、互动越多的文章越靠前，随着时间推移热度会衰减
+type RankingService interface {
+	// TopN 重新计算热榜，并且替换掉缓存里面的
+	TopN(ctx context.Context) error
+	GetTopN(ctx context.Context) ([]domain.Article, error)
+}
+
+type rankingService struct {
+	artRepo  article.ArticleRepository
+	intrRepo repository.InteractiveRepository
+	repo     repository.RankingRepository
+
+	// 一次从线上库读多少篇文章
+	batchSize int
+	// 热榜保留多少篇
+	n int
+	// 太久之前发表的文章不参与计算
+	window time.Duration
+
+	likeWeight    float64
+	readWeight    float64
+	collectWeight float64
+	gravity       float64
+	// 方便测试
+	now func() time.Time
+}
+
+func NewRankingService(artRepo article.ArticleRepository,
+	intrRepo repository.InteractiveRepository,
+	repo repository.RankingRepository) RankingService {
+	return &rankingService{
+		artRepo:       artRepo,
+		intrRepo:      intrRepo,
+		repo:          repo,
+		batchSize:     100,
+		n:             100,
+		window:        time.Hour * 24 * 7,
+		likeWeight:    3,
+		readWeight:    1,
+		collectWeight: 5,
+		gravity:       1.5,
+		now:           time.Now,
+	}
+}
+
+func (b *rankingService) GetTopN(ctx context.Context) ([]domain.Article, error) {
+	return b.repo.GetTopN(ctx)
+}
+
+func (b *rankingService) TopN(ctx context.Context) error {
+	arts, err := b.topN(ctx)
+	if err != nil {
+		return err
+	}
+	return b.repo.ReplaceTopN(ctx, arts)
+}
+
+type rankingScore struct {
+	art   domain.Article
+	score float64
+}
+
+func (b *rankingService) topN(ctx context.Context) ([]domain.Article, error) {
+	now := b.now()
+	ddl := now.Add(-b.window)
+	// 小顶堆，堆顶是目前 TopN 里面分数最低的
+	topN := queue.NewConcurrentPriorityQueue[rankingScore](b.n,
+		func(src rankingScore, dst rankingScore) int {
+			if src.score > dst.score {
+				return 1
+			} else if src.score == dst.score {
+				return 0
+			}
+			return -1
+		})
+
+	var startId int64
+	for {
+		arts, err := b.artRepo.ListPub(ctx, startId, b.batchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(arts) == 0 {
+			break
+		}
+		startId = arts[len(arts)-1].Id
+		last := len(arts) < b.batchSize
+		// 太老的文章不用查互动数据。
+		// 线上库的 Ctime 是第一次发表的时间，修改文章不会更新它
+		arts = slice.FilterMap(arts, func(idx int, src domain.Article) (domain.Article, bool) {
+			return src, src.Ctime.After(ddl)
+		})
+		if len(arts) > 0 {
+			ids := slice.Map(arts, func(idx int, src domain.Article) int64 {
+				return src.Id
+			})
+			intrs, err := b.intrRepo.GetByIds(ctx, domain.BizArrticle, ids)
+			if err != nil {
+				return nil, err
+			}
+			for _, art := range arts {
+				cur := rankingScore{
+					art:   art,
+					score: b.score(now, art, intrs[art.Id]),
+				}
+				if topN.Len() < b.n {
+					_ = topN.Enqueue(cur)
+					continue
+				}
+				// 满了，比堆顶分数高才替换
+				minEle, _ := topN.Peek()
+				if minEle.score < cur.score {
+					_, _ = topN.Dequeue()
+					_ = topN.Enqueue(cur)
+				}
+			}
+		}
+		if last {
+			break
+		}
+	}
+	// 出队的顺序是分数从低到高，倒过来放
+	res := make([]domain.Article, topN.Len())
+	for i := len(res) - 1; i >= 0; i-- {
+		ele, _ := topN.Dequeue()
+		res[i] = ele.art
+	}
+	return res, nil
+}
+
+func (b *rankingService) score(now time.Time, art domain.Article, intr domain.Interactive) float64 {
+	// 按照发表时间衰减，修改老文章不能让它重新变"新"
+	hours := now.Sub(art.Ctime).Hours()
+	if hours < 0 {
+		hours = 0
+	}
+	weighted := float64(intr.LikeCnt)*b.likeWeight +
+		float64(intr.ReadCnt)*b.readWeight +
+		float64(intr.CollectCnt)*b.collectWeight
+	return (weighted + 1) / math.Pow(hours+2, b.gravity)
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
+	artrepomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article/mocks"
+	repomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/mocks"
+)
+
+func Test_rankingService_TopN(t *testing.T) {
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	art := func(id int64, hoursAgo int) domain.Article {
+		return domain.Article{Id: id, Ctime: now.Add(-time.Duration(hoursAgo) * time.Hour)}
+	}
+	// 很早以前发表，刚刚修改过的文章
+	edited := art(3, 1000)
+	edited.Utime = now
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (article.ArticleRepository,
+			repository.InteractiveRepository, repository.RankingRepository)
+
+		wantErr error
+	}{
+		{
+			name: "计算成功",
+			mock: func(ctrl *gomock.Controller) (article.ArticleRepository,
+				repository.InteractiveRepository, repository.RankingRepository) {
+				artRepo := artrepomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().ListPub(gomock.Any(), int64(0), 3).
+					Return([]domain.Article{art(1, 1), art(2, 1), edited}, nil)
+				artRepo.EXPECT().ListPub(gomock.Any(), int64(3), 3).
+					Return([]domain.Article{art(4, 1)}, nil)
+				intrRepo := repomocks.NewMockInteractiveRepository(ctrl)
+				// 3 号发表得太早了，刚修改过也不查
+				intrRepo.EXPECT().GetByIds(gomock.Any(), domain.BizArrticle, []int64{1, 2}).
+					Return(map[int64]domain.Interactive{
+						1: {LikeCnt: 1},
+						2: {LikeCnt: 10},
+					}, nil)
+				intrRepo.EXPECT().GetByIds(gomock.Any(), domain.BizArrticle, []int64{4}).
+					Return(map[int64]domain.Interactive{
+						4: {LikeCnt: 5},
+					}, nil)
+				repo := repomocks.NewMockRankingRepository(ctrl)
+				// 只保留两篇，热度高的在前面
+				repo.EXPECT().ReplaceTopN(gomock.Any(), []domain.Article{art(2, 1), art(4, 1)}).
+					Return(nil)
+				return artRepo, intrRepo, repo
+			},
+		},
+		{
+			name: "查询互动数据失败",
+			mock: func(ctrl *gomock.Controller) (article.ArticleRepository,
+				repository.InteractiveRepository, repository.RankingRepository) {
+				artRepo := artrepomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().ListPub(gomock.Any(), int64(0), 3).
+					Return([]domain.Article{art(1, 1)}, nil)
+				intrRepo := repomocks.NewMockInteractiveRepository(ctrl)
+				intrRepo.EXPECT().GetByIds(gomock.Any(), domain.BizArrticle, []int64{1}).
+					Return(nil, errors.New("mock db error"))
+				return artRepo, intrRepo, repomocks.NewMockRankingRepository(ctrl)
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			artRepo, intrRepo, repo := tc.mock(ctrl)
+			svc := NewRankingService(artRepo, intrRepo, repo).(*rankingService)
+			svc.batchSize = 3
+			svc.n = 2
+			svc.now = func() time.Time {
+				return now
+			}
+			err := svc.TopN(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
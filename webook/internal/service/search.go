@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/search"
+)
+
+var ErrSearchRebuilding = errors.New("搜索索引正在重建")
+
+const (
+	// rebuildBatchSize 重建索引的时候，一次从线上库读多少篇文章
+	rebuildBatchSize = 500
+	// snippetLead 摘要从第一次命中的位置往前多取几个字，保留一点上下文
+	snippetLead = 20
+
+	highlightPre  = "<em>"
+	highlightPost = "</em>"
+)
+
+// SearchService 公开文章的全文搜索，索引保存在进程内存里面
+// 每个实例都有自己的索引，启动的时候从线上库重建，
+// 之后每个实例都消费文章的发表、修改和撤回事件，调用 SyncArticle 更新自己的索引
+type SearchService interface {
+	SearchArticles(ctx context.Context, query string, offset, limit int) (domain.ArticleSearchResult, error)
+	// SyncArticle 按照线上库里面文章现在的状态更新索引，
+	// 已经发表的加入索引，撤回了或者不存在的从索引里面删除。
+	// 不依赖事件里面的数据，所以重复消费和乱序都没关系
+	SyncArticle(ctx context.Context, id int64) error
+	// IndexArticle 发表之后加入索引，已经存在就覆盖
+	IndexArticle(ctx context.Context, art domain.Article) error
+	// RemoveArticle 撤回之后从索引里面删除
+	RemoveArticle(ctx context.Context, id int64) error
+	// Rebuild 从线上库重建整个索引，返回索引了多少篇文章
+	// 重建期间搜索使用旧的索引，重建完成之后再替换
+	Rebuild(ctx context.Context) (int, error)
+}
+
+type articleSearchService struct {
+	repo article.ArticleRepository
+	l    logger.LoggerV1
+	idx  atomic.Pointer[search.Index]
+
+	// mu 保护下面两个字段，以及保证写索引和替换索引不会交错
+	mu sync.Mutex
+	// building 正在重建的索引，没有在重建的时候是 nil
+	building *search.Index
+	// touched 重建期间被发表或者撤回过的文章
+	// 这些文章以实时的变更为准，不再用扫描线上库读到的数据覆盖，因为那可能是旧数据
+	touched map[int64]struct{}
+}
+
+func NewSearchService(repo article.ArticleRepository, l logger.LoggerV1) SearchService {
+	svc := &articleSearchService{
+		repo: repo,
+		l:    l,
+	}
+	svc.idx.Store(search.NewIndex())
+	return svc
+}
+
+func (s *articleSearchService) SearchArticles(ctx context.Context,
+	query string, offset, limit int) (domain.ArticleSearchResult, error) {
+	if limit <= 0 || limit > maxPageSize {
+		limit = maxPageSize
+	}
+	res := s.idx.Load().Search(query, offset, limit)
+	hits := make([]domain.ArticleSearchHit, 0, len(res.Hits))
+	for _, h := range res.Hits {
+		art := domain.Article{
+			Id:      h.Doc.Id,
+			Title:   h.Doc.Title,
+			Content: h.Doc.Content,
+			Utime:   time.UnixMilli(h.Doc.Utime),
+		}
+		hits = append(hits, domain.ArticleSearchHit{
+			Article: art,
+			Score:   h.Score,
+			Title:   search.Highlight(art.Title, res.Terms, highlightPre, highlightPost),
+			Snippet: snippet(art, res.Terms),
+		})
+	}
+	return domain.ArticleSearchResult{
+		Total: res.Total,
+		Hits:  hits,
+	}, nil
+}
+
+// snippet 从第一次命中的地方开始取摘要，并且高亮
+// 只有标题命中的时候，就用文章开头的摘要
+func snippet(art domain.Article, terms []string) string {
+	pos := search.FirstMatch(art.Content, terms)
+	if pos <= snippetLead {
+		abstract := art.Abstract()
+		res := search.Highlight(abstract, terms, highlightPre, highlightPost)
+		if len(abstract) < len(art.Content) {
+			res += "…"
+		}
+		return res
+	}
+	cs := []rune(art.Content)
+	start := pos - snippetLead
+	abstract := domain.Article{Content: string(cs[start:])}.Abstract()
+	res := "…" + search.Highlight(abstract, terms, highlightPre, highlightPost)
+	if start+len([]rune(abstract)) < len(cs) {
+		res += "…"
+	}
+	return res
+}
+
+func (s *articleSearchService) SyncArticle(ctx context.Context, id int64) error {
+	// 索引用不到作者，所以不用 GetPublishedById，撤回之后仅自己可见的文章查不出来
+	arts, err := s.repo.GetPubByIds(ctx, []int64{id})
+	if err != nil {
+		return err
+	}
+	if len(arts) == 0 {
+		return s.RemoveArticle(ctx, id)
+	}
+	return s.IndexArticle(ctx, arts[0])
+}
+
+func (s *articleSearchService) IndexArticle(ctx context.Context, art domain.Article) error {
+	doc := search.Document{
+		Id:      art.Id,
+		Title:   art.Title,
+		Content: art.Content,
+		Utime:   art.Utime.UnixMilli(),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.idx.Load().Upsert(doc)
+	if s.building != nil {
+		s.building.Upsert(doc)
+		s.touched[art.Id] = struct{}{}
+	}
+	return nil
+}
+
+func (s *articleSearchService) RemoveArticle(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.idx.Load().Remove(id)
+	if s.building != nil {
+		s.building.Remove(id)
+		s.touched[id] = struct{}{}
+	}
+	return nil
+}
+
+func (s *articleSearchService) Rebuild(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	if s.building != nil {
+		s.mu.Unlock()
+		return 0, ErrSearchRebuilding
+	}
+	building := search.NewIndex()
+	s.building = building
+	s.touched = make(map[int64]struct{})
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.building = nil
+		s.touched = nil
+		s.mu.Unlock()
+	}()
+
+	var startId int64
+	for {
+		arts, err := s.repo.ListPub(ctx, startId, rebuildBatchSize)
+		if err != nil {
+			return 0, err
+		}
+		s.mu.Lock()
+		for _, art := range arts {
+			if _, ok := s.touched[art.Id]; ok {
+				continue
+			}
+			building.Upsert(search.Document{
+				Id:      art.Id,
+				Title:   art.Title,
+				Content: art.Content,
+				Utime:   art.Utime.UnixMilli(),
+			})
+		}
+		s.mu.Unlock()
+		if len(arts) < rebuildBatchSize {
+			break
+		}
+		startId = arts[len(arts)-1].Id
+	}
+
+	s.mu.Lock()
+	s.idx.Store(building)
+	s.mu.Unlock()
+	return building.Len(), nil
+}
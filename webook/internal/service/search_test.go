@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
+	artrepomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article/mocks"
+	artdao "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao/article"
+	artdaomocks "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao/article/mocks"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+func Test_articleSearchService_Rebuild(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) article.ArticleRepository
+
+		wantErr error
+		wantCnt int
+		// 重建之后搜索“消息队列”命中的文章
+		wantIds []int64
+	}{
+		{
+			name: "分批重建",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				first := make([]domain.Article, rebuildBatchSize)
+				for i := range first {
+					first[i] = domain.Article{Id: int64(i + 1), Title: "无关的文章"}
+				}
+				first[0] = domain.Article{Id: 1, Title: "Kafka 消息队列"}
+				repo.EXPECT().ListPub(gomock.Any(), int64(0), rebuildBatchSize).
+					Return(first, nil)
+				repo.EXPECT().ListPub(gomock.Any(), int64(rebuildBatchSize), rebuildBatchSize).
+					Return([]domain.Article{
+						{Id: 1000, Title: "Redis", Content: "也可以用作消息队列"},
+					}, nil)
+				return repo
+			},
+			wantCnt: rebuildBatchSize + 1,
+			wantIds: []int64{1, 1000},
+		},
+		{
+			name: "查询失败，保留旧的索引",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().ListPub(gomock.Any(), int64(0), rebuildBatchSize).
+					Return(nil, errors.New("mock db error"))
+				return repo
+			},
+			wantErr: errors.New("mock db error"),
+			wantIds: []int64{99},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewSearchService(tc.mock(ctrl), &logger.NoOpLogger{})
+			// 旧的索引里面的数据
+			err := svc.IndexArticle(context.Background(), domain.Article{
+				Id: 99, Title: "旧的消息队列",
+			})
+			require.NoError(t, err)
+
+			cnt, err := svc.Rebuild(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCnt, cnt)
+			res, err := svc.SearchArticles(context.Background(), "消息队列", 0, 10)
+			require.NoError(t, err)
+			ids := make([]int64, 0, len(res.Hits))
+			for _, h := range res.Hits {
+				ids = append(ids, h.Article.Id)
+			}
+			assert.Equal(t, tc.wantIds, ids)
+		})
+	}
+}
+
+func Test_articleSearchService_SyncArticle(t *testing.T) {
+	testCases := []struct {
+		name string
+		// 文章用真实的 repository，只 mock DAO，这样能发现 repository 里面依赖没有注入的问题
+		mock func(ctrl *gomock.Controller) artdao.ArticleDAO
+		id   int64
+
+		wantErr error
+		// 同步之后搜索“消息队列”命中的文章
+		wantIds []int64
+	}{
+		{
+			name: "已经发表，覆盖旧的索引",
+			mock: func(ctrl *gomock.Controller) artdao.ArticleDAO {
+				d := artdaomocks.NewMockArticleDAO(ctrl)
+				d.EXPECT().GetPubByIds(gomock.Any(), []int64{1}).
+					Return([]artdao.PublishedArticle{
+						{Id: 1, Title: "Kafka 消息队列", Status: domain.ArticleStatusPublished.ToUint8()},
+					}, nil)
+				return d
+			},
+			id:      1,
+			wantIds: []int64{1, 99},
+		},
+		{
+			// 撤回了的文章和不存在的文章一样，都查不出来
+			name: "已经撤回，从索引里面删除",
+			mock: func(ctrl *gomock.Controller) artdao.ArticleDAO {
+				d := artdaomocks.NewMockArticleDAO(ctrl)
+				d.EXPECT().GetPubByIds(gomock.Any(), []int64{99}).
+					Return([]artdao.PublishedArticle{}, nil)
+				return d
+			},
+			id:      99,
+			wantIds: []int64{},
+		},
+		{
+			name: "查询失败，不动索引",
+			mock: func(ctrl *gomock.Controller) artdao.ArticleDAO {
+				d := artdaomocks.NewMockArticleDAO(ctrl)
+				d.EXPECT().GetPubByIds(gomock.Any(), []int64{99}).
+					Return(nil, errors.New("mock db error"))
+				return d
+			},
+			id:      99,
+			wantErr: errors.New("mock db error"),
+			wantIds: []int64{99},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := article.NewArticleRepository(tc.mock(ctrl), nil, &logger.NoOpLogger{})
+			svc := NewSearchService(repo, &logger.NoOpLogger{})
+			err := svc.IndexArticle(context.Background(), domain.Article{
+				Id: 99, Title: "旧的消息队列",
+			})
+			require.NoError(t, err)
+
+			err = svc.SyncArticle(context.Background(), tc.id)
+			assert.Equal(t, tc.wantErr, err)
+			res, err := svc.SearchArticles(context.Background(), "消息队列", 0, 10)
+			require.NoError(t, err)
+			ids := make([]int64, 0, len(res.Hits))
+			for _, h := range res.Hits {
+				ids = append(ids, h.Article.Id)
+			}
+			assert.Equal(t, tc.wantIds, ids)
+		})
+	}
+}
+
+func Test_snippet(t *testing.T) {
+	testCases := []struct {
+		name  string
+		art   domain.Article
+		terms []string
+		want  string
+	}{
+		{
+			name:  "开头命中",
+			art:   domain.Article{Content: "Kafka 是一个消息队列"},
+			terms: []string{"消息", "息队", "队列"},
+			want:  "Kafka 是一个<em>消息队列</em>",
+		},
+		{
+			name: "后面命中，从命中的地方截取",
+			art: domain.Article{Content: "这是一段很长很长的开头，和搜索的内容没有任何关系，" +
+				"这里才提到了 Kafka 的分区"},
+			terms: []string{"kafka"},
+			want:  "…和搜索的内容没有任何关系，这里才提到了 <em>Kafka</em> 的分区",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, snippet(tc.art, tc.terms))
+		})
+	}
+}
@@ -27,11 +27,13 @@ type ArticleHandler struct {
 }
 
 func NewArticleHandler(svc service.ArticleService,
+	intrSvc service.InteractiveService,
 	l logger.LoggerV1) *ArticleHandler {
 	return &ArticleHandler{
-		svc: svc,
-		l:   l,
-		biz: "article",
+		svc:     svc,
+		intrSvc: intrSvc,
+		l:       l,
+		biz:     domain.BizArrticle,
 	}
 }
 
@@ -53,6 +55,24 @@ func (h *ArticleHandler) RegisterRoutes(server *gin.Engine) {
 		ginx.WrapBodyAndToken[ListReq, ijwt.UserClaims](h.List))
 	g.GET("/detail/:id", ginx.WrapToken[ijwt.UserClaims](h.Detail))
 
+	// 定时发表
+	sg := g.Group("/scheduled")
+	sg.POST("/list",
+		ginx.WrapBodyAndToken[ListReq, ijwt.UserClaims](h.ListScheduled))
+	sg.POST("/reschedule",
+		ginx.WrapBodyAndToken[RescheduleReq, ijwt.UserClaims](h.Reschedule))
+	sg.POST("/cancel",
+		ginx.WrapBodyAndToken[CancelScheduleReq, ijwt.UserClaims](h.CancelSchedule))
+
+	// 历史版本
+	rev := g.Group("/revisions")
+	rev.POST("/list",
+		ginx.WrapBodyAndToken[RevisionListReq, ijwt.UserClaims](h.ListRevisions))
+	rev.POST("/diff",
+		ginx.WrapBodyAndToken[RevisionDiffReq, ijwt.UserClaims](h.DiffRevisions))
+	rev.POST("/restore",
+		ginx.WrapBodyAndToken[RevisionRestoreReq, ijwt.UserClaims](h.RestoreRevision))
+
 	pub := g.Group("/pub")
 	pub.GET("/:id", h.PubDetail, func(ctx *gin.Context) {
 		// 增加阅读计数。
@@ -72,10 +92,214 @@ func (h *ArticleHandler) RegisterRoutes(server *gin.Engine) {
 	//	ijwt.UserClaims](h.Like))
 	pub.POST("/like", ginx.WrapBodyAndToken[LikeReq,
 		ijwt.UserClaims](h.Like))
+
+	// 标签相关的接口不需要登录
+	pub.POST("/tag/list", ginx.WrapBody[TagListReq](h.l, h.ListByTag))
+	pub.POST("/tags/suggest", ginx.WrapBody[TagSuggestReq](h.l, h.SuggestTags))
+	pub.POST("/tags/counts", ginx.WrapBody[TagCountsReq](h.l, h.TagCounts))
 	//pub.POST("/cancel_like", ginx.WrapBodyAndToken[LikeReq,
 	//	ijwt.UserClaims](h.Like))
 }
 
+func (h *ArticleHandler) ListScheduled(ctx *gin.Context,
+	req ListReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	arts, err := h.svc.ListScheduled(ctx, uc.Id, req.Offset, req.Limit)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	return ginx.Result{
+		Data: slice.Map[domain.Article, ArticleVO](arts,
+			func(idx int, src domain.Article) ArticleVO {
+				return ArticleVO{
+					Id:        src.Id,
+					Title:     src.Title,
+					Abstract:  src.Abstract(),
+					Status:    src.Status.ToUint8(),
+					PublishAt: src.PublishAt.Format(time.DateTime),
+					Ctime:     src.Ctime.Format(time.DateTime),
+					Utime:     src.Utime.Format(time.DateTime),
+				}
+			}),
+	}, nil
+}
+
+func (h *ArticleHandler) Reschedule(ctx *gin.Context,
+	req RescheduleReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.Reschedule(ctx, uc.Id, req.Id, time.UnixMilli(req.PublishAt))
+	switch err {
+	case nil:
+		return ginx.Result{
+			Msg: "OK",
+		}, nil
+	case service.ErrInvalidPublishTime:
+		return ginx.Result{
+			Code: 4,
+			Msg:  "发表时间必须晚于当前时间",
+		}, nil
+	case service.ErrPossibleIncorrectAuthor:
+		// 不是本人的文章，或者文章已经发表了
+		return ginx.Result{
+			Code: 4,
+			Msg:  "输入有误",
+		}, err
+	default:
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+}
+
+func (h *ArticleHandler) CancelSchedule(ctx *gin.Context,
+	req CancelScheduleReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.CancelSchedule(ctx, uc.Id, req.Id)
+	switch err {
+	case nil:
+		return ginx.Result{
+			Msg: "OK",
+		}, nil
+	case service.ErrPossibleIncorrectAuthor:
+		return ginx.Result{
+			Code: 4,
+			Msg:  "输入有误",
+		}, err
+	default:
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+}
+
+func (h *ArticleHandler) ListByTag(ctx *gin.Context, req TagListReq) (ginx.Result, error) {
+	arts, err := h.svc.ListPubByTag(ctx, req.Tag, req.Offset, req.Limit)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	vos := slice.Map[domain.Article, ArticleVO](arts,
+		func(idx int, src domain.Article) ArticleVO {
+			return ArticleVO{
+				Id:       src.Id,
+				Title:    src.Title,
+				Abstract: src.Abstract(),
+				Category: src.Category,
+				Tags:     src.Tags,
+				Ctime:    src.Ctime.Format(time.DateTime),
+				Utime:    src.Utime.Format(time.DateTime),
+			}
+		})
+	// 不需要登录的接口，只带计数
+	return ginx.Result{
+		Data: withInteractive(ctx, h.intrSvc, h.l, 0, vos),
+	}, nil
+}
+
+func (h *ArticleHandler) SuggestTags(ctx *gin.Context, req TagSuggestReq) (ginx.Result, error) {
+	tags, err := h.svc.SuggestTags(ctx, req.Prefix, req.Limit)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	return ginx.Result{
+		Data: slice.Map[domain.Tag, TagVO](tags, func(idx int, src domain.Tag) TagVO {
+			return TagVO{Name: src.Name, Cnt: src.Cnt}
+		}),
+	}, nil
+}
+
+func (h *ArticleHandler) TagCounts(ctx *gin.Context, req TagCountsReq) (ginx.Result, error) {
+	if len(req.Tags) == 0 || len(req.Tags) > 100 {
+		return ginx.Result{
+			Code: 4,
+			Msg:  "输入有误",
+		}, nil
+	}
+	tags, err := h.svc.TagCounts(ctx, req.Tags)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	return ginx.Result{
+		Data: slice.Map[domain.Tag, TagVO](tags, func(idx int, src domain.Tag) TagVO {
+			return TagVO{Name: src.Name, Cnt: src.Cnt}
+		}),
+	}, nil
+}
+
+func (h *ArticleHandler) ListRevisions(ctx *gin.Context,
+	req RevisionListReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	revs, err := h.svc.ListRevisions(ctx, uc.Id, req.Id, req.Offset, req.Limit)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	return ginx.Result{
+		Data: slice.Map[domain.ArticleRevision, ArticleRevisionVO](revs,
+			func(idx int, src domain.ArticleRevision) ArticleRevisionVO {
+				return newArticleRevisionVO(src)
+			}),
+	}, nil
+}
+
+func (h *ArticleHandler) DiffRevisions(ctx *gin.Context,
+	req RevisionDiffReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	mode := domain.ArticleDiffModeLine
+	if req.Mode == string(domain.ArticleDiffModeWord) {
+		mode = domain.ArticleDiffModeWord
+	}
+	diff, err := h.svc.DiffRevisions(ctx, uc.Id, req.Id, req.From, req.To, mode)
+	switch err {
+	case nil:
+		return ginx.Result{
+			Data: newArticleDiffVO(diff),
+		}, nil
+	case service.ErrRevisionNotFound:
+		return ginx.Result{
+			Code: 4,
+			Msg:  "输入有误",
+		}, err
+	default:
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+}
+
+func (h *ArticleHandler) RestoreRevision(ctx *gin.Context,
+	req RevisionRestoreReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.RestoreRevision(ctx, uc.Id, req.Id, req.RevId)
+	switch err {
+	case nil:
+		return ginx.Result{
+			Msg: "OK",
+		}, nil
+	case service.ErrRevisionNotFound, service.ErrPossibleIncorrectAuthor:
+		// 如果公司有风控系统，这个时候就要上报这种非法访问的用户了。
+		return ginx.Result{
+			Code: 4,
+			Msg:  "输入有误",
+		}, err
+	default:
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+}
+
 func (a *ArticleHandler) Like(ctx *gin.Context, req LikeReq, uc ijwt.UserClaims) (ginx.Result, error) {
 	var err error
 	if req.Like {
@@ -105,7 +329,7 @@ func (a *ArticleHandler) PubDetail(ctx *gin.Context) {
 		return
 	}
 
-	uc := ctx.MustGet("users").(ijwt.UserClaims)
+	uc := ctx.MustGet(ginx.ClaimsKey).(ijwt.UserClaims)
 	var eg errgroup.Group
 	var art domain.Article
 	eg.Go(func() error {
@@ -158,6 +382,8 @@ func (a *ArticleHandler) PubDetail(ctx *gin.Context) {
 			Content: art.Content,
 			// 要把作者信息带出去
 			Author:     art.Author.Name,
+			Category:   art.Category,
+			Tags:       art.Tags,
 			Ctime:      art.Ctime.Format(time.DateTime),
 			Utime:      art.Utime.Format(time.DateTime),
 			Liked:      intr.Liked,
@@ -165,6 +391,7 @@ func (a *ArticleHandler) PubDetail(ctx *gin.Context) {
 			LikeCnt:    intr.LikeCnt,
 			ReadCnt:    intr.ReadCnt,
 			CollectCnt: intr.CollectCnt,
+			CommentCnt: intr.CommentCnt,
 		},
 	})
 }
@@ -211,8 +438,10 @@ func (a *ArticleHandler) Detail(ctx *gin.Context, usr ijwt.UserClaims) (ginx.Res
 			Content: art.Content,
 			// 这个是创作者看自己的文章列表，也不需要这个字段
 			//Author: art.Author
-			Ctime: art.Ctime.Format(time.DateTime),
-			Utime: art.Utime.Format(time.DateTime),
+			Category: art.Category,
+			Tags:     art.Tags,
+			Ctime:    art.Ctime.Format(time.DateTime),
+			Utime:    art.Utime.Format(time.DateTime),
 		},
 	}, nil
 }
@@ -222,8 +451,8 @@ func (h *ArticleHandler) Publish(ctx *gin.Context) {
 	if err := ctx.Bind(&req); err != nil {
 		return
 	}
-	c := ctx.MustGet("claims")
-	claims, ok := c.(*ijwt.UserClaims)
+	c := ctx.MustGet(ginx.ClaimsKey)
+	claims, ok := c.(ijwt.UserClaims)
 	if !ok {
 		// 你可以考虑监控住这里
 		//ctx.AbortWithStatus(http.StatusUnauthorized)
@@ -236,6 +465,13 @@ func (h *ArticleHandler) Publish(ctx *gin.Context) {
 	}
 
 	id, err := h.svc.Publish(ctx, req.toDomain(claims.Id))
+	if err == service.ErrInvalidTags {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "标签最多五个，每个不超过 32 个字",
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -258,8 +494,8 @@ func (h *ArticleHandler) Withdraw(ctx *gin.Context) {
 	if err := ctx.Bind(&req); err != nil {
 		return
 	}
-	c := ctx.MustGet("claims")
-	claims, ok := c.(*ijwt.UserClaims)
+	c := ctx.MustGet(ginx.ClaimsKey)
+	claims, ok := c.(ijwt.UserClaims)
 	if !ok {
 		// 你可以考虑监控住这里
 		//ctx.AbortWithStatus(http.StatusUnauthorized)
@@ -298,8 +534,8 @@ func (h *ArticleHandler) Edit(ctx *gin.Context) {
 	if err := ctx.Bind(&req); err != nil {
 		return
 	}
-	c := ctx.MustGet("claims")
-	claims, ok := c.(*ijwt.UserClaims)
+	c := ctx.MustGet(ginx.ClaimsKey)
+	claims, ok := c.(ijwt.UserClaims)
 	if !ok {
 		// 你可以考虑监控住这里
 		//ctx.AbortWithStatus(http.StatusUnauthorized)
@@ -313,6 +549,13 @@ func (h *ArticleHandler) Edit(ctx *gin.Context) {
 	// 检测输入，跳过这一步
 	// 调用 svc 的代码
 	id, err := h.svc.Save(ctx, req.toDomain(claims.Id))
+	if err == service.ErrInvalidTags {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "标签最多五个，每个不超过 32 个字",
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -338,21 +581,22 @@ func (h *ArticleHandler) List(ctx *gin.Context, req ListReq, uc ijwt.UserClaims)
 	// 在列表页，不显示全文，只显示一个"摘要"
 	// 比如说，简单的摘要就是前几句话
 	// 强大的摘要是 AI 帮你生成的
+	vos := slice.Map[domain.Article, ArticleVO](res,
+		func(idx int, src domain.Article) ArticleVO {
+			return ArticleVO{
+				Id:       src.Id,
+				Title:    src.Title,
+				Abstract: src.Abstract(),
+				Status:   src.Status.ToUint8(),
+				// 这个列表请求，不需要返回内容
+				//Content: src.Content,
+				// 这个是创作者看自己的文章列表，也不需要这个字段
+				//Author: src.Author
+				Ctime: src.Ctime.Format(time.DateTime),
+				Utime: src.Utime.Format(time.DateTime),
+			}
+		})
 	return ginx.Result{
-		Data: slice.Map[domain.Article, ArticleVO](res,
-			func(idx int, src domain.Article) ArticleVO {
-				return ArticleVO{
-					Id:       src.Id,
-					Title:    src.Title,
-					Abstract: src.Abstract(),
-					Status:   src.Status.ToUint8(),
-					// 这个列表请求，不需要返回内容
-					//Content: src.Content,
-					// 这个是创作者看自己的文章列表，也不需要这个字段
-					//Author: src.Author
-					Ctime: src.Ctime.Format(time.DateTime),
-					Utime: src.Utime.Format(time.DateTime),
-				}
-			}),
+		Data: withInteractive(ctx, h.intrSvc, h.l, uc.Id, vos),
 	}, nil
 }
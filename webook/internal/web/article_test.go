@@ -84,11 +84,11 @@ func TestArticleHandler_Publish(t *testing.T) {
 			defer ctrl.Finish()
 			server := gin.Default()
 			server.Use(func(ctx *gin.Context) {
-				ctx.Set("claims", &ijwt.UserClaims{
+				ctx.Set("claims", ijwt.UserClaims{
 					Id: 123,
 				})
 			})
-			h := NewArticleHandler(tc.mock(ctrl), &logger.NoOpLogger{})
+			h := NewArticleHandler(tc.mock(ctrl), nil, &logger.NoOpLogger{})
 			h.RegisterRoutes(server)
 
 			req, err := http.NewRequest(http.MethodPost,
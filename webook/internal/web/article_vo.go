@@ -1,6 +1,13 @@
 package web
 
-import "github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+import (
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/textdiff"
+)
 
 // VO view object，就是对标前端的
 type LikeReq struct {
@@ -26,11 +33,18 @@ type ArticleVO struct {
 	ReadCnt    int64 `json:"read_cnt"`
 	LikeCnt    int64 `json:"like_cnt"`
 	CollectCnt int64 `json:"collect_cnt"`
+	CommentCnt int64 `json:"comment_cnt"`
 
 	// 我个人有没有收藏，有没有点赞
 	Liked     bool `json:"liked"`
 	Collected bool `json:"collected"`
 
+	// 定时发表的时间，只有定时发表的文章才有
+	PublishAt string `json:"publish_at,omitempty"`
+
+	Category string   `json:"category"`
+	Tags     []string `json:"tags"`
+
 	Ctime string `json:"ctime"`
 	Utime string `json:"utime"`
 }
@@ -44,15 +58,133 @@ type ArticleReq struct {
 	Id      int64  `json:"id"`
 	Title   string `json:"title"`
 	Content string `json:"content"`
+	// PublishAt 定时发表的时间，毫秒数。只在发表的时候有用，不传就是立刻发表
+	PublishAt int64 `json:"publish_at"`
+	// Category 分类，只能选一个
+	Category string `json:"category"`
+	// Tags 标签，最多五个
+	Tags []string `json:"tags"`
 }
 
 func (req ArticleReq) toDomain(uid int64) domain.Article {
-	return domain.Article{
+	art := domain.Article{
 		Id:      req.Id,
 		Title:   req.Title,
 		Content: req.Content,
 		Author: domain.Author{
 			Id: uid,
 		},
+		Category: req.Category,
+		Tags:     req.Tags,
+	}
+	if req.PublishAt > 0 {
+		art.PublishAt = time.UnixMilli(req.PublishAt)
+	}
+	return art
+}
+
+type RescheduleReq struct {
+	Id int64 `json:"id"`
+	// 毫秒数
+	PublishAt int64 `json:"publish_at"`
+}
+
+type CancelScheduleReq struct {
+	Id int64 `json:"id"`
+}
+
+type TagListReq struct {
+	Tag    string `json:"tag"`
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+}
+
+type TagSuggestReq struct {
+	// 标签前缀，不传就是最热门的标签
+	Prefix string `json:"prefix"`
+	Limit  int    `json:"limit"`
+}
+
+type TagCountsReq struct {
+	Tags []string `json:"tags"`
+}
+
+type TagVO struct {
+	Name string `json:"name"`
+	// 公开文章的数量
+	Cnt int64 `json:"cnt"`
+}
+
+type RevisionListReq struct {
+	// 文章 ID
+	Id     int64 `json:"id"`
+	Offset int   `json:"offset"`
+	Limit  int   `json:"limit"`
+}
+
+type RevisionDiffReq struct {
+	Id int64 `json:"id"`
+	// 旧版本
+	From int64 `json:"from"`
+	// 新版本
+	To int64 `json:"to"`
+	// line 或者 word，默认按行比较
+	Mode string `json:"mode"`
+}
+
+type RevisionRestoreReq struct {
+	Id    int64 `json:"id"`
+	RevId int64 `json:"rev_id"`
+}
+
+type ArticleRevisionVO struct {
+	Id        int64    `json:"id"`
+	ArticleId int64    `json:"article_id"`
+	Title     string   `json:"title"`
+	Abstract  string   `json:"abstract"`
+	Status    uint8    `json:"status"`
+	Category  string   `json:"category"`
+	Tags      []string `json:"tags"`
+	Ctime     string   `json:"ctime"`
+}
+
+func newArticleRevisionVO(rev domain.ArticleRevision) ArticleRevisionVO {
+	return ArticleRevisionVO{
+		Id:        rev.Id,
+		ArticleId: rev.ArticleId,
+		Title:     rev.Title,
+		Abstract:  rev.Abstract(),
+		Status:    rev.Status.ToUint8(),
+		Category:  rev.Category,
+		Tags:      rev.Tags,
+		Ctime:     rev.Ctime.Format(time.DateTime),
+	}
+}
+
+type DiffEditVO struct {
+	// equal, insert, delete
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type ArticleDiffVO struct {
+	From    ArticleRevisionVO `json:"from"`
+	To      ArticleRevisionVO `json:"to"`
+	Title   []DiffEditVO      `json:"title"`
+	Content []DiffEditVO      `json:"content"`
+}
+
+func newArticleDiffVO(diff domain.ArticleDiff) ArticleDiffVO {
+	toVO := func(idx int, src textdiff.Edit) DiffEditVO {
+		return DiffEditVO{
+			Op:   src.Op.String(),
+			Text: src.Text,
+		}
+	}
+	return ArticleDiffVO{
+		From:    newArticleRevisionVO(diff.From),
+		To:      newArticleRevisionVO(diff.To),
+		Title:   slice.Map[textdiff.Edit, DiffEditVO](diff.Title, toVO),
+		Content: slice.Map[textdiff.Edit, DiffEditVO](diff.Content, toVO),
 	}
 }
@@ -0,0 +1,208 @@
+package web
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	ijwt "github.com/xiaoshanjiang/my-geektime/webook/internal/web/jwt"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/ginx"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+var _ handler = (*CollectionHandler)(nil)
+
+type CollectionHandler struct {
+	svc service.CollectionService
+	l   logger.LoggerV1
+}
+
+func NewCollectionHandler(svc service.CollectionService, l logger.LoggerV1) *CollectionHandler {
+	return &CollectionHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *CollectionHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/collections")
+	g.POST("/create", ginx.WrapBodyAndToken[CollectionReq, ijwt.UserClaims](h.Create))
+	g.POST("/rename", ginx.WrapBodyAndToken[CollectionReq, ijwt.UserClaims](h.Rename))
+	g.POST("/delete", ginx.WrapBodyAndToken[CollectionReq, ijwt.UserClaims](h.Delete))
+	g.POST("/list", ginx.WrapBodyAndToken[CollectionListReq, ijwt.UserClaims](h.List))
+	g.POST("/items", ginx.WrapBodyAndToken[CollectionListReq, ijwt.UserClaims](h.Items))
+	g.POST("/collect", ginx.WrapBodyAndToken[CollectReq, ijwt.UserClaims](h.Collect))
+	g.POST("/uncollect", ginx.WrapBodyAndToken[CollectReq, ijwt.UserClaims](h.Uncollect))
+	g.POST("/move", ginx.WrapBodyAndToken[CollectReq, ijwt.UserClaims](h.Move))
+}
+
+func (h *CollectionHandler) Create(ctx *gin.Context, req CollectionReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	cid, err := h.svc.Create(ctx, uc.Id, req.Name)
+	if err != nil {
+		return h.errResult(err)
+	}
+	return ginx.Result{
+		Data: cid,
+	}, nil
+}
+
+func (h *CollectionHandler) Rename(ctx *gin.Context, req CollectionReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.Rename(ctx, uc.Id, req.Cid, req.Name)
+	if err != nil {
+		return h.errResult(err)
+	}
+	return ginx.Result{
+		Msg: "OK",
+	}, nil
+}
+
+func (h *CollectionHandler) Delete(ctx *gin.Context, req CollectionReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.Delete(ctx, uc.Id, req.Cid)
+	if err != nil {
+		return h.errResult(err)
+	}
+	return ginx.Result{
+		Msg: "OK",
+	}, nil
+}
+
+func (h *CollectionHandler) List(ctx *gin.Context, req CollectionListReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	cols, err := h.svc.List(ctx, uc.Id, req.Offset, req.Limit)
+	if err != nil {
+		return h.errResult(err)
+	}
+	return ginx.Result{
+		Data: slice.Map[domain.Collection, CollectionVO](cols,
+			func(idx int, src domain.Collection) CollectionVO {
+				return CollectionVO{
+					Id:      src.Id,
+					Name:    src.Name,
+					ItemCnt: src.ItemCnt,
+				}
+			}),
+	}, nil
+}
+
+func (h *CollectionHandler) Items(ctx *gin.Context, req CollectionListReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	items, err := h.svc.ListItems(ctx, uc.Id, req.Cid, req.Offset, req.Limit)
+	if err != nil {
+		return h.errResult(err)
+	}
+	return ginx.Result{
+		Data: slice.Map[domain.CollectionItem, CollectionItemVO](items,
+			func(idx int, src domain.CollectionItem) CollectionItemVO {
+				return CollectionItemVO{
+					Biz:   src.Biz,
+					BizId: src.BizId,
+					Title: src.Title,
+					Ctime: src.Ctime.Format(time.DateTime),
+				}
+			}),
+	}, nil
+}
+
+func (h *CollectionHandler) Collect(ctx *gin.Context, req CollectReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.Collect(ctx, uc.Id, req.Cid, req.biz(), req.BizId)
+	if err != nil {
+		return h.errResult(err)
+	}
+	return ginx.Result{
+		Msg: "OK",
+	}, nil
+}
+
+func (h *CollectionHandler) Uncollect(ctx *gin.Context, req CollectReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.Uncollect(ctx, uc.Id, req.biz(), req.BizId)
+	if err != nil {
+		return h.errResult(err)
+	}
+	return ginx.Result{
+		Msg: "OK",
+	}, nil
+}
+
+func (h *CollectionHandler) Move(ctx *gin.Context, req CollectReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.Move(ctx, uc.Id, req.biz(), req.BizId, req.Cid)
+	if err != nil {
+		return h.errResult(err)
+	}
+	return ginx.Result{
+		Msg: "OK",
+	}, nil
+}
+
+// errResult 把业务错误转换成给前端的提示，其它的都是系统错误
+func (h *CollectionHandler) errResult(err error) (ginx.Result, error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCollectionName):
+		return ginx.Result{
+			Code: 4,
+			Msg:  "收藏夹名字不合法",
+		}, nil
+	case errors.Is(err, service.ErrDefaultCollection):
+		return ginx.Result{
+			Code: 4,
+			Msg:  "默认收藏夹不能修改",
+		}, nil
+	case errors.Is(err, service.ErrCollectionItemNotFound):
+		return ginx.Result{
+			Code: 4,
+			Msg:  "没有收藏过",
+		}, nil
+	case errors.Is(err, service.ErrCollectionNotFound):
+		return ginx.Result{
+			Code: 4,
+			Msg:  "收藏夹不存在",
+		}, nil
+	default:
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+}
+
+type CollectionReq struct {
+	Cid  int64  `json:"cid"`
+	Name string `json:"name"`
+}
+
+type CollectionListReq struct {
+	// 查询收藏夹里面的东西的时候用，0 是默认收藏夹
+	Cid    int64 `json:"cid"`
+	Offset int   `json:"offset"`
+	Limit  int   `json:"limit"`
+}
+
+type CollectReq struct {
+	// 不传就是文章
+	Biz   string `json:"biz"`
+	BizId int64  `json:"biz_id"`
+	// 收藏到哪个收藏夹，或者挪到哪个收藏夹，0 是默认收藏夹
+	Cid int64 `json:"cid"`
+}
+
+func (req CollectReq) biz() string {
+	if req.Biz == "" {
+		return domain.BizArrticle
+	}
+	return req.Biz
+}
+
+type CollectionVO struct {
+	Id      int64  `json:"id"`
+	Name    string `json:"name"`
+	ItemCnt int64  `json:"item_cnt"`
+}
+
+type CollectionItemVO struct {
+	Biz   string `json:"biz"`
+	BizId int64  `json:"biz_id"`
+	Title string `json:"title"`
+	// 收藏的时间
+	Ctime string `json:"ctime"`
+}
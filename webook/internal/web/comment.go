@@ -0,0 +1,195 @@
+package web
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	ijwt "github.com/xiaoshanjiang/my-geektime/webook/internal/web/jwt"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/ginx"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+var _ handler = (*CommentHandler)(nil)
+
+type CommentHandler struct {
+	svc service.CommentService
+	l   logger.LoggerV1
+}
+
+func NewCommentHandler(svc service.CommentService, l logger.LoggerV1) *CommentHandler {
+	return &CommentHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *CommentHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/comments")
+	g.POST("/post", ginx.WrapBodyAndToken[CommentPostReq, ijwt.UserClaims](h.Post))
+	g.POST("/delete", ginx.WrapBodyAndToken[CommentDeleteReq, ijwt.UserClaims](h.Delete))
+	// 看评论不需要登录
+	g.POST("/list", ginx.WrapBody[CommentListReq](h.l, h.List))
+	g.POST("/replies", ginx.WrapBody[CommentRepliesReq](h.l, h.Replies))
+}
+
+func (h *CommentHandler) Post(ctx *gin.Context,
+	req CommentPostReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	id, err := h.svc.Post(ctx, domain.Comment{
+		Biz:      bizOrDefault(req.Biz),
+		BizId:    req.BizId,
+		Uid:      uc.Id,
+		ParentId: req.ParentId,
+		Content:  req.Content,
+	})
+	switch {
+	case err == nil:
+		return ginx.Result{
+			Data: id,
+		}, nil
+	case errors.Is(err, service.ErrInvalidComment):
+		return ginx.Result{
+			Code: 4,
+			Msg:  "评论内容不合法",
+		}, nil
+	case errors.Is(err, service.ErrCommentTargetClosed),
+		errors.Is(err, service.ErrCommentNotFound):
+		return ginx.Result{
+			Code: 4,
+			Msg:  "评论的内容不存在",
+		}, nil
+	default:
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+}
+
+func (h *CommentHandler) Delete(ctx *gin.Context,
+	req CommentDeleteReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.Delete(ctx, uc.Id, req.Id)
+	switch {
+	case err == nil:
+		return ginx.Result{
+			Msg: "OK",
+		}, nil
+	case errors.Is(err, service.ErrCommentNotFound):
+		return ginx.Result{
+			Code: 4,
+			Msg:  "评论不存在",
+		}, nil
+	case errors.Is(err, service.ErrCommentNoPermission):
+		return ginx.Result{
+			Code: 4,
+			Msg:  "没有权限",
+		}, nil
+	default:
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+}
+
+func (h *CommentHandler) List(ctx *gin.Context, req CommentListReq) (ginx.Result, error) {
+	cmts, err := h.svc.ListTopLevel(ctx, bizOrDefault(req.Biz), req.BizId, req.MaxId, req.Limit)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	return ginx.Result{
+		Data: newCommentPageVO(cmts),
+	}, nil
+}
+
+func (h *CommentHandler) Replies(ctx *gin.Context, req CommentRepliesReq) (ginx.Result, error) {
+	cmts, err := h.svc.ListReplies(ctx, req.RootId, req.MinId, req.Limit)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	return ginx.Result{
+		Data: newCommentPageVO(cmts),
+	}, nil
+}
+
+func bizOrDefault(biz string) string {
+	if biz == "" {
+		return domain.BizArrticle
+	}
+	return biz
+}
+
+type CommentPostReq struct {
+	// 不传默认是文章
+	Biz   string `json:"biz"`
+	BizId int64  `json:"biz_id"`
+	// 回复的评论，发表顶级评论的时候不传
+	ParentId int64  `json:"parent_id"`
+	Content  string `json:"content"`
+}
+
+type CommentDeleteReq struct {
+	Id int64 `json:"id"`
+}
+
+type CommentListReq struct {
+	Biz   string `json:"biz"`
+	BizId int64  `json:"biz_id"`
+	// 上一页的 cursor，第一页不传
+	MaxId int64 `json:"max_id"`
+	Limit int   `json:"limit"`
+}
+
+type CommentRepliesReq struct {
+	RootId int64 `json:"root_id"`
+	// 上一页的 cursor，第一页不传
+	MinId int64 `json:"min_id"`
+	Limit int   `json:"limit"`
+}
+
+type CommentVO struct {
+	Id       int64  `json:"id"`
+	Uid      int64  `json:"uid"`
+	RootId   int64  `json:"root_id"`
+	ParentId int64  `json:"parent_id"`
+	Content  string `json:"content"`
+	ReplyCnt int64  `json:"reply_cnt"`
+	Ctime    string `json:"ctime"`
+}
+
+type CommentPageVO struct {
+	Comments []CommentVO `json:"comments"`
+	// Cursor 下一页要传的 ID，顶级评论对应 max_id，回复对应 min_id
+	Cursor int64 `json:"cursor"`
+}
+
+func newCommentPageVO(cmts []domain.Comment) CommentPageVO {
+	res := CommentPageVO{
+		Comments: slice.Map[domain.Comment, CommentVO](cmts,
+			func(idx int, src domain.Comment) CommentVO {
+				return CommentVO{
+					Id:       src.Id,
+					Uid:      src.Uid,
+					RootId:   src.RootId,
+					ParentId: src.ParentId,
+					Content:  src.Content,
+					ReplyCnt: src.ReplyCnt,
+					Ctime:    src.Ctime.Format(time.DateTime),
+				}
+			}),
+	}
+	if len(cmts) > 0 {
+		res.Cursor = cmts[len(cmts)-1].Id
+	}
+	return res
+}
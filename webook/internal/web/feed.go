@@ -0,0 +1,106 @@
+package web
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	ijwt "github.com/xiaoshanjiang/my-geektime/webook/internal/web/jwt"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/ginx"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+var _ handler = (*FeedHandler)(nil)
+
+type FeedHandler struct {
+	svc service.FeedService
+	l   logger.LoggerV1
+}
+
+func NewFeedHandler(svc service.FeedService, l logger.LoggerV1) *FeedHandler {
+	return &FeedHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *FeedHandler) RegisterRoutes(server *gin.Engine) {
+	server.POST("/feed", ginx.WrapBodyAndToken[FeedReq, ijwt.UserClaims](h.Feed))
+}
+
+func (h *FeedHandler) Feed(ctx *gin.Context, req FeedReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	cursor, err := parseFeedCursor(req.Cursor)
+	if err != nil {
+		return ginx.Result{
+			Code: 4,
+			Msg:  "输入有误",
+		}, nil
+	}
+	items, err := h.svc.GetFeed(ctx, uc.Id, cursor, req.Limit)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	res := FeedVO{
+		Items: slice.Map[domain.FeedItem, FeedItemVO](items,
+			func(idx int, src domain.FeedItem) FeedItemVO {
+				return FeedItemVO{
+					Id:       src.Aid,
+					Author:   src.Uid,
+					Title:    src.Title,
+					Abstract: src.Abstract,
+					Ctime:    src.Ctime.Format(time.DateTime),
+				}
+			}),
+	}
+	if len(items) > 0 {
+		last := items[len(items)-1]
+		res.Cursor = formatFeedCursor(domain.FeedCursor{
+			Ctime: last.Ctime.UnixMilli(),
+			Aid:   last.Aid,
+		})
+	}
+	return ginx.Result{
+		Data: res,
+	}, nil
+}
+
+// 游标对前端来说是不透明的，格式是 发表时间毫秒数_文章ID
+func formatFeedCursor(c domain.FeedCursor) string {
+	return fmt.Sprintf("%d_%d", c.Ctime, c.Aid)
+}
+
+func parseFeedCursor(s string) (domain.FeedCursor, error) {
+	var c domain.FeedCursor
+	if s == "" {
+		return c, nil
+	}
+	_, err := fmt.Sscanf(s, "%d_%d", &c.Ctime, &c.Aid)
+	return c, err
+}
+
+type FeedReq struct {
+	// 上一页返回的 cursor，第一页不传
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+type FeedVO struct {
+	Items []FeedItemVO `json:"items"`
+	// 下一页要传的 cursor，没有数据的时候为空
+	Cursor string `json:"cursor"`
+}
+
+type FeedItemVO struct {
+	Id       int64  `json:"id"`
+	Author   int64  `json:"author"`
+	Title    string `json:"title"`
+	Abstract string `json:"abstract"`
+	Ctime    string `json:"ctime"`
+}
@@ -0,0 +1,140 @@
+package web
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	ijwt "github.com/xiaoshanjiang/my-geektime/webook/internal/web/jwt"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/ginx"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+var _ handler = (*FollowHandler)(nil)
+
+type FollowHandler struct {
+	svc service.FollowService
+	l   logger.LoggerV1
+}
+
+func NewFollowHandler(svc service.FollowService, l logger.LoggerV1) *FollowHandler {
+	return &FollowHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *FollowHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/follow")
+	g.POST("/follow", ginx.WrapBodyAndToken[FollowReq, ijwt.UserClaims](h.Follow))
+	g.POST("/cancel", ginx.WrapBodyAndToken[FollowReq, ijwt.UserClaims](h.CancelFollow))
+	g.POST("/followers", ginx.WrapBodyAndToken[FollowListReq, ijwt.UserClaims](h.Followers))
+	g.POST("/followees", ginx.WrapBodyAndToken[FollowListReq, ijwt.UserClaims](h.Followees))
+}
+
+func (h *FollowHandler) Follow(ctx *gin.Context, req FollowReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.Follow(ctx, uc.Id, req.Followee)
+	switch {
+	case err == nil:
+		return ginx.Result{
+			Msg: "OK",
+		}, nil
+	case errors.Is(err, service.ErrFollowSelf):
+		return ginx.Result{
+			Code: 4,
+			Msg:  "不能关注自己",
+		}, nil
+	case errors.Is(err, service.ErrFolloweeNotFound):
+		return ginx.Result{
+			Code: 4,
+			Msg:  "用户不存在",
+		}, nil
+	default:
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+}
+
+func (h *FollowHandler) CancelFollow(ctx *gin.Context, req FollowReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.CancelFollow(ctx, uc.Id, req.Followee)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	return ginx.Result{
+		Msg: "OK",
+	}, nil
+}
+
+func (h *FollowHandler) Followers(ctx *gin.Context, req FollowListReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	res, err := h.svc.GetFollowers(ctx, req.uid(uc), req.Offset, req.Limit)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	return ginx.Result{
+		Data: slice.Map[domain.FollowRelation, FollowVO](res,
+			func(idx int, src domain.FollowRelation) FollowVO {
+				return newFollowVO(src.Follower, src)
+			}),
+	}, nil
+}
+
+func (h *FollowHandler) Followees(ctx *gin.Context, req FollowListReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	res, err := h.svc.GetFollowees(ctx, req.uid(uc), req.Offset, req.Limit)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	return ginx.Result{
+		Data: slice.Map[domain.FollowRelation, FollowVO](res,
+			func(idx int, src domain.FollowRelation) FollowVO {
+				return newFollowVO(src.Followee, src)
+			}),
+	}, nil
+}
+
+type FollowReq struct {
+	// 被关注的人
+	Followee int64 `json:"followee"`
+}
+
+type FollowListReq struct {
+	// 查询谁的列表，不传就是自己
+	Uid    int64 `json:"uid"`
+	Offset int   `json:"offset"`
+	Limit  int   `json:"limit"`
+}
+
+func (req FollowListReq) uid(uc ijwt.UserClaims) int64 {
+	if req.Uid > 0 {
+		return req.Uid
+	}
+	return uc.Id
+}
+
+type FollowVO struct {
+	// 列表里面的那个用户，粉丝列表就是粉丝，关注列表就是被关注的人
+	Uid int64 `json:"uid"`
+	// 关注的时间
+	Ctime string `json:"ctime"`
+}
+
+func newFollowVO(uid int64, fr domain.FollowRelation) FollowVO {
+	return FollowVO{
+		Uid:   uid,
+		Ctime: fr.Ctime.Format(time.DateTime),
+	}
+}
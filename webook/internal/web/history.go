@@ -0,0 +1,148 @@
+package web
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	ijwt "github.com/xiaoshanjiang/my-geektime/webook/internal/web/jwt"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/ginx"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+var _ handler = (*HistoryHandler)(nil)
+
+// HistoryHandler 我的阅读记录和我的点赞
+type HistoryHandler struct {
+	svc service.HistoryService
+	l   logger.LoggerV1
+}
+
+func NewHistoryHandler(svc service.HistoryService, l logger.LoggerV1) *HistoryHandler {
+	return &HistoryHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *HistoryHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/history")
+	g.POST("/reads", ginx.WrapBodyAndToken[HistoryReq, ijwt.UserClaims](h.Reads))
+	g.POST("/clear", ginx.WrapToken[ijwt.UserClaims](h.Clear))
+	g.POST("/likes", ginx.WrapBodyAndToken[HistoryReq, ijwt.UserClaims](h.Likes))
+}
+
+func (h *HistoryHandler) Reads(ctx *gin.Context, req HistoryReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	cursor, err := parseUserBizCursor(req.Cursor)
+	if err != nil {
+		return ginx.Result{
+			Code: 4,
+			Msg:  "输入有误",
+		}, nil
+	}
+	records, err := h.svc.ListReadHistory(ctx, uc.Id, cursor, req.Limit)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	return ginx.Result{
+		Data: newHistoryVO(records),
+	}, nil
+}
+
+func (h *HistoryHandler) Clear(ctx *gin.Context, uc ijwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.ClearReadHistory(ctx, uc.Id)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	return ginx.Result{
+		Msg: "OK",
+	}, nil
+}
+
+func (h *HistoryHandler) Likes(ctx *gin.Context, req HistoryReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	cursor, err := parseUserBizCursor(req.Cursor)
+	if err != nil {
+		return ginx.Result{
+			Code: 4,
+			Msg:  "输入有误",
+		}, nil
+	}
+	records, err := h.svc.ListLiked(ctx, uc.Id, cursor, req.Limit)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	return ginx.Result{
+		Data: newHistoryVO(records),
+	}, nil
+}
+
+// 和 feed 一样，游标对前端来说是不透明的，格式是 毫秒数_文章ID
+func formatUserBizCursor(c domain.UserBizCursor) string {
+	return fmt.Sprintf("%d_%d", c.Utime, c.BizId)
+}
+
+func parseUserBizCursor(s string) (domain.UserBizCursor, error) {
+	var c domain.UserBizCursor
+	if s == "" {
+		return c, nil
+	}
+	_, err := fmt.Sscanf(s, "%d_%d", &c.Utime, &c.BizId)
+	return c, err
+}
+
+type HistoryReq struct {
+	// 上一页返回的 cursor，第一页不传
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+type HistoryVO struct {
+	Items []HistoryItemVO `json:"items"`
+	// 下一页要传的 cursor，没有数据的时候为空
+	Cursor string `json:"cursor"`
+}
+
+type HistoryItemVO struct {
+	// 文章 ID
+	Id int64 `json:"id"`
+	// 文章撤回了的时候，标题和摘要都是空的
+	Title    string `json:"title"`
+	Abstract string `json:"abstract"`
+	// 阅读或者点赞的时间
+	Utime string `json:"utime"`
+}
+
+func newHistoryVO(records []domain.UserBizRecord) HistoryVO {
+	res := HistoryVO{
+		Items: slice.Map[domain.UserBizRecord, HistoryItemVO](records,
+			func(idx int, src domain.UserBizRecord) HistoryItemVO {
+				return HistoryItemVO{
+					Id:       src.BizId,
+					Title:    src.Title,
+					Abstract: src.Abstract,
+					Utime:    src.Utime.Format(time.DateTime),
+				}
+			}),
+	}
+	if len(records) > 0 {
+		last := records[len(records)-1]
+		res.Cursor = formatUserBizCursor(domain.UserBizCursor{
+			Utime: last.Utime.UnixMilli(),
+			BizId: last.BizId,
+		})
+	}
+	return res
+}
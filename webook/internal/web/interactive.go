@@ -0,0 +1,38 @@
+package web
+
+import (
+	"context"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+// withInteractive 给文章列表带上阅读、点赞和收藏数，uid 不为 0 的时候带上有没有点赞、收藏。
+// 整个列表只查一次，计数不是关键信息，查询失败了只记录日志，列表照样返回
+func withInteractive(ctx context.Context, svc service.InteractiveService,
+	l logger.LoggerV1, uid int64, vos []ArticleVO) []ArticleVO {
+	if len(vos) == 0 {
+		return vos
+	}
+	ids := make([]int64, 0, len(vos))
+	for _, vo := range vos {
+		ids = append(ids, vo.Id)
+	}
+	intrs, err := svc.GetByIds(ctx, domain.BizArrticle, ids, uid)
+	if err != nil {
+		l.Error("批量查询文章计数失败",
+			logger.Int64("uid", uid),
+			logger.Error(err))
+		return vos
+	}
+	for i := range vos {
+		intr := intrs[vos[i].Id]
+		vos[i].ReadCnt = intr.ReadCnt
+		vos[i].LikeCnt = intr.LikeCnt
+		vos[i].CollectCnt = intr.CollectCnt
+		vos[i].Liked = intr.Liked
+		vos[i].Collected = intr.Collected
+	}
+	return vos
+}
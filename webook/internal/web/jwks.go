@@ -0,0 +1,33 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	ijwt "github.com/xiaoshanjiang/my-geektime/webook/internal/web/jwt"
+)
+
+var _ handler = (*JWKSHandler)(nil)
+
+// JWKSHandler 公布 access token 的公钥，别的服务用 jwtx.Verifier 离线校验 token
+type JWKSHandler struct {
+	jwtHdl ijwt.Handler
+}
+
+func NewJWKSHandler(jwtHdl ijwt.Handler) *JWKSHandler {
+	return &JWKSHandler{
+		jwtHdl: jwtHdl,
+	}
+}
+
+func (h *JWKSHandler) RegisterRoutes(server *gin.Engine) {
+	// 不需要登录
+	server.GET("/.well-known/jwks.json", h.JWKS)
+}
+
+func (h *JWKSHandler) JWKS(ctx *gin.Context) {
+	// 新密钥会在用来签名之前公布，缓存五分钟不影响轮换
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, h.jwtHdl.JWKS())
+}
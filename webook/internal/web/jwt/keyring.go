@@ -0,0 +1,223 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/jwtx"
+)
+
+// LegacyKid 引入 kid 之前签发的 token 头部里面没有 kid，校验的时候当成这个 kid。
+// 把这个 kid 的密钥下线之后，这些老 token 也就失效了
+const LegacyKid = "v0"
+
+var (
+	ErrUnknownKid    = errors.New("未知的 kid")
+	ErrKeyRetired    = errors.New("密钥已经下线")
+	ErrInvalidKeySet = errors.New("密钥配置不合法")
+)
+
+// 支持的签名算法
+const (
+	AlgHS512 = "HS512"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// Key 一把签名密钥
+type Key struct {
+	Kid string
+	// 不填就是 HS512。RS256 和 EdDSA 的公钥会公布出去，
+	// 别的服务不需要知道密钥也能校验 token
+	Alg string
+	// HS512 的时候是共享密钥，RS256 和 EdDSA 的时候是 PEM 格式的私钥
+	Secret []byte
+	// 到了这个时间就不再接受这把密钥签发的 token，零值表示一直有效。
+	// 轮换的时候可以在换签名密钥的同时把老密钥的下线时间设置为
+	// 当前时间加上 refresh token 的有效期，到时候它自己就下线了
+	RetireAt time.Time
+}
+
+func (k Key) retired(now time.Time) bool {
+	return !k.RetireAt.IsZero() && !now.Before(k.RetireAt)
+}
+
+// ringKey 解析好了的密钥
+type ringKey struct {
+	Key
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+func newRingKey(k Key) (ringKey, error) {
+	if k.Alg == "" {
+		k.Alg = AlgHS512
+	}
+	switch k.Alg {
+	case AlgHS512:
+		if len(k.Secret) < 32 {
+			return ringKey{}, fmt.Errorf("%w：密钥 %s 太短了，至少要 32 个字节", ErrInvalidKeySet, k.Kid)
+		}
+		return ringKey{Key: k, method: jwt.SigningMethodHS512,
+			signKey: k.Secret, verifyKey: k.Secret}, nil
+	case AlgRS256:
+		pk, err := jwt.ParseRSAPrivateKeyFromPEM(k.Secret)
+		if err != nil {
+			return ringKey{}, fmt.Errorf("%w：密钥 %s 不是合法的 RSA 私钥 %w", ErrInvalidKeySet, k.Kid, err)
+		}
+		if pk.N.BitLen() < 2048 {
+			return ringKey{}, fmt.Errorf("%w：RSA 密钥 %s 至少要 2048 位", ErrInvalidKeySet, k.Kid)
+		}
+		return ringKey{Key: k, method: jwt.SigningMethodRS256,
+			signKey: pk, verifyKey: &pk.PublicKey}, nil
+	case AlgEdDSA:
+		pk, err := jwt.ParseEdPrivateKeyFromPEM(k.Secret)
+		if err != nil {
+			return ringKey{}, fmt.Errorf("%w：密钥 %s 不是合法的 Ed25519 私钥 %w", ErrInvalidKeySet, k.Kid, err)
+		}
+		edKey, ok := pk.(ed25519.PrivateKey)
+		if !ok {
+			return ringKey{}, fmt.Errorf("%w：密钥 %s 不是合法的 Ed25519 私钥", ErrInvalidKeySet, k.Kid)
+		}
+		return ringKey{Key: k, method: jwt.SigningMethodEdDSA,
+			signKey: edKey, verifyKey: edKey.Public()}, nil
+	default:
+		return ringKey{}, fmt.Errorf("%w：密钥 %s 的算法 %s 不支持", ErrInvalidKeySet, k.Kid, k.Alg)
+	}
+}
+
+// KeyRing 签名用 signer 对应的密钥，并且在头部带上 kid，
+// 校验的时候按照 kid 找密钥，所以新老密钥可以同时生效。
+//
+// 轮换密钥不需要停机：
+//  1. 加一把新密钥，签名密钥不变，等所有实例都加载了新配置；
+//  2. 把签名密钥换成新密钥，老密钥设置下线时间，
+//     老密钥签发的 token 在过期之前依旧可以用；
+//  3. 过了 refresh token 的有效期之后，把老密钥从配置里面删掉。
+type KeyRing struct {
+	mu     sync.RWMutex
+	keys   map[string]ringKey
+	signer string
+}
+
+func NewKeyRing(signer string, keys ...Key) (*KeyRing, error) {
+	r := &KeyRing{}
+	if err := r.Reset(signer, keys...); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reset 整体替换密钥，配置不合法的时候保留原来的密钥，
+// 免得配置写错了导致所有人都登录不了
+func (r *KeyRing) Reset(signer string, keys ...Key) error {
+	now := time.Now()
+	m := make(map[string]ringKey, len(keys))
+	for _, k := range keys {
+		if k.Kid == "" {
+			return fmt.Errorf("%w：kid 不能为空", ErrInvalidKeySet)
+		}
+		if _, ok := m[k.Kid]; ok {
+			return fmt.Errorf("%w：kid %s 重复了", ErrInvalidKeySet, k.Kid)
+		}
+		rk, err := newRingKey(k)
+		if err != nil {
+			return err
+		}
+		m[k.Kid] = rk
+	}
+	sk, ok := m[signer]
+	if !ok {
+		return fmt.Errorf("%w：找不到签名密钥 %s", ErrInvalidKeySet, signer)
+	}
+	if sk.retired(now) {
+		return fmt.Errorf("%w：签名密钥 %s 已经下线", ErrInvalidKeySet, signer)
+	}
+	r.mu.Lock()
+	r.keys = m
+	r.signer = signer
+	r.mu.Unlock()
+	return nil
+}
+
+// Signer 当前签名密钥的 kid
+func (r *KeyRing) Signer() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.signer
+}
+
+// Sign 用签名密钥签名，头部带上 kid
+func (r *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	r.mu.RLock()
+	key := r.keys[r.signer]
+	r.mu.RUnlock()
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.Kid
+	return token.SignedString(key.signKey)
+}
+
+// Keyfunc 给 jwt.Parse 用，按照头部的 kid 找密钥
+func (r *KeyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid := LegacyKid
+	if val, ok := token.Header["kid"]; ok {
+		kid, ok = val.(string)
+		if !ok {
+			return nil, ErrUnknownKid
+		}
+	}
+	r.mu.RLock()
+	key, ok := r.keys[kid]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownKid
+	}
+	if key.retired(time.Now()) {
+		return nil, ErrKeyRetired
+	}
+	// 不校验算法的话，攻击者可以换一个算法来伪造 token，
+	// 比如说拿公开的 RSA 公钥当作 HMAC 的密钥。所以算法必须和密钥完全一致
+	if token.Method.Alg() != key.method.Alg() && !legacyAlg(kid, key, token) {
+		return nil, fmt.Errorf("不支持的签名算法 %v", token.Header["alg"])
+	}
+	return key.verifyKey, nil
+}
+
+// legacyAlg 引入 kid 之前，access token 是用 HS256 签名的，
+// 所以老密钥是共享密钥的时候，也接受用同一个密钥签名的 HS256
+func legacyAlg(kid string, key ringKey, token *jwt.Token) bool {
+	return kid == LegacyKid && key.method == jwt.SigningMethodHS512 &&
+		token.Method == jwt.SigningMethodHS256
+}
+
+// JWKS 公钥，只有 RS256 和 EdDSA 的密钥有，已经下线的不算。
+// 加进来还没有用来签名的新密钥也会公布出去，别的服务可以提前拿到
+func (r *KeyRing) JWKS() jwtx.JWKSet {
+	now := time.Now()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := jwtx.JWKSet{Keys: make([]jwtx.JWK, 0, len(r.keys))}
+	for _, k := range r.keys {
+		// 共享密钥不能公布
+		if k.retired(now) || k.method == jwt.SigningMethodHS512 {
+			continue
+		}
+		jwk, err := jwtx.NewJWK(k.Kid, k.verifyKey)
+		if err != nil {
+			continue
+		}
+		res.Keys = append(res.Keys, jwk)
+	}
+	// map 是无序的，排一下序免得每次返回的都不一样
+	sort.Slice(res.Keys, func(i, j int) bool {
+		return res.Keys[i].Kid < res.Keys[j].Kid
+	})
+	return res
+}
@@ -0,0 +1,247 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/jwtx"
+)
+
+var (
+	secret0 = []byte("moyn8y9abnd7q4zkq2m73yw8tu9j5ixm")
+	secret1 = []byte("95osj3fUD7fo0mlYdDbncXz4VD2igvf0")
+)
+
+func TestKeyRing_Rotate(t *testing.T) {
+	ring, err := NewKeyRing("v0", Key{Kid: "v0", Secret: secret0})
+	require.NoError(t, err)
+	oldToken := signUserClaims(t, ring)
+
+	// 加一把新密钥，签名密钥不变
+	err = ring.Reset("v0", Key{Kid: "v0", Secret: secret0}, Key{Kid: "v1", Secret: secret1})
+	require.NoError(t, err)
+	assert.NoError(t, parseUserClaims(oldToken, ring))
+
+	// 换签名密钥，老 token 依旧可以用
+	err = ring.Reset("v1", Key{Kid: "v0", Secret: secret0}, Key{Kid: "v1", Secret: secret1})
+	require.NoError(t, err)
+	newToken := signUserClaims(t, ring)
+	assert.NoError(t, parseUserClaims(oldToken, ring))
+	assert.NoError(t, parseUserClaims(newToken, ring))
+	token, _, err := jwt.NewParser().ParseUnverified(newToken, &UserClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "v1", token.Header["kid"])
+
+	// 老密钥下线
+	err = ring.Reset("v1", Key{Kid: "v0", Secret: secret0, RetireAt: time.Now()},
+		Key{Kid: "v1", Secret: secret1})
+	require.NoError(t, err)
+	assert.ErrorIs(t, parseUserClaims(oldToken, ring), ErrKeyRetired)
+	assert.NoError(t, parseUserClaims(newToken, ring))
+
+	// 删掉老密钥
+	err = ring.Reset("v1", Key{Kid: "v1", Secret: secret1})
+	require.NoError(t, err)
+	assert.ErrorIs(t, parseUserClaims(oldToken, ring), ErrUnknownKid)
+}
+
+func TestKeyRing_Keyfunc(t *testing.T) {
+	ring, err := NewKeyRing("v1", Key{Kid: "v0", Secret: secret0}, Key{Kid: "v1", Secret: secret1})
+	require.NoError(t, err)
+	testCases := []struct {
+		name  string
+		token func(t *testing.T) string
+
+		wantErr error
+	}{
+		{
+			// 引入 kid 之前签发的
+			name: "没有 kid",
+			token: func(t *testing.T) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS512, UserClaims{Id: 1})
+				str, err := token.SignedString(secret0)
+				require.NoError(t, err)
+				return str
+			},
+		},
+		{
+			// 引入 kid 之前的 access token 是 HS256 签名的
+			name: "没有 kid，HS256",
+			token: func(t *testing.T) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, UserClaims{Id: 1})
+				str, err := token.SignedString(secret0)
+				require.NoError(t, err)
+				return str
+			},
+		},
+		{
+			// 只有老密钥才接受 HS256
+			name: "不是老密钥，HS256",
+			token: func(t *testing.T) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, UserClaims{Id: 1})
+				token.Header["kid"] = "v1"
+				str, err := token.SignedString(secret1)
+				require.NoError(t, err)
+				return str
+			},
+			wantErr: jwt.ErrTokenUnverifiable,
+		},
+		{
+			// 密钥是对的，但是换了一个 HMAC 算法
+			name: "算法和密钥对不上",
+			token: func(t *testing.T) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS384, UserClaims{Id: 1})
+				token.Header["kid"] = "v1"
+				str, err := token.SignedString(secret1)
+				require.NoError(t, err)
+				return str
+			},
+			wantErr: jwt.ErrTokenUnverifiable,
+		},
+		{
+			name: "未知的 kid",
+			token: func(t *testing.T) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS512, UserClaims{Id: 1})
+				token.Header["kid"] = "v2"
+				str, err := token.SignedString(secret1)
+				require.NoError(t, err)
+				return str
+			},
+			wantErr: ErrUnknownKid,
+		},
+		{
+			// kid 对得上，但是不是用这把密钥签名的
+			name: "kid 和密钥对不上",
+			token: func(t *testing.T) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS512, UserClaims{Id: 1})
+				token.Header["kid"] = "v1"
+				str, err := token.SignedString(secret0)
+				require.NoError(t, err)
+				return str
+			},
+			wantErr: jwt.ErrSignatureInvalid,
+		},
+		{
+			name: "不是 HMAC",
+			token: func(t *testing.T) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodNone, UserClaims{Id: 1})
+				token.Header["kid"] = "v1"
+				str, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+				require.NoError(t, err)
+				return str
+			},
+			wantErr: jwt.ErrTokenUnverifiable,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := parseUserClaims(tc.token(t), ring)
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestKeyRing_Reset(t *testing.T) {
+	testCases := []struct {
+		name   string
+		signer string
+		keys   []Key
+	}{
+		{
+			name:   "找不到签名密钥",
+			signer: "v1",
+			keys:   []Key{{Kid: "v0", Secret: secret0}},
+		},
+		{
+			name:   "签名密钥已经下线",
+			signer: "v0",
+			keys:   []Key{{Kid: "v0", Secret: secret0, RetireAt: time.Now().Add(-time.Minute)}},
+		},
+		{
+			name:   "密钥太短",
+			signer: "v0",
+			keys:   []Key{{Kid: "v0", Secret: []byte("short")}},
+		},
+		{
+			name:   "kid 重复",
+			signer: "v0",
+			keys:   []Key{{Kid: "v0", Secret: secret0}, {Kid: "v0", Secret: secret1}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ring, err := NewKeyRing("v0", Key{Kid: "v0", Secret: secret0})
+			require.NoError(t, err)
+			token := signUserClaims(t, ring)
+			err = ring.Reset(tc.signer, tc.keys...)
+			assert.ErrorIs(t, err, ErrInvalidKeySet)
+			// 配置不合法的时候保留原来的密钥
+			assert.Equal(t, "v0", ring.Signer())
+			assert.NoError(t, parseUserClaims(token, ring))
+		})
+	}
+}
+
+func TestKeyRing_EdDSA(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+	edPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	// 从共享密钥换成 EdDSA
+	ring, err := NewKeyRing("v1",
+		Key{Kid: "v0", Secret: secret0},
+		Key{Kid: "v1", Alg: AlgEdDSA, Secret: edPEM})
+	require.NoError(t, err)
+	tokenStr := signUserClaims(t, ring)
+	assert.NoError(t, parseUserClaims(tokenStr, ring))
+
+	// 只公布 EdDSA 的公钥，共享密钥不能公布
+	jwks := ring.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "v1", jwks.Keys[0].Kid)
+	assert.Equal(t, "EdDSA", jwks.Keys[0].Alg)
+
+	// 别的服务拿着公钥就能校验
+	uc, err := jwtx.NewVerifier(jwks).Verify(context.Background(), tokenStr)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), uc.Id)
+
+	// 公钥是公开的，不能拿来当 HMAC 的密钥伪造 token
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS512, UserClaims{Id: 1})
+	forged.Header["kid"] = "v1"
+	forgedStr, err := forged.SignedString([]byte(edKey.Public().(ed25519.PublicKey)))
+	require.NoError(t, err)
+	assert.ErrorIs(t, parseUserClaims(forgedStr, ring), jwt.ErrTokenUnverifiable)
+}
+
+func signUserClaims(t *testing.T, ring *KeyRing) string {
+	str, err := ring.Sign(UserClaims{
+		Id: 1,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	require.NoError(t, err)
+	return str
+}
+
+func parseUserClaims(tokenStr string, ring *KeyRing) error {
+	var uc UserClaims
+	_, err := jwt.ParseWithClaims(tokenStr, &uc, ring.Keyfunc)
+	return err
+}
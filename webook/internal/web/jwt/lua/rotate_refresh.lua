@@ -0,0 +1,24 @@
+-- 同一个 ssid 的 refresh token 是一个家族，只有最新签发的那个是有效的
+local key = KEYS[1]
+-- 和 ClearToken 用的是同一个 key
+local ssidKey = KEYS[2]
+-- 这一次用来刷新的 refresh token 的 ID
+local old = ARGV[1]
+-- 新签发的 refresh token 的 ID
+local new = ARGV[2]
+local ttl = tonumber(ARGV[3])
+
+local cur = redis.call("get", key)
+if cur == false then
+    -- 过期了，或者已经退出登录了
+    return 2
+end
+if cur ~= old then
+    -- 已经用过的 refresh token 又来了，说明它被偷了，
+    -- 分不清哪边是攻击者，整个 session 都作废
+    redis.call("del", key)
+    redis.call("set", ssidKey, "", "EX", ttl)
+    return 1
+end
+redis.call("set", key, new, "EX", ttl)
+return 0
@@ -9,9 +9,13 @@
 package jwtmocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gin "github.com/gin-gonic/gin"
+	jwt "github.com/golang-jwt/jwt/v5"
+	jwt0 "github.com/xiaoshanjiang/my-geektime/webook/internal/web/jwt"
+	jwtx "github.com/xiaoshanjiang/my-geektime/webook/pkg/jwtx"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -38,6 +42,21 @@ func (m *MockHandler) EXPECT() *MockHandlerMockRecorder {
 	return m.recorder
 }
 
+// AccessKeyfunc mocks base method.
+func (m *MockHandler) AccessKeyfunc(token *jwt.Token) (any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccessKeyfunc", token)
+	ret0, _ := ret[0].(any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AccessKeyfunc indicates an expected call of AccessKeyfunc.
+func (mr *MockHandlerMockRecorder) AccessKeyfunc(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccessKeyfunc", reflect.TypeOf((*MockHandler)(nil).AccessKeyfunc), token)
+}
+
 // CheckSession mocks base method.
 func (m *MockHandler) CheckSession(ctx *gin.Context, ssid string) error {
 	m.ctrl.T.Helper()
@@ -80,6 +99,92 @@ func (mr *MockHandlerMockRecorder) ExtractToken(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractToken", reflect.TypeOf((*MockHandler)(nil).ExtractToken), ctx)
 }
 
+// JWKS mocks base method.
+func (m *MockHandler) JWKS() jwtx.JWKSet {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].(jwtx.JWKSet)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockHandlerMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockHandler)(nil).JWKS))
+}
+
+// ListSessions mocks base method.
+func (m *MockHandler) ListSessions(ctx context.Context, uid int64) ([]jwt0.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", ctx, uid)
+	ret0, _ := ret[0].([]jwt0.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockHandlerMockRecorder) ListSessions(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockHandler)(nil).ListSessions), ctx, uid)
+}
+
+// RefreshKeyfunc mocks base method.
+func (m *MockHandler) RefreshKeyfunc(token *jwt.Token) (any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshKeyfunc", token)
+	ret0, _ := ret[0].(any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshKeyfunc indicates an expected call of RefreshKeyfunc.
+func (mr *MockHandlerMockRecorder) RefreshKeyfunc(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshKeyfunc", reflect.TypeOf((*MockHandler)(nil).RefreshKeyfunc), token)
+}
+
+// RefreshLoginToken mocks base method.
+func (m *MockHandler) RefreshLoginToken(ctx *gin.Context, rc jwt0.RefreshClaims) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshLoginToken", ctx, rc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshLoginToken indicates an expected call of RefreshLoginToken.
+func (mr *MockHandlerMockRecorder) RefreshLoginToken(ctx, rc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshLoginToken", reflect.TypeOf((*MockHandler)(nil).RefreshLoginToken), ctx, rc)
+}
+
+// RevokeAllSessions mocks base method.
+func (m *MockHandler) RevokeAllSessions(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllSessions", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllSessions indicates an expected call of RevokeAllSessions.
+func (mr *MockHandlerMockRecorder) RevokeAllSessions(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllSessions", reflect.TypeOf((*MockHandler)(nil).RevokeAllSessions), ctx, uid)
+}
+
+// RevokeSession mocks base method.
+func (m *MockHandler) RevokeSession(ctx context.Context, uid int64, ssid string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, uid, ssid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockHandlerMockRecorder) RevokeSession(ctx, uid, ssid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockHandler)(nil).RevokeSession), ctx, uid, ssid)
+}
+
 // SetJWTToken mocks base method.
 func (m *MockHandler) SetJWTToken(ctx *gin.Context, uid int64, ssid string) error {
 	m.ctrl.T.Helper()
@@ -107,3 +212,17 @@ func (mr *MockHandlerMockRecorder) SetLoginToken(ctx, uid any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLoginToken", reflect.TypeOf((*MockHandler)(nil).SetLoginToken), ctx, uid)
 }
+
+// TouchSession mocks base method.
+func (m *MockHandler) TouchSession(ctx context.Context, uid int64, ssid string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchSession", ctx, uid, ssid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchSession indicates an expected call of TouchSession.
+func (mr *MockHandlerMockRecorder) TouchSession(ctx, uid, ssid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchSession", reflect.TypeOf((*MockHandler)(nil).TouchSession), ctx, uid, ssid)
+}
@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	_ "embed"
 	"errors"
 	"fmt"
 	"strings"
@@ -10,26 +11,42 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/ginx"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/jwtx"
 )
 
 var (
-	AtKey = []byte("95osj3fUD7fo0mlYdDbncXz4VD2igvf0")
-	RtKey = []byte("95osj3fUD7fo0mlYdDbncXz4VD2igvfx")
+	//go:embed lua/rotate_refresh.lua
+	luaRotateRefresh string
+
+	ErrRefreshTokenReused  = errors.New("refresh token 被重复使用")
+	ErrRefreshTokenInvalid = errors.New("refresh token 已经失效")
+	ErrClaimsNotFound      = errors.New("ctx 里面没有登录信息")
 )
 
+// refreshTokenExpiration refresh token 的有效期，轮换的时候重新计算
+const refreshTokenExpiration = time.Hour * 24 * 7
+
 type RedisJWTHandler struct {
-	cmd redis.Cmdable
+	cmd  redis.Cmdable
+	keys Keys
 }
 
-func NewRedisJWTHandler(cmd redis.Cmdable) Handler {
+func NewRedisJWTHandler(cmd redis.Cmdable, keys Keys) Handler {
 	return &RedisJWTHandler{
-		cmd: cmd,
+		cmd:  cmd,
+		keys: keys,
 	}
 }
 
 func (h *RedisJWTHandler) SetLoginToken(ctx *gin.Context, uid int64) error {
 	ssid := uuid.New().String()
-	err := h.SetJWTToken(ctx, uid, ssid)
+	err := h.addSession(ctx, uid, ssid)
+	if err != nil {
+		return err
+	}
+	err = h.SetJWTToken(ctx, uid, ssid)
 	if err != nil {
 		return err
 	}
@@ -37,16 +54,63 @@ func (h *RedisJWTHandler) SetLoginToken(ctx *gin.Context, uid int64) error {
 	return err
 }
 
+// setRefreshToken 登录的时候开始一个新的 refresh token 家族
 func (h *RedisJWTHandler) setRefreshToken(ctx *gin.Context, uid int64, ssid string) error {
+	jti := uuid.New().String()
+	err := h.cmd.Set(ctx, h.refreshKey(ssid), jti, refreshTokenExpiration).Err()
+	if err != nil {
+		return err
+	}
+	return h.signRefreshToken(ctx, uid, ssid, jti)
+}
+
+// RefreshLoginToken 每个 refresh token 只能用一次，用了之后签发新的长短 token。
+// Redis 里面记录了每个 ssid 最新的 refresh token，
+// 拿着已经用过的 refresh token 来刷新的话，整个 ssid 都会作废
+func (h *RedisJWTHandler) RefreshLoginToken(ctx *gin.Context, rc RefreshClaims) error {
+	if rc.ID == "" {
+		// 没有 ID 的是引入轮换之前签发的，那时候还没有刷新的接口
+		return ErrRefreshTokenInvalid
+	}
+	jti := uuid.New().String()
+	res, err := h.cmd.Eval(ctx, luaRotateRefresh,
+		[]string{h.refreshKey(rc.Ssid), h.ssidKey(rc.Ssid)},
+		rc.ID, jti, int64(refreshTokenExpiration/time.Second)).Int()
+	if err != nil {
+		return err
+	}
+	switch res {
+	case 0:
+	case 1:
+		// 脚本里面已经作废了 ssid，这里只是把它从 session 索引里面删掉，失败了也没关系
+		_ = h.revokeSessions(ctx, rc.Id, rc.Ssid)
+		return ErrRefreshTokenReused
+	default:
+		return ErrRefreshTokenInvalid
+	}
+	err = h.SetJWTToken(ctx, rc.Id, rc.Ssid)
+	if err != nil {
+		return err
+	}
+	err = h.signRefreshToken(ctx, rc.Id, rc.Ssid, jti)
+	if err != nil {
+		return err
+	}
+	// 刷新也算访问了一次，失败了不影响刷新
+	_ = h.TouchSession(ctx, rc.Id, rc.Ssid)
+	return nil
+}
+
+func (h *RedisJWTHandler) signRefreshToken(ctx *gin.Context, uid int64, ssid, jti string) error {
 	claims := RefreshClaims{
 		Ssid: ssid,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24 * 7)),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(refreshTokenExpiration)),
 		},
 		Id: uid,
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
-	tokenStr, err := token.SignedString(RtKey)
+	tokenStr, err := h.keys.Refresh.Sign(claims)
 	if err != nil {
 		return err
 	}
@@ -58,13 +122,18 @@ func (h *RedisJWTHandler) ClearToken(ctx *gin.Context) error {
 	ctx.Header("x-jwt-token", "")
 	ctx.Header("x-refresh-token", "")
 
-	claims := ctx.MustGet("claims").(*UserClaims)
-	return h.cmd.Set(ctx, fmt.Sprintf("users:ssid:%s", claims.Ssid),
-		"", time.Hour*24*7).Err()
+	// 登录校验的 middleware 放进去的是值
+	val, _ := ctx.Get(ginx.ClaimsKey)
+	claims, ok := val.(UserClaims)
+	if !ok {
+		return ErrClaimsNotFound
+	}
+	// 作废当前的 session，refresh token 家族和 session 索引也一起清理掉
+	return h.revokeSessions(ctx, claims.Id, claims.Ssid)
 }
 
 func (h *RedisJWTHandler) CheckSession(ctx *gin.Context, ssid string) error {
-	val, err := h.cmd.Exists(ctx, fmt.Sprintf("users:ssid:%s", ssid)).Result()
+	val, err := h.cmd.Exists(ctx, h.ssidKey(ssid)).Result()
 	switch err {
 	case redis.Nil:
 		return nil
@@ -98,11 +167,32 @@ func (h *RedisJWTHandler) SetJWTToken(ctx *gin.Context, uid int64, ssid string)
 		Ssid:      ssid,
 		UserAgent: ctx.Request.UserAgent(),
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
-	tokenStr, err := token.SignedString(AtKey)
+	tokenStr, err := h.keys.Access.Sign(claims)
 	if err != nil {
 		return err
 	}
 	ctx.Header("x-jwt-token", tokenStr)
 	return nil
 }
+
+func (h *RedisJWTHandler) AccessKeyfunc(token *jwt.Token) (interface{}, error) {
+	return h.keys.Access.Keyfunc(token)
+}
+
+func (h *RedisJWTHandler) RefreshKeyfunc(token *jwt.Token) (interface{}, error) {
+	return h.keys.Refresh.Keyfunc(token)
+}
+
+func (h *RedisJWTHandler) JWKS() jwtx.JWKSet {
+	return h.keys.Access.JWKS()
+}
+
+// ssidKey 有这个 key 说明 ssid 已经作废了
+func (h *RedisJWTHandler) ssidKey(ssid string) string {
+	return fmt.Sprintf("users:ssid:%s", ssid)
+}
+
+// refreshKey 这个 ssid 最新的 refresh token 的 ID
+func (h *RedisJWTHandler) refreshKey(ssid string) string {
+	return fmt.Sprintf("users:refresh:%s", ssid)
+}
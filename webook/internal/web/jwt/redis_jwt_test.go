@@ -0,0 +1,188 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache/redismocks"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/ginx"
+)
+
+func TestRedisJWTHandler_RefreshLoginToken(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) redis.Cmdable
+		rc   RefreshClaims
+
+		wantErr error
+		// 成功的时候要换一个新的 refresh token
+		wantTokens bool
+	}{
+		{
+			name: "刷新成功",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().Eval(gomock.Any(), luaRotateRefresh,
+					[]string{"users:refresh:ssid-1", "users:ssid:ssid-1"},
+					"jti-1", gomock.Any(), int64(604800)).
+					Return(redis.NewCmdResult(int64(0), nil))
+				// 刷新也算访问了一次
+				cmd.EXPECT().Pipelined(gomock.Any(), gomock.Any()).Return(nil, nil)
+				return cmd
+			},
+			rc: RefreshClaims{Id: 123, Ssid: "ssid-1",
+				RegisteredClaims: jwt.RegisteredClaims{ID: "jti-1"}},
+			wantTokens: true,
+		},
+		{
+			// Lua 脚本里面已经把 ssid 作废了
+			name: "重复使用",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().Eval(gomock.Any(), luaRotateRefresh,
+					[]string{"users:refresh:ssid-1", "users:ssid:ssid-1"},
+					"jti-1", gomock.Any(), int64(604800)).
+					Return(redis.NewCmdResult(int64(1), nil))
+				// 从 session 索引里面删掉
+				cmd.EXPECT().TxPipelined(gomock.Any(), gomock.Any()).Return(nil, nil)
+				return cmd
+			},
+			rc: RefreshClaims{Id: 123, Ssid: "ssid-1",
+				RegisteredClaims: jwt.RegisteredClaims{ID: "jti-1"}},
+			wantErr: ErrRefreshTokenReused,
+		},
+		{
+			name: "家族已经过期",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().Eval(gomock.Any(), luaRotateRefresh,
+					[]string{"users:refresh:ssid-1", "users:ssid:ssid-1"},
+					"jti-1", gomock.Any(), int64(604800)).
+					Return(redis.NewCmdResult(int64(2), nil))
+				return cmd
+			},
+			rc: RefreshClaims{Id: 123, Ssid: "ssid-1",
+				RegisteredClaims: jwt.RegisteredClaims{ID: "jti-1"}},
+			wantErr: ErrRefreshTokenInvalid,
+		},
+		{
+			name: "没有 ID",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				return redismocks.NewMockCmdable(ctrl)
+			},
+			rc:      RefreshClaims{Id: 123, Ssid: "ssid-1"},
+			wantErr: ErrRefreshTokenInvalid,
+		},
+		{
+			name: "Redis 出错",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().Eval(gomock.Any(), luaRotateRefresh,
+					[]string{"users:refresh:ssid-1", "users:ssid:ssid-1"},
+					"jti-1", gomock.Any(), int64(604800)).
+					Return(redis.NewCmdResult(nil, errors.New("mock redis error")))
+				return cmd
+			},
+			rc: RefreshClaims{Id: 123, Ssid: "ssid-1",
+				RegisteredClaims: jwt.RegisteredClaims{ID: "jti-1"}},
+			wantErr: errors.New("mock redis error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			keys := testKeys(t)
+			hdl := NewRedisJWTHandler(tc.mock(ctrl), keys)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/users/refresh_token", nil)
+			err := hdl.RefreshLoginToken(ctx, tc.rc)
+			assert.Equal(t, tc.wantErr, err)
+			if !tc.wantTokens {
+				assert.Empty(t, recorder.Header().Get("x-refresh-token"))
+				return
+			}
+
+			var rc RefreshClaims
+			_, err = jwt.ParseWithClaims(recorder.Header().Get("x-refresh-token"),
+				&rc, keys.Refresh.Keyfunc)
+			require.NoError(t, err)
+			assert.Equal(t, tc.rc.Id, rc.Id)
+			assert.Equal(t, tc.rc.Ssid, rc.Ssid)
+			assert.NotEmpty(t, rc.ID)
+			assert.NotEqual(t, tc.rc.ID, rc.ID)
+
+			var uc UserClaims
+			_, err = jwt.ParseWithClaims(recorder.Header().Get("x-jwt-token"),
+				&uc, keys.Access.Keyfunc)
+			require.NoError(t, err)
+			assert.Equal(t, tc.rc.Ssid, uc.Ssid)
+		})
+	}
+}
+
+func TestRedisJWTHandler_ClearToken(t *testing.T) {
+	testCases := []struct {
+		name   string
+		mock   func(ctrl *gomock.Controller) redis.Cmdable
+		claims any
+
+		wantErr error
+	}{
+		{
+			name: "退出登录",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				// 作废当前 session，并且从 session 索引里面删掉
+				cmd.EXPECT().TxPipelined(gomock.Any(), gomock.Any()).Return(nil, nil)
+				return cmd
+			},
+			claims: UserClaims{Id: 123, Ssid: "ssid-1"},
+		},
+		{
+			name: "没有登录信息",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				return redismocks.NewMockCmdable(ctrl)
+			},
+			wantErr: ErrClaimsNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			hdl := NewRedisJWTHandler(tc.mock(ctrl), testKeys(t))
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/users/logout", nil)
+			if tc.claims != nil {
+				ctx.Set(ginx.ClaimsKey, tc.claims)
+			}
+			err := hdl.ClearToken(ctx)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func testKeys(t *testing.T) Keys {
+	access, err := NewKeyRing("v0", Key{Kid: "v0", Secret: secret0})
+	require.NoError(t, err)
+	refresh, err := NewKeyRing("v0", Key{Kid: "v0", Secret: secret1})
+	require.NoError(t, err)
+	return Keys{
+		Access:  access,
+		Refresh: refresh,
+	}
+}
@@ -0,0 +1,164 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrSessionNotFound = errors.New("登录设备不存在")
+
+// Session 一次登录就是一个 session，用 ssid 标识
+type Session struct {
+	Ssid      string
+	UserAgent string
+	IP        string
+	// 登录的时间
+	Ctime time.Time
+	// 最近一次访问的时间，登录校验的时候更新，有节流，不是很准
+	LastSeen time.Time
+}
+
+// sessionInfo 登录的时候写进去，之后不会改
+type sessionInfo struct {
+	UserAgent string `json:"ua"`
+	IP        string `json:"ip"`
+	Ctime     int64  `json:"ctime"`
+}
+
+// addSession 登录的时候把 session 加到用户的 session 索引里面。
+// 索引是两个 hash，一个放登录信息，一个放最近访问时间，
+// 更新最近访问时间的时候就不需要先读出来再写回去
+func (h *RedisJWTHandler) addSession(ctx *gin.Context, uid int64, ssid string) error {
+	now := time.Now().UnixMilli()
+	val, err := json.Marshal(sessionInfo{
+		UserAgent: ctx.Request.UserAgent(),
+		IP:        ctx.ClientIP(),
+		Ctime:     now,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = h.cmd.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, h.sessionsKey(uid), ssid, val)
+		pipe.HSet(ctx, h.sessionsSeenKey(uid), ssid, now)
+		// 最后一个 refresh token 过期之后，整个索引也就没用了
+		pipe.Expire(ctx, h.sessionsKey(uid), refreshTokenExpiration)
+		pipe.Expire(ctx, h.sessionsSeenKey(uid), refreshTokenExpiration)
+		return nil
+	})
+	return err
+}
+
+func (h *RedisJWTHandler) TouchSession(ctx context.Context, uid int64, ssid string) error {
+	_, err := h.cmd.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, h.sessionsSeenKey(uid), ssid, time.Now().UnixMilli())
+		pipe.Expire(ctx, h.sessionsKey(uid), refreshTokenExpiration)
+		pipe.Expire(ctx, h.sessionsSeenKey(uid), refreshTokenExpiration)
+		return nil
+	})
+	return err
+}
+
+func (h *RedisJWTHandler) ListSessions(ctx context.Context, uid int64) ([]Session, error) {
+	infos, err := h.cmd.HGetAll(ctx, h.sessionsKey(uid)).Result()
+	if err != nil {
+		return nil, err
+	}
+	seens, err := h.cmd.HGetAll(ctx, h.sessionsSeenKey(uid)).Result()
+	if err != nil {
+		return nil, err
+	}
+	// 超过 refresh token 有效期没有访问过的，已经没办法再用了
+	deadline := time.Now().Add(-refreshTokenExpiration)
+	res := make([]Session, 0, len(infos))
+	expired := make([]string, 0)
+	for ssid, val := range infos {
+		var info sessionInfo
+		if err = json.Unmarshal([]byte(val), &info); err != nil {
+			expired = append(expired, ssid)
+			continue
+		}
+		seen, _ := strconv.ParseInt(seens[ssid], 10, 64)
+		if seen < info.Ctime {
+			seen = info.Ctime
+		}
+		s := Session{
+			Ssid:      ssid,
+			UserAgent: info.UserAgent,
+			IP:        info.IP,
+			Ctime:     time.UnixMilli(info.Ctime),
+			LastSeen:  time.UnixMilli(seen),
+		}
+		if s.LastSeen.Before(deadline) {
+			expired = append(expired, ssid)
+			continue
+		}
+		res = append(res, s)
+	}
+	if len(expired) > 0 {
+		// 顺手清理掉，失败了也不影响，下次再清理
+		h.cmd.HDel(ctx, h.sessionsKey(uid), expired...)
+		h.cmd.HDel(ctx, h.sessionsSeenKey(uid), expired...)
+	}
+	// 最近访问的在前面
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].LastSeen.After(res[j].LastSeen)
+	})
+	return res, nil
+}
+
+func (h *RedisJWTHandler) RevokeSession(ctx context.Context, uid int64, ssid string) error {
+	// 只能作废自己的 session
+	ok, err := h.cmd.HExists(ctx, h.sessionsKey(uid), ssid).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSessionNotFound
+	}
+	return h.revokeSessions(ctx, uid, ssid)
+}
+
+func (h *RedisJWTHandler) RevokeAllSessions(ctx context.Context, uid int64) error {
+	ssids, err := h.cmd.HKeys(ctx, h.sessionsKey(uid)).Result()
+	if err != nil {
+		return err
+	}
+	if len(ssids) == 0 {
+		return nil
+	}
+	return h.revokeSessions(ctx, uid, ssids...)
+}
+
+// revokeSessions 和退出登录一样，用 users:ssid:%s 标记 session 已经作废，
+// CheckSession 会拒绝这些 session 的 access token 和 refresh token
+func (h *RedisJWTHandler) revokeSessions(ctx context.Context, uid int64, ssids ...string) error {
+	_, err := h.cmd.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, ssid := range ssids {
+			pipe.Set(ctx, h.ssidKey(ssid), "", refreshTokenExpiration)
+			pipe.Del(ctx, h.refreshKey(ssid))
+		}
+		pipe.HDel(ctx, h.sessionsKey(uid), ssids...)
+		pipe.HDel(ctx, h.sessionsSeenKey(uid), ssids...)
+		return nil
+	})
+	return err
+}
+
+// sessionsKey 用户的 session 索引，ssid => 登录信息
+func (h *RedisJWTHandler) sessionsKey(uid int64) string {
+	return fmt.Sprintf("users:sessions:%d", uid)
+}
+
+// sessionsSeenKey ssid => 最近访问时间
+func (h *RedisJWTHandler) sessionsSeenKey(uid int64) string {
+	return fmt.Sprintf("users:sessions:seen:%d", uid)
+}
@@ -0,0 +1,122 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache/redismocks"
+)
+
+func TestRedisJWTHandler_ListSessions(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	ms := func(t time.Time) string {
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	}
+	info := func(ctime time.Time) string {
+		return `{"ua":"Mozilla/5.0","ip":"127.0.0.1","ctime":` + ms(ctime) + `}`
+	}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) redis.Cmdable
+
+		wantSessions []Session
+		wantErr      error
+	}{
+		{
+			// ssid-3 已经八天没有访问了，refresh token 也过期了
+			name: "最近访问的在前面，清理过期的",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().HGetAll(gomock.Any(), "users:sessions:123").
+					Return(redis.NewMapStringStringResult(map[string]string{
+						"ssid-1": info(now.Add(-time.Hour * 2)),
+						"ssid-2": info(now.Add(-time.Hour)),
+						"ssid-3": info(now.Add(-time.Hour * 24 * 8)),
+					}, nil))
+				cmd.EXPECT().HGetAll(gomock.Any(), "users:sessions:seen:123").
+					Return(redis.NewMapStringStringResult(map[string]string{
+						"ssid-1": ms(now),
+					}, nil))
+				cmd.EXPECT().HDel(gomock.Any(), "users:sessions:123", "ssid-3").
+					Return(redis.NewIntResult(1, nil))
+				cmd.EXPECT().HDel(gomock.Any(), "users:sessions:seen:123", "ssid-3").
+					Return(redis.NewIntResult(0, nil))
+				return cmd
+			},
+			wantSessions: []Session{
+				{Ssid: "ssid-1", UserAgent: "Mozilla/5.0", IP: "127.0.0.1",
+					Ctime: now.Add(-time.Hour * 2), LastSeen: now},
+				// 没有访问记录的，最近访问时间就是登录时间
+				{Ssid: "ssid-2", UserAgent: "Mozilla/5.0", IP: "127.0.0.1",
+					Ctime: now.Add(-time.Hour), LastSeen: now.Add(-time.Hour)},
+			},
+		},
+		{
+			name: "Redis 出错",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().HGetAll(gomock.Any(), "users:sessions:123").
+					Return(redis.NewMapStringStringResult(nil, errors.New("mock redis error")))
+				return cmd
+			},
+			wantErr: errors.New("mock redis error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			hdl := NewRedisJWTHandler(tc.mock(ctrl), testKeys(t))
+			sessions, err := hdl.ListSessions(context.Background(), 123)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantSessions, sessions)
+		})
+	}
+}
+
+func TestRedisJWTHandler_RevokeSession(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) redis.Cmdable
+
+		wantErr error
+	}{
+		{
+			name: "作废成功",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().HExists(gomock.Any(), "users:sessions:123", "ssid-1").
+					Return(redis.NewBoolResult(true, nil))
+				cmd.EXPECT().TxPipelined(gomock.Any(), gomock.Any()).Return(nil, nil)
+				return cmd
+			},
+		},
+		{
+			// 不能作废别人的 session
+			name: "不是自己的 session",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().HExists(gomock.Any(), "users:sessions:123", "ssid-1").
+					Return(redis.NewBoolResult(false, nil))
+				return cmd
+			},
+			wantErr: ErrSessionNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			hdl := NewRedisJWTHandler(tc.mock(ctrl), testKeys(t))
+			err := hdl.RevokeSession(context.Background(), 123, "ssid-1")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -1,31 +1,55 @@
 package jwt
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
-)
 
-var JWTKey = []byte("moyn8y9abnd7q4zkq2m73yw8tu9j5ixm")
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/jwtx"
+)
 
 type Handler interface {
 	SetLoginToken(ctx *gin.Context, uid int64) error
 	SetJWTToken(ctx *gin.Context, uid int64, ssid string) error
+	// RefreshLoginToken 轮换 refresh token，同时签发新的 access token。
+	// refresh token 被重复使用的时候返回 ErrRefreshTokenReused，整个 session 都会作废
+	RefreshLoginToken(ctx *gin.Context, rc RefreshClaims) error
 	ClearToken(ctx *gin.Context) error
+	// CheckSession 退出登录、被作废了的 session 都会返回 error
 	CheckSession(ctx *gin.Context, ssid string) error
+	// TouchSession 更新 session 最近访问的时间
+	TouchSession(ctx context.Context, uid int64, ssid string) error
+	// ListSessions 还有效的 session，也就是登录的设备，最近访问的在前面
+	ListSessions(ctx context.Context, uid int64) ([]Session, error)
+	// RevokeSession 作废自己的某个 session，不是自己的返回 ErrSessionNotFound
+	RevokeSession(ctx context.Context, uid int64, ssid string) error
+	// RevokeAllSessions 在所有设备上退出登录
+	RevokeAllSessions(ctx context.Context, uid int64) error
 	ExtractToken(ctx *gin.Context) string
+	// AccessKeyfunc 校验 access token 的时候用，按照头部的 kid 找密钥
+	AccessKeyfunc(token *jwt.Token) (interface{}, error)
+	// RefreshKeyfunc 校验 refresh token 的时候用
+	RefreshKeyfunc(token *jwt.Token) (interface{}, error)
+	// JWKS access token 的公钥，别的服务拿来离线校验。
+	// 只有用 RS256 或者 EdDSA 签名的时候才有，refresh token 只有 webook 自己校验
+	JWKS() jwtx.JWKSet
+}
+
+// Keys access token 和 refresh token 用不同的密钥，
+// 免得拿着 access token 去刷新
+type Keys struct {
+	Access  *KeyRing
+	Refresh *KeyRing
 }
 
+// RefreshClaims 同一个 Ssid 的 refresh token 是一个家族，
+// RegisteredClaims.ID 是每个 refresh token 自己的 ID，轮换的时候会变
 type RefreshClaims struct {
 	Id   int64
 	Ssid string
 	jwt.RegisteredClaims
 }
 
-type UserClaims struct {
-	jwt.RegisteredClaims
-	// 声明你自己的要放进去 token 里面的数据
-	Id   int64
-	Ssid string
-	// 自己随便加
-	UserAgent string
-}
+// UserClaims 定义在 jwtx 里面，别的服务校验 token 的时候也要用
+type UserClaims = jwtx.UserClaims
@@ -8,12 +8,18 @@ import (
 	"github.com/ecodeclub/ekit/set"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	lru "github.com/hashicorp/golang-lru"
 	ijwt "github.com/xiaoshanjiang/my-geektime/webook/internal/web/jwt"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/ginx"
 )
 
 type JWTLoginMiddlewareBuilder struct {
 	publicPaths set.Set[string]
 	ijwt.Handler
+	// ssid => 上一次更新最近访问时间的时间，每个实例自己节流
+	lastTouched *lru.Cache
+	// 同一个 session 多久更新一次最近访问时间
+	touchInterval time.Duration
 }
 
 func NewLoginJWTMiddlewareBuilder(jwtHdl ijwt.Handler) *JWTLoginMiddlewareBuilder {
@@ -26,9 +32,26 @@ func NewLoginJWTMiddlewareBuilder(jwtHdl ijwt.Handler) *JWTLoginMiddlewareBuilde
 	s.Add("/oauth2/wechat/authurl")
 	s.Add("/oauth2/wechat/callback")
 	s.Add("/users/login")
+	// 带的是 refresh token，在 RefreshToken 里面校验
+	s.Add("/users/refresh_token")
+	// 忘记密码的时候肯定是没有登录的
+	s.Add("/users/password/forgot")
+	s.Add("/users/password/reset")
+	s.Add("/articles/pub/tag/list")
+	s.Add("/articles/pub/tags/suggest")
+	s.Add("/articles/pub/tags/counts")
+	s.Add("/articles/pub/search")
+	s.Add("/articles/pub/hot")
+	s.Add("/comments/list")
+	s.Add("/comments/replies")
+	s.Add("/.well-known/jwks.json")
+	// 只有在参数不对的时候才会返回 error
+	lastTouched, _ := lru.New(100000)
 	return &JWTLoginMiddlewareBuilder{
-		publicPaths: s,
-		Handler:     jwtHdl,
+		publicPaths:   s,
+		Handler:       jwtHdl,
+		lastTouched:   lastTouched,
+		touchInterval: time.Minute,
 	}
 }
 
@@ -57,9 +80,8 @@ func (j *JWTLoginMiddlewareBuilder) Build() gin.HandlerFunc {
 
 		tokenStr := authSegments[1]
 		uc := ijwt.UserClaims{}
-		token, err := jwt.ParseWithClaims(tokenStr, &uc, func(token *jwt.Token) (interface{}, error) {
-			return ijwt.JWTKey, nil
-		})
+		// 按照 token 头部的 kid 找密钥，轮换密钥的时候新老 token 都能通过
+		token, err := jwt.ParseWithClaims(tokenStr, &uc, j.AccessKeyfunc)
 		if err != nil || !token.Valid || uc.Id == 0 {
 			// 不正确的 token
 			ctx.AbortWithStatus(http.StatusUnauthorized)
@@ -95,6 +117,18 @@ func (j *JWTLoginMiddlewareBuilder) Build() gin.HandlerFunc {
 
 		// 说明 token 是合法的
 		// 我们把这个 token 里面的数据放到 ctx 里面，后面用的时候就不用再次 Parse 了
-		ctx.Set("user", uc)
+		ctx.Set(ginx.ClaimsKey, uc)
+		j.touch(ctx, uc)
 	}
 }
+
+// touch 更新 session 的最近访问时间，同一个 session 每个实例每分钟最多更新一次，
+// 免得每个请求都写一次 Redis。失败了也不影响请求
+func (j *JWTLoginMiddlewareBuilder) touch(ctx *gin.Context, uc ijwt.UserClaims) {
+	now := time.Now()
+	if val, ok := j.lastTouched.Get(uc.Ssid); ok && now.Sub(val.(time.Time)) < j.touchInterval {
+		return
+	}
+	j.lastTouched.Add(uc.Ssid, now)
+	_ = j.TouchSession(ctx, uc.Id, uc.Ssid)
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	ijwt "github.com/xiaoshanjiang/my-geektime/webook/internal/web/jwt"
+	jwtmocks "github.com/xiaoshanjiang/my-geektime/webook/internal/web/jwt/mocks"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/ginx"
+)
+
+// 从 middleware 一路走到 ginx.WrapToken 包装的 handler，
+// 确认 middleware 放进去的 claims 能被 handler 拿到
+func TestJWTLoginMiddlewareBuilder_WrapToken(t *testing.T) {
+	ring, err := ijwt.NewKeyRing(ijwt.LegacyKid, ijwt.Key{
+		Kid:    ijwt.LegacyKid,
+		Secret: []byte("moyn8y9abnd7q4zkq2m73yw8tu9j5ixm"),
+	})
+	require.NoError(t, err)
+	token, err := ring.Sign(ijwt.UserClaims{
+		Id:        123,
+		Ssid:      "ssid-1",
+		UserAgent: "Mozilla/5.0",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) ijwt.Handler
+		auth string
+
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "登录了",
+			mock: func(ctrl *gomock.Controller) ijwt.Handler {
+				hdl := jwtmocks.NewMockHandler(ctrl)
+				hdl.EXPECT().AccessKeyfunc(gomock.Any()).DoAndReturn(ring.Keyfunc)
+				hdl.EXPECT().CheckSession(gomock.Any(), "ssid-1").Return(nil)
+				hdl.EXPECT().TouchSession(gomock.Any(), int64(123), "ssid-1").Return(nil)
+				return hdl
+			},
+			auth:     "Bearer " + token,
+			wantCode: http.StatusOK,
+			wantBody: `{"code":0,"msg":"","data":123}`,
+		},
+		{
+			name: "没有登录",
+			mock: func(ctrl *gomock.Controller) ijwt.Handler {
+				return jwtmocks.NewMockHandler(ctrl)
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.New()
+			server.Use(NewLoginJWTMiddlewareBuilder(tc.mock(ctrl)).Build())
+			server.POST("/users/profile", ginx.WrapToken[ijwt.UserClaims](
+				func(ctx *gin.Context, uc ijwt.UserClaims) (ginx.Result, error) {
+					return ginx.Result{Data: uc.Id}, nil
+				}))
+
+			req := httptest.NewRequest(http.MethodPost, "/users/profile", nil)
+			req.Header.Set("User-Agent", "Mozilla/5.0")
+			if tc.auth != "" {
+				req.Header.Set("Authorization", tc.auth)
+			}
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			if tc.wantBody != "" {
+				assert.JSONEq(t, tc.wantBody, recorder.Body.String())
+			}
+		})
+	}
+}
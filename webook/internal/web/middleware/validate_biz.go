@@ -6,7 +6,7 @@ package middleware
 //		bizId := ctx.GetHeader("biz_id")
 //		// order
 //		biz := ctx.GetHeader("biz")
-//		uc := ctx.MustGet("user").(jwt.UserClaims)
+//		uc := ctx.MustGet("claims").(jwt.UserClaims)
 //      单体应用就是数据库，
 //      微服务呢？调用微服务 - 做客户端缓存
 //		validate(biz, bizId, uc.Id)
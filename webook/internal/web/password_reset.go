@@ -0,0 +1,134 @@
+package web
+
+import (
+	regexp "github.com/dlclark/regexp2"
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	ijwt "github.com/xiaoshanjiang/my-geektime/webook/internal/web/jwt"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/ginx"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+var _ handler = (*PasswordResetHandler)(nil)
+
+// PasswordResetHandler 忘记密码，通过邮件里面的链接重置密码
+type PasswordResetHandler struct {
+	svc              service.PasswordResetService
+	jwtHdl           ijwt.Handler
+	emailRegexExp    *regexp.Regexp
+	passwordRegexExp *regexp.Regexp
+	l                logger.LoggerV1
+}
+
+func NewPasswordResetHandler(svc service.PasswordResetService,
+	jwtHdl ijwt.Handler, l logger.LoggerV1) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		svc:              svc,
+		jwtHdl:           jwtHdl,
+		emailRegexExp:    regexp.MustCompile(emailRegexPattern, regexp.None),
+		passwordRegexExp: regexp.MustCompile(passwordRegexPattern, regexp.None),
+		l:                l,
+	}
+}
+
+func (h *PasswordResetHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/users/password")
+	// 这两个接口都不需要登录
+	g.POST("/forgot", ginx.WrapBody[ForgotPasswordReq](h.l, h.Forgot))
+	g.POST("/reset", ginx.WrapBody[ResetPasswordReq](h.l, h.Reset))
+}
+
+func (h *PasswordResetHandler) Forgot(ctx *gin.Context, req ForgotPasswordReq) (ginx.Result, error) {
+	isEmail, err := h.emailRegexExp.MatchString(req.Email)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	if !isEmail {
+		return ginx.Result{
+			Code: 4,
+			Msg:  "邮箱不正确",
+		}, nil
+	}
+	err = h.svc.SendResetLink(ctx, req.Email)
+	switch err {
+	case nil:
+		// 不管邮箱有没有注册，都是这个提示
+		return ginx.Result{
+			Msg: "如果这个邮箱已经注册，你会收到一封重置密码的邮件",
+		}, nil
+	case service.ErrPasswordResetTooMany:
+		return ginx.Result{
+			Code: 4,
+			Msg:  "发送太频繁，请稍后再试",
+		}, nil
+	default:
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+}
+
+func (h *PasswordResetHandler) Reset(ctx *gin.Context, req ResetPasswordReq) (ginx.Result, error) {
+	if req.Password != req.ConfirmPassword {
+		return ginx.Result{
+			Code: 4,
+			Msg:  "两次输入的密码不相同",
+		}, nil
+	}
+	isPassword, err := h.passwordRegexExp.MatchString(req.Password)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	if !isPassword {
+		return ginx.Result{
+			Code: 4,
+			Msg:  "密码必须包含数字、特殊字符，并且长度不能小于 8 位",
+		}, nil
+	}
+	uid, err := h.svc.Reset(ctx, req.Token, req.Password)
+	switch err {
+	case nil:
+	case service.ErrInvalidResetToken:
+		return ginx.Result{
+			Code: 4,
+			Msg:  "链接已经失效，请重新发送重置密码的邮件",
+		}, nil
+	default:
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	// 密码可能已经泄露了，所有设备都要重新登录
+	err = h.jwtHdl.RevokeAllSessions(ctx, uid)
+	if err != nil {
+		h.l.Error("重置密码之后退出所有设备失败",
+			logger.Int64("uid", uid), logger.Error(err))
+		return ginx.Result{
+			Code: 5,
+			Msg:  "密码已经重置，但是退出其它设备失败，请登录之后在设备管理里面退出",
+		}, nil
+	}
+	return ginx.Result{
+		Msg: "密码已经重置，请重新登录",
+	}, nil
+}
+
+type ForgotPasswordReq struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordReq struct {
+	// 邮件里面的链接带的 token
+	Token           string `json:"token"`
+	Password        string `json:"password"`
+	ConfirmPassword string `json:"confirmPassword"`
+}
@@ -0,0 +1,63 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/ginx"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+var _ handler = (*RankingHandler)(nil)
+
+type RankingHandler struct {
+	svc     service.RankingService
+	intrSvc service.InteractiveService
+	l       logger.LoggerV1
+}
+
+func NewRankingHandler(svc service.RankingService,
+	intrSvc service.InteractiveService, l logger.LoggerV1) *RankingHandler {
+	return &RankingHandler{
+		svc:     svc,
+		intrSvc: intrSvc,
+		l:       l,
+	}
+}
+
+func (h *RankingHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/articles/pub")
+	// 热榜不需要登录
+	g.GET("/hot", h.Hot)
+}
+
+func (h *RankingHandler) Hot(ctx *gin.Context) {
+	arts, err := h.svc.GetTopN(ctx)
+	if err != nil {
+		h.l.Error("获取热榜失败", logger.Error(err))
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	vos := slice.Map[domain.Article, ArticleVO](arts,
+		func(idx int, src domain.Article) ArticleVO {
+			return ArticleVO{
+				Id:       src.Id,
+				Title:    src.Title,
+				Abstract: src.Abstract(),
+				Author:   src.Author.Name,
+				Utime:    src.Utime.Format(time.DateTime),
+			}
+		})
+	// 热榜不需要登录，只带计数
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: withInteractive(ctx, h.intrSvc, h.l, 0, vos),
+	})
+}
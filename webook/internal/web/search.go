@@ -0,0 +1,86 @@
+package web
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/ginx"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+var _ handler = (*SearchHandler)(nil)
+
+type SearchHandler struct {
+	svc service.SearchService
+	l   logger.LoggerV1
+}
+
+func NewSearchHandler(svc service.SearchService, l logger.LoggerV1) *SearchHandler {
+	return &SearchHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *SearchHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/articles/pub")
+	// 搜索不需要登录
+	g.POST("/search", ginx.WrapBody[SearchReq](h.l, h.Search))
+}
+
+func (h *SearchHandler) Search(ctx *gin.Context, req SearchReq) (ginx.Result, error) {
+	query := strings.TrimSpace(req.Query)
+	if query == "" || len([]rune(query)) > 64 {
+		return ginx.Result{
+			Code: 4,
+			Msg:  "输入有误",
+		}, nil
+	}
+	res, err := h.svc.SearchArticles(ctx, query, req.Offset, req.Limit)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	return ginx.Result{
+		Data: SearchResultVO{
+			Total: res.Total,
+			Hits: slice.Map[domain.ArticleSearchHit, SearchHitVO](res.Hits,
+				func(idx int, src domain.ArticleSearchHit) SearchHitVO {
+					return SearchHitVO{
+						Id:      src.Article.Id,
+						Title:   src.Title,
+						Snippet: src.Snippet,
+						Score:   src.Score,
+						Utime:   src.Article.Utime.Format(time.DateTime),
+					}
+				}),
+		},
+	}, nil
+}
+
+type SearchReq struct {
+	Query  string `json:"query"`
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+}
+
+type SearchResultVO struct {
+	Total int           `json:"total"`
+	Hits  []SearchHitVO `json:"hits"`
+}
+
+type SearchHitVO struct {
+	Id int64 `json:"id"`
+	// Title 和 Snippet 里面命中的部分用 <em> 包裹，其余部分已经做过 HTML 转义
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+	Utime   string  `json:"utime"`
+}
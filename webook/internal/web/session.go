@@ -0,0 +1,157 @@
+package web
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+
+	ijwt "github.com/xiaoshanjiang/my-geektime/webook/internal/web/jwt"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/ginx"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+var _ handler = (*SessionHandler)(nil)
+
+// SessionHandler 登录设备管理，每次登录都是一个 session
+type SessionHandler struct {
+	jwtHdl ijwt.Handler
+	l      logger.LoggerV1
+}
+
+func NewSessionHandler(jwtHdl ijwt.Handler, l logger.LoggerV1) *SessionHandler {
+	return &SessionHandler{
+		jwtHdl: jwtHdl,
+		l:      l,
+	}
+}
+
+func (h *SessionHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/users/sessions")
+	g.POST("/list", ginx.WrapToken[ijwt.UserClaims](h.List))
+	g.POST("/revoke", ginx.WrapBodyAndToken[RevokeSessionReq, ijwt.UserClaims](h.Revoke))
+	// 在所有设备上退出登录，包括当前这个
+	g.POST("/revoke_all", ginx.WrapToken[ijwt.UserClaims](h.RevokeAll))
+}
+
+func (h *SessionHandler) List(ctx *gin.Context, uc ijwt.UserClaims) (ginx.Result, error) {
+	sessions, err := h.jwtHdl.ListSessions(ctx, uc.Id)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	return ginx.Result{
+		Data: slice.Map[ijwt.Session, SessionVO](sessions, func(idx int, src ijwt.Session) SessionVO {
+			return SessionVO{
+				Ssid:     src.Ssid,
+				Device:   deviceFromUserAgent(src.UserAgent),
+				IP:       src.IP,
+				Ctime:    src.Ctime.Format(time.DateTime),
+				LastSeen: src.LastSeen.Format(time.DateTime),
+				Current:  src.Ssid == uc.Ssid,
+			}
+		}),
+	}, nil
+}
+
+func (h *SessionHandler) Revoke(ctx *gin.Context, req RevokeSessionReq, uc ijwt.UserClaims) (ginx.Result, error) {
+	err := h.jwtHdl.RevokeSession(ctx, uc.Id, req.Ssid)
+	switch err {
+	case nil:
+		return ginx.Result{
+			Msg: "OK",
+		}, nil
+	case ijwt.ErrSessionNotFound:
+		return ginx.Result{
+			Code: 4,
+			Msg:  "登录设备不存在",
+		}, nil
+	default:
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+}
+
+func (h *SessionHandler) RevokeAll(ctx *gin.Context, uc ijwt.UserClaims) (ginx.Result, error) {
+	err := h.jwtHdl.RevokeAllSessions(ctx, uc.Id)
+	if err != nil {
+		return ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		}, err
+	}
+	ctx.Header("x-jwt-token", "")
+	ctx.Header("x-refresh-token", "")
+	return ginx.Result{
+		Msg: "OK",
+	}, nil
+}
+
+// deviceFromUserAgent 粗略地从 User-Agent 里面看出是什么浏览器和系统，
+// 比如说 Chrome / macOS，只是给用户辨认自己的设备用的
+func deviceFromUserAgent(ua string) string {
+	browser := ""
+	switch {
+	case strings.Contains(ua, "MicroMessenger"):
+		browser = "微信"
+	case strings.Contains(ua, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(ua, "Firefox/"):
+		browser = "Firefox"
+	// Chrome 的 User-Agent 里面也有 Safari，所以要先判断 Chrome
+	case strings.Contains(ua, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		browser = "Safari"
+	}
+	os := ""
+	switch {
+	// iPhone 的 User-Agent 里面也有 Mac OS X，所以要先判断 iPhone
+	case strings.Contains(ua, "iPhone"):
+		os = "iPhone"
+	case strings.Contains(ua, "iPad"):
+		os = "iPad"
+	case strings.Contains(ua, "Android"):
+		os = "Android"
+	case strings.Contains(ua, "Windows"):
+		os = "Windows"
+	case strings.Contains(ua, "Mac OS X"):
+		os = "macOS"
+	case strings.Contains(ua, "Linux"):
+		os = "Linux"
+	}
+	switch {
+	case browser != "" && os != "":
+		return browser + " / " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	default:
+		return "未知设备"
+	}
+}
+
+type RevokeSessionReq struct {
+	Ssid string `json:"ssid"`
+}
+
+type SessionVO struct {
+	Ssid string `json:"ssid"`
+	// 从 User-Agent 里面看出来的浏览器和系统
+	Device string `json:"device"`
+	// 登录的时候的 IP
+	IP string `json:"ip"`
+	// 登录的时间
+	Ctime    string `json:"ctime"`
+	LastSeen string `json:"lastSeen"`
+	// 是不是当前正在用的这个
+	Current bool `json:"current"`
+}
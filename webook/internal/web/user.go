@@ -11,7 +11,9 @@ import (
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/domain"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
 	ijwt "github.com/xiaoshanjiang/my-geektime/webook/internal/web/jwt"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/ginx"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
@@ -31,6 +33,7 @@ var _ handler = (*UserHandler)(nil)
 type UserHandler struct {
 	svc              service.UserService
 	codeSvc          service.CodeService
+	followSvc        service.FollowService
 	emailRegexExp    *regexp.Regexp
 	passwordRegexExp *regexp.Regexp
 	// 只有在使用 JWT 的时候才有用
@@ -38,10 +41,13 @@ type UserHandler struct {
 }
 
 func NewUserHandler(svc service.UserService,
-	codeSvc service.CodeService, jwtHdl ijwt.Handler) *UserHandler {
+	codeSvc service.CodeService,
+	followSvc service.FollowService,
+	jwtHdl ijwt.Handler) *UserHandler {
 	return &UserHandler{
 		svc:              svc,
 		codeSvc:          codeSvc,
+		followSvc:        followSvc,
 		emailRegexExp:    regexp.MustCompile(emailRegexPattern, regexp.None),
 		passwordRegexExp: regexp.MustCompile(passwordRegexPattern, regexp.None),
 		Handler:          jwtHdl,
@@ -62,6 +68,7 @@ func (c *UserHandler) RegisterRoutes(server *gin.Engine) {
 	//ug.POST("/login", c.Login)   // session 机制
 	ug.POST("/login", c.LoginJWT) // JWT 机制
 	ug.POST("/logout", c.LogoutJWT)
+	ug.POST("/refresh_token", c.RefreshToken)
 	ug.POST("/edit", c.Edit)
 	//ug.GET("/profile", c.Profile)   // session 机制
 	ug.GET("/profile", c.ProfileJWT) // JWT 机制
@@ -83,15 +90,13 @@ func (u *UserHandler) LogoutJWT(ctx *gin.Context) {
 	})
 }
 
-// RefreshToken 可以同时刷新长短 token，用 redis 来记录是否有效，即 refresh_token 是一次性的
-// 参考登录校验部分，比较 User-Agent 来增强安全性
+// RefreshToken 同时刷新长短 token，refresh token 是一次性的，每次刷新都会换一个新的。
+// 用过的 refresh token 再拿来刷新，说明它可能被偷了，整个登录态都会作废
 func (u *UserHandler) RefreshToken(ctx *gin.Context) {
 	// 只有这个接口，拿出来的才是 refresh_token，其它地方都是 access token
 	refreshToken := u.ExtractToken(ctx)
 	var rc ijwt.RefreshClaims
-	token, err := jwt.ParseWithClaims(refreshToken, &rc, func(token *jwt.Token) (interface{}, error) {
-		return ijwt.RtKey, nil
-	})
+	token, err := jwt.ParseWithClaims(refreshToken, &rc, u.RefreshKeyfunc)
 	if err != nil || !token.Valid {
 		zap.L().Error("2TS9bvGP3LQkMRZZmND1fhJ9 系统异常", zap.Error(err))
 		ctx.AbortWithStatus(http.StatusUnauthorized)
@@ -105,19 +110,26 @@ func (u *UserHandler) RefreshToken(ctx *gin.Context) {
 		ctx.AbortWithStatus(http.StatusUnauthorized)
 		return
 	}
-	// 搞个新的 access_token
-	err = u.SetJWTToken(ctx, rc.Id, rc.Ssid)
-	if err != nil {
+	// 换一个新的 refresh_token，顺便搞个新的 access_token
+	err = u.RefreshLoginToken(ctx, rc)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "刷新成功",
+		})
+	case ijwt.ErrRefreshTokenReused:
+		zap.L().Warn("Xq3bLrM8dTz7VhK2pNcA9sWe refresh token 被重复使用，session 已作废",
+			zap.Int64("uid", rc.Id), zap.String("ssid", rc.Ssid))
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+	case ijwt.ErrRefreshTokenInvalid:
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+	default:
 		// 正常来说，msg 的部分就应该包含足够的定位信息
-		zap.L().Error("0QKxctrgT4LYWd5P2xZMjP4X 设置JWT token出现异常",
+		zap.L().Error("0QKxctrgT4LYWd5P2xZMjP4X 刷新 token 出现异常",
 			zap.Error(err),
 			zap.String("method", "UserHandler:RefreshToken"))
-		ctx.AbortWithStatus(http.StatusUnauthorized)
-		return
+		ctx.JSON(http.StatusOK, Result{Code: 5, Msg: "系统错误"})
 	}
-	ctx.JSON(http.StatusOK, Result{
-		Msg: "刷新成功",
-	})
 }
 
 func (c *UserHandler) LoginSMS(ctx *gin.Context) {
@@ -260,7 +272,8 @@ func (c *UserHandler) LoginJWT(ctx *gin.Context) {
 		ctx.String(http.StatusOK, "用户名或者密码不正确，请重试")
 		return
 	}
-	err = c.setJWTToken(ctx, u.Id)
+	// 和短信登录一样，同时返回长短 token
+	err = c.SetLoginToken(ctx, u.Id)
 	if err != nil {
 		ctx.String(http.StatusOK, "系统异常")
 		return
@@ -268,25 +281,6 @@ func (c *UserHandler) LoginJWT(ctx *gin.Context) {
 	ctx.String(http.StatusOK, "登录成功")
 }
 
-func (c *UserHandler) setJWTToken(ctx *gin.Context, uid int64) error {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, ijwt.UserClaims{
-		Id:        uid,
-		UserAgent: ctx.GetHeader("User-Agent"),
-		RegisteredClaims: jwt.RegisteredClaims{
-			// 演示目的设置为一分钟过期
-			//ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
-			// 在压测的时候，要将过期时间设置更长一些
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 30)),
-		},
-	})
-	tokenStr, err := token.SignedString(ijwt.JWTKey)
-	if err != nil {
-		return err
-	}
-	ctx.Header("x-jwt-token", tokenStr)
-	return nil
-}
-
 // Login 用户登录接口
 func (c *UserHandler) Login(ctx *gin.Context) {
 	type LoginReq struct {
@@ -354,7 +348,7 @@ func (c *UserHandler) Edit(ctx *gin.Context) {
 		return
 	}
 
-	uc := ctx.MustGet("user").(ijwt.UserClaims)
+	uc := ctx.MustGet(ginx.ClaimsKey).(ijwt.UserClaims)
 	err = c.svc.UpdateNonSensitiveInfo(ctx, domain.User{
 		Id:       uc.Id,
 		Nickname: req.Nickname,
@@ -376,21 +370,40 @@ func (c *UserHandler) ProfileJWT(ctx *gin.Context) {
 		Nickname string
 		Birthday string
 		AboutMe  string
-	}
-	uc := ctx.MustGet("claims").(ijwt.UserClaims)
-	u, err := c.svc.Profile(ctx, uc.Id)
-	if err != nil {
+		// 粉丝数和关注数
+		Followers int64
+		Followees int64
+	}
+	uc := ctx.MustGet(ginx.ClaimsKey).(ijwt.UserClaims)
+	var (
+		eg      errgroup.Group
+		u       domain.User
+		statics domain.FollowStatics
+	)
+	eg.Go(func() error {
+		var err error
+		u, err = c.svc.Profile(ctx, uc.Id)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		statics, err = c.followSvc.GetStatics(ctx, uc.Id)
+		return err
+	})
+	if err := eg.Wait(); err != nil {
 		// 按照道理来说，这边 id 对应的数据肯定存在，所以要是没找到，
 		// 那就说明是系统出了问题。
 		ctx.String(http.StatusOK, "系统错误")
 		return
 	}
 	ctx.JSON(http.StatusOK, Profile{
-		Email:    u.Email,
-		Phone:    u.Phone,
-		Nickname: u.Nickname,
-		Birthday: u.Birthday.Format(time.DateOnly),
-		AboutMe:  u.AboutMe,
+		Email:     u.Email,
+		Phone:     u.Phone,
+		Nickname:  u.Nickname,
+		Birthday:  u.Birthday.Format(time.DateOnly),
+		AboutMe:   u.AboutMe,
+		Followers: statics.Followers,
+		Followees: statics.Followees,
 	})
 }
 
@@ -204,7 +204,7 @@ func TestUserHandler_SignUp(t *testing.T) {
 			defer ctrl.Finish()
 			usersvc, codesvc, jwthdl := tc.mock(ctrl)
 			// 利用 mock 来构造 UserHandler
-			hdl := NewUserHandler(usersvc, codesvc, jwthdl)
+			hdl := NewUserHandler(usersvc, codesvc, nil, jwthdl)
 
 			// 注册路由
 			server := gin.Default()
@@ -243,6 +243,7 @@ func TestUserHandler_LoginJWT(t *testing.T) {
 
 				codesvc := svcmocks.NewMockCodeService(ctrl)
 				hdl := jwtmocks.NewMockHandler(ctrl)
+				hdl.EXPECT().SetLoginToken(gomock.Any(), int64(0)).Return(nil)
 				return usersvc, codesvc, hdl
 			},
 			reqBuilder: func(t *testing.T) *http.Request {
@@ -310,7 +311,7 @@ func TestUserHandler_LoginJWT(t *testing.T) {
 			defer ctrl.Finish()
 			usersvc, codesvc, jwthdl := tc.mock(ctrl)
 			// 利用 mock 来构造 UserHandler
-			hdl := NewUserHandler(usersvc, codesvc, jwthdl)
+			hdl := NewUserHandler(usersvc, codesvc, nil, jwthdl)
 
 			// 注册路由
 			server := gin.Default()
@@ -398,7 +399,7 @@ func TestEmailPattern(t *testing.T) {
 		},
 	}
 
-	h := NewUserHandler(nil, nil, nil)
+	h := NewUserHandler(nil, nil, nil, nil)
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -437,7 +438,7 @@ func TestPasswordPattern(t *testing.T) {
 		},
 	}
 
-	h := NewUserHandler(nil, nil, nil)
+	h := NewUserHandler(nil, nil, nil, nil)
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -0,0 +1,69 @@
+package ioc
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/ecodeclub/ekit"
+	"github.com/spf13/viper"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/blob"
+)
+
+// InitBlobStorage 按照配置选择对象存储
+//
+//	oss:
+//	  type: "s3" # s3 或者 local
+//	  bucket: "webook-1314583317"
+//	  region: "ap-nanjing"
+//	  endpoint: "https://cos.ap-nanjing.myqcloud.com"
+//	  dir: "./data/oss" # local 的时候才有用
+//
+// s3 的密钥从环境变量 COS_APP_ID 和 COS_APP_SECRET 里面读取
+func InitBlobStorage() blob.Storage {
+	type Config struct {
+		Type     string `yaml:"type"`
+		Bucket   string `yaml:"bucket"`
+		Region   string `yaml:"region"`
+		Endpoint string `yaml:"endpoint"`
+		Dir      string `yaml:"dir"`
+	}
+	c := Config{
+		Type: "local",
+		Dir:  "./data/oss",
+	}
+	err := viper.UnmarshalKey("oss", &c)
+	if err != nil {
+		panic(fmt.Errorf("初始化配置失败 %v, 原因 %w", c, err))
+	}
+	switch c.Type {
+	case "local":
+		s, err := blob.NewLocalStorage(c.Dir)
+		if err != nil {
+			panic(err)
+		}
+		return s
+	case "s3":
+		if c.Bucket == "" {
+			panic("没有配置 oss.bucket")
+		}
+		sess, err := session.NewSession(&aws.Config{
+			Credentials: credentials.NewStaticCredentials(
+				os.Getenv("COS_APP_ID"), os.Getenv("COS_APP_SECRET"), ""),
+			Region:   ekit.ToPtr[string](c.Region),
+			Endpoint: ekit.ToPtr[string](c.Endpoint),
+			// 强制使用 /bucket/key 的形态
+			S3ForcePathStyle: ekit.ToPtr[bool](true),
+		})
+		if err != nil {
+			panic(err)
+		}
+		return blob.NewS3Storage(s3.New(sess), c.Bucket)
+	default:
+		panic(fmt.Errorf("未知的对象存储 %s", c.Type))
+	}
+}
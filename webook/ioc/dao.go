@@ -0,0 +1,31 @@
+package ioc
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao/article"
+)
+
+// InitArticleDAO 按照配置选择文章的存储，默认是 MySQL
+//
+//	article:
+//	  dao: "mongo" # gorm, mongo 或者 oss
+//
+// 选择 mongo 的时候才会连接 MongoDB。
+// oss 的元数据保存在 MySQL，线上库的内容保存在对象存储，具体看 InitBlobStorage
+func InitArticleDAO(db *gorm.DB) article.ArticleDAO {
+	typ := viper.GetString("article.dao")
+	switch typ {
+	case "", "gorm":
+		return article.NewGORMArticleDAO(db)
+	case "mongo":
+		return article.NewMongoDBDAOWithOutbox(InitMongoDB(), InitSnowflakeNode(), db)
+	case "oss":
+		return article.NewOssDAO(InitBlobStorage(), db)
+	default:
+		panic(fmt.Errorf("未知的文章存储 %s", typ))
+	}
+}
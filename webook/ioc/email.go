@@ -0,0 +1,65 @@
+package ioc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service/email"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service/email/localemail"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+// InitEmailService 按照配置选择邮件的实现，目前只有本地的实现
+//
+//	email:
+//	  type: local
+//	  # 不配置的话直接打印出来
+//	  file: ./data/email.log
+func InitEmailService() email.Service {
+	type Config struct {
+		Type string `yaml:"type"`
+		File string `yaml:"file"`
+	}
+	c := Config{
+		Type: "local",
+	}
+	err := viper.UnmarshalKey("email", &c)
+	if err != nil {
+		panic(fmt.Errorf("初始化配置失败 %v, 原因 %w", c, err))
+	}
+	switch c.Type {
+	case "local":
+		return localemail.NewService(c.File)
+	default:
+		panic(fmt.Errorf("不支持的邮件类型 %s", c.Type))
+	}
+}
+
+// InitEmailMemoryService 直接打印到控制台的实现
+func InitEmailMemoryService() email.Service {
+	return localemail.NewService("")
+}
+
+// InitPasswordResetService 重置密码的链接来自配置
+//
+//	passwordReset:
+//	  link: http://localhost:3000/users/password/reset
+//	  expiration: 30m
+func InitPasswordResetService(userRepo repository.UserRepository,
+	repo repository.PasswordResetRepository,
+	emailSvc email.Service,
+	l logger.LoggerV1) service.PasswordResetService {
+	link := viper.GetString("passwordReset.link")
+	if link == "" {
+		link = "http://localhost:3000/users/password/reset"
+	}
+	expiration := viper.GetDuration("passwordReset.expiration")
+	if expiration <= 0 {
+		expiration = time.Minute * 30
+	}
+	return service.NewEmailPasswordResetService(userRepo, repo, emailSvc, l, link, expiration)
+}
@@ -0,0 +1,25 @@
+package ioc
+
+import (
+	"github.com/spf13/viper"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+// InitFeedService 粉丝数达到阈值的作者从推模型切换到拉模型
+//
+//	feed:
+//	  threshold: 1000
+func InitFeedService(repo repository.FeedRepository,
+	followRepo repository.FollowRepository,
+	artRepo article.ArticleRepository,
+	l logger.LoggerV1) service.FeedService {
+	threshold := viper.GetInt64("feed.threshold")
+	if threshold <= 0 {
+		threshold = 1000
+	}
+	return service.NewFeedService(repo, followRepo, artRepo, l, threshold)
+}
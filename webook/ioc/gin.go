@@ -16,6 +16,7 @@ import (
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/web"
 	ijwt "github.com/xiaoshanjiang/my-geektime/webook/internal/web/jwt"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/web/middleware"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/ginx"
 	"github.com/xiaoshanjiang/my-geektime/webook/pkg/ginx/middlewares/logger"
 	logger2 "github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
 )
@@ -25,11 +26,31 @@ func InitWebServer(
 	userHdl *web.UserHandler,
 	oauth2WechatHdl *web.OAuth2WechatHandler,
 	articleHdl *web.ArticleHandler,
+	searchHdl *web.SearchHandler,
+	commentHdl *web.CommentHandler,
+	followHdl *web.FollowHandler,
+	feedHdl *web.FeedHandler,
+	rankingHdl *web.RankingHandler,
+	collectionHdl *web.CollectionHandler,
+	historyHdl *web.HistoryHandler,
+	jwksHdl *web.JWKSHandler,
+	sessionHdl *web.SessionHandler,
+	passwordResetHdl *web.PasswordResetHandler,
 ) *gin.Engine {
 	server := gin.Default()
 	server.Use(mdls...)
 	userHdl.RegisterRoutes(server)
 	articleHdl.RegisterRoutes(server)
+	searchHdl.RegisterRoutes(server)
+	commentHdl.RegisterRoutes(server)
+	followHdl.RegisterRoutes(server)
+	feedHdl.RegisterRoutes(server)
+	rankingHdl.RegisterRoutes(server)
+	collectionHdl.RegisterRoutes(server)
+	historyHdl.RegisterRoutes(server)
+	jwksHdl.RegisterRoutes(server)
+	sessionHdl.RegisterRoutes(server)
+	passwordResetHdl.RegisterRoutes(server)
 	oauth2WechatHdl.RegisterRoutes(server)
 	return server
 }
@@ -37,6 +58,8 @@ func InitWebServer(
 func InitMiddlewares(redisClient redis.Cmdable,
 	l logger2.LoggerV1,
 	jwtHdl ijwt.Handler) []gin.HandlerFunc {
+	// ginx 里面的 Wrap 系列方法用这个来记录业务错误
+	ginx.L = l
 	bd := logger.NewBuilder(func(ctx context.Context, al *logger.AccessLog) {
 		l.Debug("HTTP请求", logger2.Field{Key: "al", Value: al})
 	}).AllowReqBody(true).AllowRespBody()
@@ -58,7 +81,7 @@ func corsHandler() gin.HandlerFunc {
 		//AllowMethods: []string{"POST", "GET"},
 		AllowHeaders: []string{"Content-Type", "Authorization"},
 		// 你不加这个，前端是拿不到的
-		ExposeHeaders: []string{"x-jwt-token"},
+		ExposeHeaders: []string{"x-jwt-token", "x-refresh-token"},
 		// 是否允许你带 cookie 之类的东西
 		AllowCredentials: true,
 		AllowOriginFunc: func(origin string) bool {
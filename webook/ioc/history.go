@@ -0,0 +1,38 @@
+package ioc
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/job"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+// InitHistoryService 阅读记录保留多久来自配置
+//
+//	history:
+//	  retention: 2160h
+func InitHistoryService(repo repository.ReadHistoryRepository,
+	intrRepo repository.InteractiveRepository,
+	artRepo article.ArticleRepository,
+	l logger.LoggerV1) service.HistoryService {
+	return service.NewHistoryService(repo, intrRepo, artRepo, l, historyRetention())
+}
+
+func InitReadHistoryCleanJob(repo repository.ReadHistoryRepository,
+	l logger.LoggerV1) *job.ReadHistoryCleanJob {
+	return job.NewReadHistoryCleanJob(repo, l, historyRetention())
+}
+
+// historyRetention 默认保留九十天
+func historyRetention() time.Duration {
+	retention := viper.GetDuration("history.retention")
+	if retention <= 0 {
+		retention = time.Hour * 24 * 90
+	}
+	return retention
+}
@@ -0,0 +1,22 @@
+package ioc
+
+import (
+	"github.com/spf13/viper"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+// InitInteractiveRepository 按照 biz 选择点赞和收藏是不是异步更新计数，默认都是同步的
+//
+//	interactive:
+//	  asyncBizs:
+//	    - "article"
+func InitInteractiveRepository(d dao.InteractiveDAO,
+	c cache.InteractiveCache,
+	l logger.LoggerV1) repository.InteractiveRepository {
+	return repository.NewCachedInteractiveRepositoryV1(d, c, l,
+		viper.GetStringSlice("interactive.asyncBizs"))
+}
@@ -0,0 +1,15 @@
+package ioc
+
+import (
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/job"
+)
+
+// NewJobs 和 NewConsumers 一样，所有的后台任务在这里注册
+func NewJobs(j1 *job.ScheduledPublishJob,
+	j2 *job.SearchIndexJob,
+	j3 *job.BlobCompensateJob,
+	j4 *job.RankingJob,
+	j5 *job.OutboxRelayJob,
+	j6 *job.ReadHistoryCleanJob) []job.Job {
+	return []job.Job{j1, j2, j3, j4, j5, j6}
+}
@@ -1,12 +1,40 @@
 package ioc
 
 import (
+	"fmt"
+
 	"github.com/IBM/sarama"
 	"github.com/spf13/viper"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/events"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/article"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/feed"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/interactive"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/search"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/saramax"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/saramax/memory"
 )
 
+// InitBroker 按照配置选择消息队列，默认是 Kafka
+//
+//	events:
+//	  type: "memory" # kafka 或者 memory
+//	  partitions: 4
+//
+// memory 是进程内的实现，消息不会持久化，只适合单机运行和测试，
+// 这时候不需要连接 Kafka
+func InitBroker() saramax.Broker {
+	typ := viper.GetString("events.type")
+	switch typ {
+	case "", "kafka":
+		return saramax.NewKafkaBroker(InitKafka())
+	case "memory":
+		return memory.NewBroker(viper.GetInt32("events.partitions"))
+	default:
+		panic(fmt.Errorf("未知的消息队列 %s", typ))
+	}
+}
+
 func InitKafka() sarama.Client {
 	type Config struct {
 		Addrs []string `yaml:"addrs"`
@@ -25,8 +53,28 @@ func InitKafka() sarama.Client {
 	return client
 }
 
-func NewSyncProducer(client sarama.Client) sarama.SyncProducer {
-	res, err := sarama.NewSyncProducerFromClient(client)
+// InitDeadLetterReplayer 重放死信用自己的客户端，要从最老的消息开始消费
+func InitDeadLetterReplayer(l logger.LoggerV1) *saramax.DeadLetterReplayer {
+	type Config struct {
+		Addrs []string `yaml:"addrs"`
+	}
+	saramaCfg := sarama.NewConfig()
+	saramaCfg.Producer.Return.Successes = true
+	saramaCfg.Consumer.Offsets.Initial = sarama.OffsetOldest
+	var cfg Config
+	err := viper.UnmarshalKey("kafka", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	client, err := sarama.NewClient(cfg.Addrs, saramaCfg)
+	if err != nil {
+		panic(err)
+	}
+	return saramax.NewDeadLetterReplayer(client, NewSyncProducer(saramax.NewKafkaBroker(client)), l)
+}
+
+func NewSyncProducer(b saramax.Broker) sarama.SyncProducer {
+	res, err := b.NewSyncProducer()
 	if err != nil {
 		panic(err)
 	}
@@ -34,6 +82,12 @@ func NewSyncProducer(client sarama.Client) sarama.SyncProducer {
 }
 
 // NewConsumers 面临的问题依旧是所有的 Consumer 在这里注册一下
-func NewConsumers(c1 *article.InteractiveReadEventConsumer) []events.Consumer {
-	return []events.Consumer{c1}
+func NewConsumers(c1 *article.InteractiveReadEventConsumer,
+	c2 *feed.ArticlePublishedConsumer,
+	c3 *feed.FollowEventConsumer,
+	c4 *interactive.CntEventConsumer,
+	c5 *article.HistoryReadEventConsumer,
+	c6 *search.ArticleEventConsumer,
+	c7 *feed.ArticleWithdrawnConsumer) []events.Consumer {
+	return []events.Consumer{c1, c2, c3, c4, c5, c6, c7}
 }
@@ -0,0 +1,33 @@
+package ioc
+
+import (
+	"os"
+
+	"github.com/spf13/viper"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/search"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/saramax"
+)
+
+// InitSearchIndexConsumer 每个实例都用自己的消费者组更新搜索索引
+//
+//	search:
+//	  instance: "webook-0"
+//
+// instance 每个实例都要不一样，并且重启之后不能变，比如说 StatefulSet 的 Pod 名字。
+// 没有配置的时候用主机名，同一台机器上跑多个实例的时候一定要配置
+func InitSearchIndexConsumer(broker saramax.Broker,
+	l logger.LoggerV1,
+	svc service.SearchService) *search.ArticleEventConsumer {
+	instance := viper.GetString("search.instance")
+	if instance == "" {
+		host, err := os.Hostname()
+		if err != nil {
+			panic(err)
+		}
+		instance = host
+	}
+	return search.NewArticleEventConsumer(broker, l, svc, instance)
+}
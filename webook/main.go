@@ -19,6 +19,11 @@ func main() {
 	// 要把配置初始化放在最前面
 	initViperV2Watch()
 	initLogger()
+	// 带了子命令的话，执行完就退出，不启动 Web 服务
+	if name := pflag.Arg(0); name != "" {
+		runCommand(name)
+		return
+	}
 	app := InitWebServer()
 	for _, c := range app.consumers {
 		err := c.Start()
@@ -26,6 +31,12 @@ func main() {
 			panic(err)
 		}
 	}
+	for _, j := range app.jobs {
+		err := j.Start()
+		if err != nil {
+			panic(err)
+		}
+	}
 	server := app.web
 	// 注册路由
 	server.GET("/hello", func(ctx *gin.Context) {
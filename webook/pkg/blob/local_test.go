@@ -0,0 +1,41 @@
+package blob
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = s.Get(ctx, "1")
+	assert.Equal(t, ErrNotFound, err)
+
+	err = s.Put(ctx, "1", []byte("测试内容 abc"), "text/plain;charset=utf-8")
+	require.NoError(t, err)
+	data, err := s.Get(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "测试内容 abc", string(data))
+
+	// 覆盖
+	err = s.Put(ctx, "1", []byte("新的内容"), "text/plain;charset=utf-8")
+	require.NoError(t, err)
+	data, err = s.Get(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "新的内容", string(data))
+
+	require.NoError(t, s.Delete(ctx, "1"))
+	_, err = s.Get(ctx, "1")
+	assert.Equal(t, ErrNotFound, err)
+	// 重复删除
+	assert.NoError(t, s.Delete(ctx, "1"))
+
+	for _, key := range []string{"", "..", "../1", "a/b", ".tmp-1"} {
+		assert.Error(t, s.Put(ctx, key, []byte("x"), ""), key)
+	}
+}
@@ -0,0 +1,60 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/ecodeclub/ekit"
+)
+
+// S3Storage 基于 S3 协议的实现，腾讯云 COS、阿里云 OSS、MinIO 都可以用
+type S3Storage struct {
+	client *s3.S3
+	bucket *string
+}
+
+func NewS3Storage(client *s3.S3, bucket string) *S3Storage {
+	return &S3Storage{
+		client: client,
+		bucket: ekit.ToPtr[string](bucket),
+	}
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	_, err := s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      s.bucket,
+		Key:         ekit.ToPtr[string](key),
+		Body:        bytes.NewReader(data),
+		ContentType: ekit.ToPtr[string](contentType),
+	})
+	return err
+}
+
+func (s *S3Storage) Get(ctx context.Context, key string) ([]byte, error) {
+	res, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: s.bucket,
+		Key:    ekit.ToPtr[string](key),
+	})
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == s3.ErrCodeNoSuchKey {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	return io.ReadAll(res.Body)
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	// S3 删除不存在的对象也是成功的
+	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: s.bucket,
+		Key:    ekit.ToPtr[string](key),
+	})
+	return err
+}
@@ -0,0 +1,17 @@
+package blob
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrNotFound = errors.New("对象不存在")
+
+// Storage 对象存储的抽象，key 由调用方决定
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get 对象不存在的时候返回 ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete 删除不存在的对象不会返回错误
+	Delete(ctx context.Context, key string) error
+}
@@ -15,6 +15,10 @@ type Result struct {
 	Data any    `json:"data"`
 }
 
+// ClaimsKey 登录校验的 middleware 把 token 里面的 claims 放到 ctx 的这个 key 下面，
+// 放的是值，不是指针。WrapToken 之类的方法也从这里取
+const ClaimsKey = "claims"
+
 // L 使用包变量
 var L logger.LoggerV1
 
@@ -67,7 +71,7 @@ func WrapBody[T any](l logger.LoggerV1, fn func(ctx *gin.Context, req T) (Result
 func WrapToken[C jwt.Claims](fn func(ctx *gin.Context, uc C) (Result, error)) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		// 执行一些东西
-		val, ok := ctx.Get("users")
+		val, ok := ctx.Get(ClaimsKey)
 		if !ok {
 			ctx.AbortWithStatus(http.StatusUnauthorized)
 			return
@@ -103,7 +107,7 @@ func WrapBodyAndToken[Req any, C jwt.Claims](fn func(ctx *gin.Context, req Req,
 			return
 		}
 
-		val, ok := ctx.Get("users")
+		val, ok := ctx.Get(ClaimsKey)
 		if !ok {
 			ctx.AbortWithStatus(http.StatusUnauthorized)
 			return
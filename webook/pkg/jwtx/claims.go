@@ -0,0 +1,14 @@
+package jwtx
+
+import "github.com/golang-jwt/jwt/v5"
+
+// UserClaims webook 的 access token 里面的数据。
+// 字段名字就是 JSON 里面的名字，改名字会导致已经签发的 token 解析不出来
+type UserClaims struct {
+	jwt.RegisteredClaims
+	// 声明你自己的要放进去 token 里面的数据
+	Id   int64
+	Ssid string
+	// 自己随便加
+	UserAgent string
+}
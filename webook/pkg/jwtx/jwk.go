@@ -0,0 +1,94 @@
+package jwtx
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var ErrUnsupportedKey = errors.New("不支持的公钥类型")
+
+// JWK RFC 7517 里面的公钥，只支持 RSA 和 Ed25519
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet 就是 /.well-known/jwks.json 返回的内容
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK *rsa.PublicKey 对应 RS256，ed25519.PublicKey 对应 EdDSA
+func NewJWK(kid string, pub crypto.PublicKey) (JWK, error) {
+	enc := base64.RawURLEncoding
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: "RS256",
+			N:   enc.EncodeToString(key.N.Bytes()),
+			E:   enc.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: kid,
+			Use: "sig",
+			Alg: "EdDSA",
+			Crv: "Ed25519",
+			X:   enc.EncodeToString(key),
+		}, nil
+	default:
+		return JWK{}, fmt.Errorf("%w %T", ErrUnsupportedKey, pub)
+	}
+}
+
+// PublicKey 还原成 jwt 校验签名要用的公钥
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	enc := base64.RawURLEncoding
+	switch {
+	case k.Kty == "RSA":
+		n, err := enc.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := enc.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		eVal := new(big.Int).SetBytes(e)
+		if len(n) == 0 || !eVal.IsInt64() || eVal.Int64() <= 1 {
+			return nil, fmt.Errorf("%w：RSA 公钥 %s 不合法", ErrUnsupportedKey, k.Kid)
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(eVal.Int64()),
+		}, nil
+	case k.Kty == "OKP" && k.Crv == "Ed25519":
+		x, err := enc.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w：Ed25519 公钥 %s 长度不对", ErrUnsupportedKey, k.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("%w %s %s", ErrUnsupportedKey, k.Kty, k.Crv)
+	}
+}
@@ -0,0 +1,179 @@
+package jwtx
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownKid   = errors.New("未知的 kid")
+	ErrInvalidToken = errors.New("token 不合法")
+)
+
+// Verifier 给别的服务用，拿 webook 公布的公钥离线校验 access token，不需要知道任何密钥。
+//
+// 离线校验只能确认 token 是 webook 签发的、没有过期，
+// 用户主动退出登录之后，token 在过期之前依旧能通过校验
+type Verifier struct {
+	fetch func(ctx context.Context) (JWKSet, error)
+	// 公钥缓存多久
+	ttl time.Duration
+	// 遇到不认识的 kid 会立刻重新拉取，但是两次拉取至少间隔这么久，
+	// 免得有人拿着乱写的 kid 把 webook 打垮
+	minRefresh time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]verifyKey
+	fetchedAt time.Time
+
+	// 同一时刻只有一个 goroutine 去拉取
+	refreshMu sync.Mutex
+	// 上一次尝试拉取的时间，拉取失败了也算
+	triedAt time.Time
+}
+
+type verifyKey struct {
+	alg string
+	key crypto.PublicKey
+}
+
+// NewVerifier 用固定的公钥校验，适合公钥随着配置下发的场景
+func NewVerifier(set JWKSet) *Verifier {
+	v := &Verifier{}
+	v.setKeys(set, time.Time{})
+	return v
+}
+
+// NewRemoteVerifier 从 jwksURL 拉取公钥，比如说 https://webook.com/.well-known/jwks.json。
+// 公钥缓存五分钟，webook 轮换密钥的时候新公钥会提前公布出来
+func NewRemoteVerifier(jwksURL string, client *http.Client) *Verifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Verifier{
+		fetch: func(ctx context.Context) (JWKSet, error) {
+			return fetchJWKS(ctx, client, jwksURL)
+		},
+		ttl:        time.Minute * 5,
+		minRefresh: time.Minute,
+	}
+}
+
+// Verify 校验签名和过期时间，成功的时候返回 token 里面的数据
+func (v *Verifier) Verify(ctx context.Context, tokenStr string) (UserClaims, error) {
+	var uc UserClaims
+	token, err := jwt.ParseWithClaims(tokenStr, &uc, func(token *jwt.Token) (interface{}, error) {
+		return v.keyfunc(ctx, token)
+	}, jwt.WithValidMethods([]string{"RS256", "EdDSA"}))
+	if err != nil {
+		return UserClaims{}, err
+	}
+	if !token.Valid || uc.Id == 0 {
+		return UserClaims{}, ErrInvalidToken
+	}
+	// jwt 在没有 exp 的时候不会报错，webook 签发的 token 一定有 exp
+	if uc.ExpiresAt == nil {
+		return UserClaims{}, fmt.Errorf("%w：没有过期时间", ErrInvalidToken)
+	}
+	return uc, nil
+}
+
+func (v *Verifier) keyfunc(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, ErrUnknownKid
+	}
+	key, ok, stale := v.lookup(kid)
+	if !ok || stale {
+		err := v.refresh(ctx)
+		// 拉取失败的时候继续用缓存里面的公钥
+		key, ok, _ = v.lookup(kid)
+		if !ok && err != nil {
+			return nil, err
+		}
+	}
+	if !ok {
+		return nil, ErrUnknownKid
+	}
+	// 公钥只能用来校验它自己的算法，防止算法混淆攻击
+	if token.Method.Alg() != key.alg {
+		return nil, fmt.Errorf("%w：kid %s 的算法是 %s", ErrInvalidToken, kid, key.alg)
+	}
+	return key.key, nil
+}
+
+func (v *Verifier) lookup(kid string) (verifyKey, bool, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	key, ok := v.keys[kid]
+	stale := v.fetch != nil && time.Since(v.fetchedAt) > v.ttl
+	return key, ok, stale
+}
+
+// refresh 重新拉取公钥，离上一次尝试不到 minRefresh 的时候什么也不做
+func (v *Verifier) refresh(ctx context.Context) error {
+	if v.fetch == nil {
+		return nil
+	}
+	v.refreshMu.Lock()
+	defer v.refreshMu.Unlock()
+	if time.Since(v.triedAt) < v.minRefresh {
+		return nil
+	}
+	v.triedAt = time.Now()
+	set, err := v.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	v.setKeys(set, time.Now())
+	return nil
+}
+
+func (v *Verifier) setKeys(set JWKSet, fetchedAt time.Time) {
+	keys := make(map[string]verifyKey, len(set.Keys))
+	for _, k := range set.Keys {
+		pub, err := k.PublicKey()
+		if err != nil {
+			// 不认识的公钥跳过，webook 以后可能会加新的算法
+			continue
+		}
+		alg := k.Alg
+		if alg == "" {
+			alg = "RS256"
+			if k.Kty == "OKP" {
+				alg = "EdDSA"
+			}
+		}
+		keys[k.Kid] = verifyKey{alg: alg, key: pub}
+	}
+	v.mu.Lock()
+	v.keys = keys
+	v.fetchedAt = fetchedAt
+	v.mu.Unlock()
+}
+
+func fetchJWKS(ctx context.Context, client *http.Client, url string) (JWKSet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return JWKSet{}, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return JWKSet{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return JWKSet{}, fmt.Errorf("拉取公钥失败，状态码 %d", resp.StatusCode)
+	}
+	var set JWKSet
+	err = json.NewDecoder(resp.Body).Decode(&set)
+	return set, err
+}
@@ -0,0 +1,165 @@
+package jwtx
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	edJWK, err := NewJWK("ed", edPub)
+	require.NoError(t, err)
+	rsaJWK, err := NewJWK("rsa", &rsaKey.PublicKey)
+	require.NoError(t, err)
+	v := NewVerifier(JWKSet{Keys: []JWK{edJWK, rsaJWK}})
+
+	validClaims := UserClaims{
+		Id: 123,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+	testCases := []struct {
+		name  string
+		token func(t *testing.T) string
+
+		wantClaims bool
+		wantErr    error
+	}{
+		{
+			name: "EdDSA",
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodEdDSA, "ed", validClaims, edKey)
+			},
+			wantClaims: true,
+		},
+		{
+			name: "RS256",
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodRS256, "rsa", validClaims, rsaKey)
+			},
+			wantClaims: true,
+		},
+		{
+			name: "未知的 kid",
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodEdDSA, "unknown", validClaims, edKey)
+			},
+			wantErr: ErrUnknownKid,
+		},
+		{
+			// 拿着 ed 的 kid，但是用 rsa 的私钥签名
+			name: "kid 和算法对不上",
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodRS256, "ed", validClaims, rsaKey)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			// 公钥是公开的，不能让人拿它当 HMAC 的密钥
+			name: "HMAC",
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodHS256, "ed", validClaims, []byte(edPub))
+			},
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name: "过期了",
+			token: func(t *testing.T) string {
+				claims := validClaims
+				claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+				return sign(t, jwt.SigningMethodEdDSA, "ed", claims, edKey)
+			},
+			wantErr: jwt.ErrTokenExpired,
+		},
+		{
+			name: "没有过期时间",
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodEdDSA, "ed", UserClaims{Id: 123}, edKey)
+			},
+			wantErr: ErrInvalidToken,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uc, err := v.Verify(context.Background(), tc.token(t))
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(123), uc.Id)
+		})
+	}
+}
+
+func TestRemoteVerifier_Rotate(t *testing.T) {
+	oldPub, oldKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	newPub, newKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	oldJWK, err := NewJWK("old", oldPub)
+	require.NoError(t, err)
+	newJWK, err := NewJWK("new", newPub)
+	require.NoError(t, err)
+
+	var published atomic.Value
+	published.Store(JWKSet{Keys: []JWK{oldJWK}})
+	var fetched atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched.Add(1)
+		_ = json.NewEncoder(w).Encode(published.Load())
+	}))
+	defer server.Close()
+
+	v := NewRemoteVerifier(server.URL, server.Client())
+	claims := UserClaims{
+		Id: 123,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+	_, err = v.Verify(context.Background(), sign(t, jwt.SigningMethodEdDSA, "old", claims, oldKey))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetched.Load())
+
+	// 公钥还在缓存里面，不会重新拉取
+	_, err = v.Verify(context.Background(), sign(t, jwt.SigningMethodEdDSA, "old", claims, oldKey))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetched.Load())
+
+	// webook 公布了新公钥，遇到新的 kid 会重新拉取，不过要间隔 minRefresh
+	published.Store(JWKSet{Keys: []JWK{oldJWK, newJWK}})
+	newToken := sign(t, jwt.SigningMethodEdDSA, "new", claims, newKey)
+	_, err = v.Verify(context.Background(), newToken)
+	assert.ErrorIs(t, err, ErrUnknownKid)
+	assert.Equal(t, int32(1), fetched.Load())
+
+	v.minRefresh = 0
+	_, err = v.Verify(context.Background(), newToken)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), fetched.Load())
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, claims UserClaims, key any) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	str, err := token.SignedString(key)
+	require.NoError(t, err)
+	return str
+}
@@ -0,0 +1,54 @@
+// Package outbox 实现事务性发件箱。
+// 业务在自己的本地事务里面调用 Save，把要发送的消息和业务数据一起提交，
+// 之后由 Relay 把消息投递到 Kafka。只要业务提交了，消息最终一定会发出去，至少一次。
+package outbox
+
+import (
+	"strconv"
+
+	"github.com/IBM/sarama"
+	"gorm.io/gorm"
+)
+
+// HeaderMessageId Relay 投递的时候带上发件箱里面的消息 ID
+const HeaderMessageId = "x-outbox-id"
+
+// Message 发件箱里面等待投递的消息，投递成功之后就删掉
+type Message struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Topic string `gorm:"type:varchar(128)"`
+	// 分区用的 key，可以为空
+	MsgKey string `gorm:"type:varchar(128)"`
+	Value  []byte `gorm:"type:blob"`
+
+	// 哪一轮投递抢占了这条消息，以及抢占到什么时候
+	// 过了 LeaseUntil 还没有删掉，说明投递失败了或者实例崩溃了，别的实例可以重新抢占
+	Owner      string `gorm:"type:varchar(64);index"`
+	LeaseUntil int64  `gorm:"index"`
+	// 尝试投递了多少次，排查问题用
+	Attempts int
+	Ctime    int64
+}
+
+func (Message) TableName() string {
+	return "outbox_messages"
+}
+
+// MessageId 从 Kafka 消息的头部读出发件箱里面的消息 ID，不是从发件箱投递的消息返回 0
+func MessageId(msg *sarama.ConsumerMessage) int64 {
+	for _, h := range msg.Headers {
+		if h != nil && string(h.Key) == HeaderMessageId {
+			id, _ := strconv.ParseInt(string(h.Value), 10, 64)
+			return id
+		}
+	}
+	return 0
+}
+
+// Save 把消息写进发件箱，tx 必须是业务正在使用的事务
+func Save(tx *gorm.DB, msgs ...Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	return tx.Create(&msgs).Error
+}
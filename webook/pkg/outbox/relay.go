@@ -0,0 +1,125 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+// Relay 把发件箱里面的消息投递到 Kafka
+// 多个实例可以同时运行，每一轮先用租约抢占一批消息，只投递自己抢到的。
+// 投递成功了但是删除失败，或者租约过期之后被别的实例抢走，都会导致重复投递，
+// 所以下游要自己保证幂等。投递失败的消息等租约过期之后重试，因此不保证顺序
+type Relay struct {
+	db       *gorm.DB
+	producer sarama.SyncProducer
+	l        logger.LoggerV1
+
+	batchSize int
+	// 抢占之后最多占用多久，要比投递一批消息的时间长
+	lease time.Duration
+	now   func() time.Time
+}
+
+func NewRelay(db *gorm.DB, producer sarama.SyncProducer, l logger.LoggerV1) *Relay {
+	return &Relay{
+		db:        db,
+		producer:  producer,
+		l:         l,
+		batchSize: 100,
+		lease:     time.Minute,
+		now:       time.Now,
+	}
+}
+
+// BatchSize 一轮最多投递多少条
+func (r *Relay) BatchSize() int {
+	return r.batchSize
+}
+
+// RelayOnce 抢占一批消息并且投递，返回投递成功了多少条
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	msgs, owner, err := r.claim(ctx)
+	if err != nil || len(msgs) == 0 {
+		return 0, err
+	}
+	pms := make([]*sarama.ProducerMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		pm := &sarama.ProducerMessage{
+			Topic: msg.Topic,
+			Value: sarama.ByteEncoder(msg.Value),
+			// 重复投递的时候 ID 不变，offset 会变，下游按照这个去重
+			Headers: []sarama.RecordHeader{{
+				Key:   []byte(HeaderMessageId),
+				Value: []byte(strconv.FormatInt(msg.Id, 10)),
+			}},
+			Metadata: msg.Id,
+		}
+		if msg.MsgKey != "" {
+			pm.Key = sarama.StringEncoder(msg.MsgKey)
+		}
+		pms = append(pms, pm)
+	}
+	failed := make(map[int64]struct{})
+	err = r.producer.SendMessages(pms)
+	if err != nil {
+		var perrs sarama.ProducerErrors
+		if !errors.As(err, &perrs) {
+			return 0, err
+		}
+		for _, perr := range perrs {
+			id, _ := perr.Msg.Metadata.(int64)
+			failed[id] = struct{}{}
+			r.l.Error("投递发件箱消息失败",
+				logger.Int64("id", id),
+				logger.String("topic", perr.Msg.Topic),
+				logger.Error(perr.Err))
+		}
+	}
+	delivered := make([]int64, 0, len(msgs))
+	for _, msg := range msgs {
+		if _, ok := failed[msg.Id]; !ok {
+			delivered = append(delivered, msg.Id)
+		}
+	}
+	if len(delivered) == 0 {
+		return 0, nil
+	}
+	// 只删除自己抢占的，租约过期被别人抢走的留给别人删
+	err = r.db.WithContext(ctx).
+		Where("id IN ? AND owner = ?", delivered, owner).
+		Delete(&Message{}).Error
+	return len(delivered), err
+}
+
+// claim 抢占一批租约已经过期的消息
+func (r *Relay) claim(ctx context.Context) ([]Message, string, error) {
+	now := r.now().UnixMilli()
+	// 每一轮都用新的 owner，同一个实例上一轮没有处理完的也不会混进来
+	owner := uuid.New().String()
+	res := r.db.WithContext(ctx).Model(&Message{}).
+		Where("lease_until < ?", now).
+		Order("id").
+		Limit(r.batchSize).
+		Updates(map[string]any{
+			"owner":       owner,
+			"lease_until": now + r.lease.Milliseconds(),
+			"attempts":    gorm.Expr("attempts + 1"),
+		})
+	if res.Error != nil || res.RowsAffected == 0 {
+		return nil, owner, res.Error
+	}
+	var msgs []Message
+	err := r.db.WithContext(ctx).
+		Where("owner = ?", owner).
+		Order("id").
+		Find(&msgs).Error
+	return msgs, owner, err
+}
@@ -0,0 +1,129 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+func TestRelay_RelayOnce(t *testing.T) {
+	cols := []string{"id", "topic", "msg_key", "value", "owner", "lease_until", "attempts", "ctime"}
+	testCases := []struct {
+		name    string
+		sqlmock func(mock sqlmock.Sqlmock)
+		// 发送失败的消息
+		failed map[int64]error
+
+		wantCnt int
+		wantErr error
+	}{
+		{
+			name: "全部投递成功",
+			sqlmock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE `outbox_messages` SET .* WHERE lease_until < \\? ORDER BY id LIMIT 100").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectQuery("SELECT \\* FROM `outbox_messages` WHERE owner = \\?").
+					WillReturnRows(sqlmock.NewRows(cols).
+						AddRow(1, "article_published", "1", []byte("{}"), "o", 0, 1, 0).
+						AddRow(2, "article_published", "", []byte("{}"), "o", 0, 1, 0))
+				mock.ExpectExec("DELETE FROM `outbox_messages` WHERE id IN \\(\\?,\\?\\) AND owner = \\?").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			wantCnt: 2,
+		},
+		{
+			name: "部分投递失败，只删除成功的",
+			sqlmock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE `outbox_messages` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectQuery("SELECT \\* FROM `outbox_messages` WHERE owner = \\?").
+					WillReturnRows(sqlmock.NewRows(cols).
+						AddRow(1, "article_published", "1", []byte("{}"), "o", 0, 1, 0).
+						AddRow(2, "article_published", "2", []byte("{}"), "o", 0, 1, 0))
+				mock.ExpectExec("DELETE FROM `outbox_messages` WHERE id IN \\(\\?\\) AND owner = \\?").
+					WithArgs(int64(2), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			failed:  map[int64]error{1: errors.New("mock kafka error")},
+			wantCnt: 1,
+		},
+		{
+			name: "没有待投递的消息",
+			sqlmock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE `outbox_messages` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			name: "抢占失败",
+			sqlmock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE `outbox_messages` SET .*").
+					WillReturnError(errors.New("mock db error"))
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			tc.sqlmock(mock)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			producer := &fakeProducer{failed: tc.failed}
+			relay := NewRelay(db, producer, &logger.NoOpLogger{})
+			relay.now = func() time.Time {
+				return time.UnixMilli(1000)
+			}
+			cnt, err := relay.RelayOnce(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCnt, cnt)
+			assert.NoError(t, mock.ExpectationsWereMet())
+			// 每条消息都带上了发件箱里面的 ID，下游靠它去重
+			for _, pm := range producer.sent {
+				headers := make([]*sarama.RecordHeader, 0, len(pm.Headers))
+				for i := range pm.Headers {
+					headers = append(headers, &pm.Headers[i])
+				}
+				assert.Equal(t, pm.Metadata, MessageId(&sarama.ConsumerMessage{Headers: headers}))
+			}
+		})
+	}
+}
+
+// fakeProducer 和真实的 SyncProducer 一样，部分失败的时候返回 sarama.ProducerErrors
+type fakeProducer struct {
+	sarama.SyncProducer
+	failed map[int64]error
+	sent   []*sarama.ProducerMessage
+}
+
+func (f *fakeProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	f.sent = append(f.sent, msgs...)
+	var errs sarama.ProducerErrors
+	for _, msg := range msgs {
+		if err, ok := f.failed[msg.Metadata.(int64)]; ok {
+			errs = append(errs, &sarama.ProducerError{Msg: msg, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
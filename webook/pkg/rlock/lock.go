@@ -0,0 +1,139 @@
+package rlock
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	//go:embed lua/unlock.lua
+	luaUnlock string
+	//go:embed lua/refresh.lua
+	luaRefresh string
+
+	// ErrFailedToPreemptLock 锁被别人拿着
+	ErrFailedToPreemptLock = errors.New("rlock: 抢锁失败")
+	// ErrLockNotHold 锁已经不是自己的了，可能是过期了被别人拿走了
+	ErrLockNotHold = errors.New("rlock: 未持有锁")
+	// ErrRefreshTimeout 续约一直超时，锁在下一次续约完成之前就可能过期了
+	ErrRefreshTimeout = errors.New("rlock: 续约超时，锁可能已经过期")
+)
+
+// Client 基于 Redis 的分布式锁
+type Client struct {
+	client redis.Cmdable
+}
+
+func NewClient(client redis.Cmdable) *Client {
+	return &Client{
+		client: client,
+	}
+}
+
+// TryLock 尝试加锁，拿不到的时候立刻返回 ErrFailedToPreemptLock
+func (c *Client) TryLock(ctx context.Context, key string, expiration time.Duration) (*Lock, error) {
+	// value 用来区分是谁加的锁，解锁和续约的时候都要校验
+	val := uuid.New().String()
+	// 从发出命令开始算，宁可早一点认为锁过期了
+	start := time.Now()
+	ok, err := c.client.SetNX(ctx, key, val, expiration).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrFailedToPreemptLock
+	}
+	return &Lock{
+		client:     c.client,
+		key:        key,
+		value:      val,
+		expiration: expiration,
+		validUntil: start.Add(expiration),
+		unlock:     make(chan struct{}),
+	}, nil
+}
+
+type Lock struct {
+	client     redis.Cmdable
+	key        string
+	value      string
+	expiration time.Duration
+	// 最近一次加锁或者续约成功之后，锁至少在这之前都是自己的
+	validUntil time.Time
+	unlock     chan struct{}
+}
+
+// Refresh 续约，把过期时间重置为 expiration
+func (l *Lock) Refresh(ctx context.Context) error {
+	start := time.Now()
+	res, err := l.client.Eval(ctx, luaRefresh, []string{l.key},
+		l.value, l.expiration.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if res != 1 {
+		return ErrLockNotHold
+	}
+	l.validUntil = start.Add(l.expiration)
+	return nil
+}
+
+// AutoRefresh 每隔 interval 续约一次，每次续约的超时时间是 timeout。
+// 超时会立刻重试，但是如果重试完成之前锁就可能过期了，就不再重试，返回 ErrRefreshTimeout，
+// 免得锁已经被别人拿走了，调用者还以为自己持有锁。其它错误或者 Unlock 之后返回。
+// 这个方法会阻塞，一般是开一个 goroutine 来调用
+func (l *Lock) AutoRefresh(interval time.Duration, timeout time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	// 超时之后立刻重试，不用等下一个 interval
+	retry := make(chan struct{}, 1)
+	for {
+		select {
+		case <-ticker.C:
+		case <-retry:
+		case <-l.unlock:
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := l.Refresh(ctx)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) {
+			if time.Now().Add(timeout).After(l.validUntil) {
+				return ErrRefreshTimeout
+			}
+			// ticker 和 retry 同时就绪的时候可能走了 ticker，retry 里面还有一个，不能阻塞在这里
+			select {
+			case retry <- struct{}{}:
+			default:
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Unlock 释放锁，同时停止 AutoRefresh
+func (l *Lock) Unlock(ctx context.Context) error {
+	defer func() {
+		select {
+		case <-l.unlock:
+		default:
+			close(l.unlock)
+		}
+	}()
+	res, err := l.client.Eval(ctx, luaUnlock, []string{l.key}, l.value).Int64()
+	if err != nil {
+		return err
+	}
+	if res != 1 {
+		return ErrLockNotHold
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package rlock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache/redismocks"
+)
+
+func TestClient_TryLock(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) redis.Cmdable
+
+		wantErr error
+	}{
+		{
+			name: "加锁成功",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewBoolResult(true, nil)
+				cmd.EXPECT().SetNX(gomock.Any(), "job:ranking", gomock.Any(), time.Minute).
+					Return(res)
+				return cmd
+			},
+		},
+		{
+			name: "别人持有锁",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewBoolResult(false, nil)
+				cmd.EXPECT().SetNX(gomock.Any(), "job:ranking", gomock.Any(), time.Minute).
+					Return(res)
+				return cmd
+			},
+			wantErr: ErrFailedToPreemptLock,
+		},
+		{
+			name: "Redis 错误",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewBoolResult(false, errors.New("mock redis error"))
+				cmd.EXPECT().SetNX(gomock.Any(), "job:ranking", gomock.Any(), time.Minute).
+					Return(res)
+				return cmd
+			},
+			wantErr: errors.New("mock redis error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			c := NewClient(tc.mock(ctrl))
+			l, err := c.TryLock(context.Background(), "job:ranking", time.Minute)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, "job:ranking", l.key)
+			assert.NotEmpty(t, l.value)
+		})
+	}
+}
+
+func TestLock_Refresh(t *testing.T) {
+	testCases := []struct {
+		name string
+		res  *redis.Cmd
+
+		wantErr error
+	}{
+		{
+			name: "续约成功",
+			res:  redis.NewCmdResult(int64(1), nil),
+		},
+		{
+			name:    "锁已经不是自己的了",
+			res:     redis.NewCmdResult(int64(0), nil),
+			wantErr: ErrLockNotHold,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			cmd := redismocks.NewMockCmdable(ctrl)
+			cmd.EXPECT().Eval(gomock.Any(), luaRefresh, []string{"job:ranking"},
+				[]any{"my-value", int64(60000)}).Return(tc.res)
+			l := &Lock{
+				client:     cmd,
+				key:        "job:ranking",
+				value:      "my-value",
+				expiration: time.Minute,
+				unlock:     make(chan struct{}),
+			}
+			assert.Equal(t, tc.wantErr, l.Refresh(context.Background()))
+		})
+	}
+}
+
+func TestLock_AutoRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cmd := redismocks.NewMockCmdable(ctrl)
+	// 第一次续约超时，立刻重试；第二次成功；第三次发现锁丢了，退出
+	cmd.EXPECT().Eval(gomock.Any(), luaRefresh, gomock.Any(), gomock.Any()).
+		Return(redis.NewCmdResult(nil, context.DeadlineExceeded))
+	cmd.EXPECT().Eval(gomock.Any(), luaRefresh, gomock.Any(), gomock.Any()).
+		Return(redis.NewCmdResult(int64(1), nil))
+	cmd.EXPECT().Eval(gomock.Any(), luaRefresh, gomock.Any(), gomock.Any()).
+		Return(redis.NewCmdResult(int64(0), nil))
+	l := &Lock{
+		client:     cmd,
+		key:        "job:ranking",
+		value:      "my-value",
+		expiration: time.Minute,
+		validUntil: time.Now().Add(time.Minute),
+		unlock:     make(chan struct{}),
+	}
+	err := l.AutoRefresh(time.Millisecond*10, time.Second)
+	assert.Equal(t, ErrLockNotHold, err)
+}
+
+func TestLock_AutoRefreshTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cmd := redismocks.NewMockCmdable(ctrl)
+	// Redis 一直超时，不能无限重试下去
+	cmd.EXPECT().Eval(gomock.Any(), luaRefresh, gomock.Any(), gomock.Any()).
+		Return(redis.NewCmdResult(nil, context.DeadlineExceeded)).AnyTimes()
+	l := &Lock{
+		client:     cmd,
+		key:        "job:ranking",
+		value:      "my-value",
+		expiration: time.Millisecond * 100,
+		validUntil: time.Now().Add(time.Millisecond * 100),
+		unlock:     make(chan struct{}),
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- l.AutoRefresh(time.Millisecond*10, time.Millisecond*20)
+	}()
+	select {
+	case err := <-done:
+		assert.Equal(t, ErrRefreshTimeout, err)
+	case <-time.After(time.Second):
+		t.Fatal("AutoRefresh 没有退出")
+	}
+}
+
+func TestLock_Unlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cmd := redismocks.NewMockCmdable(ctrl)
+	cmd.EXPECT().Eval(gomock.Any(), luaUnlock, []string{"job:ranking"},
+		[]any{"my-value"}).Return(redis.NewCmdResult(int64(1), nil))
+	l := &Lock{
+		client:     cmd,
+		key:        "job:ranking",
+		value:      "my-value",
+		expiration: time.Minute,
+		unlock:     make(chan struct{}),
+	}
+	// Unlock 之后 AutoRefresh 要退出
+	done := make(chan error, 1)
+	go func() {
+		done <- l.AutoRefresh(time.Hour, time.Second)
+	}()
+	assert.NoError(t, l.Unlock(context.Background()))
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("AutoRefresh 没有退出")
+	}
+}
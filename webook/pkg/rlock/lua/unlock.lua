@@ -0,0 +1,6 @@
+-- 只有锁还是自己的时候才删除
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("DEL", KEYS[1])
+else
+    return 0
+end
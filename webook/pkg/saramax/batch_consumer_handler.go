@@ -0,0 +1,171 @@
+package saramax
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+// BatchHandler 批量消费，攒够 batchSize 条消息，或者等了 batchDuration 之后，调用一次 fn
+// 一个 claim 只对应一个 partition，所以同一批消息都来自同一个 partition，并且偏移量是递增的
+type BatchHandler[T any] struct {
+	l  logger.LoggerV1
+	fn func(msgs []*sarama.ConsumerMessage, ts []T) error
+	// 在当前 goroutine 里面立刻重试整批消息的策略
+	policy RetryPolicy
+	// 没有设置的时候，重试次数用完了就停止消费，不提交
+	router *deadLetterRouter
+
+	batchSize     int
+	batchDuration time.Duration
+}
+
+func NewBatchHandler[T any](l logger.LoggerV1,
+	fn func(msgs []*sarama.ConsumerMessage, ts []T) error) *BatchHandler[T] {
+	return &BatchHandler[T]{
+		l:             l,
+		fn:            fn,
+		policy:        NewExponentialBackoff(time.Millisecond*100, time.Second, 3),
+		batchSize:     100,
+		batchDuration: time.Second,
+	}
+}
+
+// BatchSize 一批最多多少条消息
+func (b *BatchHandler[T]) BatchSize(size int) *BatchHandler[T] {
+	b.batchSize = size
+	return b
+}
+
+// BatchDuration 一批最多等多久，等不到 BatchSize 条也会处理
+func (b *BatchHandler[T]) BatchDuration(d time.Duration) *BatchHandler[T] {
+	b.batchDuration = d
+	return b
+}
+
+// WithRetryPolicy 设置立刻重试的策略，这期间会阻塞整个 partition，所以不要等太久
+func (b *BatchHandler[T]) WithRetryPolicy(policy RetryPolicy) *BatchHandler[T] {
+	b.policy = policy
+	return b
+}
+
+// WithDeadLetter 和 Handler 的一样。立刻重试也失败了的一批消息，每一条都按照 policy
+// 转发到重试 topic，重试几轮还是失败就转发到死信 topic。反序列化失败的消息直接转发到死信 topic。
+// 全部转发成功之后才提交，转发失败就停止消费，重新消费的时候已经转发过的会再转发一次。
+// 消费者要同时订阅 RetryTopic(topic)，fn 要能处理从重试 topic 来的消息
+func (b *BatchHandler[T]) WithDeadLetter(producer sarama.SyncProducer, policy RetryPolicy) *BatchHandler[T] {
+	b.router = &deadLetterRouter{
+		producer: producer,
+		policy:   policy,
+		now:      time.Now,
+	}
+	return b
+}
+
+func (b *BatchHandler[T]) Setup(session sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (b *BatchHandler[T]) Cleanup(session sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (b *BatchHandler[T]) ConsumeClaim(session sarama.ConsumerGroupSession,
+	claim sarama.ConsumerGroupClaim) error {
+	msgsCh := claim.Messages()
+	for {
+		msgs := make([]*sarama.ConsumerMessage, 0, b.batchSize)
+		ts := make([]T, 0, b.batchSize)
+		// 这一批里面最后一条消息，包括反序列化失败的，提交的时候用
+		var last *sarama.ConsumerMessage
+		closed := false
+		ctx, cancel := context.WithTimeout(session.Context(), b.batchDuration)
+		for i := 0; i < b.batchSize && !closed && ctx.Err() == nil; i++ {
+			select {
+			case <-ctx.Done():
+			case msg, ok := <-msgsCh:
+				if !ok {
+					// 再均衡或者退出了
+					closed = true
+					continue
+				}
+				// 从重试 topic 来的消息，没到时间就等着
+				if err := waitRetryAt(session.Context(), msg, time.Now()); err != nil {
+					cancel()
+					return nil
+				}
+				last = msg
+				t, err := decode[T](msg.Value)
+				if err != nil {
+					b.l.Error("反序列化消息失败",
+						logger.Error(err),
+						logger.String("topic", msg.Topic),
+						logger.Int64("partition", int64(msg.Partition)),
+						logger.Int64("offset", msg.Offset))
+					if b.router != nil && !forward(b.l, msg, err, b.router.deadLetter) {
+						cancel()
+						return fmt.Errorf("转发消息到死信 topic 失败，停止消费 topic %s partition %d",
+							claim.Topic(), claim.Partition())
+					}
+					continue
+				}
+				msgs = append(msgs, msg)
+				ts = append(ts, t)
+			}
+		}
+		cancel()
+		if err := b.handle(msgs, ts); err != nil && !b.forwardRetry(msgs, err) {
+			// 不能跳过这一批，不然下一批提交的时候会把这一批也提交掉。
+			// 直接退出，不提交，再均衡或者重启之后会从这一批重新消费
+			return fmt.Errorf("批量处理消息失败，停止消费 topic %s partition %d: %w",
+				claim.Topic(), claim.Partition(), err)
+		}
+		if last != nil {
+			// 提交最后一条，前面的也就一起提交了
+			session.MarkMessage(last, "")
+		}
+		if closed || session.Context().Err() != nil {
+			return nil
+		}
+	}
+}
+
+// handle 按照重试策略立刻重试整批消息，还是失败就返回最后一次的错误
+func (b *BatchHandler[T]) handle(msgs []*sarama.ConsumerMessage, ts []T) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	for attempt := 1; ; attempt++ {
+		err := b.fn(msgs, ts)
+		if err == nil {
+			return nil
+		}
+		b.l.Error("批量处理消息失败",
+			logger.Error(err),
+			logger.String("topic", msgs[0].Topic),
+			logger.Int64("partition", int64(msgs[0].Partition)),
+			logger.Int64("first_offset", msgs[0].Offset),
+			logger.Int64("last_offset", msgs[len(msgs)-1].Offset))
+		interval, ok := b.policy.Next(attempt)
+		if !ok {
+			return err
+		}
+		time.Sleep(interval)
+	}
+}
+
+// forwardRetry 把失败的一批消息逐条转发到重试 topic，全部成功了才可以提交
+func (b *BatchHandler[T]) forwardRetry(msgs []*sarama.ConsumerMessage, cause error) bool {
+	if b.router == nil {
+		return false
+	}
+	for _, msg := range msgs {
+		if !forward(b.l, msg, cause, b.router.retry) {
+			return false
+		}
+	}
+	return true
+}
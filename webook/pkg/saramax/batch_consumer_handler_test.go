@@ -0,0 +1,144 @@
+package saramax
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+type batchEvt struct {
+	Id int64 `json:"id"`
+}
+
+func TestBatchHandler_ConsumeClaim(t *testing.T) {
+	testCases := []struct {
+		name string
+		// 第几批返回什么错误
+		fn   func(batch int) error
+		msgs []string
+		// 是不是转发到重试 topic 和死信 topic
+		deadLetter bool
+
+		wantErr bool
+		// 提交了的最后一条消息
+		wantMarked []int64
+		// 转发到了哪些 topic
+		wantSent []string
+	}{
+		{
+			name: "两批都成功",
+			fn: func(batch int) error {
+				return nil
+			},
+			msgs:       []string{`{"id":1}`, `{"id":2}`, `{"id":3}`},
+			wantMarked: []int64{1, 2},
+		},
+		{
+			// 第一批失败了，不能因为第二批成功了就把第一批也提交了
+			name: "第一批失败",
+			fn: func(batch int) error {
+				if batch == 0 {
+					return errors.New("mock db error")
+				}
+				return nil
+			},
+			msgs:    []string{`{"id":1}`, `{"id":2}`, `{"id":3}`},
+			wantErr: true,
+		},
+		{
+			name: "第一批失败，转发到重试 topic",
+			fn: func(batch int) error {
+				if batch == 0 {
+					return errors.New("mock db error")
+				}
+				return nil
+			},
+			msgs:       []string{`{"id":1}`, `{"id":2}`, `{"id":3}`},
+			deadLetter: true,
+			wantMarked: []int64{1, 2},
+			wantSent:   []string{"read_article_retry", "read_article_retry"},
+		},
+		{
+			name: "反序列化失败",
+			fn: func(batch int) error {
+				return nil
+			},
+			msgs:       []string{`{"id":1}`, `abc`},
+			deadLetter: true,
+			wantMarked: []int64{1},
+			wantSent:   []string{"read_article_dlq"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ch := make(chan *sarama.ConsumerMessage, len(tc.msgs))
+			for i, val := range tc.msgs {
+				ch <- &sarama.ConsumerMessage{Topic: "read_article", Offset: int64(i), Value: []byte(val)}
+			}
+			close(ch)
+			batch := 0
+			h := NewBatchHandler[batchEvt](&logger.NoOpLogger{},
+				func(msgs []*sarama.ConsumerMessage, ts []batchEvt) error {
+					batch++
+					return tc.fn(batch - 1)
+				}).BatchSize(2).BatchDuration(time.Second).
+				// 测试里面不要立刻重试
+				WithRetryPolicy(NewExponentialBackoff(time.Millisecond, time.Millisecond, 1))
+			var sent []string
+			if tc.deadLetter {
+				producer := mocks.NewSyncProducer(t, nil)
+				for range tc.wantSent {
+					producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(
+						func(msg *sarama.ProducerMessage) error {
+							sent = append(sent, msg.Topic)
+							return nil
+						})
+				}
+				h.WithDeadLetter(producer, NewExponentialBackoff(time.Second, time.Second*5, 3))
+			}
+			session := &fakeSession{ctx: context.Background()}
+			err := h.ConsumeClaim(session, &fakeClaim{msgs: ch})
+			assert.Equal(t, tc.wantErr, err != nil)
+			assert.Equal(t, tc.wantMarked, session.marked)
+			assert.Equal(t, tc.wantSent, sent)
+		})
+	}
+}
+
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	ctx    context.Context
+	marked []int64
+}
+
+func (s *fakeSession) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.marked = append(s.marked, msg.Offset)
+}
+
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+	msgs chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Topic() string {
+	return "read_article"
+}
+
+func (c *fakeClaim) Partition() int32 {
+	return 0
+}
+
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage {
+	return c.msgs
+}
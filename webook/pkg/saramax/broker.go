@@ -0,0 +1,30 @@
+package saramax
+
+import (
+	"github.com/IBM/sarama"
+)
+
+// Broker 消息队列，生产者和消费者组都从这里创建
+// 线上用 Kafka，单机运行和测试可以用 memory 包里面的内存实现
+type Broker interface {
+	NewConsumerGroup(group string) (sarama.ConsumerGroup, error)
+	NewSyncProducer() (sarama.SyncProducer, error)
+}
+
+type KafkaBroker struct {
+	client sarama.Client
+}
+
+func NewKafkaBroker(client sarama.Client) *KafkaBroker {
+	return &KafkaBroker{
+		client: client,
+	}
+}
+
+func (k *KafkaBroker) NewConsumerGroup(group string) (sarama.ConsumerGroup, error) {
+	return sarama.NewConsumerGroupFromClient(group, k.client)
+}
+
+func (k *KafkaBroker) NewSyncProducer() (sarama.SyncProducer, error) {
+	return sarama.NewSyncProducerFromClient(k.client)
+}
@@ -1,7 +1,8 @@
 package saramax
 
 import (
-	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/IBM/sarama"
 	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
@@ -12,57 +13,106 @@ type HandlerV1[T any] func(msg *sarama.ConsumerMessage, t T) error
 type Handler[T any] struct {
 	l  logger.LoggerV1
 	fn func(msg *sarama.ConsumerMessage, t T) error
+	// 在当前 goroutine 里面立刻重试的策略
+	policy RetryPolicy
+	// 没有设置的时候，重试次数用完了就停止消费，不提交
+	router *deadLetterRouter
 }
 
 func NewHandler[T any](l logger.LoggerV1, fn func(msg *sarama.ConsumerMessage, t T) error) *Handler[T] {
 	return &Handler[T]{
-		l:  l,
-		fn: fn,
+		l:      l,
+		fn:     fn,
+		policy: NewExponentialBackoff(time.Millisecond*100, time.Second, 3),
 	}
 }
 
-func (h Handler[T]) Setup(session sarama.ConsumerGroupSession) error {
+// WithRetryPolicy 设置立刻重试的策略，这期间会阻塞整个 partition，所以不要等太久
+func (h *Handler[T]) WithRetryPolicy(policy RetryPolicy) *Handler[T] {
+	h.policy = policy
+	return h
+}
+
+// WithDeadLetter 立刻重试也失败了的消息，按照 policy 转发到重试 topic 再重试几轮，
+// 还是失败就转发到死信 topic。反序列化失败的消息直接转发到死信 topic。
+// 转发成功之后就提交，不会因为一条消息卡住整个 partition。
+// 消费者要同时订阅 RetryTopic(topic)
+func (h *Handler[T]) WithDeadLetter(producer sarama.SyncProducer, policy RetryPolicy) *Handler[T] {
+	h.router = &deadLetterRouter{
+		producer: producer,
+		policy:   policy,
+		now:      time.Now,
+	}
+	return h
+}
+
+func (h *Handler[T]) Setup(session sarama.ConsumerGroupSession) error {
 	return nil
 }
 
-func (h Handler[T]) Cleanup(session sarama.ConsumerGroupSession) error {
+func (h *Handler[T]) Cleanup(session sarama.ConsumerGroupSession) error {
 	return nil
 }
 
-func (h Handler[T]) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+func (h *Handler[T]) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	msgs := claim.Messages()
 	for msg := range msgs {
-		var t T
-		err := json.Unmarshal(msg.Value, &t)
+		// 从重试 topic 来的消息，没到时间就等着
+		if err := waitRetryAt(session.Context(), msg, time.Now()); err != nil {
+			return nil
+		}
+		t, err := decode[T](msg.Value)
 		if err != nil {
 			h.l.Error("反序列化消息失败",
-				logger.Error(err),
-				logger.String("topic", msg.Topic),
-				logger.Int64("topic", int64(msg.Partition)),
-				logger.Int64("topic", msg.Offset))
-			continue
-		}
-		// 在这里执行重试
-		for i := 0; i < 3; i++ {
-			err = h.fn(msg, t)
-			if err == nil {
-				break
-			}
-			h.l.Error("处理消息失败",
 				logger.Error(err),
 				logger.String("topic", msg.Topic),
 				logger.Int64("partition", int64(msg.Partition)),
 				logger.Int64("offset", msg.Offset))
+			// 重试也没有用，和 BatchHandler 一样，没有死信 topic 就跳过
+			if h.router != nil && !forward(h.l, msg, err, h.router.deadLetter) {
+				return fmt.Errorf("转发消息到死信 topic 失败，停止消费 topic %s partition %d",
+					claim.Topic(), claim.Partition())
+			}
+			session.MarkMessage(msg, "")
+			continue
 		}
+		err = h.handle(msg, t)
 		if err != nil {
 			h.l.Error("处理消息失败-重试次数上限",
 				logger.Error(err),
 				logger.String("topic", msg.Topic),
 				logger.Int64("partition", int64(msg.Partition)),
 				logger.Int64("offset", msg.Offset))
-		} else {
+			if h.router == nil || !forward(h.l, msg, err, h.router.retry) {
+				// 不能跳过这一条，不然后面的消息提交的时候会把它也提交掉。
+				// 直接退出，不提交，再均衡或者重启之后会从这一条重新消费
+				return fmt.Errorf("处理消息失败，停止消费 topic %s partition %d: %w",
+					claim.Topic(), claim.Partition(), err)
+			}
 			session.MarkMessage(msg, "")
+			continue
 		}
+		session.MarkMessage(msg, "")
 	}
 	return nil
 }
+
+// handle 按照重试策略立刻重试
+func (h *Handler[T]) handle(msg *sarama.ConsumerMessage, t T) error {
+	for attempt := 1; ; attempt++ {
+		err := h.fn(msg, t)
+		if err == nil {
+			return nil
+		}
+		h.l.Error("处理消息失败",
+			logger.Error(err),
+			logger.String("topic", msg.Topic),
+			logger.Int64("partition", int64(msg.Partition)),
+			logger.Int64("offset", msg.Offset))
+		interval, ok := h.policy.Next(attempt)
+		if !ok {
+			return err
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,105 @@
+package saramax
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+func TestHandler_ConsumeClaim(t *testing.T) {
+	testCases := []struct {
+		name string
+		// 第几条消息返回什么错误
+		fn   func(idx int64) error
+		msgs []string
+		// 是不是转发到重试 topic 和死信 topic
+		deadLetter bool
+
+		wantErr    bool
+		wantMarked []int64
+		wantSent   []string
+	}{
+		{
+			name: "全部成功",
+			fn: func(idx int64) error {
+				return nil
+			},
+			msgs:       []string{`{"id":1}`, `{"id":2}`},
+			wantMarked: []int64{0, 1},
+		},
+		{
+			// 第一条失败了，不能因为第二条成功了就把第一条也提交了
+			name: "第一条失败",
+			fn: func(idx int64) error {
+				if idx == 0 {
+					return errors.New("mock db error")
+				}
+				return nil
+			},
+			msgs:    []string{`{"id":1}`, `{"id":2}`},
+			wantErr: true,
+		},
+		{
+			name: "第一条失败，转发到重试 topic",
+			fn: func(idx int64) error {
+				if idx == 0 {
+					return errors.New("mock db error")
+				}
+				return nil
+			},
+			msgs:       []string{`{"id":1}`, `{"id":2}`},
+			deadLetter: true,
+			wantMarked: []int64{0, 1},
+			wantSent:   []string{"read_article_retry"},
+		},
+		{
+			name: "反序列化失败，转发到死信 topic",
+			fn: func(idx int64) error {
+				return nil
+			},
+			msgs:       []string{`abc`, `{"id":2}`},
+			deadLetter: true,
+			wantMarked: []int64{0, 1},
+			wantSent:   []string{"read_article_dlq"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ch := make(chan *sarama.ConsumerMessage, len(tc.msgs))
+			for i, val := range tc.msgs {
+				ch <- &sarama.ConsumerMessage{Topic: "read_article", Offset: int64(i), Value: []byte(val)}
+			}
+			close(ch)
+			h := NewHandler[batchEvt](&logger.NoOpLogger{},
+				func(msg *sarama.ConsumerMessage, evt batchEvt) error {
+					return tc.fn(msg.Offset)
+				}).
+				// 测试里面不要立刻重试
+				WithRetryPolicy(NewExponentialBackoff(time.Millisecond, time.Millisecond, 1))
+			var sent []string
+			if tc.deadLetter {
+				producer := mocks.NewSyncProducer(t, nil)
+				for range tc.wantSent {
+					producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(
+						func(msg *sarama.ProducerMessage) error {
+							sent = append(sent, msg.Topic)
+							return nil
+						})
+				}
+				h.WithDeadLetter(producer, NewExponentialBackoff(time.Second, time.Second*5, 3))
+			}
+			session := &fakeSession{ctx: context.Background()}
+			err := h.ConsumeClaim(session, &fakeClaim{msgs: ch})
+			assert.Equal(t, tc.wantErr, err != nil)
+			assert.Equal(t, tc.wantMarked, session.marked)
+			assert.Equal(t, tc.wantSent, sent)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package saramax
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+// 转发到重试 topic 和死信 topic 的时候，额外加上的头部
+// 原本的头部会原样保留
+const (
+	// HeaderOriginTopic 消息最开始是从哪个 topic 来的，重放的时候发回这个 topic
+	HeaderOriginTopic     = "x-origin-topic"
+	HeaderOriginPartition = "x-origin-partition"
+	HeaderOriginOffset    = "x-origin-offset"
+	// HeaderError 最后一次处理失败的原因
+	HeaderError = "x-error"
+	// HeaderRetryCount 已经经过重试 topic 重试了几轮
+	HeaderRetryCount = "x-retry-count"
+	// HeaderRetryAt 重试 topic 里面的消息，不早于这个时间处理，毫秒数
+	HeaderRetryAt = "x-retry-at"
+)
+
+// RetryTopic 每个 topic 都有自己的重试 topic，消费者要同时订阅这两个 topic
+func RetryTopic(topic string) string {
+	return topic + "_retry"
+}
+
+// DeadLetterTopic 重试了也处理不了的消息，最终放到这里，等人来处理
+func DeadLetterTopic(topic string) string {
+	return topic + "_dlq"
+}
+
+// deadLetterRouter 把处理不了的消息转发到重试 topic 或者死信 topic
+type deadLetterRouter struct {
+	producer sarama.SyncProducer
+	// 经过重试 topic 重试的策略
+	policy RetryPolicy
+	now    func() time.Time
+}
+
+// retry 重试次数还没有用完就转发到重试 topic，否则转发到死信 topic
+func (r *deadLetterRouter) retry(msg *sarama.ConsumerMessage, cause error) (string, error) {
+	cnt := headerInt(msg, HeaderRetryCount) + 1
+	interval, ok := r.policy.Next(int(cnt))
+	if !ok {
+		return r.deadLetter(msg, cause)
+	}
+	origin := originTopic(msg)
+	topic := RetryTopic(origin)
+	pm := r.forwardMessage(msg, topic, cause)
+	pm.Headers = setHeader(pm.Headers, HeaderRetryCount, strconv.FormatInt(cnt, 10))
+	pm.Headers = setHeader(pm.Headers, HeaderRetryAt,
+		strconv.FormatInt(r.now().Add(interval).UnixMilli(), 10))
+	_, _, err := r.producer.SendMessage(pm)
+	return topic, err
+}
+
+// deadLetter 直接转发到死信 topic，比如说反序列化失败的消息，重试也没有用
+func (r *deadLetterRouter) deadLetter(msg *sarama.ConsumerMessage, cause error) (string, error) {
+	topic := DeadLetterTopic(originTopic(msg))
+	_, _, err := r.producer.SendMessage(r.forwardMessage(msg, topic, cause))
+	return topic, err
+}
+
+func (r *deadLetterRouter) forwardMessage(msg *sarama.ConsumerMessage,
+	topic string, cause error) *sarama.ProducerMessage {
+	headers := make([]sarama.RecordHeader, 0, len(msg.Headers)+6)
+	for _, h := range msg.Headers {
+		headers = append(headers, *h)
+	}
+	// 第一次失败的时候记录来源，之后经过重试 topic 的就不要覆盖了
+	if headerValue(msg, HeaderOriginTopic) == "" {
+		headers = setHeader(headers, HeaderOriginTopic, msg.Topic)
+		headers = setHeader(headers, HeaderOriginPartition,
+			strconv.FormatInt(int64(msg.Partition), 10))
+		headers = setHeader(headers, HeaderOriginOffset,
+			strconv.FormatInt(msg.Offset, 10))
+	}
+	headers = setHeader(headers, HeaderError, cause.Error())
+	return &sarama.ProducerMessage{
+		Topic:   topic,
+		Key:     sarama.ByteEncoder(msg.Key),
+		Value:   sarama.ByteEncoder(msg.Value),
+		Headers: headers,
+	}
+}
+
+// forward 返回是不是转发成功了，转发成功了才可以提交
+func forward(l logger.LoggerV1, msg *sarama.ConsumerMessage, cause error,
+	fn func(msg *sarama.ConsumerMessage, cause error) (string, error)) bool {
+	topic, err := fn(msg, cause)
+	if err != nil {
+		l.Error("转发消息失败",
+			logger.Error(err),
+			logger.String("target", topic),
+			logger.String("topic", msg.Topic),
+			logger.Int64("partition", int64(msg.Partition)),
+			logger.Int64("offset", msg.Offset))
+		return false
+	}
+	l.Warn("转发消息",
+		logger.String("target", topic),
+		logger.String("topic", msg.Topic),
+		logger.Int64("partition", int64(msg.Partition)),
+		logger.Int64("offset", msg.Offset))
+	return true
+}
+
+// waitRetryAt 重试 topic 里面的消息，要等到约定的时间才能处理
+func waitRetryAt(ctx context.Context, msg *sarama.ConsumerMessage, now time.Time) error {
+	retryAt := headerInt(msg, HeaderRetryAt)
+	if retryAt <= 0 {
+		return nil
+	}
+	d := time.UnixMilli(retryAt).Sub(now)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func originTopic(msg *sarama.ConsumerMessage) string {
+	if origin := headerValue(msg, HeaderOriginTopic); origin != "" {
+		return origin
+	}
+	// 第一次失败的消息还没有这个头部，来源就是它自己所在的 topic
+	return strings.TrimSuffix(msg.Topic, "_retry")
+}
+
+func headerValue(msg *sarama.ConsumerMessage, key string) string {
+	for _, h := range msg.Headers {
+		if h != nil && string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func headerInt(msg *sarama.ConsumerMessage, key string) int64 {
+	val, _ := strconv.ParseInt(headerValue(msg, key), 10, 64)
+	return val
+}
+
+func setHeader(headers []sarama.RecordHeader, key, val string) []sarama.RecordHeader {
+	for i := range headers {
+		if string(headers[i].Key) == key {
+			headers[i].Value = []byte(val)
+			return headers
+		}
+	}
+	return append(headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(val)})
+}
@@ -0,0 +1,136 @@
+package saramax
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExponentialBackoff_Next(t *testing.T) {
+	policy := NewExponentialBackoff(time.Second, time.Second*5, 5)
+	testCases := []struct {
+		attempt  int
+		wantWait time.Duration
+		wantOk   bool
+	}{
+		{attempt: 1, wantWait: time.Second, wantOk: true},
+		{attempt: 2, wantWait: time.Second * 2, wantOk: true},
+		{attempt: 3, wantWait: time.Second * 4, wantOk: true},
+		// 超过上限用上限
+		{attempt: 4, wantWait: time.Second * 5, wantOk: true},
+		{attempt: 5, wantOk: false},
+	}
+	for _, tc := range testCases {
+		wait, ok := policy.Next(tc.attempt)
+		assert.Equal(t, tc.wantOk, ok)
+		assert.Equal(t, tc.wantWait, wait)
+	}
+}
+
+func TestDeadLetterRouter_retry(t *testing.T) {
+	now := time.UnixMilli(1000)
+	testCases := []struct {
+		name string
+		msg  *sarama.ConsumerMessage
+
+		wantTopic   string
+		wantHeaders map[string]string
+	}{
+		{
+			name: "第一次失败，进入重试 topic",
+			msg: &sarama.ConsumerMessage{
+				Topic:     "article_published",
+				Partition: 1,
+				Offset:    10,
+				Headers: []*sarama.RecordHeader{
+					{Key: []byte("trace_id"), Value: []byte("abc")},
+				},
+			},
+			wantTopic: "article_published_retry",
+			wantHeaders: map[string]string{
+				"trace_id":            "abc",
+				HeaderOriginTopic:     "article_published",
+				HeaderOriginPartition: "1",
+				HeaderOriginOffset:    "10",
+				HeaderError:           "mock error",
+				HeaderRetryCount:      "1",
+				HeaderRetryAt:         "2000",
+			},
+		},
+		{
+			name: "重试 topic 里面再次失败，保留来源",
+			msg: &sarama.ConsumerMessage{
+				Topic:     "article_published_retry",
+				Partition: 0,
+				Offset:    3,
+				Headers: []*sarama.RecordHeader{
+					{Key: []byte(HeaderOriginTopic), Value: []byte("article_published")},
+					{Key: []byte(HeaderOriginPartition), Value: []byte("1")},
+					{Key: []byte(HeaderOriginOffset), Value: []byte("10")},
+					{Key: []byte(HeaderRetryCount), Value: []byte("1")},
+				},
+			},
+			wantTopic: "article_published_retry",
+			wantHeaders: map[string]string{
+				HeaderOriginTopic:     "article_published",
+				HeaderOriginPartition: "1",
+				HeaderOriginOffset:    "10",
+				HeaderError:           "mock error",
+				HeaderRetryCount:      "2",
+				HeaderRetryAt:         "3000",
+			},
+		},
+		{
+			name: "重试次数用完，进入死信 topic",
+			msg: &sarama.ConsumerMessage{
+				Topic:     "article_published_retry",
+				Partition: 0,
+				Offset:    3,
+				Headers: []*sarama.RecordHeader{
+					{Key: []byte(HeaderOriginTopic), Value: []byte("article_published")},
+					{Key: []byte(HeaderRetryCount), Value: []byte("2")},
+					{Key: []byte(HeaderRetryAt), Value: []byte("3000")},
+				},
+			},
+			wantTopic: "article_published_dlq",
+			wantHeaders: map[string]string{
+				HeaderOriginTopic: "article_published",
+				HeaderError:       "mock error",
+				HeaderRetryCount:  "2",
+				HeaderRetryAt:     "3000",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			producer := mocks.NewSyncProducer(t, nil)
+			var sent *sarama.ProducerMessage
+			producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(
+				func(msg *sarama.ProducerMessage) error {
+					sent = msg
+					return nil
+				})
+			r := &deadLetterRouter{
+				producer: producer,
+				policy:   NewExponentialBackoff(time.Second, time.Second*5, 3),
+				now: func() time.Time {
+					return now
+				},
+			}
+			topic, err := r.retry(tc.msg, errors.New("mock error"))
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantTopic, topic)
+			assert.Equal(t, tc.wantTopic, sent.Topic)
+			headers := make(map[string]string, len(sent.Headers))
+			for _, h := range sent.Headers {
+				headers[string(h.Key)] = string(h.Value)
+			}
+			assert.Equal(t, tc.wantHeaders, headers)
+		})
+	}
+}
@@ -0,0 +1,204 @@
+// Package memory 进程内的消息队列，实现了 sarama 的 SyncProducer 和 ConsumerGroup，
+// 所以 saramax 里面的 Handler 不用做任何修改就可以使用。
+// 支持 topic、按照 key 分区、消费者组以及提交偏移量，但是消息只保存在内存里面，
+// 重启之后就没了，所以只适合单机运行和测试。
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+type Broker struct {
+	// 每个 topic 的分区数量，topic 在第一次用到的时候创建
+	partitions int32
+
+	mu     sync.Mutex
+	topics map[string]*topic
+	groups map[string]*group
+}
+
+func NewBroker(partitions int32) *Broker {
+	if partitions <= 0 {
+		partitions = 1
+	}
+	return &Broker{
+		partitions: partitions,
+		topics:     make(map[string]*topic),
+		groups:     make(map[string]*group),
+	}
+}
+
+func (b *Broker) NewConsumerGroup(group string) (sarama.ConsumerGroup, error) {
+	return &consumerGroup{
+		b:    b,
+		g:    b.group(group),
+		errs: make(chan error),
+	}, nil
+}
+
+func (b *Broker) NewSyncProducer() (sarama.SyncProducer, error) {
+	return &syncProducer{b: b}, nil
+}
+
+func (b *Broker) topic(name string) *topic {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t, ok := b.topics[name]
+	if !ok {
+		t = newTopic(name, b.partitions)
+		b.topics[name] = t
+	}
+	return t
+}
+
+func (b *Broker) group(name string) *group {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	g, ok := b.groups[name]
+	if !ok {
+		g = newGroup(b)
+		b.groups[name] = g
+	}
+	return g
+}
+
+func (b *Broker) produce(msg *sarama.ProducerMessage) (int32, int64, error) {
+	t := b.topic(msg.Topic)
+	partition, err := t.partitioner.Partition(msg, int32(len(t.partitions)))
+	if err != nil {
+		return 0, 0, err
+	}
+	cm := &sarama.ConsumerMessage{
+		Topic:     msg.Topic,
+		Partition: partition,
+		Timestamp: time.Now(),
+	}
+	if msg.Key != nil {
+		cm.Key, err = msg.Key.Encode()
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if msg.Value != nil {
+		cm.Value, err = msg.Value.Encode()
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	for i := range msg.Headers {
+		h := msg.Headers[i]
+		cm.Headers = append(cm.Headers, &h)
+	}
+	offset := t.partitions[partition].append(cm)
+	msg.Partition = partition
+	msg.Offset = offset
+	return partition, offset, nil
+}
+
+type topic struct {
+	partitions []*partition
+	// 和 Kafka 的默认行为一样，有 key 的按照 key 的哈希分区，没有 key 的随机
+	partitioner sarama.Partitioner
+}
+
+func newTopic(name string, cnt int32) *topic {
+	t := &topic{
+		partitions:  make([]*partition, cnt),
+		partitioner: sarama.NewHashPartitioner(name),
+	}
+	for i := range t.partitions {
+		t.partitions[i] = &partition{notify: make(chan struct{})}
+	}
+	return t
+}
+
+type partition struct {
+	mu   sync.Mutex
+	msgs []*sarama.ConsumerMessage
+	// 有新消息的时候关闭，唤醒所有在等待的消费者，然后换一个新的
+	notify chan struct{}
+}
+
+func (p *partition) append(msg *sarama.ConsumerMessage) int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	msg.Offset = int64(len(p.msgs))
+	p.msgs = append(p.msgs, msg)
+	close(p.notify)
+	p.notify = make(chan struct{})
+	return msg.Offset
+}
+
+// fetch 返回从 offset 开始的消息，没有消息的时候，等返回的 channel 关闭了再来取
+func (p *partition) fetch(offset int64) ([]*sarama.ConsumerMessage, <-chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if offset < int64(len(p.msgs)) {
+		return p.msgs[offset:], nil
+	}
+	return nil, p.notify
+}
+
+func (p *partition) highWaterMark() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return int64(len(p.msgs))
+}
+
+type syncProducer struct {
+	b *Broker
+}
+
+func (s *syncProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	return s.b.produce(msg)
+}
+
+func (s *syncProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	var errs sarama.ProducerErrors
+	for _, msg := range msgs {
+		if _, _, err := s.b.produce(msg); err != nil {
+			errs = append(errs, &sarama.ProducerError{Msg: msg, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (s *syncProducer) Close() error {
+	return nil
+}
+
+// 内存实现不支持事务
+
+func (s *syncProducer) TxnStatus() sarama.ProducerTxnStatusFlag {
+	return sarama.ProducerTxnFlagReady
+}
+
+func (s *syncProducer) IsTransactional() bool {
+	return false
+}
+
+func (s *syncProducer) BeginTxn() error {
+	return sarama.ErrNonTransactedProducer
+}
+
+func (s *syncProducer) CommitTxn() error {
+	return sarama.ErrNonTransactedProducer
+}
+
+func (s *syncProducer) AbortTxn() error {
+	return sarama.ErrNonTransactedProducer
+}
+
+func (s *syncProducer) AddOffsetsToTxn(offsets map[string][]*sarama.PartitionOffsetMetadata, groupId string) error {
+	return sarama.ErrNonTransactedProducer
+}
+
+func (s *syncProducer) AddMessageToTxn(msg *sarama.ConsumerMessage, groupId string, metadata *string) error {
+	return sarama.ErrNonTransactedProducer
+}
@@ -0,0 +1,179 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collectHandler 把收到的消息记下来，收到 want 条之后通知
+type collectHandler struct {
+	mu   sync.Mutex
+	msgs []*sarama.ConsumerMessage
+	want int
+	done chan struct{}
+	// 不为 0 的时候，只标记 offset 小于它的消息
+	markBefore int64
+}
+
+func newCollectHandler(want int) *collectHandler {
+	return &collectHandler{want: want, done: make(chan struct{})}
+}
+
+func (h *collectHandler) Setup(session sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *collectHandler) Cleanup(session sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *collectHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
+	claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		h.mu.Lock()
+		h.msgs = append(h.msgs, msg)
+		if len(h.msgs) == h.want {
+			close(h.done)
+		}
+		h.mu.Unlock()
+		if h.markBefore == 0 || msg.Offset < h.markBefore {
+			session.MarkMessage(msg, "")
+		}
+	}
+	return nil
+}
+
+func (h *collectHandler) wait(t *testing.T) []*sarama.ConsumerMessage {
+	select {
+	case <-h.done:
+	case <-time.After(time.Second):
+		t.Fatal("没有收到足够的消息")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.msgs
+}
+
+func send(t *testing.T, b *Broker, topic, key, val string) (int32, int64) {
+	p, err := b.NewSyncProducer()
+	require.NoError(t, err)
+	msg := &sarama.ProducerMessage{
+		Topic:   topic,
+		Value:   sarama.StringEncoder(val),
+		Headers: []sarama.RecordHeader{{Key: []byte("h"), Value: []byte("v")}},
+	}
+	if key != "" {
+		msg.Key = sarama.StringEncoder(key)
+	}
+	partition, offset, err := p.SendMessage(msg)
+	require.NoError(t, err)
+	return partition, offset
+}
+
+func consume(b *Broker, group string, topics []string, h sarama.ConsumerGroupHandler) (context.CancelFunc, chan error) {
+	cg, _ := b.NewConsumerGroup(group)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- cg.Consume(ctx, topics, h)
+	}()
+	return cancel, done
+}
+
+func TestBroker_KeyPartition(t *testing.T) {
+	b := NewBroker(4)
+	p1, o1 := send(t, b, "test_topic", "key-1", "a")
+	p2, o2 := send(t, b, "test_topic", "key-1", "b")
+	// 同一个 key 进同一个分区，offset 递增
+	assert.Equal(t, p1, p2)
+	assert.Equal(t, int64(0), o1)
+	assert.Equal(t, int64(1), o2)
+}
+
+func TestBroker_Consume(t *testing.T) {
+	b := NewBroker(4)
+	// 先发的消息，消费者启动之后也能收到
+	send(t, b, "test_topic", "key-1", "a")
+
+	h := newCollectHandler(3)
+	cancel, done := consume(b, "test_group", []string{"test_topic"}, h)
+	send(t, b, "test_topic", "key-1", "b")
+	send(t, b, "test_topic", "key-1", "c")
+	msgs := h.wait(t)
+	cancel()
+	assert.NoError(t, <-done)
+
+	vals := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		vals = append(vals, string(msg.Value))
+		assert.Equal(t, "key-1", string(msg.Key))
+		assert.Equal(t, []*sarama.RecordHeader{{Key: []byte("h"), Value: []byte("v")}}, msg.Headers)
+	}
+	// 同一个分区里面的消息是有序的
+	assert.Equal(t, []string{"a", "b", "c"}, vals)
+}
+
+func TestBroker_Groups(t *testing.T) {
+	b := NewBroker(2)
+	h1 := newCollectHandler(2)
+	h2 := newCollectHandler(2)
+	cancel1, done1 := consume(b, "group_1", []string{"test_topic"}, h1)
+	cancel2, done2 := consume(b, "group_2", []string{"test_topic"}, h2)
+	send(t, b, "test_topic", "key-1", "a")
+	send(t, b, "test_topic", "key-2", "b")
+	// 不同的消费者组各自收到全部消息
+	assert.Len(t, h1.wait(t), 2)
+	assert.Len(t, h2.wait(t), 2)
+	cancel1()
+	cancel2()
+	assert.NoError(t, <-done1)
+	assert.NoError(t, <-done2)
+}
+
+func TestBroker_CommittedOffset(t *testing.T) {
+	b := NewBroker(1)
+	for _, val := range []string{"a", "b", "c"} {
+		send(t, b, "test_topic", "", val)
+	}
+	// 第一次只提交了 a
+	h := newCollectHandler(3)
+	h.markBefore = 1
+	cancel, done := consume(b, "test_group", []string{"test_topic"}, h)
+	h.wait(t)
+	cancel()
+	assert.NoError(t, <-done)
+
+	// 重启之后从 b 开始
+	h = newCollectHandler(2)
+	cancel, done = consume(b, "test_group", []string{"test_topic"}, h)
+	msgs := h.wait(t)
+	cancel()
+	assert.NoError(t, <-done)
+	assert.Equal(t, "b", string(msgs[0].Value))
+	assert.Equal(t, "c", string(msgs[1].Value))
+}
+
+func TestGroup_Assignment(t *testing.T) {
+	b := NewBroker(3)
+	g := b.group("test_group")
+	m1 := g.join([]string{"topic_a"})
+	_, _, claims := g.assignment(m1)
+	assert.Equal(t, map[string][]int32{"topic_a": {0, 1, 2}}, claims)
+
+	m2 := g.join([]string{"topic_a", "topic_b"})
+	gen, _, claims1 := g.assignment(m1)
+	_, _, claims2 := g.assignment(m2)
+	assert.Equal(t, int32(2), gen)
+	assert.Equal(t, map[string][]int32{"topic_a": {0, 2}}, claims1)
+	assert.Equal(t, map[string][]int32{"topic_a": {1}, "topic_b": {0, 1, 2}}, claims2)
+
+	g.leave(m2)
+	_, _, claims = g.assignment(m1)
+	assert.Equal(t, map[string][]int32{"topic_a": {0, 1, 2}}, claims)
+}
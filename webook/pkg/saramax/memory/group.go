@@ -0,0 +1,314 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/IBM/sarama"
+)
+
+// group 消费者组。
+// 成员加入或者离开的时候，代数加一，所有成员重新分配分区，和 Kafka 的 rebalance 一样
+type group struct {
+	b *Broker
+
+	mu         sync.Mutex
+	members    map[string][]string
+	nextMember int64
+	generation int32
+	// 有成员变动的时候关闭，通知当前这一代的所有会话退出
+	rebalance chan struct{}
+	// topic -> partition -> 下一条要消费的消息
+	offsets map[string]map[int32]int64
+}
+
+func newGroup(b *Broker) *group {
+	return &group{
+		b:         b,
+		members:   make(map[string][]string),
+		rebalance: make(chan struct{}),
+		offsets:   make(map[string]map[int32]int64),
+	}
+}
+
+func (g *group) join(topics []string) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.nextMember++
+	id := "member-" + strconv.FormatInt(g.nextMember, 10)
+	g.members[id] = topics
+	g.bumpGeneration()
+	return id
+}
+
+func (g *group) leave(member string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.members, member)
+	g.bumpGeneration()
+}
+
+func (g *group) bumpGeneration() {
+	g.generation++
+	close(g.rebalance)
+	g.rebalance = make(chan struct{})
+}
+
+// assignment 计算 member 在当前这一代分到的分区。
+// 每个 topic 的分区按照成员 ID 的顺序轮流分配给订阅了它的成员
+func (g *group) assignment(member string) (int32, <-chan struct{}, map[string][]int32) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	res := make(map[string][]int32)
+	for _, tp := range g.members[member] {
+		if _, ok := res[tp]; ok {
+			continue
+		}
+		var subscribers []string
+		for id, topics := range g.members {
+			for _, t := range topics {
+				if t == tp {
+					subscribers = append(subscribers, id)
+					break
+				}
+			}
+		}
+		sort.Strings(subscribers)
+		idx := sort.SearchStrings(subscribers, member)
+		partitions := []int32{}
+		cnt := int32(len(g.b.topic(tp).partitions))
+		for p := int32(idx); p < cnt; p += int32(len(subscribers)) {
+			partitions = append(partitions, p)
+		}
+		res[tp] = partitions
+	}
+	return g.generation, g.rebalance, res
+}
+
+// offset 已经提交的偏移量，没有提交过就从最老的消息开始
+func (g *group) offset(topic string, partition int32) int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.offsets[topic][partition]
+}
+
+func (g *group) commit(topic string, partition int32, offset int64, force bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	partitions, ok := g.offsets[topic]
+	if !ok {
+		partitions = make(map[int32]int64)
+		g.offsets[topic] = partitions
+	}
+	// 和 sarama 一样，MarkOffset 只能往前走，ResetOffset 才能回退
+	if force || offset > partitions[partition] {
+		partitions[partition] = offset
+	}
+}
+
+type consumerGroup struct {
+	b      *Broker
+	g      *group
+	errs   chan error
+	closed atomic.Bool
+}
+
+// Consume 和 sarama 不同，重新分配分区的时候不会返回，而是在内部开始新的会话，
+// 直到 ctx 结束或者某个 ConsumeClaim 自己退出了
+func (c *consumerGroup) Consume(ctx context.Context, topics []string, handler sarama.ConsumerGroupHandler) error {
+	if c.closed.Load() {
+		return sarama.ErrClosedConsumerGroup
+	}
+	member := c.g.join(topics)
+	defer c.g.leave(member)
+	for ctx.Err() == nil && !c.closed.Load() {
+		generation, rebalance, claims := c.g.assignment(member)
+		rebalanced, err := c.session(ctx, member, generation, rebalance, claims, handler)
+		if err != nil {
+			return err
+		}
+		if !rebalanced {
+			return nil
+		}
+	}
+	return nil
+}
+
+// session 返回是不是因为重新分配分区才结束的
+func (c *consumerGroup) session(ctx context.Context, member string, generation int32,
+	rebalance <-chan struct{}, claims map[string][]int32,
+	handler sarama.ConsumerGroupHandler) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var rebalanced atomic.Bool
+	go func() {
+		select {
+		case <-rebalance:
+			rebalanced.Store(true)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	sess := &session{ctx: ctx, g: c.g, member: member, generation: generation, claims: claims}
+	if err := handler.Setup(sess); err != nil {
+		return false, err
+	}
+	var wg sync.WaitGroup
+	for tp, partitions := range claims {
+		for _, p := range partitions {
+			claim := newClaim(tp, p, c.b.topic(tp).partitions[p], c.g.offset(tp, p))
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				claim.feed(ctx)
+			}()
+			go func() {
+				defer wg.Done()
+				// 任何一个 ConsumeClaim 退出，整个会话都结束
+				defer cancel()
+				if err := handler.ConsumeClaim(sess, claim); err != nil {
+					c.sendError(err)
+				}
+			}()
+		}
+	}
+	// 没有分到分区的成员，等着下一次重新分配
+	<-ctx.Done()
+	wg.Wait()
+	err := handler.Cleanup(sess)
+	return rebalanced.Load(), err
+}
+
+func (c *consumerGroup) sendError(err error) {
+	select {
+	case c.errs <- err:
+	default:
+	}
+}
+
+func (c *consumerGroup) Errors() <-chan error {
+	return c.errs
+}
+
+func (c *consumerGroup) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+// 内存实现不支持暂停
+
+func (c *consumerGroup) Pause(partitions map[string][]int32) {}
+
+func (c *consumerGroup) Resume(partitions map[string][]int32) {}
+
+func (c *consumerGroup) PauseAll() {}
+
+func (c *consumerGroup) ResumeAll() {}
+
+type session struct {
+	ctx        context.Context
+	g          *group
+	member     string
+	generation int32
+	claims     map[string][]int32
+}
+
+func (s *session) Claims() map[string][]int32 {
+	return s.claims
+}
+
+func (s *session) MemberID() string {
+	return s.member
+}
+
+func (s *session) GenerationID() int32 {
+	return s.generation
+}
+
+func (s *session) MarkOffset(topic string, partition int32, offset int64, metadata string) {
+	s.g.commit(topic, partition, offset, false)
+}
+
+// Commit 偏移量在标记的时候就已经提交了
+func (s *session) Commit() {}
+
+func (s *session) ResetOffset(topic string, partition int32, offset int64, metadata string) {
+	s.g.commit(topic, partition, offset, true)
+}
+
+func (s *session) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.MarkOffset(msg.Topic, msg.Partition, msg.Offset+1, metadata)
+}
+
+func (s *session) Context() context.Context {
+	return s.ctx
+}
+
+type claim struct {
+	topic     string
+	partition int32
+	p         *partition
+	offset    int64
+	msgs      chan *sarama.ConsumerMessage
+}
+
+func newClaim(topic string, partition int32, p *partition, offset int64) *claim {
+	return &claim{
+		topic:     topic,
+		partition: partition,
+		p:         p,
+		offset:    offset,
+		msgs:      make(chan *sarama.ConsumerMessage, 64),
+	}
+}
+
+// feed 把分区里面的消息源源不断地推给 Messages，会话结束的时候关闭 Messages
+func (c *claim) feed(ctx context.Context) {
+	defer close(c.msgs)
+	offset := c.offset
+	for {
+		msgs, notify := c.p.fetch(offset)
+		for _, msg := range msgs {
+			// 每个消费者拿到的都是副本，免得互相影响
+			cp := *msg
+			select {
+			case c.msgs <- &cp:
+				offset++
+			case <-ctx.Done():
+				return
+			}
+		}
+		if notify == nil {
+			continue
+		}
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *claim) Topic() string {
+	return c.topic
+}
+
+func (c *claim) Partition() int32 {
+	return c.partition
+}
+
+func (c *claim) InitialOffset() int64 {
+	return c.offset
+}
+
+func (c *claim) HighWaterMarkOffset() int64 {
+	return c.p.highWaterMark()
+}
+
+func (c *claim) Messages() <-chan *sarama.ConsumerMessage {
+	return c.msgs
+}
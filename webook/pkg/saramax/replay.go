@@ -0,0 +1,195 @@
+package saramax
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/IBM/sarama"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/logger"
+)
+
+// DeadLetterReplayer 把死信 topic 里面的消息发回原本的 topic
+// 一般是修复了 bug 之后手动执行一次。
+// 用消费者组记录重放到哪里了，所以同一条死信只会被重放一次
+type DeadLetterReplayer struct {
+	// 要求 Consumer.Offsets.Initial 是 sarama.OffsetOldest，不然第一次重放会跳过已有的死信
+	client   sarama.Client
+	producer sarama.SyncProducer
+	l        logger.LoggerV1
+	group    string
+}
+
+func NewDeadLetterReplayer(client sarama.Client, producer sarama.SyncProducer,
+	l logger.LoggerV1) *DeadLetterReplayer {
+	return &DeadLetterReplayer{
+		client:   client,
+		producer: producer,
+		l:        l,
+		group:    "dlq_replay",
+	}
+}
+
+// Replay 重放 topic 的死信，重放到开始执行时候的最新消息就结束，返回重放了多少条
+func (r *DeadLetterReplayer) Replay(ctx context.Context, topic string) (int64, error) {
+	dlq := DeadLetterTopic(topic)
+	partitions, err := r.client.Partitions(dlq)
+	if err != nil {
+		return 0, err
+	}
+	if len(partitions) == 0 {
+		return 0, nil
+	}
+	cg, err := sarama.NewConsumerGroupFromClient(r.group, r.client)
+	if err != nil {
+		return 0, err
+	}
+	defer cg.Close()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	h := &replayHandler{
+		r:         r,
+		remaining: int32(len(partitions)),
+		done:      make(chan struct{}),
+		cancel:    cancel,
+	}
+	go func() {
+		select {
+		case <-h.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	// 再均衡之后 Consume 会返回，没有重放完就继续
+	for ctx.Err() == nil {
+		err = cg.Consume(ctx, []string{dlq}, h)
+		if err != nil {
+			return h.cnt.Load(), err
+		}
+	}
+	if err = h.failure(); err != nil {
+		return h.cnt.Load(), err
+	}
+	select {
+	case <-h.done:
+		return h.cnt.Load(), nil
+	default:
+		return h.cnt.Load(), ctx.Err()
+	}
+}
+
+func (r *DeadLetterReplayer) replay(msg *sarama.ConsumerMessage) error {
+	origin := headerValue(msg, HeaderOriginTopic)
+	if origin == "" {
+		origin = strings.TrimSuffix(msg.Topic, "_dlq")
+	}
+	// 原本的头部保留，重试和失败相关的去掉，回到原本的 topic 就是一条新消息
+	headers := make([]sarama.RecordHeader, 0, len(msg.Headers))
+	for _, h := range msg.Headers {
+		if h == nil || strings.HasPrefix(string(h.Key), "x-retry-") || string(h.Key) == HeaderError {
+			continue
+		}
+		headers = append(headers, *h)
+	}
+	_, _, err := r.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:   origin,
+		Key:     sarama.ByteEncoder(msg.Key),
+		Value:   sarama.ByteEncoder(msg.Value),
+		Headers: headers,
+	})
+	return err
+}
+
+type replayHandler struct {
+	r   *DeadLetterReplayer
+	cnt atomic.Int64
+	// 还有多少个 partition 没有重放完
+	remaining int32
+	doneOnce  sync.Once
+	done      chan struct{}
+	// 重放完了的 partition，再均衡之后不要重复计数
+	finished sync.Map
+	// 重放失败就整个结束，不然会一直重试同一条
+	cancel context.CancelFunc
+	mu     sync.Mutex
+	err    error
+}
+
+func (h *replayHandler) Setup(session sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *replayHandler) Cleanup(session sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *replayHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
+	claim sarama.ConsumerGroupClaim) error {
+	// 开始的时候最新的消息，重放到这里就结束，之后进来的死信留给下一次
+	hwm := claim.HighWaterMarkOffset()
+	start := claim.InitialOffset()
+	if start < 0 {
+		// 还没有提交过，从最老的消息开始
+		oldest, err := h.r.client.GetOffset(claim.Topic(), claim.Partition(), sarama.OffsetOldest)
+		if err != nil {
+			h.fail(err)
+			return err
+		}
+		start = oldest
+	}
+	if start >= hwm {
+		h.finish(claim.Partition())
+	} else {
+		for msg := range claim.Messages() {
+			if err := h.r.replay(msg); err != nil {
+				h.r.l.Error("重放死信失败",
+					logger.Error(err),
+					logger.String("topic", msg.Topic),
+					logger.Int64("partition", int64(msg.Partition)),
+					logger.Int64("offset", msg.Offset))
+				// 不提交，下一次执行的时候从这里继续
+				h.fail(err)
+				return err
+			}
+			h.cnt.Add(1)
+			session.MarkMessage(msg, "")
+			if msg.Offset >= hwm-1 {
+				h.finish(claim.Partition())
+				break
+			}
+		}
+	}
+	// 任何一个 ConsumeClaim 返回都会结束整个 session，所以要等所有的 partition 都重放完
+	select {
+	case <-h.done:
+	case <-session.Context().Done():
+	}
+	return nil
+}
+
+func (h *replayHandler) finish(partition int32) {
+	if _, loaded := h.finished.LoadOrStore(partition, struct{}{}); loaded {
+		return
+	}
+	if atomic.AddInt32(&h.remaining, -1) == 0 {
+		h.doneOnce.Do(func() {
+			close(h.done)
+		})
+	}
+}
+
+func (h *replayHandler) fail(err error) {
+	h.mu.Lock()
+	if h.err == nil {
+		h.err = err
+	}
+	h.mu.Unlock()
+	h.cancel()
+}
+
+func (h *replayHandler) failure() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.err
+}
@@ -0,0 +1,46 @@
+package saramax
+
+import (
+	"time"
+)
+
+// RetryPolicy 决定处理失败之后还要不要重试，以及重试之前要等多久
+type RetryPolicy interface {
+	// Next attempt 是已经失败了多少次，从 1 开始
+	// 返回 false 表示不要再重试了
+	Next(attempt int) (time.Duration, bool)
+}
+
+// ExponentialBackoff 指数退避，每次失败之后等待的时间翻倍，直到 max
+type ExponentialBackoff struct {
+	initial time.Duration
+	max     time.Duration
+	// 最多尝试多少次，包括第一次
+	maxAttempts int
+}
+
+func NewExponentialBackoff(initial, max time.Duration, maxAttempts int) *ExponentialBackoff {
+	return &ExponentialBackoff{
+		initial:     initial,
+		max:         max,
+		maxAttempts: maxAttempts,
+	}
+}
+
+func (e *ExponentialBackoff) Next(attempt int) (time.Duration, bool) {
+	if attempt >= e.maxAttempts {
+		return 0, false
+	}
+	interval := e.initial
+	for i := 1; i < attempt; i++ {
+		interval *= 2
+		// 溢出或者超过上限都用上限
+		if interval <= 0 || interval >= e.max {
+			return e.max, true
+		}
+	}
+	if interval > e.max {
+		interval = e.max
+	}
+	return interval, true
+}
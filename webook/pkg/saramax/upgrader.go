@@ -0,0 +1,24 @@
+package saramax
+
+import (
+	"encoding/json"
+)
+
+// Upgrader 消息反序列化之后，如果 *T 实现了这个接口，就先调用 Upgrade
+// 把老版本的消息升级到当前版本。
+// 返回 error 的消息没法处理，和反序列化失败一样对待
+type Upgrader interface {
+	Upgrade() error
+}
+
+func decode[T any](data []byte) (T, error) {
+	var t T
+	err := json.Unmarshal(data, &t)
+	if err != nil {
+		return t, err
+	}
+	if u, ok := any(&t).(Upgrader); ok {
+		err = u.Upgrade()
+	}
+	return t, err
+}
@@ -0,0 +1,93 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Highlight 用 pre 和 post 包裹 text 里面命中 terms 的部分，不区分大小写。
+// 重叠或者相邻的命中会被合并成一段，比如搜“数据库”的时候，
+// “数据”和“据库”两个二元词合并之后只会高亮一次。
+// 除了 pre 和 post，其余的文本都会做 HTML 转义，所以结果可以直接渲染
+func Highlight(text string, terms []string, pre, post string) string {
+	runes := []rune(text)
+	ranges := matches(runes, terms)
+	var sb strings.Builder
+	last := 0
+	for _, r := range ranges {
+		sb.WriteString(html.EscapeString(string(runes[last:r[0]])))
+		sb.WriteString(pre)
+		sb.WriteString(html.EscapeString(string(runes[r[0]:r[1]])))
+		sb.WriteString(post)
+		last = r[1]
+	}
+	sb.WriteString(html.EscapeString(string(runes[last:])))
+	return sb.String()
+}
+
+// FirstMatch 返回 text 里面第一次命中 terms 的位置，按照字符计算
+// 没有命中返回 -1
+func FirstMatch(text string, terms []string) int {
+	ranges := matches([]rune(text), terms)
+	if len(ranges) == 0 {
+		return -1
+	}
+	return ranges[0][0]
+}
+
+// matches 找到所有命中的区间，左闭右开，已经按照起点排序并且合并过
+func matches(text []rune, terms []string) [][2]int {
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+	covered := make([]bool, len(text)+1)
+	for _, term := range terms {
+		t := []rune(term)
+		if len(t) == 0 {
+			continue
+		}
+		// 英文单词要完整匹配，不然搜 go 的时候 google 也会被高亮
+		word := !isCJK(t[0])
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if word && (isWordRune(lower, i-1) || isWordRune(lower, i+len(t))) {
+				continue
+			}
+			if runesEqual(lower[i:i+len(t)], t) {
+				for j := i; j < i+len(t); j++ {
+					covered[j] = true
+				}
+			}
+		}
+	}
+	var res [][2]int
+	start := -1
+	for i, c := range covered {
+		if c && start < 0 {
+			start = i
+		}
+		if !c && start >= 0 {
+			res = append(res, [2]int{start, i})
+			start = -1
+		}
+	}
+	return res
+}
+
+func isWordRune(text []rune, i int) bool {
+	if i < 0 || i >= len(text) {
+		return false
+	}
+	r := text[i]
+	return !isCJK(r) && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
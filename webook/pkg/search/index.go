@@ -0,0 +1,205 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Document 被索引的文档
+type Document struct {
+	Id      int64
+	Title   string
+	Content string
+	// Utime 分数相同的时候，新的文档排在前面
+	Utime int64
+}
+
+type Hit struct {
+	Doc   Document
+	Score float64
+}
+
+type Result struct {
+	// Total 命中的文档总数，用于分页
+	Total int
+	// Terms 查询切分出来的词，用于高亮
+	Terms []string
+	Hits  []Hit
+}
+
+// Index 内存中的倒排索引，并发安全
+// 排序使用 BM25，标题里面的词权重更高
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[int64]*entry
+	postings map[string]map[int64]posting
+	// 所有文档的长度之和，用于计算平均长度
+	totalLen int
+
+	k1         float64
+	b          float64
+	titleBoost float64
+}
+
+type entry struct {
+	doc    Document
+	length int
+	terms  []string
+}
+
+// posting 某个词在某篇文档的标题和内容里面分别出现了多少次
+type posting struct {
+	title   int
+	content int
+}
+
+func NewIndex() *Index {
+	return &Index{
+		docs:       make(map[int64]*entry),
+		postings:   make(map[string]map[int64]posting),
+		k1:         1.2,
+		b:          0.75,
+		titleBoost: 3,
+	}
+}
+
+// Upsert 添加文档，如果已经存在就替换
+func (idx *Index) Upsert(doc Document) {
+	freqs := make(map[string]posting)
+	titleTokens := Tokenize(doc.Title)
+	for _, t := range titleTokens {
+		p := freqs[t]
+		p.title++
+		freqs[t] = p
+	}
+	contentTokens := Tokenize(doc.Content)
+	for _, t := range contentTokens {
+		p := freqs[t]
+		p.content++
+		freqs[t] = p
+	}
+	e := &entry{
+		doc:    doc,
+		length: len(titleTokens) + len(contentTokens),
+		terms:  make([]string, 0, len(freqs)),
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(doc.Id)
+	for t, p := range freqs {
+		docs, ok := idx.postings[t]
+		if !ok {
+			docs = make(map[int64]posting)
+			idx.postings[t] = docs
+		}
+		docs[doc.Id] = p
+		e.terms = append(e.terms, t)
+	}
+	idx.docs[doc.Id] = e
+	idx.totalLen += e.length
+}
+
+// Remove 删除文档，返回文档原本是否存在
+func (idx *Index) Remove(id int64) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return idx.remove(id)
+}
+
+func (idx *Index) remove(id int64) bool {
+	e, ok := idx.docs[id]
+	if !ok {
+		return false
+	}
+	for _, t := range e.terms {
+		docs := idx.postings[t]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(idx.postings, t)
+		}
+	}
+	idx.totalLen -= e.length
+	delete(idx.docs, id)
+	return true
+}
+
+// Len 文档数量
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Search 返回包含所有查询词的文档，按照分数倒序
+func (idx *Index) Search(query string, offset, limit int) Result {
+	terms := QueryTerms(query)
+	res := Result{Terms: terms}
+	if len(terms) == 0 {
+		return res
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	lists := make([]map[int64]posting, 0, len(terms))
+	for _, t := range terms {
+		docs, ok := idx.postings[t]
+		if !ok {
+			// 有一个词没有命中，那么就没有结果
+			return res
+		}
+		lists = append(lists, docs)
+	}
+	// 从最短的倒排表开始求交集
+	sort.Slice(lists, func(i, j int) bool {
+		return len(lists[i]) < len(lists[j])
+	})
+
+	n := float64(len(idx.docs))
+	avgLen := float64(idx.totalLen) / n
+	idfs := make([]float64, len(lists))
+	for i, docs := range lists {
+		df := float64(len(docs))
+		idfs[i] = math.Log(1 + (n-df+0.5)/(df+0.5))
+	}
+
+	var hits []Hit
+outer:
+	for id := range lists[0] {
+		e := idx.docs[id]
+		score := 0.0
+		for i, docs := range lists {
+			p, ok := docs[id]
+			if !ok {
+				continue outer
+			}
+			tf := idx.titleBoost*float64(p.title) + float64(p.content)
+			norm := idx.k1 * (1 - idx.b + idx.b*float64(e.length)/avgLen)
+			score += idfs[i] * tf * (idx.k1 + 1) / (tf + norm)
+		}
+		hits = append(hits, Hit{Doc: e.doc, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Doc.Utime != hits[j].Doc.Utime {
+			return hits[i].Doc.Utime > hits[j].Doc.Utime
+		}
+		return hits[i].Doc.Id > hits[j].Doc.Id
+	})
+	res.Total = len(hits)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(hits) {
+		return res
+	}
+	end := offset + limit
+	if end > len(hits) {
+		end = len(hits)
+	}
+	res.Hits = hits[offset:end]
+	return res
+}
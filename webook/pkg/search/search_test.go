@@ -0,0 +1,140 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "英文转小写，丢弃标点",
+			text: "Hello, Go-1.20!",
+			want: []string{"hello", "go", "1", "20"},
+		},
+		{
+			name: "中文单字和二元词",
+			text: "数据库",
+			want: []string{"数", "数据", "据", "据库", "库"},
+		},
+		{
+			name: "中英混合",
+			text: "学Go语言",
+			want: []string{"学", "go", "语", "语言", "言"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Tokenize(tc.text))
+		})
+	}
+}
+
+func TestQueryTerms(t *testing.T) {
+	testCases := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "中文只用二元词",
+			query: "数据库",
+			want:  []string{"数据", "据库"},
+		},
+		{
+			name:  "单个汉字",
+			query: "库 Go go",
+			want:  []string{"库", "go"},
+		},
+		{
+			name:  "只有标点",
+			query: "，。!",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, QueryTerms(tc.query))
+		})
+	}
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex()
+	idx.Upsert(Document{Id: 1, Title: "MySQL 索引优化", Content: "联合索引和最左匹配原则", Utime: 1})
+	idx.Upsert(Document{Id: 2, Title: "Redis 入门", Content: "Redis 也可以用作消息队列，但是不如 Kafka", Utime: 2})
+	idx.Upsert(Document{Id: 3, Title: "Kafka 消息队列", Content: "消息队列的分区和消费者组", Utime: 3})
+
+	// 标题命中的排在前面
+	res := idx.Search("消息队列", 0, 10)
+	assert.Equal(t, 2, res.Total)
+	assert.Equal(t, int64(3), res.Hits[0].Doc.Id)
+	assert.Equal(t, int64(2), res.Hits[1].Doc.Id)
+
+	// 所有词都要命中
+	res = idx.Search("redis 索引", 0, 10)
+	assert.Equal(t, 0, res.Total)
+
+	// 分页
+	res = idx.Search("消息队列", 1, 10)
+	assert.Equal(t, 2, res.Total)
+	assert.Equal(t, 1, len(res.Hits))
+	assert.Equal(t, int64(2), res.Hits[0].Doc.Id)
+
+	// 更新之后旧的内容搜不到了
+	idx.Upsert(Document{Id: 3, Title: "Kafka 入门", Content: "分区和消费者组", Utime: 4})
+	res = idx.Search("消息队列", 0, 10)
+	assert.Equal(t, 1, res.Total)
+	assert.Equal(t, int64(2), res.Hits[0].Doc.Id)
+
+	assert.True(t, idx.Remove(2))
+	assert.False(t, idx.Remove(2))
+	res = idx.Search("消息队列", 0, 10)
+	assert.Equal(t, 0, res.Total)
+	assert.Equal(t, 2, idx.Len())
+}
+
+func TestHighlight(t *testing.T) {
+	testCases := []struct {
+		name  string
+		text  string
+		terms []string
+		want  string
+	}{
+		{
+			name:  "合并重叠的二元词",
+			text:  "MySQL 数据库的索引",
+			terms: []string{"数据", "据库"},
+			want:  "MySQL <em>数据库</em>的索引",
+		},
+		{
+			name:  "英文不区分大小写，并且完整匹配",
+			text:  "Go 和 google",
+			terms: []string{"go"},
+			want:  "<em>Go</em> 和 google",
+		},
+		{
+			name:  "转义 HTML",
+			text:  "<script>go</script>",
+			terms: []string{"go"},
+			want:  "&lt;script&gt;<em>go</em>&lt;/script&gt;",
+		},
+		{
+			name:  "没有命中",
+			text:  "abc",
+			terms: []string{"x"},
+			want:  "abc",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Highlight(tc.text, tc.terms, "<em>", "</em>"))
+		})
+	}
+	assert.Equal(t, 6, FirstMatch("MySQL 数据库", []string{"据库", "数据"}))
+	assert.Equal(t, -1, FirstMatch("MySQL", []string{"数据"}))
+}
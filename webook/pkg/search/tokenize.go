@@ -0,0 +1,89 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenize 把文本切分成索引用的词。
+// 英文和数字按照连续的字母数字切分，并且转成小写；
+// 中日韩文字没有空格，所以同时生成单字和相邻两个字组成的二元词，
+// 这样无论是搜一个字还是搜一个词都能命中
+func Tokenize(text string) []string {
+	var res []string
+	walk(text, func(word string) {
+		res = append(res, word)
+	}, func(run []rune) {
+		for i := range run {
+			res = append(res, string(run[i]))
+			if i+1 < len(run) {
+				res = append(res, string(run[i:i+2]))
+			}
+		}
+	})
+	return res
+}
+
+// QueryTerms 把查询切分成词，并且去重。
+// 和 Tokenize 不同的是，连续的中文只生成二元词，只有单个汉字的时候才用单字
+func QueryTerms(query string) []string {
+	var res []string
+	seen := make(map[string]struct{})
+	add := func(term string) {
+		if _, ok := seen[term]; ok {
+			return
+		}
+		seen[term] = struct{}{}
+		res = append(res, term)
+	}
+	walk(query, add, func(run []rune) {
+		if len(run) == 1 {
+			add(string(run))
+			return
+		}
+		for i := 0; i+1 < len(run); i++ {
+			add(string(run[i : i+2]))
+		}
+	})
+	return res
+}
+
+// walk 遍历文本，onWord 处理英文单词，onCJK 处理一段连续的中日韩文字
+// 标点和空白都会被丢弃
+func walk(text string, onWord func(word string), onCJK func(run []rune)) {
+	var (
+		word strings.Builder
+		cjk  []rune
+	)
+	flushWord := func() {
+		if word.Len() > 0 {
+			onWord(word.String())
+			word.Reset()
+		}
+	}
+	flushCJK := func() {
+		if len(cjk) > 0 {
+			onCJK(cjk)
+			cjk = nil
+		}
+	}
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word.WriteRune(unicode.ToLower(r))
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
@@ -0,0 +1,257 @@
+package textdiff
+
+import (
+	"strings"
+	"unicode"
+)
+
+type Op uint8
+
+const (
+	OpEqual Op = iota
+	OpInsert
+	OpDelete
+)
+
+func (o Op) String() string {
+	switch o {
+	case OpInsert:
+		return "insert"
+	case OpDelete:
+		return "delete"
+	default:
+		return "equal"
+	}
+}
+
+// Edit 是编辑脚本中的一段，相邻的同类操作会被合并
+type Edit struct {
+	Op   Op
+	Text string
+}
+
+// Lines 按行比较，每一行保留行尾的换行符
+func Lines(a, b string) []Edit {
+	return Diff(splitLines(a), splitLines(b))
+}
+
+// Words 按词比较。
+// 英文和数字按照连续的字母数字切分，中文没有空格，所以每个汉字单独作为一个词
+func Words(a, b string) []Edit {
+	return Diff(splitWords(a), splitWords(b))
+}
+
+// Diff 比较两个 token 序列，使用的是 Myers 算法的线性空间版本
+// 这样即便是两篇完全不同的长文章，也不会占用平方级别的内存
+func Diff(a, b []string) []Edit {
+	// 先把 token 映射成 int，后面比较的时候就不用反复比较字符串了
+	ids := make(map[string]int, len(a)+len(b))
+	toIds := func(tokens []string) []int {
+		res := make([]int, len(tokens))
+		for i, t := range tokens {
+			id, ok := ids[t]
+			if !ok {
+				id = len(ids)
+				ids[t] = id
+			}
+			res[i] = id
+		}
+		return res
+	}
+	d := &differ{
+		a:    a,
+		b:    b,
+		aIds: toIds(a),
+		bIds: toIds(b),
+	}
+	d.compare(0, len(a), 0, len(b))
+	return d.edits
+}
+
+type differ struct {
+	a, b       []string
+	aIds, bIds []int
+	edits      []Edit
+}
+
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	// 公共前缀
+	start := aLo
+	for aLo < aHi && bLo < bHi && d.aIds[aLo] == d.bIds[bLo] {
+		aLo++
+		bLo++
+	}
+	d.emit(OpEqual, d.a[start:aLo])
+	// 公共后缀，最后才输出
+	aEnd, bEnd := aHi, bHi
+	for aLo < aEnd && bLo < bEnd && d.aIds[aEnd-1] == d.bIds[bEnd-1] {
+		aEnd--
+		bEnd--
+	}
+
+	switch {
+	case aLo == aEnd:
+		d.emit(OpInsert, d.b[bLo:bEnd])
+	case bLo == bEnd:
+		d.emit(OpDelete, d.a[aLo:aEnd])
+	default:
+		x, y, ok := d.bisect(aLo, aEnd, bLo, bEnd)
+		if ok {
+			d.compare(aLo, x, bLo, y)
+			d.compare(x, aEnd, y, bEnd)
+		} else {
+			d.emit(OpDelete, d.a[aLo:aEnd])
+			d.emit(OpInsert, d.b[bLo:bEnd])
+		}
+	}
+	d.emit(OpEqual, d.a[aEnd:aHi])
+}
+
+// bisect 找到 middle snake，返回的 x, y 是切分点
+func (d *differ) bisect(aLo, aHi, bLo, bHi int) (int, int, bool) {
+	a, b := d.aIds[aLo:aHi], d.bIds[bLo:bHi]
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	vOffset := maxD
+	vLength := 2*maxD + 2
+	v1 := make([]int, vLength)
+	v2 := make([]int, vLength)
+	for i := range v1 {
+		v1[i] = -1
+		v2[i] = -1
+	}
+	v1[vOffset+1] = 0
+	v2[vOffset+1] = 0
+	delta := n - m
+	// 如果 delta 是奇数，那么正向路径会先和反向路径重叠
+	front := delta%2 != 0
+	k1start, k1end, k2start, k2end := 0, 0, 0, 0
+	for dd := 0; dd < maxD; dd++ {
+		// 正向
+		for k1 := -dd + k1start; k1 <= dd-k1end; k1 += 2 {
+			k1Offset := vOffset + k1
+			var x1 int
+			if k1 == -dd || (k1 != dd && v1[k1Offset-1] < v1[k1Offset+1]) {
+				x1 = v1[k1Offset+1]
+			} else {
+				x1 = v1[k1Offset-1] + 1
+			}
+			y1 := x1 - k1
+			for x1 < n && y1 < m && a[x1] == b[y1] {
+				x1++
+				y1++
+			}
+			v1[k1Offset] = x1
+			if x1 > n {
+				k1end += 2
+			} else if y1 > m {
+				k1start += 2
+			} else if front {
+				k2Offset := vOffset + delta - k1
+				if k2Offset >= 0 && k2Offset < vLength && v2[k2Offset] != -1 {
+					if x1 >= n-v2[k2Offset] {
+						return aLo + x1, bLo + y1, true
+					}
+				}
+			}
+		}
+		// 反向
+		for k2 := -dd + k2start; k2 <= dd-k2end; k2 += 2 {
+			k2Offset := vOffset + k2
+			var x2 int
+			if k2 == -dd || (k2 != dd && v2[k2Offset-1] < v2[k2Offset+1]) {
+				x2 = v2[k2Offset+1]
+			} else {
+				x2 = v2[k2Offset-1] + 1
+			}
+			y2 := x2 - k2
+			for x2 < n && y2 < m && a[n-x2-1] == b[m-y2-1] {
+				x2++
+				y2++
+			}
+			v2[k2Offset] = x2
+			if x2 > n {
+				k2end += 2
+			} else if y2 > m {
+				k2start += 2
+			} else if !front {
+				k1Offset := vOffset + delta - k2
+				if k1Offset >= 0 && k1Offset < vLength && v1[k1Offset] != -1 {
+					x1 := v1[k1Offset]
+					y1 := vOffset + x1 - k1Offset
+					if x1 >= n-x2 {
+						return aLo + x1, bLo + y1, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func (d *differ) emit(op Op, tokens []string) {
+	if len(tokens) == 0 {
+		return
+	}
+	text := strings.Join(tokens, "")
+	if l := len(d.edits); l > 0 && d.edits[l-1].Op == op {
+		d.edits[l-1].Text += text
+		return
+	}
+	// 保持先删除后插入的顺序，方便前端展示
+	if l := len(d.edits); op == OpDelete && l > 0 && d.edits[l-1].Op == OpInsert {
+		if l > 1 && d.edits[l-2].Op == OpDelete {
+			d.edits[l-2].Text += text
+			return
+		}
+		ins := d.edits[l-1]
+		d.edits[l-1] = Edit{Op: OpDelete, Text: text}
+		d.edits = append(d.edits, ins)
+		return
+	}
+	d.edits = append(d.edits, Edit{Op: op, Text: text})
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	res := strings.SplitAfter(s, "\n")
+	// 以换行符结尾的时候，最后会多出来一个空字符串
+	if res[len(res)-1] == "" {
+		res = res[:len(res)-1]
+	}
+	return res
+}
+
+func splitWords(s string) []string {
+	var (
+		res   []string
+		start = -1
+	)
+	for i, r := range s {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			res = append(res, s[start:i])
+			start = -1
+		}
+		// 汉字、标点、空白都单独作为一个 token
+		res = append(res, string(r))
+	}
+	if start >= 0 {
+		res = append(res, s[start:])
+	}
+	return res
+}
+
+func isWordRune(r rune) bool {
+	if unicode.Is(unicode.Han, r) {
+		return false
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -0,0 +1,150 @@
+package textdiff
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines(t *testing.T) {
+	testCases := []struct {
+		name string
+		a    string
+		b    string
+		want []Edit
+	}{
+		{
+			name: "完全相同",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+			want: []Edit{{Op: OpEqual, Text: "a\nb\n"}},
+		},
+		{
+			name: "都是空",
+			want: nil,
+		},
+		{
+			name: "新增一行",
+			a:    "a\nc\n",
+			b:    "a\nb\nc\n",
+			want: []Edit{
+				{Op: OpEqual, Text: "a\n"},
+				{Op: OpInsert, Text: "b\n"},
+				{Op: OpEqual, Text: "c\n"},
+			},
+		},
+		{
+			name: "修改一行",
+			a:    "a\nb\nc",
+			b:    "a\nx\nc",
+			want: []Edit{
+				{Op: OpEqual, Text: "a\n"},
+				{Op: OpDelete, Text: "b\n"},
+				{Op: OpInsert, Text: "x\n"},
+				{Op: OpEqual, Text: "c"},
+			},
+		},
+		{
+			name: "全部删除",
+			a:    "a\nb",
+			b:    "",
+			want: []Edit{{Op: OpDelete, Text: "a\nb"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Lines(tc.a, tc.b))
+		})
+	}
+}
+
+func TestWords(t *testing.T) {
+	testCases := []struct {
+		name string
+		a    string
+		b    string
+		want []Edit
+	}{
+		{
+			name: "英文单词",
+			a:    "hello world",
+			b:    "hello go world",
+			want: []Edit{
+				{Op: OpEqual, Text: "hello "},
+				{Op: OpInsert, Text: "go "},
+				{Op: OpEqual, Text: "world"},
+			},
+		},
+		{
+			name: "中文按字切分",
+			a:    "我喜欢写代码",
+			b:    "我讨厌写代码",
+			want: []Edit{
+				{Op: OpEqual, Text: "我"},
+				{Op: OpDelete, Text: "喜欢"},
+				{Op: OpInsert, Text: "讨厌"},
+				{Op: OpEqual, Text: "写代码"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Words(tc.a, tc.b))
+		})
+	}
+}
+
+// TestDiffRandom 随机生成输入，校验编辑脚本能够还原两边的输入，并且是最短的
+func TestDiffRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	gen := func() []string {
+		res := make([]string, r.Intn(20))
+		for i := range res {
+			res[i] = string(rune('a' + r.Intn(4)))
+		}
+		return res
+	}
+	for i := 0; i < 500; i++ {
+		a, b := gen(), gen()
+		edits := Diff(a, b)
+		var src, dst strings.Builder
+		changed := 0
+		for _, e := range edits {
+			switch e.Op {
+			case OpEqual:
+				src.WriteString(e.Text)
+				dst.WriteString(e.Text)
+			case OpDelete:
+				src.WriteString(e.Text)
+				changed += len(e.Text)
+			case OpInsert:
+				dst.WriteString(e.Text)
+				changed += len(e.Text)
+			}
+		}
+		assert.Equal(t, strings.Join(a, ""), src.String())
+		assert.Equal(t, strings.Join(b, ""), dst.String())
+		assert.Equal(t, len(a)+len(b)-2*lcs(a, b), changed)
+	}
+}
+
+func lcs(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				dp[i][j] = dp[i-1][j-1] + 1
+			} else if dp[i-1][j] > dp[i][j-1] {
+				dp[i][j] = dp[i-1][j]
+			} else {
+				dp[i][j] = dp[i][j-1]
+			}
+		}
+	}
+	return dp[len(a)][len(b)]
+}
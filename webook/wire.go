@@ -6,15 +6,20 @@ import (
 	"github.com/google/wire"
 
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/article"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/feed"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/follow"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/interactive"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/job"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
 	article2 "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao"
-	article3 "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao/article"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/web"
 	ijwt "github.com/xiaoshanjiang/my-geektime/webook/internal/web/jwt"
 	"github.com/xiaoshanjiang/my-geektime/webook/ioc"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/outbox"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/rlock"
 )
 
 func InitWebServer() *App {
@@ -22,28 +27,68 @@ func InitWebServer() *App {
 		// 最基础的第三方依赖
 		ioc.InitDB, ioc.InitRedis,
 		ioc.InitLogger,
-		ioc.InitKafka,
+		// 按照配置选择 Kafka 或者内存实现
+		ioc.InitBroker,
 		ioc.NewConsumers,
 		ioc.NewSyncProducer,
+		ioc.NewJobs,
 
 		// consumer
 		article.NewInteractiveReadEventConsumer,
+		article.NewHistoryReadEventConsumer,
+		interactive.NewCntEventConsumer,
+		feed.NewArticlePublishedConsumer,
+		feed.NewArticleWithdrawnConsumer,
+		feed.NewFollowEventConsumer,
+		ioc.InitSearchIndexConsumer,
 		article.NewKafkaProducer,
+		follow.NewKafkaProducer,
+
+		// job
+		job.NewScheduledPublishJob,
+		job.NewSearchIndexJob,
+		job.NewBlobCompensateJob,
+		job.NewRankingJob,
+		rlock.NewClient,
+		job.NewOutboxRelayJob,
+		// 阅读记录的保留期限来自配置
+		ioc.InitReadHistoryCleanJob,
+		outbox.NewRelay,
 
 		// DAO 部分
 		dao.NewGORMUserDAO,
-		article3.NewGORMArticleDAO,
+		// 文章按照配置选择 MySQL 或者 MongoDB
+		ioc.InitArticleDAO,
 		dao.NewGORMInteractiveDAO,
+		dao.NewGORMCommentDAO,
+		dao.NewGORMFollowDAO,
+		dao.NewGORMFeedDAO,
+		dao.NewGORMCollectionDAO,
+		dao.NewGORMReadHistoryDAO,
 
 		// Cache 部分
 		cache.NewRedisInteractiveCache,
 		cache.NewRedisUserCache,
 		cache.NewRedisCodeCache,
+		cache.NewRedisArticleCache,
+		cache.NewRedisCommentCache,
+		cache.NewRedisFollowCache,
+		cache.NewRedisRankingCache,
+		cache.NewRankingLocalCache,
+		cache.NewRedisPasswordResetCache,
 
 		// repository 部分
 		repository.NewCachedUserRepository,
 		repository.NewCachedCodeRepository,
-		repository.NewCachedInteractiveRepository,
+		// 按照配置选择点赞和收藏是不是异步更新计数
+		ioc.InitInteractiveRepository,
+		repository.NewCachedCommentRepository,
+		repository.NewCachedFollowRepository,
+		repository.NewFeedRepository,
+		repository.NewCachedRankingRepository,
+		repository.NewCollectionRepository,
+		repository.NewReadHistoryRepository,
+		repository.NewCachedPasswordResetRepository,
 		article2.NewArticleRepository,
 
 		// service 部分
@@ -51,14 +96,37 @@ func InitWebServer() *App {
 		// 直接基于内存实现
 		ioc.InitSmsMemoryService,
 		ioc.InitWechatService,
+		// 邮件的实现来自配置
+		ioc.InitEmailService,
+		ioc.InitPasswordResetService,
 		service.NewUserService,
 		service.NewSMSCodeService,
 		service.NewArticleService,
+		service.NewSearchService,
+		service.NewInteractiveService,
+		service.NewCommentService,
+		service.NewFollowService,
+		service.NewRankingService,
+		service.NewCollectionService,
+		ioc.InitHistoryService,
+		// feed 的推拉阈值来自配置
+		ioc.InitFeedService,
 
 		// handler 部分
+		ioc.InitJWTKeys,
 		ijwt.NewRedisJWTHandler,
 		web.NewUserHandler,
 		web.NewArticleHandler,
+		web.NewSearchHandler,
+		web.NewCommentHandler,
+		web.NewFollowHandler,
+		web.NewFeedHandler,
+		web.NewRankingHandler,
+		web.NewCollectionHandler,
+		web.NewHistoryHandler,
+		web.NewJWKSHandler,
+		web.NewSessionHandler,
+		web.NewPasswordResetHandler,
 		web.NewOAuth2WechatHandler,
 		// ioc.NewWechatHandlerConfig,
 
@@ -7,16 +7,21 @@
 package main
 
 import (
-	article3 "github.com/xiaoshanjiang/my-geektime/webook/internal/events/article"
+	article2 "github.com/xiaoshanjiang/my-geektime/webook/internal/events/article"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/feed"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/follow"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/events/interactive"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/job"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository"
-	article2 "github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
+	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/article"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/cache"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao"
-	"github.com/xiaoshanjiang/my-geektime/webook/internal/repository/dao/article"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/service"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/web"
 	"github.com/xiaoshanjiang/my-geektime/webook/internal/web/jwt"
 	"github.com/xiaoshanjiang/my-geektime/webook/ioc"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/outbox"
+	"github.com/xiaoshanjiang/my-geektime/webook/pkg/rlock"
 )
 
 import (
@@ -28,7 +33,8 @@ import (
 func InitWebServer() *App {
 	cmdable := ioc.InitRedis()
 	loggerV1 := ioc.InitLogger()
-	handler := jwt.NewRedisJWTHandler(cmdable)
+	keys := ioc.InitJWTKeys(loggerV1)
+	handler := jwt.NewRedisJWTHandler(cmdable, keys)
 	v := ioc.InitMiddlewares(cmdable, loggerV1, handler)
 	db := ioc.InitDB(loggerV1)
 	userDAO := dao.NewGORMUserDAO(db)
@@ -39,25 +45,80 @@ func InitWebServer() *App {
 	codeCache := cache.NewRedisCodeCache(cmdable)
 	codeRepository := repository.NewCachedCodeRepository(codeCache)
 	codeService := service.NewSMSCodeService(smsService, codeRepository)
-	userHandler := web.NewUserHandler(userService, codeService, handler)
+	followDAO := dao.NewGORMFollowDAO(db)
+	followCache := cache.NewRedisFollowCache(cmdable)
+	followRepository := repository.NewCachedFollowRepository(followDAO, followCache, loggerV1)
+	broker := ioc.InitBroker()
+	syncProducer := ioc.NewSyncProducer(broker)
+	producer := follow.NewKafkaProducer(syncProducer)
+	followService := service.NewFollowService(followRepository, userRepository, producer, loggerV1)
+	userHandler := web.NewUserHandler(userService, codeService, followService, handler)
 	wechatService := ioc.InitWechatService(loggerV1)
 	oAuth2WechatHandler := web.NewOAuth2WechatHandler(wechatService, userService, handler)
-	articleDAO := article.NewGORMArticleDAO(db)
-	articleRepository := article2.NewArticleRepository(articleDAO, loggerV1)
-	client := ioc.InitKafka()
-	syncProducer := ioc.NewSyncProducer(client)
-	producer := article3.NewKafkaProducer(syncProducer)
-	articleService := service.NewArticleService(articleRepository, loggerV1, producer)
-	articleHandler := web.NewArticleHandler(articleService, loggerV1)
-	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler)
+	articleDAO := ioc.InitArticleDAO(db)
+	articleCache := cache.NewRedisArticleCache(cmdable)
+	articleRepository := article.NewArticleRepository(articleDAO, articleCache, loggerV1)
+	articleProducer := article2.NewKafkaProducer(syncProducer)
+	articleService := service.NewArticleService(articleRepository, loggerV1, articleProducer)
 	interactiveDAO := dao.NewGORMInteractiveDAO(db)
 	interactiveCache := cache.NewRedisInteractiveCache(cmdable)
-	interactiveRepository := repository.NewCachedInteractiveRepository(interactiveDAO, interactiveCache, loggerV1)
-	interactiveReadEventConsumer := article3.NewInteractiveReadEventConsumer(client, loggerV1, interactiveRepository)
-	v2 := ioc.NewConsumers(interactiveReadEventConsumer)
+	interactiveRepository := ioc.InitInteractiveRepository(interactiveDAO, interactiveCache, loggerV1)
+	commentDAO := dao.NewGORMCommentDAO(db)
+	commentCache := cache.NewRedisCommentCache(cmdable)
+	commentRepository := repository.NewCachedCommentRepository(commentDAO, commentCache, loggerV1)
+	interactiveService := service.NewInteractiveService(interactiveRepository, commentRepository, loggerV1)
+	articleHandler := web.NewArticleHandler(articleService, interactiveService, loggerV1)
+	searchService := service.NewSearchService(articleRepository, loggerV1)
+	searchHandler := web.NewSearchHandler(searchService, loggerV1)
+	commentService := service.NewCommentService(commentRepository, articleRepository, loggerV1)
+	commentHandler := web.NewCommentHandler(commentService, loggerV1)
+	followHandler := web.NewFollowHandler(followService, loggerV1)
+	feedDAO := dao.NewGORMFeedDAO(db)
+	feedRepository := repository.NewFeedRepository(feedDAO)
+	feedService := ioc.InitFeedService(feedRepository, followRepository, articleRepository, loggerV1)
+	feedHandler := web.NewFeedHandler(feedService, loggerV1)
+	redisRankingCache := cache.NewRedisRankingCache(cmdable)
+	rankingLocalCache := cache.NewRankingLocalCache()
+	rankingRepository := repository.NewCachedRankingRepository(redisRankingCache, rankingLocalCache)
+	rankingService := service.NewRankingService(articleRepository, interactiveRepository, rankingRepository)
+	rankingHandler := web.NewRankingHandler(rankingService, interactiveService, loggerV1)
+	collectionDAO := dao.NewGORMCollectionDAO(db)
+	collectionRepository := repository.NewCollectionRepository(collectionDAO)
+	collectionService := service.NewCollectionService(collectionRepository, interactiveRepository, articleRepository, loggerV1)
+	collectionHandler := web.NewCollectionHandler(collectionService, loggerV1)
+	readHistoryDAO := dao.NewGORMReadHistoryDAO(db)
+	readHistoryRepository := repository.NewReadHistoryRepository(readHistoryDAO)
+	historyService := ioc.InitHistoryService(readHistoryRepository, interactiveRepository, articleRepository, loggerV1)
+	historyHandler := web.NewHistoryHandler(historyService, loggerV1)
+	jwksHandler := web.NewJWKSHandler(handler)
+	sessionHandler := web.NewSessionHandler(handler, loggerV1)
+	passwordResetCache := cache.NewRedisPasswordResetCache(cmdable)
+	passwordResetRepository := repository.NewCachedPasswordResetRepository(passwordResetCache)
+	emailService := ioc.InitEmailService()
+	passwordResetService := ioc.InitPasswordResetService(userRepository, passwordResetRepository, emailService, loggerV1)
+	passwordResetHandler := web.NewPasswordResetHandler(passwordResetService, handler, loggerV1)
+	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler, searchHandler, commentHandler, followHandler, feedHandler, rankingHandler, collectionHandler, historyHandler, jwksHandler, sessionHandler, passwordResetHandler)
+	interactiveReadEventConsumer := article2.NewInteractiveReadEventConsumer(broker, loggerV1, interactiveRepository)
+	articlePublishedConsumer := feed.NewArticlePublishedConsumer(broker, syncProducer, loggerV1, feedService)
+	followEventConsumer := feed.NewFollowEventConsumer(broker, syncProducer, loggerV1, feedService)
+	cntEventConsumer := interactive.NewCntEventConsumer(broker, syncProducer, loggerV1, interactiveRepository)
+	historyReadEventConsumer := article2.NewHistoryReadEventConsumer(broker, loggerV1, readHistoryRepository)
+	articleEventConsumer := ioc.InitSearchIndexConsumer(broker, loggerV1, searchService)
+	articleWithdrawnConsumer := feed.NewArticleWithdrawnConsumer(broker, syncProducer, loggerV1, feedService)
+	v2 := ioc.NewConsumers(interactiveReadEventConsumer, articlePublishedConsumer, followEventConsumer, cntEventConsumer, historyReadEventConsumer, articleEventConsumer, articleWithdrawnConsumer)
+	scheduledPublishJob := job.NewScheduledPublishJob(articleService, loggerV1)
+	searchIndexJob := job.NewSearchIndexJob(searchService, loggerV1)
+	blobCompensateJob := job.NewBlobCompensateJob(articleDAO, loggerV1)
+	client := rlock.NewClient(cmdable)
+	rankingJob := job.NewRankingJob(rankingService, client, loggerV1)
+	relay := outbox.NewRelay(db, syncProducer, loggerV1)
+	outboxRelayJob := job.NewOutboxRelayJob(relay, loggerV1)
+	readHistoryCleanJob := ioc.InitReadHistoryCleanJob(readHistoryRepository, loggerV1)
+	v3 := ioc.NewJobs(scheduledPublishJob, searchIndexJob, blobCompensateJob, rankingJob, outboxRelayJob, readHistoryCleanJob)
 	app := &App{
 		web:       engine,
 		consumers: v2,
+		jobs:      v3,
 	}
 	return app
 }